@@ -3,11 +3,15 @@ package cli
 import (
 	"fmt"
 	"os"
+	"time"
 	clp "github.com/urfave/cli"
 	"github.com/opwire/opwire-testa/lib/bootstrap"
 	"github.com/opwire/opwire-testa/lib/utils"
 )
 
+const NO_VERSION_CHECK_ENV string = "OPWIRE_TESTA_NO_VERSION_CHECK"
+const CI_ENV string = "CI"
+
 type Commander struct {
 	app *clp.App
 }
@@ -42,7 +46,7 @@ func NewCommander(manifest Manifest) (*Commander, error) {
 			Usage: "Directories contain test suite files",
 		},
 		clp.StringSliceFlag{
-			Name: "incl-files, i",
+			Name: "incl-files, test-file, i",
 			Usage: "File inclusion patterns",
 		},
 		clp.StringSliceFlag{
@@ -63,6 +67,103 @@ func NewCommander(manifest Manifest) (*Commander, error) {
 		},
 	}
 
+	testRunnerFlags := []clp.Flag{
+		clp.Float64Flag{
+			Name: "rate-limit",
+			Usage: "Maximum number of requests per second",
+		},
+		clp.DurationFlag{
+			Name: "request-delay",
+			Usage: "Fixed delay between two requests (e.g. 200ms)",
+		},
+		clp.StringFlag{
+			Name: "max-response-size",
+			Usage: "Maximum size of a response body (e.g. 512KB, 10MB)",
+		},
+		clp.DurationFlag{
+			Name: "clock-skew",
+			Usage: "Allowed clock skew for the time-based assertions (e.g. 2s)",
+		},
+		clp.BoolFlag{
+			Name: "http3",
+			Usage: "Send the requests over HTTP/3 (experimental)",
+		},
+		clp.BoolFlag{
+			Name: "soft-assertions",
+			Usage: "Evaluate every matcher of an expectation and list all of the failures",
+		},
+		clp.BoolFlag{
+			Name: "strict-deprecations",
+			Usage: "Fail the testcases which use deprecated fields",
+		},
+		clp.IntFlag{
+			Name: "breaker-threshold",
+			Usage: "Skip the remaining testcases after N consecutive connection errors (0: disabled)",
+		},
+		clp.DurationFlag{
+			Name: "slow-threshold",
+			Usage: "Warn about the testcases slower than this duration (e.g. 2s)",
+		},
+		clp.IntFlag{
+			Name: "max-warnings",
+			Value: -1,
+			Usage: "Fail the run when the number of warnings exceeds N (-1: unlimited)",
+		},
+		clp.BoolFlag{
+			Name: "allow-destructive",
+			Usage: "Run the destructive test cases against the approved targets",
+		},
+		clp.BoolFlag{
+			Name: "i-know-what-im-doing",
+			Usage: "Run the destructive test cases against any target",
+		},
+		clp.StringSliceFlag{
+			Name: "matcher-plugin",
+			Usage: "Go plugin (.so) registering custom matchers",
+		},
+		clp.IntFlag{
+			Name: "concurrency",
+			Usage: "Number of test suite files examined in parallel (default: 1)",
+		},
+		clp.StringFlag{
+			Name: "env",
+			Usage: "Named environment of the configuration file (e.g. staging)",
+		},
+		clp.IntFlag{
+			Name: "retry-failed",
+			Usage: "Re-run the failed testcases up to N times, those passing are reported as flaky",
+		},
+		clp.BoolFlag{
+			Name: "shuffle",
+			Usage: "Run the test suites and their testcases in a random order",
+		},
+		clp.Int64Flag{
+			Name: "seed",
+			Usage: "Seed of the random order of --shuffle, to reproduce an order (0: random)",
+		},
+		clp.IntFlag{
+			Name: "shard-index",
+			Usage: "Index (1..shard-total) of the shard of testcases run by this job",
+		},
+		clp.IntFlag{
+			Name: "shard-total",
+			Usage: "Number of the shards which the testcases are partitioned into",
+		},
+		clp.StringFlag{
+			Name: "report",
+			Usage: "Path of the JSON report of the run (merged by the 'report merge' command)",
+		},
+		clp.Int64Flag{
+			Name: "faker-seed",
+			Usage: "Seed of the fake data functions, to reproduce the payloads (0: random)",
+		},
+	}
+
+	dryRunFlag := clp.BoolFlag{
+		Name: "dry-run",
+		Usage: "Display the testcases which would run and their requests, without sending them",
+	}
+
 	app := clp.NewApp()
 	app.Name = "opwire-testa"
 	app.Usage = "Testing toolkit for opwire-agent"
@@ -73,15 +174,238 @@ func NewCommander(manifest Manifest) (*Commander, error) {
 			Name: "run",
 			Aliases: []string{"start"},
 			Usage: "Run tests",
-			Flags: append([]clp.Flag{}, testSourceFlags...),
+			Flags: append(append([]clp.Flag{
+				dryRunFlag,
+				clp.BoolFlag{
+					Name: "watch",
+					Usage: "Run the testcases of the changed test suite files again, until interrupted",
+				},
+			}, testSourceFlags...), testRunnerFlags...),
 			Action: func(c *clp.Context) error {
 				o := readScriptSourceFlags(manifest, c)
+				if _, err := readTestRunnerFlags(o, c); err != nil {
+					return err
+				}
+				o.DryRun = c.Bool("dry-run")
+				o.Watch = c.Bool("watch")
 				ctl, err := bootstrap.NewRunController(o)
 				if err != nil {
 					return err
 				}
-				ctl.Execute(&CmdRunFlags{})
-				return nil
+				return ctl.Execute(&CmdRunFlags{})
+			},
+		},
+		{
+			Name: "bundle",
+			Usage: "Pack testing scripts into a portable archive and run it",
+			Subcommands: []clp.Command{
+				{
+					Name: "pack",
+					Usage: "Pack the files of the test directories into a single archive",
+					Flags: append([]clp.Flag{
+						clp.StringFlag{
+							Name: "output, o",
+							Usage: "Path of the archive file (default: opwire-testa-bundle.zip)",
+						},
+						clp.StringSliceFlag{
+							Name: "matcher-plugin",
+							Usage: "Go plugin (.so) registering custom matchers, packed with the scripts",
+						},
+					}, testSourceFlags...),
+					Action: func(c *clp.Context) error {
+						o := readScriptSourceFlags(manifest, c)
+						o.MatcherPlugins = c.StringSlice("matcher-plugin")
+						ctl, err := bootstrap.NewBunController(o)
+						if err != nil {
+							return err
+						}
+						f := new(CmdBunFlags)
+						f.Output = c.String("output")
+						return ctl.Pack(f)
+					},
+				},
+				{
+					Name: "run",
+					Usage: "Run the testing scripts of an archive",
+					ArgsUsage: "<bundle-file>",
+					Flags: append(append([]clp.Flag{ dryRunFlag }, testSourceFlags...), testRunnerFlags...),
+					Action: func(c *clp.Context) error {
+						o := readScriptSourceFlags(manifest, c)
+						if _, err := readTestRunnerFlags(o, c); err != nil {
+							return err
+						}
+						o.DryRun = c.Bool("dry-run")
+						if len(o.ConfigPath) > 0 || len(o.MatcherPlugins) > 0 {
+							return fmt.Errorf("The configuration file and the plugins are packed into the bundle, --config-path and --matcher-plugin must not be given")
+						}
+						bun, err := bootstrap.NewBunController(o)
+						if err != nil {
+							return err
+						}
+						unpacked, cleanup, err := bun.Unpack(c.Args().First())
+						if err != nil {
+							return err
+						}
+						// the bundle replaces the configuration and the plugins of the host
+						o.TestDirs = unpacked.TestDirs
+						o.ConfigPath = unpacked.Config
+						o.MatcherPlugins = unpacked.Plugins
+						ctl, err := bootstrap.NewRunController(o)
+						if err != nil {
+							cleanup()
+							return err
+						}
+						ctl.AddCleanup(cleanup)
+						return ctl.Execute(&CmdRunFlags{})
+					},
+				},
+			},
+		},
+		{
+			Name: "tags",
+			Usage: "Manage the tags of testing scripts",
+			Subcommands: []clp.Command{
+				{
+					Name: "add",
+					Usage: "Add a tag to the selected test cases",
+					ArgsUsage: "<tag>",
+					Flags: append([]clp.Flag{
+						clp.BoolFlag{
+							Name: "dry-run",
+							Usage: "Display the changes without rewriting the files",
+						},
+					}, testSourceFlags...),
+					Action: func(c *clp.Context) error {
+						o := readScriptSourceFlags(manifest, c)
+						ctl, err := bootstrap.NewTagController(o)
+						if err != nil {
+							return err
+						}
+						f := new(CmdTagFlags)
+						f.Tag = c.Args().First()
+						f.DryRun = c.Bool("dry-run")
+						return ctl.Add(f)
+					},
+				},
+				{
+					Name: "list",
+					Usage: "List the tags of the selected test cases",
+					Flags: append([]clp.Flag{
+						clp.BoolFlag{
+							Name: "count",
+							Usage: "Display the number of test cases per tag",
+						},
+					}, testSourceFlags...),
+					Action: func(c *clp.Context) error {
+						o := readScriptSourceFlags(manifest, c)
+						ctl, err := bootstrap.NewTagController(o)
+						if err != nil {
+							return err
+						}
+						f := new(CmdTagFlags)
+						f.Count = c.Bool("count")
+						return ctl.List(f)
+					},
+				},
+			},
+		},
+		{
+			Name: "report",
+			Usage: "Manage the JSON reports of the runs",
+			Subcommands: []clp.Command{
+				{
+					Name: "merge",
+					Usage: "Combine the reports of the shards of a run into one report",
+					ArgsUsage: "<report-file>...",
+					Flags: []clp.Flag{
+						clp.StringFlag{
+							Name: "config-path, c",
+							Usage: "Path to configuration file (the key verifying and signing the reports)",
+						},
+						clp.StringFlag{
+							Name: "output, o",
+							Usage: "Path of the combined report (default: opwire-testa-report.json)",
+						},
+						clp.BoolFlag{
+							Name: "no-color",
+							Usage: "Display output in plain text, without color",
+						},
+					},
+					Action: func(c *clp.Context) error {
+						o := &ControllerOptions{ manifest: manifest }
+						o.NoColor = c.Bool("no-color")
+						ctl, err := bootstrap.NewRptController(o)
+						if err != nil {
+							return err
+						}
+						f := new(CmdRptFlags)
+						f.ConfigPath = c.String("config-path")
+						f.Output = c.String("output")
+						f.Reports = c.Args()
+						return ctl.Merge(f)
+					},
+				},
+				{
+					Name: "verify",
+					Usage: "Verify the signature of a report with the key of the configuration file",
+					ArgsUsage: "<report-file>",
+					Flags: []clp.Flag{
+						clp.StringFlag{
+							Name: "config-path, c",
+							Usage: "Path to configuration file",
+						},
+						clp.BoolFlag{
+							Name: "no-color",
+							Usage: "Display output in plain text, without color",
+						},
+					},
+					Action: func(c *clp.Context) error {
+						o := &ControllerOptions{ manifest: manifest }
+						o.NoColor = c.Bool("no-color")
+						ctl, err := bootstrap.NewRptController(o)
+						if err != nil {
+							return err
+						}
+						f := new(CmdRptFlags)
+						f.ConfigPath = c.String("config-path")
+						f.Reports = c.Args()
+						return ctl.Verify(f)
+					},
+				},
+			},
+		},
+		{
+			Name: "snapshot",
+			Usage: "Maintain the snapshot generated test cases",
+			Subcommands: []clp.Command{
+				{
+					Name: "drift",
+					Usage: "Re-execute the snapshot tests and report the drifted expectations",
+					Flags: append(append([]clp.Flag{
+						clp.BoolFlag{
+							Name: "prune",
+							Usage: "Remove the test cases of the endpoints which respond 404",
+						},
+						clp.BoolFlag{
+							Name: "dry-run",
+							Usage: "Display the pruned test cases without rewriting the files",
+						},
+					}, testSourceFlags...), testRunnerFlags...),
+					Action: func(c *clp.Context) error {
+						o := readScriptSourceFlags(manifest, c)
+						if _, err := readTestRunnerFlags(o, c); err != nil {
+							return err
+						}
+						ctl, err := bootstrap.NewSnpController(o)
+						if err != nil {
+							return err
+						}
+						f := new(CmdSnpFlags)
+						f.Prune = c.Bool("prune")
+						f.DryRun = c.Bool("dry-run")
+						return ctl.Drift(f)
+					},
+				},
 			},
 		},
 		{
@@ -156,11 +480,137 @@ func NewCommander(manifest Manifest) (*Commander, error) {
 				},
 			},
 		},
+		{
+			Name: "demo-server",
+			Usage: "Start a demo server for learning and examples",
+			Flags: []clp.Flag{
+				clp.StringFlag{
+					Name: "host",
+					Usage: "Host name or IP address to listen on",
+				},
+				clp.IntFlag{
+					Name: "port, p",
+					Usage: "Port number to listen on",
+				},
+				clp.BoolFlag{
+					Name: "no-color",
+					Usage: "Display output in plain text, without color",
+				},
+			},
+			Action: func(c *clp.Context) error {
+				o := &ControllerOptions{ manifest: manifest }
+				o.NoColor = c.Bool("no-color")
+				o.Host = c.String("host")
+				o.Port = c.Int("port")
+				ctl, err := bootstrap.NewDemoController(o)
+				if err != nil {
+					return err
+				}
+				ctl.Execute(&CmdDemoFlags{})
+				return nil
+			},
+		},
+		{
+			Name: "init",
+			Usage: "Create an example project with testing scripts",
+			Flags: []clp.Flag{
+				clp.StringFlag{
+					Name: "target-dir, d",
+					Usage: "Directory where the example project is created",
+				},
+				clp.BoolFlag{
+					Name: "force",
+					Usage: "Overwrite existing files",
+				},
+				clp.BoolFlag{
+					Name: "no-color",
+					Usage: "Display output in plain text, without color",
+				},
+			},
+			Action: func(c *clp.Context) error {
+				o := &ControllerOptions{ manifest: manifest }
+				o.NoColor = c.Bool("no-color")
+				ctl, err := bootstrap.NewInitController(o)
+				if err != nil {
+					return err
+				}
+				f := new(CmdInitFlags)
+				f.TargetDir = c.String("target-dir")
+				f.Force = c.Bool("force")
+				ctl.Execute(f)
+				return nil
+			},
+		},
+		{
+			Name: "migrate",
+			Usage: "Upgrade testing scripts to the current format",
+			Flags: append([]clp.Flag{
+				clp.BoolFlag{
+					Name: "dry-run",
+					Usage: "Display the changes without rewriting the files",
+				},
+			}, testSourceFlags...),
+			Action: func(c *clp.Context) error {
+				o := readScriptSourceFlags(manifest, c)
+				ctl, err := bootstrap.NewMigController(o)
+				if err != nil {
+					return err
+				}
+				f := new(CmdMigFlags)
+				f.DryRun = c.Bool("dry-run")
+				return ctl.Execute(f)
+			},
+		},
+		{
+			Name: "self-update",
+			Usage: "Update opwire-testa to the latest release",
+			Flags: []clp.Flag{
+				clp.BoolFlag{
+					Name: "check",
+					Usage: "Only display the latest version",
+				},
+				clp.BoolFlag{
+					Name: "no-color",
+					Usage: "Display output in plain text, without color",
+				},
+			},
+			Action: func(c *clp.Context) error {
+				o := &ControllerOptions{ manifest: manifest }
+				o.NoColor = c.Bool("no-color")
+				ctl, err := bootstrap.NewUpdController(o)
+				if err != nil {
+					return err
+				}
+				f := new(CmdUpdFlags)
+				f.CheckOnly = c.Bool("check")
+				return ctl.Execute(f)
+			},
+		},
 		{
 			Name: "help",
 			Usage: "Shows a list of commands or help for one command",
 		},
 	}
+	app.Before = func(c *clp.Context) error {
+		// the notice is displayed to the interactive sessions, never on the
+		// path of the test runs (nor of the CI pipelines)
+		switch c.Args().First() {
+		case "self-update", "run":
+			return nil
+		case "bundle":
+			if c.Args().Get(1) == "run" {
+				return nil
+			}
+		}
+		if len(os.Getenv(NO_VERSION_CHECK_ENV)) > 0 || len(os.Getenv(CI_ENV)) > 0 || !utils.IsTerminal(os.Stdout) {
+			return nil
+		}
+		if ctl, err := bootstrap.NewUpdController(&ControllerOptions{ manifest: manifest }); err == nil {
+			ctl.Notify()
+		}
+		return nil
+	}
+
 	c.app = app
 	return c, nil
 }
@@ -184,6 +634,47 @@ func readScriptSourceFlags(manifest Manifest, c *clp.Context) *ControllerOptions
 	return o
 }
 
+func readTestRunnerFlags(o *ControllerOptions, c *clp.Context) (*ControllerOptions, error) {
+	var err error
+	o.RateLimit = c.Float64("rate-limit")
+	o.RequestDelay = c.Duration("request-delay")
+	o.MaxResponseSize, err = utils.ParseByteSize(c.String("max-response-size"))
+	if err != nil {
+		return o, err
+	}
+	o.ClockSkew = c.Duration("clock-skew")
+	o.Http3 = c.Bool("http3")
+	o.SoftAssertions = c.Bool("soft-assertions")
+	o.StrictDeprecations = c.Bool("strict-deprecations")
+	o.BreakerThreshold = c.Int("breaker-threshold")
+	o.SlowThreshold = c.Duration("slow-threshold")
+	o.MaxWarnings = c.Int("max-warnings")
+	o.AllowDestructive = c.Bool("allow-destructive")
+	o.ForceDestructive = c.Bool("i-know-what-im-doing")
+	o.MatcherPlugins = c.StringSlice("matcher-plugin")
+	o.Concurrency = c.Int("concurrency")
+	if o.Concurrency < 0 {
+		return o, fmt.Errorf("Invalid concurrency [%d], a positive number is expected", o.Concurrency)
+	}
+	o.Environment = c.String("env")
+	o.RetryFailed = c.Int("retry-failed")
+	if o.RetryFailed < 0 {
+		return o, fmt.Errorf("Invalid retry-failed [%d], a positive number is expected", o.RetryFailed)
+	}
+	o.FakerSeed = c.Int64("faker-seed")
+	o.Shuffle = c.Bool("shuffle")
+	o.Seed = c.Int64("seed")
+	o.ShardIndex = c.Int("shard-index")
+	o.ShardTotal = c.Int("shard-total")
+	if o.ShardTotal > 0 || o.ShardIndex > 0 {
+		if o.ShardTotal < 1 || o.ShardIndex < 1 || o.ShardIndex > o.ShardTotal {
+			return o, fmt.Errorf("Invalid shard [%d/%d], expected --shard-index between 1 and --shard-total", o.ShardIndex, o.ShardTotal)
+		}
+	}
+	o.ReportPath = c.String("report")
+	return o, nil
+}
+
 type Manifest interface {
 	GetRevision() string
 	GetVersion() string
@@ -198,6 +689,32 @@ type ControllerOptions struct {
 	TestName string
 	Tags []string
 	NoColor bool
+	RateLimit float64
+	RequestDelay time.Duration
+	MaxResponseSize int64
+	ClockSkew time.Duration
+	Http3 bool
+	SoftAssertions bool
+	StrictDeprecations bool
+	BreakerThreshold int
+	SlowThreshold time.Duration
+	MaxWarnings int
+	AllowDestructive bool
+	ForceDestructive bool
+	MatcherPlugins []string
+	Concurrency int
+	Environment string
+	FakerSeed int64
+	RetryFailed int
+	Shuffle bool
+	Seed int64
+	ShardIndex int
+	ShardTotal int
+	ReportPath string
+	DryRun bool
+	Watch bool
+	Host string
+	Port int
 	manifest Manifest
 }
 
@@ -229,6 +746,110 @@ func (a *ControllerOptions) GetNoColor() bool {
 	return a.NoColor
 }
 
+func (a *ControllerOptions) GetRateLimit() float64 {
+	return a.RateLimit
+}
+
+func (a *ControllerOptions) GetRequestDelay() time.Duration {
+	return a.RequestDelay
+}
+
+func (a *ControllerOptions) GetMaxResponseSize() int64 {
+	return a.MaxResponseSize
+}
+
+func (a *ControllerOptions) GetClockSkew() time.Duration {
+	return a.ClockSkew
+}
+
+func (a *ControllerOptions) GetHttp3() bool {
+	return a.Http3
+}
+
+func (a *ControllerOptions) GetSoftAssertions() bool {
+	return a.SoftAssertions
+}
+
+func (a *ControllerOptions) GetStrictDeprecations() bool {
+	return a.StrictDeprecations
+}
+
+func (a *ControllerOptions) GetBreakerThreshold() int {
+	return a.BreakerThreshold
+}
+
+func (a *ControllerOptions) GetSlowThreshold() time.Duration {
+	return a.SlowThreshold
+}
+
+func (a *ControllerOptions) GetMaxWarnings() int {
+	return a.MaxWarnings
+}
+
+func (a *ControllerOptions) GetAllowDestructive() bool {
+	return a.AllowDestructive
+}
+
+func (a *ControllerOptions) GetForceDestructive() bool {
+	return a.ForceDestructive
+}
+
+func (a *ControllerOptions) GetMatcherPlugins() []string {
+	return a.MatcherPlugins
+}
+
+func (a *ControllerOptions) GetConcurrency() int {
+	return a.Concurrency
+}
+
+func (a *ControllerOptions) GetEnvironment() string {
+	return a.Environment
+}
+
+func (a *ControllerOptions) GetFakerSeed() int64 {
+	return a.FakerSeed
+}
+
+func (a *ControllerOptions) GetRetryFailed() int {
+	return a.RetryFailed
+}
+
+func (a *ControllerOptions) GetShuffle() bool {
+	return a.Shuffle
+}
+
+func (a *ControllerOptions) GetSeed() int64 {
+	return a.Seed
+}
+
+func (a *ControllerOptions) GetShardIndex() int {
+	return a.ShardIndex
+}
+
+func (a *ControllerOptions) GetShardTotal() int {
+	return a.ShardTotal
+}
+
+func (a *ControllerOptions) GetReportPath() string {
+	return a.ReportPath
+}
+
+func (a *ControllerOptions) GetDryRun() bool {
+	return a.DryRun
+}
+
+func (a *ControllerOptions) GetWatch() bool {
+	return a.Watch
+}
+
+func (a *ControllerOptions) GetHost() string {
+	return a.Host
+}
+
+func (a *ControllerOptions) GetPort() int {
+	return a.Port
+}
+
 func (a *ControllerOptions) GetVersion() string {
 	if a.manifest == nil {
 		return ""
@@ -280,3 +901,92 @@ type CmdRunFlags struct {
 
 type CmdGenFlags struct {
 }
+
+type CmdBunFlags struct {
+	Output string
+}
+
+func (f *CmdBunFlags) GetOutput() string {
+	return f.Output
+}
+
+type CmdTagFlags struct {
+	Tag string
+	DryRun bool
+	Count bool
+}
+
+func (f *CmdTagFlags) GetTag() string {
+	return f.Tag
+}
+
+func (f *CmdTagFlags) GetDryRun() bool {
+	return f.DryRun
+}
+
+func (f *CmdTagFlags) GetCount() bool {
+	return f.Count
+}
+
+type CmdSnpFlags struct {
+	Prune bool
+	DryRun bool
+}
+
+func (f *CmdSnpFlags) GetPrune() bool {
+	return f.Prune
+}
+
+func (f *CmdSnpFlags) GetDryRun() bool {
+	return f.DryRun
+}
+
+type CmdRptFlags struct {
+	ConfigPath string
+	Output string
+	Reports []string
+}
+
+func (f *CmdRptFlags) GetConfigPath() string {
+	return f.ConfigPath
+}
+
+func (f *CmdRptFlags) GetOutput() string {
+	return f.Output
+}
+
+func (f *CmdRptFlags) GetReports() []string {
+	return f.Reports
+}
+
+type CmdDemoFlags struct {
+}
+
+type CmdInitFlags struct {
+	TargetDir string
+	Force bool
+}
+
+func (f *CmdInitFlags) GetTargetDir() string {
+	return f.TargetDir
+}
+
+func (f *CmdInitFlags) GetForce() bool {
+	return f.Force
+}
+
+type CmdUpdFlags struct {
+	CheckOnly bool
+}
+
+func (f *CmdUpdFlags) GetCheckOnly() bool {
+	return f.CheckOnly
+}
+
+type CmdMigFlags struct {
+	DryRun bool
+}
+
+func (f *CmdMigFlags) GetDryRun() bool {
+	return f.DryRun
+}
@@ -0,0 +1,143 @@
+package bootstrap
+
+import(
+	"fmt"
+	"path/filepath"
+	"github.com/opwire/opwire-testa/lib/bundle"
+	"github.com/opwire/opwire-testa/lib/config"
+	"github.com/opwire/opwire-testa/lib/format"
+	"github.com/opwire/opwire-testa/lib/script"
+	"github.com/opwire/opwire-testa/lib/storage"
+	"github.com/opwire/opwire-testa/lib/tag"
+	"github.com/opwire/opwire-testa/lib/utils"
+)
+
+type BunControllerOptions interface {
+	script.Source
+	GetConfigPath() string
+	GetMatcherPlugins() []string
+	GetVersion() string
+	GetNoColor() bool
+}
+
+type BunController struct {
+	configPath string
+	matcherPlugins []string
+	scriptSelector *script.Selector
+	scriptSource script.Source
+	packer *bundle.Packer
+	tagManager *tag.Manager
+	outputPrinter *format.OutputPrinter
+}
+
+func NewBunController(opts BunControllerOptions) (ref *BunController, err error) {
+	ref = &BunController{}
+	if opts != nil {
+		ref.configPath = opts.GetConfigPath()
+		ref.matcherPlugins = opts.GetMatcherPlugins()
+	}
+
+	// testing temporary storage
+	ref.scriptSource, err = script.NewSource(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// create a Script Selector instance
+	ref.scriptSelector, err = script.NewSelector(ref.scriptSource)
+	if err != nil {
+		return nil, err
+	}
+
+	// create a Packer instance
+	ref.packer, err = bundle.NewPacker(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// create a Manager instance
+	ref.tagManager, err = tag.NewManager(ref.scriptSource)
+	if err != nil {
+		return nil, err
+	}
+
+	// create a OutputPrinter instance
+	ref.outputPrinter, err = format.NewOutputPrinter(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return ref, err
+}
+
+type BunPackArguments interface {
+	GetOutput() string
+}
+
+func (r *BunController) Pack(args BunPackArguments) error {
+	output := "opwire-testa-bundle.zip"
+	if args != nil && len(args.GetOutput()) > 0 {
+		output = args.GetOutput()
+	}
+
+	r.outputPrinter.Println()
+	r.outputPrinter.Println(r.outputPrinter.Heading("Context"))
+	printScriptSourceArgs(r.outputPrinter, r.scriptSource, r.scriptSelector, r.tagManager)
+
+	r.outputPrinter.Println()
+	r.outputPrinter.Println(r.outputPrinter.Heading("Packing"))
+	// the configuration file is found as the run command does
+	configPath := r.configPath
+	if len(configPath) == 0 {
+		if defaultPath := filepath.Join(utils.FindWorkingDir(), config.DEFAULT_CONFIG_FILE); utils.IsFile(defaultPath) {
+			configPath = defaultPath
+		}
+	}
+	manifest, err := r.packer.Pack(&bundle.Sources{
+		TestDirs: r.scriptSource.GetTestDirs(),
+		ConfigPath: configPath,
+		Plugins: r.matcherPlugins,
+	}, output)
+	if err != nil {
+		r.outputPrinter.Println(r.outputPrinter.Section(err.Error()))
+		r.outputPrinter.Println()
+		return err
+	}
+	r.outputPrinter.Println(r.outputPrinter.ContextInfo("Bundle", output))
+	r.outputPrinter.Println(r.outputPrinter.ContextInfo("Files", fmt.Sprintf("%d", manifest.Files)))
+	r.outputPrinter.Println()
+	return nil
+}
+
+// Unpack extracts a bundle into a temporary workspace, the returned function
+// removes the workspace once the run is completed. A bundle without
+// configuration file is given an empty one, the configuration of the host is
+// never used.
+func (r *BunController) Unpack(source string) (*bundle.Manifest, func(), error) {
+	if len(source) == 0 {
+		return nil, nil, fmt.Errorf("The bundle file must be provided")
+	}
+	workspace, err := storage.NewWorkspace()
+	if err != nil {
+		return nil, nil, err
+	}
+	manifest, err := r.packer.Unpack(source, workspace.GetPath())
+	if err != nil {
+		workspace.Cleanup()
+		return nil, nil, err
+	}
+	if len(manifest.Config) == 0 {
+		manifest.Config = filepath.Join(workspace.GetPath(), config.DEFAULT_CONFIG_FILE)
+		if utils.IsFile(manifest.Config) {
+			workspace.Cleanup()
+			return nil, nil, fmt.Errorf("The bundle has an unlisted %s file", config.DEFAULT_CONFIG_FILE)
+		}
+		file, err := storage.GetFs().Create(manifest.Config)
+		if err != nil {
+			workspace.Cleanup()
+			return nil, nil, err
+		}
+		file.Close()
+	}
+	return manifest, func() { workspace.Cleanup() }, nil
+}
@@ -0,0 +1,55 @@
+package bootstrap
+
+import (
+	"github.com/opwire/opwire-testa/lib/demo"
+	"github.com/opwire/opwire-testa/lib/format"
+)
+
+type DemoControllerOptions interface {
+	demo.ServerOptions
+	GetNoColor() bool
+}
+
+type DemoController struct {
+	server *demo.Server
+	outputPrinter *format.OutputPrinter
+}
+
+func NewDemoController(opts DemoControllerOptions) (ref *DemoController, err error) {
+	ref = &DemoController{}
+
+	// create a demo Server instance
+	ref.server, err = demo.NewServer(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// create a OutputPrinter instance
+	ref.outputPrinter, err = format.NewOutputPrinter(opts)
+	if err != nil {
+		return nil, err
+	}
+	ref.server.SetLogWriter(ref.outputPrinter.GetWriter())
+
+	return ref, err
+}
+
+type DemoArguments interface {}
+
+func (r *DemoController) Execute(args DemoArguments) error {
+	r.outputPrinter.Println()
+	r.outputPrinter.Println(r.outputPrinter.Heading("Demo server"))
+	r.outputPrinter.Println(r.outputPrinter.ContextInfo("Listening on", "http://" + r.server.GetAddress()))
+	r.outputPrinter.Println(r.outputPrinter.ContextInfo("Endpoints", "",
+		"/echo: returns the received request as a JSON object",
+		"/status/{code}: responds with the given status code",
+		"/delay/{duration}: responds after the given duration (e.g. 500ms)",
+		"/format/{json|yaml|text}: responds with a document in the given format",
+	))
+	r.outputPrinter.Println()
+	err := r.server.ListenAndServe()
+	if err != nil {
+		r.outputPrinter.Println(r.outputPrinter.ContextInfo("Error", err.Error()))
+	}
+	return err
+}
@@ -0,0 +1,83 @@
+package bootstrap
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"github.com/opwire/opwire-testa/lib/client"
+	"github.com/opwire/opwire-testa/lib/script"
+	"github.com/opwire/opwire-testa/lib/utils"
+)
+
+// planTestSuites prints the test cases which the run would examine, selected
+// by the same filters, with the requests resolved against the environment, but
+// sends no request (neither the lifecycle hooks). The prerequisites of the
+// planned test cases are considered as passed.
+func (r *RunController) planTestSuites(descriptors map[string]*script.Descriptor, startTime time.Time) {
+	r.outputPrinter.Println()
+	r.outputPrinter.Println(r.outputPrinter.Heading("Dry run"))
+
+	for _, path := range r.orderDescriptors(descriptors) {
+		descriptor := descriptors[path]
+		if descriptor.TestSuite == nil {
+			continue
+		}
+		file := descriptor.Locator.RelativePath
+		r.outputPrinter.Println(r.outputPrinter.TestSuiteTitle(file))
+		cases, _ := r.orderTestCases(descriptor)
+		for _, testcase := range cases {
+			tagstr, ok := r.checkTestCase(file, testcase)
+			if !ok {
+				continue
+			}
+			req, err := r.specHandler.Resolve(testcase, descriptor.TestSuite.GetResultCache())
+			r.outputPrinter.Println(r.outputPrinter.TestCase(testcase.Title), tagstr)
+			r.outputPrinter.Println(r.outputPrinter.Section(describeRequest(req, err)))
+			r.counter.Planned += 1
+			r.recordResult(file, testcase, RESULT_PLANNED, nil, nil)
+		}
+	}
+
+	r.outputPrinter.Println()
+	r.outputPrinter.Println(r.outputPrinter.Heading("Summary"))
+	total := r.counter.Planned + r.counter.Pending + r.counter.Skipped + r.counter.Unreachable
+	r.outputPrinter.Printf("[*] Total: %d test case(s), in %d file(s)", total, len(descriptors))
+	r.outputPrinter.Println()
+	r.outputPrinter.Printf("[*] Planned: %d, Pending: %d, Skipped: %d", r.counter.Planned, r.counter.Pending, r.counter.Skipped)
+	r.outputPrinter.Println()
+	duration := time.Since(startTime)
+	r.closeResultSinks(&RunSummary{
+		Files: len(descriptors),
+		Total: total,
+		Pending: r.counter.Pending,
+		Skipped: r.counter.Skipped,
+		Unreachable: r.counter.Unreachable,
+		Warnings: r.warnings,
+		Duration: duration,
+	})
+	r.outputPrinter.Printf("[*] Elapsed time: %s", duration.String())
+	r.outputPrinter.Println()
+	r.outputPrinter.Println()
+}
+
+// describeRequest returns the method and the URL of a resolved request, the
+// sensitive values masked. The expressions which cannot be evaluated before
+// the run (e.g. the captured variables) are displayed as written.
+func describeRequest(req *client.HttpRequest, err error) string {
+	method := "GET"
+	if len(req.Method) > 0 {
+		method = strings.ToUpper(req.Method)
+	}
+	target := client.BuildUrl(req)
+	if err != nil {
+		if unescaped, err := url.PathUnescape(target); err == nil {
+			target = unescaped
+		}
+	}
+	text := utils.Redact(fmt.Sprintf("%s %s", method, target), req.GetSensitiveValues(), client.SENSITIVE_MASK)
+	if err != nil {
+		text = text + "\n" + err.Error()
+	}
+	return text
+}
@@ -0,0 +1,41 @@
+package bootstrap
+
+import (
+	"net/http"
+	"github.com/opwire/opwire-testa/lib/client"
+	"github.com/opwire/opwire-testa/lib/config"
+	"github.com/opwire/opwire-testa/lib/engine"
+)
+
+// environmentOptions overrides the PDP, the default headers and the transport
+// of the spec handlers with the settings of the selected environment.
+type environmentOptions struct {
+	RunControllerOptions
+	environment *config.Environment
+	transport http.RoundTripper
+}
+
+func newEnvironmentOptions(opts RunControllerOptions, environment *config.Environment) (*environmentOptions, error) {
+	transport, err := environment.NewTransport()
+	if err != nil {
+		return nil, err
+	}
+	if transport == nil {
+		if provider, ok := opts.(engine.TransportProvider); ok {
+			transport = provider.GetTransport()
+		}
+	}
+	return &environmentOptions{ RunControllerOptions: opts, environment: environment, transport: transport }, nil
+}
+
+func (o *environmentOptions) GetPDP() string {
+	return o.environment.PDP
+}
+
+func (o *environmentOptions) GetDefaultHeaders() []client.HttpHeader {
+	return o.environment.Headers
+}
+
+func (o *environmentOptions) GetTransport() http.RoundTripper {
+	return o.transport
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/opwire/opwire-testa/lib/format"
 	"github.com/opwire/opwire-testa/lib/script"
 	"github.com/opwire/opwire-testa/lib/tag"
+	"github.com/opwire/opwire-testa/lib/utils"
 )
 
 type GenControllerOptions interface {
@@ -141,12 +142,27 @@ func (r *GenController) Execute(args GenArguments) error {
 type CurlGenerator struct {}
 
 func (g *CurlGenerator) generateCommand(w io.Writer, req *client.HttpRequest) error {
+	secrets := req.GetSensitiveValues()
 	fmt.Fprintf(w, "curl \\\n")
 	fmt.Fprintf(w, "  --request %s \\\n", req.Method)
-	fmt.Fprintf(w, "  --url \"%s\" \\\n", client.BuildUrl(req))
+	fmt.Fprintf(w, "  --url \"%s\" \\\n", utils.Redact(client.BuildUrl(req), secrets, client.SENSITIVE_MASK))
 	for _, header := range req.Headers {
-		fmt.Fprintf(w, "  --header '%s: %s' \\\n", header.Name, header.Value)
+		value := header.Value
+		if header.Sensitive {
+			value = client.SENSITIVE_MASK
+		}
+		fmt.Fprintf(w, "  --header '%s: %s' \\\n", header.Name, value)
+	}
+	if len(req.IdempotencyKey) > 0 {
+		key := req.IdempotencyKey
+		if key == client.IDEMPOTENCY_KEY_AUTO || key == client.IDEMPOTENCY_KEY_AUTO_PER_ATTEMPT {
+			key, _ = utils.GenerateUUID()
+		}
+		fmt.Fprintf(w, "  --header 'Idempotency-Key: %s' \\\n", key)
+	}
+	if req.ExpectContinue != nil && *req.ExpectContinue {
+		fmt.Fprintf(w, "  --header 'Expect: 100-continue' \\\n")
 	}
-	fmt.Fprintf(w, "  --data='%s'\n", req.Body)
+	fmt.Fprintf(w, "  --data='%s'\n", utils.Redact(req.Body, secrets, client.SENSITIVE_MASK))
 	return nil
 }
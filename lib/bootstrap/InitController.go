@@ -0,0 +1,66 @@
+package bootstrap
+
+import (
+	"github.com/opwire/opwire-testa/lib/format"
+	"github.com/opwire/opwire-testa/lib/tutorial"
+	"github.com/opwire/opwire-testa/lib/utils"
+)
+
+type InitControllerOptions interface {
+	GetNoColor() bool
+}
+
+type InitController struct {
+	outputPrinter *format.OutputPrinter
+}
+
+func NewInitController(opts InitControllerOptions) (ref *InitController, err error) {
+	ref = &InitController{}
+
+	// create a OutputPrinter instance
+	ref.outputPrinter, err = format.NewOutputPrinter(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return ref, err
+}
+
+type InitArguments interface {
+	tutorial.GeneratorOptions
+	GetTargetDir() string
+}
+
+func (r *InitController) Execute(args InitArguments) error {
+	generator, err := tutorial.NewGenerator(args)
+	if err != nil {
+		return err
+	}
+
+	targetDir := args.GetTargetDir()
+	if len(targetDir) == 0 {
+		targetDir = utils.FindWorkingDir()
+	}
+
+	r.outputPrinter.Println()
+	r.outputPrinter.Println(r.outputPrinter.Heading("Generating"))
+
+	for _, outcome := range generator.Generate(targetDir) {
+		path, _ := utils.DetectRelativePath(outcome.Path)
+		if outcome.Created {
+			r.outputPrinter.Println(r.outputPrinter.Success(path))
+		} else {
+			r.outputPrinter.Println(r.outputPrinter.Skipped(path))
+			if outcome.Error != nil {
+				r.outputPrinter.Println(r.outputPrinter.Section(outcome.Error.Error()))
+			}
+		}
+	}
+
+	r.outputPrinter.Println()
+	r.outputPrinter.Println(r.outputPrinter.Heading("Next steps"))
+	r.outputPrinter.Println(r.outputPrinter.ContextInfo("Start the demo server", "opwire-testa demo-server"))
+	r.outputPrinter.Println(r.outputPrinter.ContextInfo("Run the examples", "opwire-testa run --env=demo --tags=+example"))
+	r.outputPrinter.Println()
+	return nil
+}
@@ -0,0 +1,317 @@
+package bootstrap
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"github.com/opwire/opwire-testa/lib/client"
+	"github.com/opwire/opwire-testa/lib/storage"
+	"github.com/opwire/opwire-testa/lib/utils"
+)
+
+const DEFAULT_REPORT_PATH string = "opwire-testa-report.json"
+const REPORT_SIGNATURE_ALGORITHM string = "hmac-sha256"
+
+// JsonReport is the document written by --report, the reports of the shards
+// of a run are combined by the "report merge" command.
+type JsonReport struct {
+	Shard *ReportShard `json:"shard,omitempty"`
+	Summary ReportSummary `json:"summary"`
+	TestCases []*ReportTestCase `json:"testcases"`
+	Provenance *ReportProvenance `json:"provenance,omitempty"`
+	MergedFrom []*ReportProvenance `json:"merged-from,omitempty"`
+	Signature *ReportSignature `json:"signature,omitempty"`
+}
+
+type ReportShard struct {
+	Index int `json:"index"`
+	Total int `json:"total"`
+}
+
+type ReportSummary struct {
+	Files int `json:"files"`
+	Total int `json:"total"`
+	Pending int `json:"pending"`
+	Skipped int `json:"skipped"`
+	Cracked int `json:"cracked"`
+	Failed int `json:"failed"`
+	Passed int `json:"passed"`
+	Flaky int `json:"flaky"`
+	Unreachable int `json:"unreachable"`
+	Warnings map[string]int `json:"warnings,omitempty"`
+	DurationMs int64 `json:"duration-ms"`
+}
+
+type ReportTestCase struct {
+	File string `json:"file"`
+	Title string `json:"title"`
+	Status string `json:"status"`
+	DurationMs int64 `json:"duration-ms"`
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// ReportProvenance identifies the machine, the user, the testing scripts and
+// the tool of a signed report.
+type ReportProvenance struct {
+	Hostname string `json:"hostname"`
+	User string `json:"user"`
+	Commit string `json:"commit,omitempty"`
+	Version string `json:"version,omitempty"`
+}
+
+// ReportSignature is the HMAC of the report, computed without the signature
+// itself, with the key of the configuration file.
+type ReportSignature struct {
+	Algorithm string `json:"algorithm"`
+	Value string `json:"value"`
+}
+
+// jsonReportSink collects the records of a run and writes the report when the
+// run is closed, the sensitive values of the errors are masked.
+type jsonReportSink struct {
+	path string
+	report *JsonReport
+	provenance *ReportProvenance
+	signingKey []byte
+}
+
+func newJsonReportSink(path string, shard *ReportShard) *jsonReportSink {
+	return &jsonReportSink{ path: path, report: &JsonReport{ Shard: shard, TestCases: make([]*ReportTestCase, 0) } }
+}
+
+// signWith embeds the provenance into the report and signs it with the key.
+func (s *jsonReportSink) signWith(key []byte, provenance *ReportProvenance) {
+	s.signingKey = key
+	s.provenance = provenance
+}
+
+func (s *jsonReportSink) Record(record *TestRecord) error {
+	testcase := &ReportTestCase{ File: record.File, Status: record.Status }
+	if record.TestCase != nil {
+		testcase.Title = record.TestCase.Title
+	}
+	if record.Result != nil {
+		testcase.DurationMs = record.Result.Duration.Milliseconds()
+		secrets := collectSensitiveValues(record.TestCase, record.Result)
+		for key, err := range record.Result.Errors {
+			if testcase.Errors == nil {
+				testcase.Errors = make(map[string]string, 0)
+			}
+			testcase.Errors[key] = utils.Redact(err.Error(), secrets, client.SENSITIVE_MASK)
+		}
+	}
+	s.report.TestCases = append(s.report.TestCases, testcase)
+	return nil
+}
+
+func (s *jsonReportSink) Close(summary *RunSummary) error {
+	s.report.Summary = ReportSummary{
+		Files: summary.Files,
+		Total: summary.Total,
+		Pending: summary.Pending,
+		Skipped: summary.Skipped,
+		Cracked: summary.Cracked,
+		Failed: summary.Failed,
+		Passed: summary.Passed,
+		Flaky: summary.Flaky,
+		Unreachable: summary.Unreachable,
+		Warnings: summary.Warnings,
+		DurationMs: summary.Duration.Milliseconds(),
+	}
+	if s.signingKey != nil {
+		s.report.Provenance = s.provenance
+		if err := signJsonReport(s.report, s.signingKey); err != nil {
+			return err
+		}
+	}
+	err := writeJsonReport(s.path, s.report)
+	// the next run of watch mode writes its own test cases
+	s.report.TestCases = make([]*ReportTestCase, 0)
+	return err
+}
+
+func readJsonReport(path string) (*JsonReport, error) {
+	file, err := storage.GetFs().Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	content, err := ioutil.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	report := &JsonReport{}
+	if err := json.Unmarshal(content, report); err != nil {
+		return nil, fmt.Errorf("Invalid report [%s]: %s", path, err.Error())
+	}
+	return report, nil
+}
+
+func writeJsonReport(path string, report *JsonReport) error {
+	content, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	file, err := storage.GetFs().Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(content)
+	return err
+}
+
+// signJsonReport computes the signature of the report, any change of the
+// written document (the provenance included) invalidates it.
+func signJsonReport(report *JsonReport, key []byte) error {
+	value, err := computeReportSignature(report, key)
+	if err != nil {
+		return err
+	}
+	report.Signature = &ReportSignature{ Algorithm: REPORT_SIGNATURE_ALGORITHM, Value: value }
+	return nil
+}
+
+func verifyJsonReport(report *JsonReport, key []byte) error {
+	signature := report.Signature
+	if signature == nil {
+		return fmt.Errorf("The report is not signed")
+	}
+	if signature.Algorithm != REPORT_SIGNATURE_ALGORITHM {
+		return fmt.Errorf("Unsupported signature algorithm [%s]", signature.Algorithm)
+	}
+	expected, err := computeReportSignature(report, key)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal([]byte(expected), []byte(signature.Value)) {
+		return fmt.Errorf("The signature of the report is mismatched, the report has been modified or signed with another key")
+	}
+	return nil
+}
+
+func computeReportSignature(report *JsonReport, key []byte) (string, error) {
+	unsigned := *report
+	unsigned.Signature = nil
+	content, err := json.Marshal(&unsigned)
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(content)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// collectProvenance describes the machine and the user of the run, the commit
+// is the one of the git repository of the testing scripts (if any).
+func collectProvenance(testDirs []string, version string) *ReportProvenance {
+	provenance := &ReportProvenance{ Version: version }
+	provenance.Hostname, _ = os.Hostname()
+	provenance.User, _ = utils.FindUsername()
+	if len(testDirs) > 0 {
+		if output, err := exec.Command("git", "-C", testDirs[0], "rev-parse", "HEAD").Output(); err == nil {
+			provenance.Commit = strings.TrimSpace(string(output))
+		}
+	}
+	return provenance
+}
+
+// mergeJsonReports combines the reports of the shards of a run: the counters
+// are added, the files are counted once (every shard loads all of them) and
+// the duration is the one of the longest shard. The shards must be complete.
+// With a key, every report must be signed and is verified, the provenances of
+// the shards are kept by the merged report (to be signed in turn).
+func mergeJsonReports(reports []*JsonReport, key []byte) (*JsonReport, error) {
+	merged := &JsonReport{ TestCases: make([]*ReportTestCase, 0) }
+	if err := checkShards(reports); err != nil {
+		return nil, err
+	}
+	for i, report := range reports {
+		if report.Signature == nil {
+			// the merged report is signed, so are all of the shards
+			if key != nil {
+				return nil, fmt.Errorf("Report [%d] is not signed", i + 1)
+			}
+			continue
+		}
+		if key == nil {
+			return nil, fmt.Errorf("Report [%d] is signed, the report-signing key of the configuration is required to verify it", i + 1)
+		}
+		if err := verifyJsonReport(report, key); err != nil {
+			return nil, fmt.Errorf("Report [%d] is not verified: %s", i + 1, err.Error())
+		}
+		if report.Provenance != nil {
+			merged.MergedFrom = append(merged.MergedFrom, report.Provenance)
+		}
+	}
+	for _, report := range reports {
+		summary := report.Summary
+		if summary.Files > merged.Summary.Files {
+			merged.Summary.Files = summary.Files
+		}
+		merged.Summary.Total += summary.Total
+		merged.Summary.Pending += summary.Pending
+		merged.Summary.Skipped += summary.Skipped
+		merged.Summary.Cracked += summary.Cracked
+		merged.Summary.Failed += summary.Failed
+		merged.Summary.Passed += summary.Passed
+		merged.Summary.Flaky += summary.Flaky
+		merged.Summary.Unreachable += summary.Unreachable
+		for category, count := range summary.Warnings {
+			if merged.Summary.Warnings == nil {
+				merged.Summary.Warnings = make(map[string]int, 0)
+			}
+			merged.Summary.Warnings[category] += count
+		}
+		if summary.DurationMs > merged.Summary.DurationMs {
+			merged.Summary.DurationMs = summary.DurationMs
+		}
+		merged.TestCases = append(merged.TestCases, report.TestCases...)
+	}
+	sort.SliceStable(merged.TestCases, func(i, j int) bool {
+		return merged.TestCases[i].File < merged.TestCases[j].File
+	})
+	return merged, nil
+}
+
+// checkShards verifies that the reports are the shards of a single run, each
+// of them given once.
+func checkShards(reports []*JsonReport) error {
+	total := 0
+	shards := make(map[int]bool, 0)
+	for _, report := range reports {
+		if report.Shard == nil {
+			if len(reports) > 1 {
+				return fmt.Errorf("A report without shard cannot be merged with other reports")
+			}
+			return nil
+		}
+		if total == 0 {
+			total = report.Shard.Total
+		}
+		if report.Shard.Total != total {
+			return fmt.Errorf("Shard [%d/%d] does not belong to a run of %d shards", report.Shard.Index, report.Shard.Total, total)
+		}
+		if shards[report.Shard.Index] {
+			return fmt.Errorf("Shard [%d/%d] is given twice", report.Shard.Index, report.Shard.Total)
+		}
+		shards[report.Shard.Index] = true
+	}
+	missing := make([]string, 0)
+	for index := 1; index <= total; index++ {
+		if !shards[index] {
+			missing = append(missing, fmt.Sprintf("%d/%d", index, total))
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("Shards [%s] are missing", strings.Join(missing, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package bootstrap
+
+import(
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_mergeJsonReports(t *testing.T) {
+	first := &JsonReport{
+		Shard: &ReportShard{ Index: 1, Total: 2 },
+		Summary: ReportSummary{ Files: 2, Total: 2, Passed: 1, Failed: 1, Warnings: map[string]int{ "slow": 1 }, DurationMs: 300 },
+		TestCases: []*ReportTestCase{
+			{ File: "b.yml", Title: "Read", Status: RESULT_PASSED },
+			{ File: "a.yml", Title: "Create", Status: RESULT_FAILED },
+		},
+	}
+	second := &JsonReport{
+		Shard: &ReportShard{ Index: 2, Total: 2 },
+		Summary: ReportSummary{ Files: 2, Total: 1, Flaky: 1, Warnings: map[string]int{ "slow": 2 }, DurationMs: 500 },
+		TestCases: []*ReportTestCase{
+			{ File: "a.yml", Title: "Delete", Status: RESULT_FLAKY },
+		},
+	}
+
+	merged, err := mergeJsonReports([]*JsonReport{ first, second }, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, ReportSummary{ Files: 2, Total: 3, Passed: 1, Failed: 1, Flaky: 1, Warnings: map[string]int{ "slow": 3 }, DurationMs: 500 }, merged.Summary)
+	titles := make([]string, 0)
+	for _, testcase := range merged.TestCases {
+		titles = append(titles, testcase.Title)
+	}
+	assert.Equal(t, []string{ "Create", "Delete", "Read" }, titles)
+
+	_, err = mergeJsonReports([]*JsonReport{ first, first }, nil)
+	assert.EqualError(t, err, "Shard [1/2] is given twice")
+
+	_, err = mergeJsonReports([]*JsonReport{ first }, nil)
+	assert.EqualError(t, err, "Shards [2/2] are missing")
+
+	third := &JsonReport{ Shard: &ReportShard{ Index: 2, Total: 3 } }
+	_, err = mergeJsonReports([]*JsonReport{ first, third }, nil)
+	assert.EqualError(t, err, "Shard [2/3] does not belong to a run of 2 shards")
+
+	_, err = mergeJsonReports([]*JsonReport{ first, second, &JsonReport{} }, nil)
+	assert.NotNil(t, err)
+}
+
+func Test_mergeJsonReports_Signed(t *testing.T) {
+	key := []byte("secret")
+	newShard := func(index int) *JsonReport {
+		report := &JsonReport{
+			Shard: &ReportShard{ Index: index, Total: 2 },
+			Summary: ReportSummary{ Files: 1, Total: 1, Passed: 1 },
+			TestCases: []*ReportTestCase{
+				{ File: "a.yml", Title: fmt.Sprintf("Case %d", index), Status: RESULT_PASSED },
+			},
+			Provenance: &ReportProvenance{ Hostname: fmt.Sprintf("runner-%d", index), User: "ci" },
+		}
+		assert.Nil(t, signJsonReport(report, key))
+		return report
+	}
+
+	t.Run("Signed shards are verified", func(t *testing.T) {
+		first, second := newShard(1), newShard(2)
+		merged, err := mergeJsonReports([]*JsonReport{ first, second }, key)
+		assert.Nil(t, err)
+		assert.Equal(t, []*ReportProvenance{ first.Provenance, second.Provenance }, merged.MergedFrom)
+		assert.Equal(t, 2, merged.Summary.Passed)
+	})
+
+	t.Run("Signed shards require the key", func(t *testing.T) {
+		_, err := mergeJsonReports([]*JsonReport{ newShard(1), newShard(2) }, nil)
+		assert.EqualError(t, err, "Report [1] is signed, the report-signing key of the configuration is required to verify it")
+	})
+
+	t.Run("Tampered shards are rejected", func(t *testing.T) {
+		second := newShard(2)
+		second.Summary.Passed = 0
+		second.Summary.Failed = 1
+		_, err := mergeJsonReports([]*JsonReport{ newShard(1), second }, key)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Unsigned shards are rejected with the key", func(t *testing.T) {
+		second := newShard(2)
+		second.Signature = nil
+		_, err := mergeJsonReports([]*JsonReport{ newShard(1), second }, key)
+		assert.EqualError(t, err, "Report [2] is not signed")
+	})
+}
+
+func Test_signJsonReport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "opwire-testa-report-")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "report.json")
+
+	key := []byte("s3cr3t")
+	report := &JsonReport{
+		Summary: ReportSummary{ Files: 1, Total: 2, Passed: 1, Failed: 1, Warnings: map[string]int{ "slow": 1, "deprecated": 2 } },
+		TestCases: []*ReportTestCase{
+			{ File: "a.yml", Title: "Create", Status: RESULT_PASSED },
+			{ File: "a.yml", Title: "Read", Status: RESULT_FAILED, Errors: map[string]string{ "StatusCode": "500", "Body": "empty" } },
+		},
+		Provenance: &ReportProvenance{ Hostname: "ci-runner", User: "builder", Commit: "0a1b2c", Version: "1.0.0" },
+	}
+	assert.Nil(t, signJsonReport(report, key))
+	assert.Equal(t, REPORT_SIGNATURE_ALGORITHM, report.Signature.Algorithm)
+	assert.Nil(t, writeJsonReport(path, report))
+
+	t.Run("Verify the written report", func(t *testing.T) {
+		written, err := readJsonReport(path)
+		assert.Nil(t, err)
+		assert.Nil(t, verifyJsonReport(written, key))
+		assert.NotNil(t, verifyJsonReport(written, []byte("another")))
+	})
+
+	t.Run("Tampered reports", func(t *testing.T) {
+		written, err := readJsonReport(path)
+		assert.Nil(t, err)
+		written.TestCases[1].Status = RESULT_PASSED
+		assert.NotNil(t, verifyJsonReport(written, key))
+
+		written, err = readJsonReport(path)
+		assert.Nil(t, err)
+		written.Provenance.Hostname = "laptop"
+		assert.NotNil(t, verifyJsonReport(written, key))
+
+		written.Signature = nil
+		assert.EqualError(t, verifyJsonReport(written, key), "The report is not signed")
+	})
+}
+
+func Test_collectProvenance(t *testing.T) {
+	provenance := collectProvenance(nil, "1.0.0")
+	hostname, _ := os.Hostname()
+	assert.Equal(t, hostname, provenance.Hostname)
+	assert.Equal(t, "1.0.0", provenance.Version)
+	assert.Equal(t, "", provenance.Commit)
+}
@@ -0,0 +1,78 @@
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+	"github.com/opwire/opwire-testa/lib/config"
+	"github.com/opwire/opwire-testa/lib/engine"
+	"github.com/opwire/opwire-testa/lib/sieve"
+)
+
+// runLifecycleHooks executes the before-all or after-all hooks of the
+// configuration in order, it stops at the first failure.
+func (r *RunController) runLifecycleHooks(heading string, hooks []*config.LifecycleHook, cache *sieve.RestCache) error {
+	if len(hooks) == 0 {
+		return nil
+	}
+	r.outputPrinter.Println()
+	r.outputPrinter.Println(r.outputPrinter.Heading(heading))
+	for _, hook := range hooks {
+		if hook == nil {
+			continue
+		}
+		startTime := time.Now()
+		errs := r.runLifecycleHook(hook, cache)
+		exectime := printDuration(r.outputPrinter, time.Since(startTime))
+		if len(errs) > 0 {
+			var secrets []string
+			if hook.Request != nil {
+				secrets = hook.Request.GetSensitiveValues()
+			}
+			r.outputPrinter.Println(r.outputPrinter.Failure(hook.Title), exectime)
+			r.printErrorMap(errs, secrets)
+			return fmt.Errorf("%s hook [%s] failed", heading, hook.Title)
+		}
+		r.outputPrinter.Println(r.outputPrinter.Success(hook.Title), exectime)
+	}
+	return nil
+}
+
+func (r *RunController) runLifecycleHook(hook *config.LifecycleHook, cache *sieve.RestCache) map[string]error {
+	if len(hook.Exec) > 0 {
+		if err := execLifecycleCommand(hook); err != nil {
+			return map[string]error{ "Exec": err }
+		}
+		return nil
+	}
+	testcase := &engine.TestCase{ Title: hook.Title, Request: hook.Request, Expectation: hook.Expectation, Capture: hook.Capture }
+	result, _ := r.specHandler.Examine(testcase, cache, nil)
+	return result.Errors
+}
+
+func execLifecycleCommand(hook *config.LifecycleHook) error {
+	ctx := context.Background()
+	if hook.Timeout != nil {
+		timeout, err := time.ParseDuration(*hook.Timeout)
+		if err != nil {
+			return fmt.Errorf("Invalid timeout [%s]: %s", *hook.Timeout, err.Error())
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	output, err := exec.CommandContext(ctx, hook.Exec[0], hook.Exec[1:]...).CombinedOutput()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("Command [%s] timed out after %s", strings.Join(hook.Exec, " "), *hook.Timeout)
+	}
+	if err != nil {
+		text := strings.TrimSpace(string(output))
+		if len(text) > 0 {
+			return fmt.Errorf("Command [%s] failed: %s\n%s", strings.Join(hook.Exec, " "), err.Error(), text)
+		}
+		return fmt.Errorf("Command [%s] failed: %s", strings.Join(hook.Exec, " "), err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package bootstrap
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/opwire/opwire-testa/lib/format"
+	"github.com/opwire/opwire-testa/lib/script"
+	"github.com/opwire/opwire-testa/lib/storage"
+	"github.com/opwire/opwire-testa/lib/tag"
+)
+
+const MIGRATING_FILE_EXT string = ".migrating"
+
+type MigControllerOptions interface {
+	script.Source
+	GetVersion() string
+	GetNoColor() bool
+}
+
+type MigController struct {
+	scriptLoader *script.Loader
+	scriptSelector *script.Selector
+	scriptSource script.Source
+	scriptMigrator *script.Migrator
+	tagManager *tag.Manager
+	outputPrinter *format.OutputPrinter
+}
+
+func NewMigController(opts MigControllerOptions) (ref *MigController, err error) {
+	ref = &MigController{}
+
+	// testing temporary storage
+	ref.scriptSource, err = script.NewSource(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// create a Script Loader instance
+	ref.scriptLoader, err = script.NewLoader(ref.scriptSource)
+	if err != nil {
+		return nil, err
+	}
+
+	// create a Script Selector instance
+	ref.scriptSelector, err = script.NewSelector(ref.scriptSource)
+	if err != nil {
+		return nil, err
+	}
+
+	// create a Script Migrator instance
+	ref.scriptMigrator, err = script.NewMigrator(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// create a Manager instance
+	ref.tagManager, err = tag.NewManager(ref.scriptSource)
+	if err != nil {
+		return nil, err
+	}
+
+	// create a OutputPrinter instance
+	ref.outputPrinter, err = format.NewOutputPrinter(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return ref, err
+}
+
+type MigArguments interface {
+	GetDryRun() bool
+}
+
+func (r *MigController) Execute(args MigArguments) error {
+	// display environment of command
+	r.outputPrinter.Println()
+	r.outputPrinter.Println(r.outputPrinter.Heading("Context"))
+	printScriptSourceArgs(r.outputPrinter, r.scriptSource, r.scriptSelector, r.tagManager)
+
+	dryRun := args != nil && args.GetDryRun()
+	if dryRun {
+		r.outputPrinter.Println(r.outputPrinter.ContextInfo("Dry run", "enabled"))
+	}
+
+	// Load testing script files from "test-dirs", invalid ones included
+	descriptors := r.scriptLoader.Load()
+
+	// filter testing script files by "inclusive-files"
+	descriptors = filterDescriptorsByInclusivePatterns(descriptors, r.scriptSource.GetInclFiles())
+
+	// filter testing script files by "exclusive-files"
+	descriptors = filterDescriptorsByExclusivePatterns(descriptors, r.scriptSource.GetExclFiles())
+
+	r.outputPrinter.Println()
+	r.outputPrinter.Println(r.outputPrinter.Heading("Migrating"))
+
+	failures := 0
+	for _, d := range descriptors {
+		r.outputPrinter.Println(r.outputPrinter.TestSuiteTitle(d.Locator.RelativePath))
+		if err := r.migrateFile(d.Locator, dryRun); err != nil {
+			r.outputPrinter.Println(r.outputPrinter.Section(err.Error()))
+			failures += 1
+		}
+	}
+
+	r.outputPrinter.Println()
+	if failures > 0 {
+		return fmt.Errorf("Migrating %d file(s) failed", failures)
+	}
+	return nil
+}
+
+func (r *MigController) migrateFile(locator *script.Locator, dryRun bool) error {
+	fs := storage.GetFs()
+
+	file, err := fs.Open(locator.AbsolutePath)
+	if err != nil {
+		return err
+	}
+	content, err := ioutil.ReadAll(file)
+	file.Close()
+	if err != nil {
+		return err
+	}
+
+	output, changes, err := r.scriptMigrator.Migrate(content)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		r.outputPrinter.Println(r.outputPrinter.Section("Up to date"))
+		return nil
+	}
+	r.outputPrinter.Println(r.outputPrinter.Section(strings.Join(changes, "\n")))
+
+	if dryRun {
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A: difflib.SplitLines(string(content)),
+			B: difflib.SplitLines(string(output)),
+			FromFile: locator.RelativePath,
+			ToFile: locator.RelativePath + " (migrated)",
+			Context: 3,
+		})
+		if err != nil {
+			return err
+		}
+		r.outputPrinter.Println(diff)
+		return nil
+	}
+
+	// the migrated content is written beside the file, then replaces it, so
+	// that a failed write never leaves the file truncated
+	temp := locator.AbsolutePath + MIGRATING_FILE_EXT
+	target, err := fs.Create(temp)
+	if err != nil {
+		return err
+	}
+	_, err = target.Write(output)
+	if closeErr := target.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = fs.Rename(temp, locator.AbsolutePath)
+	}
+	if err != nil {
+		fs.RemoveAll(temp)
+	}
+	return err
+}
@@ -0,0 +1,51 @@
+package bootstrap
+
+import (
+	"time"
+	"github.com/opwire/opwire-testa/lib/engine"
+)
+
+const RESULT_PASSED string = "passed"
+const RESULT_FAILED string = "failed"
+const RESULT_CRACKED string = "cracked"
+const RESULT_PENDING string = "pending"
+const RESULT_SKIPPED string = "skipped"
+const RESULT_UNREACHABLE string = "unreachable"
+const RESULT_FLAKY string = "flaky"
+const RESULT_PLANNED string = "planned"
+
+// ResultSink receives the outcomes of a run, programs embedding the testkit
+// implement it to store the results in their own systems (databases, queues).
+type ResultSink interface {
+	// Record is called once per test case, in the order of the report.
+	Record(record *TestRecord) error
+	// Close is called with the summary at the end of the run.
+	Close(summary *RunSummary) error
+}
+
+// TestRecord holds the outcome of a test case. The Result is nil for the test
+// cases which are not examined (pending, skipped, unreachable). The sensitive
+// values are masked in the Result and the Error, the TestCase is the one of
+// the script.
+type TestRecord struct {
+	File string
+	TestCase *engine.TestCase
+	Status string
+	Result *engine.ExaminationResult
+	Error error
+	Time time.Time
+}
+
+type RunSummary struct {
+	Files int
+	Total int
+	Pending int
+	Skipped int
+	Cracked int
+	Failed int
+	Passed int
+	Flaky int
+	Unreachable int
+	Warnings map[string]int
+	Duration time.Duration
+}
@@ -0,0 +1,158 @@
+package bootstrap
+
+import (
+	"fmt"
+	"time"
+	"github.com/opwire/opwire-testa/lib/config"
+	"github.com/opwire/opwire-testa/lib/format"
+)
+
+type RptControllerOptions interface {
+	GetNoColor() bool
+	GetVersion() string
+}
+
+type RptController struct {
+	outputPrinter *format.OutputPrinter
+	version string
+}
+
+func NewRptController(opts RptControllerOptions) (ref *RptController, err error) {
+	ref = &RptController{}
+	if opts != nil {
+		ref.version = opts.GetVersion()
+	}
+
+	// create a OutputPrinter instance
+	ref.outputPrinter, err = format.NewOutputPrinter(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return ref, err
+}
+
+type RptMergeArguments interface {
+	GetConfigPath() string
+	GetOutput() string
+	GetReports() []string
+}
+
+// Merge combines the reports written by the shards of a run into one report.
+func (r *RptController) Merge(args RptMergeArguments) error {
+	if args == nil || len(args.GetReports()) == 0 {
+		return fmt.Errorf("The report files must be provided")
+	}
+	output := args.GetOutput()
+	if len(output) == 0 {
+		output = DEFAULT_REPORT_PATH
+	}
+
+	reports := make([]*JsonReport, 0)
+	for _, path := range args.GetReports() {
+		report, err := readJsonReport(path)
+		if err != nil {
+			return err
+		}
+		reports = append(reports, report)
+	}
+	key, err := loadSigningKey(args.GetConfigPath())
+	if err != nil {
+		return err
+	}
+	merged, err := mergeJsonReports(reports, key)
+	if err != nil {
+		return err
+	}
+	if key != nil {
+		merged.Provenance = collectProvenance(nil, r.version)
+		if err := signJsonReport(merged, key); err != nil {
+			return err
+		}
+	}
+	if err := writeJsonReport(output, merged); err != nil {
+		return err
+	}
+
+	r.outputPrinter.Println()
+	r.outputPrinter.Println(r.outputPrinter.Heading("Summary"))
+	summary := merged.Summary
+	r.outputPrinter.Printf("[*] Total: %d test case(s), in %d file(s), from %d report(s)", summary.Total, summary.Files, len(reports))
+	r.outputPrinter.Println()
+	r.outputPrinter.Printf("[*] Pending: %d, Skipped: %d, Cracked: %d, Failed: %d, Passed: %d",
+		summary.Pending, summary.Skipped, summary.Cracked, summary.Failed, summary.Passed)
+	r.outputPrinter.Println()
+	if summary.Flaky > 0 {
+		r.outputPrinter.Printf("[*] Flaky: %d test case(s) passed after failing, quarantine them", summary.Flaky)
+		r.outputPrinter.Println()
+	}
+	if summary.Unreachable > 0 {
+		r.outputPrinter.Printf("[*] Unreachable: %d test case(s) not executed", summary.Unreachable)
+		r.outputPrinter.Println()
+	}
+	for _, testcase := range merged.TestCases {
+		if testcase.Status == RESULT_FAILED || testcase.Status == RESULT_CRACKED {
+			r.outputPrinter.Println("    - " + r.outputPrinter.WarnMsg(fmt.Sprintf("%s: %s (%s)", testcase.File, testcase.Title, testcase.Status)))
+		}
+	}
+	r.outputPrinter.Printf("[*] Elapsed time: %s (longest shard)", time.Duration(summary.DurationMs) * time.Millisecond)
+	r.outputPrinter.Println()
+	r.outputPrinter.Printf("[*] Report: %s", output)
+	r.outputPrinter.Println()
+	r.outputPrinter.Println()
+	return nil
+}
+
+type RptVerifyArguments interface {
+	GetConfigPath() string
+	GetReports() []string
+}
+
+// Verify checks the signature of a report written by a run, with the key of
+// the configuration file, and displays its provenance.
+func (r *RptController) Verify(args RptVerifyArguments) error {
+	if args == nil || len(args.GetReports()) != 1 {
+		return fmt.Errorf("One report file must be provided")
+	}
+	key, err := loadSigningKey(args.GetConfigPath())
+	if err != nil {
+		return err
+	}
+	if key == nil {
+		return fmt.Errorf("The configuration has no report-signing key")
+	}
+	path := args.GetReports()[0]
+	report, err := readJsonReport(path)
+	if err != nil {
+		return err
+	}
+	if err := verifyJsonReport(report, key); err != nil {
+		return fmt.Errorf("Report [%s] is not verified: %s", path, err.Error())
+	}
+
+	r.outputPrinter.Println()
+	r.outputPrinter.Println(r.outputPrinter.Heading("Verified"))
+	r.outputPrinter.Println(r.outputPrinter.Success(path))
+	if p := report.Provenance; p != nil {
+		r.outputPrinter.Println(r.outputPrinter.ContextInfo("Hostname", p.Hostname))
+		r.outputPrinter.Println(r.outputPrinter.ContextInfo("User", p.User))
+		r.outputPrinter.Println(r.outputPrinter.ContextInfo("Commit", p.Commit))
+		r.outputPrinter.Println(r.outputPrinter.ContextInfo("Version", p.Version))
+	}
+	r.outputPrinter.Println()
+	return nil
+}
+
+// loadSigningKey returns the key of the report signing of the configuration
+// file, nil if the reports are not signed.
+func loadSigningKey(configPath string) ([]byte, error) {
+	configLoader, err := config.NewLoader(nil)
+	if err != nil {
+		return nil, err
+	}
+	configuration, err := configLoader.Load(configPath)
+	if err != nil {
+		return nil, err
+	}
+	return configuration.GetSigningKey()
+}
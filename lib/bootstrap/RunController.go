@@ -1,22 +1,59 @@
 package bootstrap
 
 import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/binary"
+	"errors"
 	"flag"
 	"fmt"
+	"hash/fnv"
 	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
+	"github.com/opwire/opwire-testa/lib/client"
+	"github.com/opwire/opwire-testa/lib/config"
 	"github.com/opwire/opwire-testa/lib/format"
 	"github.com/opwire/opwire-testa/lib/engine"
 	"github.com/opwire/opwire-testa/lib/script"
 	"github.com/opwire/opwire-testa/lib/sieve"
+	"github.com/opwire/opwire-testa/lib/storage"
 	"github.com/opwire/opwire-testa/lib/tag"
+	"github.com/opwire/opwire-testa/lib/utils"
 )
 
 type RunControllerOptions interface {
 	script.Source
+	engine.SpecHandlerOptions
 	GetConfigPath() string
 	GetNoColor() bool
+	GetStrictDeprecations() bool
+	GetBreakerThreshold() int
+	GetSlowThreshold() time.Duration
+	GetMaxWarnings() int
+	GetAllowDestructive() bool
+	GetForceDestructive() bool
+	GetMatcherPlugins() []string
+	GetConcurrency() int
+	GetEnvironment() string
+	GetFakerSeed() int64
+	GetRetryFailed() int
+	GetShuffle() bool
+	GetSeed() int64
+	GetShardIndex() int
+	GetShardTotal() int
+	GetReportPath() string
+	GetVersion() string
+	GetDryRun() bool
+	GetWatch() bool
 }
 
 type RunController struct {
@@ -25,25 +62,87 @@ type RunController struct {
 	scriptSource script.Source
 	tagManager *tag.Manager
 	specHandler *engine.SpecHandler
+	workerHandlers []*engine.SpecHandler
 	outputPrinter *format.OutputPrinter
-	counter struct{
-		Pending int
-		Skipped int
-		Success int
-		Failure int
-		Cracked int
-	}
+	configuration *config.Configuration
+	environmentName string
+	environment *config.Environment
+	variables *sieve.VariableStore
+	fakerSeed int64
+	retryFailed int
+	shuffle bool
+	seed int64
+	shardIndex int
+	shardTotal int
+	dryRun bool
+	watch bool
+	flakies []string
+	allowDestructive bool
+	forceDestructive bool
+	strictDeprecations bool
+	deprecations []string
+	breakerThreshold int
+	consecutiveErrors *int32
+	slowThreshold time.Duration
+	maxWarnings int
+	warnings map[string]int
+	cleanups []func()
+	resultSinks []ResultSink
+	statuses map[*engine.TestCase]string
+	counter runCounter
 	t *testing.T
 }
 
+type runCounter struct {
+	Pending int
+	Skipped int
+	Success int
+	Failure int
+	Cracked int
+	Unreachable int
+	Flaky int
+	Planned int
+}
+
+func (c *runCounter) add(other runCounter) {
+	c.Pending += other.Pending
+	c.Skipped += other.Skipped
+	c.Success += other.Success
+	c.Failure += other.Failure
+	c.Cracked += other.Cracked
+	c.Unreachable += other.Unreachable
+	c.Flaky += other.Flaky
+	c.Planned += other.Planned
+}
+
 func NewRunController(opts RunControllerOptions) (r *RunController, err error) {
-	r = &RunController{}
+	r = &RunController{ maxWarnings: -1, warnings: make(map[string]int, 0), consecutiveErrors: new(int32) }
+	r.statuses = make(map[*engine.TestCase]string, 0)
+
+	// load the configuration (test directories, policies of tags, environments)
+	configLoader, err := config.NewLoader(nil)
+	if err != nil {
+		return nil, err
+	}
+	var configPath string
+	if opts != nil {
+		configPath = opts.GetConfigPath()
+	}
+	r.configuration, err = configLoader.Load(configPath)
+	if err != nil {
+		return nil, err
+	}
 
-	// testing temporary storage
-	r.scriptSource, err = script.NewSource(opts)
+	// testing temporary storage, the test directories of the configuration
+	// are used unless they are given by the options
+	source, err := script.NewSource(opts)
 	if err != nil {
 		return nil, err
 	}
+	if len(source.TestDirs) == 0 {
+		source.TestDirs = r.configuration.GetTestDirs()
+	}
+	r.scriptSource = source
 
 	// create a Script Loader instance
 	r.scriptLoader, err = script.NewLoader(r.scriptSource)
@@ -63,8 +162,31 @@ func NewRunController(opts RunControllerOptions) (r *RunController, err error) {
 		return nil, err
 	}
 
+	// the requests are sent to the selected environment
+	var handlerOpts engine.SpecHandlerOptions = opts
+	if opts != nil && len(opts.GetEnvironment()) > 0 {
+		r.environmentName = opts.GetEnvironment()
+		r.environment, err = r.configuration.GetEnvironment(r.environmentName)
+		if err != nil {
+			return nil, err
+		}
+		handlerOpts, err = newEnvironmentOptions(opts, r.environment)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// the rate limit and the delay between the requests apply to all of the
+	// invokers of the run (the hooks and the workers included)
+	if opts != nil {
+		handlerOpts = &limitedOptions{
+			SpecHandlerOptions: handlerOpts,
+			limiter: client.NewRateLimiter(opts.GetRateLimit(), opts.GetRequestDelay()),
+		}
+	}
+
 	// create a Spec Handler instance
-	r.specHandler, err = engine.NewSpecHandler(r.scriptSource)
+	r.specHandler, err = engine.NewSpecHandler(handlerOpts)
 	if err != nil {
 		return nil, err
 	}
@@ -74,6 +196,64 @@ func NewRunController(opts RunControllerOptions) (r *RunController, err error) {
 	if err != nil {
 		return nil, err
 	}
+	r.specHandler.SetTargetGuard(r.guardDestructiveTarget)
+
+	if opts != nil {
+		r.allowDestructive = opts.GetAllowDestructive()
+		r.forceDestructive = opts.GetForceDestructive()
+		r.strictDeprecations = opts.GetStrictDeprecations()
+		r.breakerThreshold = opts.GetBreakerThreshold()
+		r.slowThreshold = opts.GetSlowThreshold()
+		r.maxWarnings = opts.GetMaxWarnings()
+		r.fakerSeed = opts.GetFakerSeed()
+		r.retryFailed = opts.GetRetryFailed()
+		r.shuffle = opts.GetShuffle()
+		r.seed = opts.GetSeed()
+		r.shardIndex = opts.GetShardIndex()
+		r.shardTotal = opts.GetShardTotal()
+		r.dryRun = opts.GetDryRun()
+		r.watch = opts.GetWatch()
+	}
+	if r.shuffle && r.seed == 0 {
+		r.seed = time.Now().UnixNano()
+	}
+
+	// write the outcomes into a report, the reports of the shards are merged
+	if opts != nil && len(opts.GetReportPath()) > 0 {
+		var shard *ReportShard
+		if r.shardTotal > 1 {
+			shard = &ReportShard{ Index: r.shardIndex, Total: r.shardTotal }
+		}
+		sink := newJsonReportSink(opts.GetReportPath(), shard)
+		// the report is signed when the configuration has a key
+		key, err := r.configuration.GetSigningKey()
+		if err != nil {
+			return nil, err
+		}
+		if key != nil {
+			sink.signWith(key, collectProvenance(r.scriptSource.GetTestDirs(), opts.GetVersion()))
+		}
+		r.AddResultSink(sink)
+	}
+
+	// each worker of a concurrent run sends its requests with its own invoker
+	if opts != nil && opts.GetConcurrency() > 1 {
+		for i := 0; i < opts.GetConcurrency(); i++ {
+			handler, err := engine.NewSpecHandler(handlerOpts)
+			if err != nil {
+				return nil, err
+			}
+			handler.SetTargetGuard(r.guardDestructiveTarget)
+			r.workerHandlers = append(r.workerHandlers, handler)
+		}
+	}
+
+	// register the custom matchers of the plugins
+	if opts != nil {
+		if err = engine.LoadMatcherPlugins(opts.GetMatcherPlugins()); err != nil {
+			return nil, err
+		}
+	}
 
 	return r, nil
 }
@@ -82,6 +262,18 @@ func (r *RunController) SetT(t *testing.T) {
 	r.t = t
 }
 
+// AddCleanup registers a function called at the end of the run.
+func (r *RunController) AddCleanup(cleanup func()) {
+	r.cleanups = append(r.cleanups, cleanup)
+}
+
+// AddResultSink registers a receiver of the outcomes of the test cases.
+func (r *RunController) AddResultSink(sink ResultSink) {
+	if sink != nil {
+		r.resultSinks = append(r.resultSinks, sink)
+	}
+}
+
 func (r *RunController) GetOutputPrinter() *format.OutputPrinter {
 	return r.outputPrinter
 }
@@ -89,13 +281,78 @@ func (r *RunController) GetOutputPrinter() *format.OutputPrinter {
 type RunArguments interface {}
 
 func (r *RunController) Execute(args RunArguments) error {
-	// start time
-	startTime := time.Now()
+	r.printContext()
 
-	// begin environments
+	// create the test runners
+	var internalTests []testing.InternalTest
+	if r.watch {
+		internalTests = []testing.InternalTest{ r.wrapWatch() }
+	} else {
+		var err error
+		internalTests, err = r.wrapRun(nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	// a dry run examines nothing
+	if internalTests == nil {
+		return nil
+	}
+
+	// Run the tests
+	if r.t != nil {
+		return runTests(r.t, internalTests)
+	}
+
+	flag.Set("test.v", "false")
+	if false {
+		testing.MainStart(testDeps(defaultMatchString), internalTests, nil, nil).Run()
+	} else {
+		testing.Main(defaultMatchString, internalTests, nil, nil)
+	}
+
+	return nil
+}
+
+func (r *RunController) printContext() {
 	r.outputPrinter.Println()
 	r.outputPrinter.Println(r.outputPrinter.Heading("Context"))
 	printScriptSourceArgs(r.outputPrinter, r.scriptSource, r.scriptSelector, r.tagManager)
+	if len(r.environmentName) > 0 {
+		r.outputPrinter.Println(r.outputPrinter.ContextInfo("Environment", r.environmentName))
+	}
+	if r.fakerSeed != 0 {
+		sieve.SetFakerSeed(r.fakerSeed)
+		r.outputPrinter.Println(r.outputPrinter.ContextInfo("Faker seed", fmt.Sprintf("%d", r.fakerSeed)))
+	}
+	if r.shardTotal > 1 {
+		r.outputPrinter.Println(r.outputPrinter.ContextInfo("Shard", fmt.Sprintf("%d of %d", r.shardIndex, r.shardTotal)))
+	}
+	if r.dryRun {
+		r.outputPrinter.Println(r.outputPrinter.ContextInfo("Dry run", "no request is sent"))
+	}
+	if r.shuffle {
+		r.outputPrinter.Println(r.outputPrinter.ContextInfo("Shuffled with seed", fmt.Sprintf("%d (use --shuffle --seed %d to reproduce)", r.seed, r.seed)))
+	}
+	if r.watch {
+		r.outputPrinter.Println(r.outputPrinter.ContextInfo("Watch", "the test suites of the changed files are examined again (Ctrl-C to stop)"))
+	}
+}
+
+// wrapRun loads the test suite files, or only the given ones (by their paths),
+// and returns the runners of their test cases followed by the summary of the
+// run. A dry run prints the test cases and returns no runner.
+func (r *RunController) wrapRun(files map[string]bool) ([]testing.InternalTest, error) {
+	// start time
+	startTime := time.Now()
+
+	// the outcomes of the previous run (in watch mode) are discarded
+	r.counter = runCounter{}
+	r.flakies = nil
+	r.statuses = make(map[*engine.TestCase]string, 0)
+	r.warnings = make(map[string]int, 0)
+	atomic.StoreInt32(r.consecutiveErrors, 0)
 
 	// begin prerequisites
 	r.outputPrinter.Println()
@@ -103,6 +360,13 @@ func (r *RunController) Execute(args RunArguments) error {
 
 	// load test specifications
 	descriptors := r.scriptLoader.Load()
+	if files != nil {
+		for key := range descriptors {
+			if !files[key] {
+				delete(descriptors, key)
+			}
+		}
+	}
 
 	// filter invalid descriptors and display errors
 	descriptors, rejected := filterInvalidDescriptors(descriptors)
@@ -117,6 +381,58 @@ func (r *RunController) Execute(args RunArguments) error {
 	// filter testing script files by "exclusive-files"
 	descriptors = filterDescriptorsByExclusivePatterns(descriptors, r.scriptSource.GetExclFiles())
 
+	// collect deprecation warnings
+	r.deprecations = collectDeprecations(descriptors)
+
+	// create the temporary workspace of this run
+	workspace, err1 := storage.NewWorkspace()
+	if err1 != nil {
+		return nil, err1
+	}
+	// the captured variables are shared by the test suites of the run, and
+	// kept by the next runs of watch mode
+	if r.variables == nil {
+		r.variables = sieve.NewVariableStore()
+		if r.environment != nil {
+			for name, value := range r.environment.Variables {
+				r.variables.Set(name, value)
+			}
+		}
+	}
+	for _, d := range descriptors {
+		if d.TestSuite != nil {
+			d.TestSuite.GetResultCache().SetVariable(sieve.RUN_WORKDIR, workspace.GetPath())
+			d.TestSuite.GetResultCache().SetVariableStore(r.variables)
+		}
+	}
+
+	// list the test cases and their requests instead of examining them
+	if r.dryRun {
+		r.planTestSuites(descriptors, startTime)
+		workspace.Cleanup()
+		for _, cleanup := range r.cleanups {
+			cleanup()
+		}
+		return nil, nil
+	}
+
+	// provision the shared state of the run
+	hookCache, err3 := sieve.NewRestCache()
+	if err3 != nil {
+		return nil, err3
+	}
+	hookCache.SetVariable(sieve.RUN_WORKDIR, workspace.GetPath())
+	hookCache.SetVariableStore(r.variables)
+	if err := r.runLifecycleHooks("Setup", r.configuration.BeforeAll, hookCache); err != nil {
+		r.runLifecycleHooks("Teardown", r.configuration.AfterAll, hookCache)
+		workspace.Cleanup()
+		for _, cleanup := range r.cleanups {
+			cleanup()
+		}
+		r.outputPrinter.Println()
+		return nil, err
+	}
+
 	// begin testing
 	r.outputPrinter.Println()
 	r.outputPrinter.Println(r.outputPrinter.Heading("Testing"))
@@ -124,18 +440,23 @@ func (r *RunController) Execute(args RunArguments) error {
 	// create the test runners
 	internalTests, err2 := r.wrapTestSuites(descriptors)
 	if err2 != nil {
-		return err2
+		return nil, err2
 	}
 
 	// summary
 	internalTests = append(internalTests, testing.InternalTest{
 		Name: "Summary",
 		F: func(t *testing.T) {
+			// tear down the shared state of the run
+			if err := r.runLifecycleHooks("Teardown", r.configuration.AfterAll, hookCache); err != nil {
+				t.Fail()
+			}
+
 			// summarize testing
 			r.outputPrinter.Println()
 			r.outputPrinter.Println(r.outputPrinter.Heading("Summary"))
 
-			totalTestcases := (r.counter.Pending + r.counter.Skipped + r.counter.Cracked + r.counter.Failure + r.counter.Success)
+			totalTestcases := (r.counter.Pending + r.counter.Skipped + r.counter.Cracked + r.counter.Unreachable + r.counter.Failure + r.counter.Success + r.counter.Flaky)
 			totalFiles := len(descriptors)
 			r.outputPrinter.Printf("[*] Total: %d test case(s), in %d file(s)", totalTestcases, totalFiles)
 			r.outputPrinter.Println()
@@ -144,29 +465,77 @@ func (r *RunController) Execute(args RunArguments) error {
 				r.counter.Pending, r.counter.Skipped, r.counter.Cracked, r.counter.Failure, r.counter.Success)
 			r.outputPrinter.Println()
 
+			// test cases which passed after failing
+			if r.counter.Flaky > 0 {
+				r.outputPrinter.Printf("[*] Flaky: %d test case(s) passed after failing, quarantine them", r.counter.Flaky)
+				r.outputPrinter.Println()
+				for _, flaky := range r.flakies {
+					r.outputPrinter.Println("    - " + r.outputPrinter.WarnMsg(flaky))
+				}
+			}
+
+			// circuit breaker
+			if r.counter.Unreachable > 0 {
+				r.outputPrinter.Printf("[*] Unreachable: %d test case(s) not executed after %d consecutive connection errors",
+					r.counter.Unreachable, r.breakerThreshold)
+				r.outputPrinter.Println()
+			}
+
+			// deprecation warnings
+			if len(r.deprecations) > 0 {
+				r.outputPrinter.Printf("[*] Deprecations: %d", len(r.deprecations))
+				r.outputPrinter.Println()
+				for _, deprecation := range r.deprecations {
+					r.outputPrinter.Println("    - " + r.outputPrinter.WarnMsg(deprecation))
+				}
+			}
+
+			// warnings
+			if !r.strictDeprecations {
+				r.warnings[engine.WARNING_DEPRECATION] += len(r.deprecations)
+			}
+			totalWarnings, summary := summarizeWarnings(r.warnings)
+			if totalWarnings > 0 {
+				r.outputPrinter.Printf("[*] Warnings: %d (%s)", totalWarnings, summary)
+				r.outputPrinter.Println()
+			}
+			if r.maxWarnings >= 0 && totalWarnings > r.maxWarnings {
+				r.outputPrinter.Println("    - " + r.outputPrinter.WarnMsg(fmt.Sprintf("The number of warnings exceeds the maximum (%d)", r.maxWarnings)))
+				t.Fail()
+			}
+
 			// total elapsed time
 			duration := time.Since(startTime)
 			r.outputPrinter.Printf("[*] Elapsed time: %s", duration.String())
 			r.outputPrinter.Println()
 
+			// close the result sinks
+			r.closeResultSinks(&RunSummary{
+				Files: totalFiles,
+				Total: totalTestcases,
+				Pending: r.counter.Pending,
+				Skipped: r.counter.Skipped,
+				Cracked: r.counter.Cracked,
+				Failed: r.counter.Failure,
+				Passed: r.counter.Success,
+				Flaky: r.counter.Flaky,
+				Unreachable: r.counter.Unreachable,
+				Warnings: r.warnings,
+				Duration: duration,
+			})
+
+			// remove the temporary workspace
+			workspace.Cleanup()
+			for _, cleanup := range r.cleanups {
+				cleanup()
+			}
+
 			// endof testing
 			r.outputPrinter.Println()
 		},
 	})
 
-	// Run the tests
-	if r.t != nil {
-		return runTests(r.t, internalTests)
-	}
-
-	flag.Set("test.v", "false")
-	if false {
-		testing.MainStart(testDeps(defaultMatchString), internalTests, nil, nil).Run()
-	} else {
-		testing.Main(defaultMatchString, internalTests, nil, nil)
-	}
-
-	return nil
+	return internalTests, nil
 }
 
 func runTests(t *testing.T, internalTests []testing.InternalTest) error {
@@ -193,9 +562,13 @@ func (r *RunController) wrapTestSuites(descriptors map[string]*script.Descriptor
 	if r.specHandler == nil {
 		panic(fmt.Errorf("SpecHandler must not be nil"))
 	}
+	paths := r.orderDescriptors(descriptors)
+	if len(r.workerHandlers) > 1 {
+		return []testing.InternalTest{ r.wrapWorkerPool(paths, descriptors) }, nil
+	}
 	tests := make([]testing.InternalTest, 0)
-	for _, descriptor := range descriptors {
-		test, err := r.wrapDescriptor(descriptor)
+	for _, path := range paths {
+		test, err := r.wrapDescriptor(descriptors[path])
 		if err == nil {
 			tests = append(tests, test)
 		}
@@ -203,6 +576,145 @@ func (r *RunController) wrapTestSuites(descriptors map[string]*script.Descriptor
 	return tests, nil
 }
 
+// orderDescriptors returns the paths of the test suite files in the order of
+// the run.
+func (r *RunController) orderDescriptors(descriptors map[string]*script.Descriptor) []string {
+	paths := make([]string, 0, len(descriptors))
+	for path := range descriptors {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	if r.shuffle {
+		random := rand.New(rand.NewSource(r.seed))
+		random.Shuffle(len(paths), func(i, j int) {
+			paths[i], paths[j] = paths[j], paths[i]
+		})
+	}
+	return paths
+}
+
+// wrapWorkerPool runs the test suites on a pool of workers, the test cases of
+// a file are examined in order by a single worker. The output and the records
+// of each file are buffered, then flushed in the order of the files.
+func (r *RunController) wrapWorkerPool(paths []string, descriptors map[string]*script.Descriptor) (testing.InternalTest) {
+	return testing.InternalTest{
+		Name: "Workers",
+		F: func (t *testing.T) {
+			jobs := make(chan int)
+			done := make(chan int)
+			workers := make([]*RunController, len(paths))
+			buffers := make([]*bytes.Buffer, len(paths))
+			var wg sync.WaitGroup
+			for _, handler := range r.workerHandlers {
+				wg.Add(1)
+				go func(handler *engine.SpecHandler) {
+					defer wg.Done()
+					for i := range jobs {
+						buffers[i] = &bytes.Buffer{}
+						workers[i] = r.fork(handler, buffers[i])
+						workers[i].runDescriptor(t, descriptors[paths[i]])
+						done <- i
+					}
+				}(handler)
+			}
+			go func() {
+				for i := range paths {
+					jobs <- i
+				}
+				close(jobs)
+				wg.Wait()
+				close(done)
+			}()
+			finished := make([]bool, len(paths))
+			next := 0
+			for i := range done {
+				finished[i] = true
+				for next < len(paths) && finished[next] {
+					r.join(workers[next], buffers[next])
+					workers[next], buffers[next] = nil, nil
+					next++
+				}
+			}
+		},
+	}
+}
+
+// limitedOptions shares the rate limiter of a run between its spec handlers,
+// the other options are the ones of the run.
+type limitedOptions struct {
+	engine.SpecHandlerOptions
+	limiter *client.RateLimiter
+}
+
+func (o *limitedOptions) GetRateLimiter() *client.RateLimiter {
+	return o.limiter
+}
+
+func (o *limitedOptions) GetTransport() http.RoundTripper {
+	if provider, ok := o.SpecHandlerOptions.(engine.TransportProvider); ok {
+		return provider.GetTransport()
+	}
+	return nil
+}
+
+func (o *limitedOptions) GetPDP() string {
+	if provider, ok := o.SpecHandlerOptions.(engine.EnvironmentProvider); ok {
+		return provider.GetPDP()
+	}
+	return ""
+}
+
+func (o *limitedOptions) GetDefaultHeaders() []client.HttpHeader {
+	if provider, ok := o.SpecHandlerOptions.(engine.EnvironmentProvider); ok {
+		return provider.GetDefaultHeaders()
+	}
+	return nil
+}
+
+// fork creates a worker sharing the configuration of the controller, with its
+// own SpecHandler, output and counters.
+func (r *RunController) fork(handler *engine.SpecHandler, output io.Writer) *RunController {
+	w := *r
+	w.specHandler = handler
+	w.outputPrinter = r.outputPrinter.Fork(output)
+	w.warnings = make(map[string]int, 0)
+	w.statuses = make(map[*engine.TestCase]string, 0)
+	w.resultSinks = []ResultSink{ &recordBuffer{} }
+	w.counter = runCounter{}
+	w.flakies = nil
+	return &w
+}
+
+// join flushes the output and the records of a worker, then adds its counters.
+func (r *RunController) join(w *RunController, output *bytes.Buffer) {
+	r.outputPrinter.GetWriter().Write(output.Bytes())
+	for _, record := range w.resultSinks[0].(*recordBuffer).records {
+		for _, sink := range r.resultSinks {
+			if err := sink.Record(record); err != nil {
+				r.outputPrinter.Println(r.outputPrinter.Warning("Result sink: " + err.Error()))
+			}
+		}
+	}
+	r.counter.add(w.counter)
+	r.flakies = append(r.flakies, w.flakies...)
+	for category, count := range w.warnings {
+		r.warnings[category] += count
+	}
+}
+
+type recordBuffer struct {
+	records []*TestRecord
+}
+
+func (b *recordBuffer) Record(record *TestRecord) error {
+	b.records = append(b.records, record)
+	return nil
+}
+
+func (b *recordBuffer) Close(summary *RunSummary) error {
+	return nil
+}
+
 func (r *RunController) wrapDescriptor(descriptor *script.Descriptor) (testing.InternalTest, error) {
 	testsuite := descriptor.TestSuite
 	if testsuite == nil {
@@ -212,66 +724,430 @@ func (r *RunController) wrapDescriptor(descriptor *script.Descriptor) (testing.I
 		Name: descriptor.Locator.RelativePath,
 		F: func (t *testing.T) {
 			r.outputPrinter.Println(r.outputPrinter.TestSuiteTitle(descriptor.Locator.RelativePath))
-			tests := make([]testing.InternalTest, 0)
-			for _, testcase := range testsuite.TestCases {
-				tests = append(tests, r.wrapTestCase(testcase, testsuite.GetResultCache()))
-			}
-			testing.RunTests(defaultMatchString, tests)
+			testing.RunTests(defaultMatchString, r.wrapTestCases(descriptor))
 		},
 	}, nil
 }
 
-func (r *RunController) wrapTestCase(testcase *engine.TestCase, cache *sieve.RestCache) (testing.InternalTest) {
+// runDescriptor examines the test cases of a file on the current goroutine.
+func (r *RunController) runDescriptor(t *testing.T, descriptor *script.Descriptor) {
+	if descriptor.TestSuite == nil {
+		return
+	}
+	r.outputPrinter.Println(r.outputPrinter.TestSuiteTitle(descriptor.Locator.RelativePath))
+	runTests(t, r.wrapTestCases(descriptor))
+}
+
+// orderTestCases returns the test cases of the file examined by this run, in
+// their order, with the indexes of their definitions in the file.
+func (r *RunController) orderTestCases(descriptor *script.Descriptor) ([]*engine.TestCase, []int) {
+	testsuite := descriptor.TestSuite
+	file := descriptor.Locator.RelativePath
+	// the prerequisites (depends-on) are examined first
+	order, err := testsuite.Schedule()
+	if r.shuffle {
+		// each file has its own source, the order does not depend on the workers
+		hash := fnv.New64a()
+		hash.Write([]byte(file))
+		order, err = testsuite.Shuffle(rand.New(rand.NewSource(r.seed ^ int64(hash.Sum64()))))
+	}
+	if err != nil {
+		return nil, nil
+	}
+	// the data-driven test cases are expanded into one test case per row
+	cases := make([]*engine.TestCase, 0, len(order))
+	indexes := make([]int, 0, len(order))
+	shardKeys := getShardKeys(testsuite.TestCases)
+	for _, k := range order {
+		if !r.isInShard(file, shardKeys[k]) {
+			continue
+		}
+		for _, testcase := range testsuite.TestCases[k].ExpandDataRows() {
+			cases = append(cases, testcase)
+			indexes = append(indexes, k)
+		}
+	}
+	return cases, indexes
+}
+
+func (r *RunController) wrapTestCases(descriptor *script.Descriptor) []testing.InternalTest {
+	testsuite := descriptor.TestSuite
+	file := descriptor.Locator.RelativePath
+	tests := make([]testing.InternalTest, 0)
+	cases, indexes := r.orderTestCases(descriptor)
+	// the shared state of the test suite is created before the barriers
+	testsuite.GetSession()
+	for i := 0; i < len(cases); i++ {
+		// consecutive testcases sharing a barrier are examined concurrently
+		if barrier := cases[i].GetBarrier(); len(barrier) > 0 {
+			j := i + 1
+			for j < len(cases) && cases[j].GetBarrier() == barrier {
+				j++
+			}
+			deprecations := make([][]script.Deprecation, 0)
+			for k := i; k < j; k++ {
+				deprecations = append(deprecations, filterDeprecationsByIndex(descriptor.Deprecations, indexes[k]))
+			}
+			tests = append(tests, r.wrapBarrier(file, barrier, cases[i:j], testsuite, deprecations))
+			i = j - 1
+			continue
+		}
+		deprecations := filterDeprecationsByIndex(descriptor.Deprecations, indexes[i])
+		tests = append(tests, r.wrapTestCase(file, cases[i], testsuite, deprecations))
+	}
+	return tests
+}
+
+// isInShard reports whether the test case belongs to the shard of this job,
+// the shards partition the test cases by a hash of the file and the shard key
+// of the test case.
+func (r *RunController) isInShard(file string, shardKey string) bool {
+	if r.shardTotal <= 1 {
+		return true
+	}
+	// the low bits of a simple hash hardly differ between similar titles
+	sum := sha1.Sum([]byte(file + "\x00" + shardKey))
+	return int(binary.BigEndian.Uint32(sum[:4]) % uint32(r.shardTotal)) == r.shardIndex - 1
+}
+
+// getShardKeys returns the shard key of each test case: the title of the first
+// test case of its dependency component (depends-on, in both directions), so
+// that a test case is examined by the same shard as all of its prerequisites.
+func getShardKeys(testcases []*engine.TestCase) []string {
+	indexes := make(map[*engine.TestCase]int, len(testcases))
+	roots := make([]int, len(testcases))
+	for i, testcase := range testcases {
+		indexes[testcase] = i
+		roots[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if roots[i] != i {
+			roots[i] = find(roots[i])
+		}
+		return roots[i]
+	}
+	for i, testcase := range testcases {
+		for _, prerequisite := range testcase.GetPrerequisites() {
+			if j, found := indexes[prerequisite]; found {
+				a, b := find(i), find(j)
+				// the first test case of a component is its root
+				if a < b {
+					roots[b] = a
+				} else {
+					roots[a] = b
+				}
+			}
+		}
+	}
+	keys := make([]string, len(testcases))
+	for i := range testcases {
+		keys[i] = testcases[find(i)].Title
+	}
+	return keys
+}
+
+func (r *RunController) wrapTestCase(file string, testcase *engine.TestCase, testsuite *engine.TestSuite, deprecations []script.Deprecation) (testing.InternalTest) {
 	return testing.InternalTest{
 		Name: testcase.Title,
 		F: func (t *testing.T) {
-			if testcase.Pending != nil && *testcase.Pending {
-				r.outputPrinter.Println(r.outputPrinter.Pending(testcase.Title))
-				r.counter.Pending += 1
+			tagstr, ok := r.checkTestCase(file, testcase)
+			if !ok {
 				return
 			}
-			if !r.scriptSelector.IsMatched(testcase.Title) {
-				label := printUnmatchedPattern(r.outputPrinter, "unmatched")
-				r.outputPrinter.Println(r.outputPrinter.Skipped(testcase.Title), label)
-				r.counter.Skipped += 1
-				return
+			result, failures, err := r.examineTestCase(testsuite, testcase)
+			r.reportTestCase(file, testcase, tagstr, result, failures, err, deprecations)
+		},
+	}
+}
+
+func (r *RunController) wrapBarrier(file string, barrier string, testcases []*engine.TestCase, testsuite *engine.TestSuite, deprecations [][]script.Deprecation) (testing.InternalTest) {
+	return testing.InternalTest{
+		Name: barrier,
+		F: func (t *testing.T) {
+			r.outputPrinter.Println(r.outputPrinter.TestCase("barrier: " + barrier))
+			type outcome struct {
+				tagstr string
+				result *engine.ExaminationResult
+				failures int
+				err error
 			}
-			active, mark := r.tagManager.IsActive(testcase.Tags)
-			tagstr := printMarkedTags(r.outputPrinter, testcase.Tags, mark)
-			if !active {
-				r.outputPrinter.Println(r.outputPrinter.Skipped(testcase.Title), tagstr)
-				r.counter.Skipped += 1
-				return
+			outcomes := make([]*outcome, len(testcases))
+			var wg sync.WaitGroup
+			for i, testcase := range testcases {
+				tagstr, ok := r.checkTestCase(file, testcase)
+				if !ok {
+					continue
+				}
+				outcomes[i] = &outcome{ tagstr: tagstr }
+				wg.Add(1)
+				go func(testcase *engine.TestCase, o *outcome) {
+					defer wg.Done()
+					o.result, o.failures, o.err = r.examineTestCase(testsuite, testcase)
+				}(testcase, outcomes[i])
 			}
-
-			result, err := r.specHandler.Examine(testcase, cache)
-			if result == nil {
-				panic(fmt.Errorf("Result of Examine() must not be nil"))
+			wg.Wait()
+			for i, o := range outcomes {
+				if o != nil {
+					r.reportTestCase(file, testcases[i], o.tagstr, o.result, o.failures, o.err, deprecations[i])
+				}
 			}
+		},
+	}
+}
 
-			exectime := printDuration(r.outputPrinter, result.Duration)
-			if err != nil {
-				r.outputPrinter.Println(r.outputPrinter.Cracked(testcase.Title), tagstr, exectime)
-				r.printErrorMap(result.Errors)
-				r.counter.Cracked += 1
-				return
+func (r *RunController) checkTestCase(file string, testcase *engine.TestCase) (string, bool) {
+	if testcase.Pending != nil && *testcase.Pending {
+		r.outputPrinter.Println(r.outputPrinter.Pending(testcase.Title))
+		r.counter.Pending += 1
+		r.recordResult(file, testcase, RESULT_PENDING, nil, nil)
+		return "", false
+	}
+	if !r.scriptSelector.IsMatched(testcase.Title) {
+		label := printUnmatchedPattern(r.outputPrinter, "unmatched")
+		r.outputPrinter.Println(r.outputPrinter.Skipped(testcase.Title), label)
+		r.counter.Skipped += 1
+		r.recordResult(file, testcase, RESULT_SKIPPED, nil, nil)
+		return "", false
+	}
+	active, mark := r.tagManager.IsActive(testcase.Tags)
+	tagstr := printMarkedTags(r.outputPrinter, testcase.Tags, mark)
+	if !active {
+		r.outputPrinter.Println(r.outputPrinter.Skipped(testcase.Title), tagstr)
+		r.counter.Skipped += 1
+		r.recordResult(file, testcase, RESULT_SKIPPED, nil, nil)
+		return tagstr, false
+	}
+	if condition := testcase.GetSkipCondition(); condition != nil && condition.Evaluate(r.lookupReference) {
+		label := printUnmatchedPattern(r.outputPrinter, fmt.Sprintf("skip-if [%s]", condition))
+		r.outputPrinter.Println(r.outputPrinter.Skipped(testcase.Title), tagstr, label)
+		r.counter.Skipped += 1
+		r.recordResult(file, testcase, RESULT_SKIPPED, nil, nil)
+		return tagstr, false
+	}
+	for _, prerequisite := range testcase.GetPrerequisites() {
+		if status := r.statuses[prerequisite]; status != RESULT_PASSED && status != RESULT_FLAKY && status != RESULT_PLANNED {
+			if len(status) == 0 {
+				status = "not examined"
 			}
-			if len(result.Errors) > 0 {
-				r.outputPrinter.Println(r.outputPrinter.Failure(testcase.Title), tagstr, exectime)
-				r.printErrorMap(result.Errors)
-				r.counter.Failure += 1
-				return
+			label := printUnmatchedPattern(r.outputPrinter, fmt.Sprintf("prerequisite [%s] %s", prerequisite.Title, status))
+			r.outputPrinter.Println(r.outputPrinter.Skipped(testcase.Title), tagstr, label)
+			r.counter.Skipped += 1
+			r.recordResult(file, testcase, RESULT_SKIPPED, nil, nil)
+			return tagstr, false
+		}
+	}
+	r.configuration.GetTagPolicy(testcase.Tags).Apply(testcase)
+	if testcase.IsDestructive() && !r.allowDestructive && !r.forceDestructive {
+		label := printUnmatchedPattern(r.outputPrinter, "destructive, use --allow-destructive")
+		r.outputPrinter.Println(r.outputPrinter.Skipped(testcase.Title), tagstr, label)
+		r.counter.Skipped += 1
+		r.recordResult(file, testcase, RESULT_SKIPPED, nil, nil)
+		return tagstr, false
+	}
+	if r.breakerThreshold > 0 && int(atomic.LoadInt32(r.consecutiveErrors)) >= r.breakerThreshold {
+		label := printUnmatchedPattern(r.outputPrinter, "target unreachable")
+		r.outputPrinter.Println(r.outputPrinter.Unreachable(testcase.Title), tagstr, label)
+		r.counter.Unreachable += 1
+		r.recordResult(file, testcase, RESULT_UNREACHABLE, nil, nil)
+		return tagstr, false
+	}
+	return tagstr, true
+}
+
+// lookupReference resolves the references of the skip-if conditions: the
+// variables of the process (env.NAME), the name of the selected environment
+// (environment) and the variables of the run (vars.NAME).
+func (r *RunController) lookupReference(ref string) string {
+	switch {
+	case strings.HasPrefix(ref, "env."):
+		return os.Getenv(strings.TrimPrefix(ref, "env."))
+	case ref == "environment":
+		return r.environmentName
+	case strings.HasPrefix(ref, "vars.") && r.variables != nil:
+		value, _ := r.variables.Get(strings.TrimPrefix(ref, "vars."))
+		return value
+	}
+	return ""
+}
+
+// guardDestructiveTarget refuses to send the requests of the destructive test
+// cases to a host which is not approved by the configuration.
+func (r *RunController) guardDestructiveTarget(testcase *engine.TestCase, req *client.HttpRequest) error {
+	if !testcase.IsDestructive() || r.forceDestructive {
+		return nil
+	}
+	var host string
+	if u, err := url.Parse(client.BuildUrl(req)); err == nil {
+		host = u.Hostname()
+	}
+	if !r.configuration.IsApprovedTarget(host) {
+		return fmt.Errorf("Destructive test case refused, the target [%s] is not listed in destructive-targets (use --i-know-what-im-doing to override)", host)
+	}
+	return nil
+}
+
+// examineTestCase examines the test case again (up to --retry-failed times)
+// while it fails, it returns the number of the failed attempts before the
+// reported one. Only the idempotent requests, or the test cases marked with
+// retry-failed, are sent again.
+func (r *RunController) examineTestCase(testsuite *engine.TestSuite, testcase *engine.TestCase) (*engine.ExaminationResult, int, error) {
+	result, err := r.specHandler.ExamineRepeated(testsuite, testcase)
+	failures := 0
+	for failures < r.retryFailed && testcase.IsRetryable() && (err != nil || len(result.Errors) > 0) && result.Status != "refused" {
+		failures++
+		result, err = r.specHandler.ExamineRepeated(testsuite, testcase)
+	}
+	return result, failures, err
+}
+
+func (r *RunController) reportTestCase(file string, testcase *engine.TestCase, tagstr string, result *engine.ExaminationResult, failures int, err error, deprecations []script.Deprecation) {
+	if result == nil {
+		panic(fmt.Errorf("Result of Examine() must not be nil"))
+	}
+
+	if r.strictDeprecations && err == nil {
+		if result.Errors == nil {
+			result.Errors = make(map[string]error, 0)
+		}
+		for _, deprecation := range deprecations {
+			result.Errors["Deprecation/" + deprecation.Field] = errors.New(deprecation.String())
+		}
+	}
+
+	if r.slowThreshold > 0 && result.Duration > r.slowThreshold {
+		result.Warnings = append(result.Warnings, engine.Warning{
+			Category: engine.WARNING_SLOW,
+			Message: fmt.Sprintf("Testcase took %s, longer than %s", result.Duration, r.slowThreshold),
+		})
+	}
+	defer r.printWarnings(result.Warnings, collectSensitiveValues(testcase, result))
+
+	exectime := printDuration(r.outputPrinter, result.Duration)
+	if result.Retries > 0 {
+		exectime = exectime + fmt.Sprintf(" (%d retries, waited %s)", result.Retries, result.RetryWait)
+	}
+	if result.Repeat != nil {
+		exectime = exectime + fmt.Sprintf(" (%s)", result.Repeat)
+	}
+	if err != nil {
+		r.outputPrinter.Println(r.outputPrinter.Cracked(testcase.Title), tagstr, exectime)
+		r.printErrorMap(result.Errors, collectSensitiveValues(testcase, result))
+		r.counter.Cracked += 1
+		atomic.AddInt32(r.consecutiveErrors, 1)
+		r.recordResult(file, testcase, RESULT_CRACKED, result, err)
+		return
+	}
+	atomic.StoreInt32(r.consecutiveErrors, 0)
+	if len(result.Errors) > 0 {
+		r.outputPrinter.Println(r.outputPrinter.Failure(testcase.Title), tagstr, exectime)
+		r.printErrorMap(result.Errors, collectSensitiveValues(testcase, result))
+		r.counter.Failure += 1
+		r.recordResult(file, testcase, RESULT_FAILED, result, nil)
+		return
+	}
+	if failures > 0 {
+		exectime = exectime + fmt.Sprintf(" (passed after %d failed attempt(s))", failures)
+		r.outputPrinter.Println(r.outputPrinter.Flaky(testcase.Title), tagstr, exectime)
+		r.counter.Flaky += 1
+		r.flakies = append(r.flakies, fmt.Sprintf("%s: %s", file, testcase.Title))
+		r.recordResult(file, testcase, RESULT_FLAKY, result, nil)
+		return
+	}
+	r.outputPrinter.Println(r.outputPrinter.Success(testcase.Title), tagstr, exectime)
+	r.counter.Success += 1
+	r.recordResult(file, testcase, RESULT_PASSED, result, nil)
+}
+
+func (r *RunController) recordResult(file string, testcase *engine.TestCase, status string, result *engine.ExaminationResult, err error) {
+	// a data-driven test case passes when all of its rows pass
+	if previous, found := r.statuses[testcase.GetOrigin()]; !found || previous == RESULT_PASSED || previous == RESULT_FLAKY && status != RESULT_PASSED {
+		r.statuses[testcase.GetOrigin()] = status
+	}
+	if secrets := collectSensitiveValues(testcase, result); len(secrets) > 0 {
+		result = redactResult(result, secrets)
+		if err != nil {
+			err = errors.New(utils.Redact(err.Error(), secrets, client.SENSITIVE_MASK))
+		}
+	}
+	for _, sink := range r.resultSinks {
+		record := &TestRecord{
+			File: file,
+			TestCase: testcase,
+			Status: status,
+			Result: result,
+			Error: err,
+			Time: time.Now(),
+		}
+		if err := sink.Record(record); err != nil {
+			r.outputPrinter.Println(r.outputPrinter.Warning("Result sink: " + err.Error()))
+		}
+	}
+}
+
+func (r *RunController) closeResultSinks(summary *RunSummary) {
+	for _, sink := range r.resultSinks {
+		if err := sink.Close(summary); err != nil {
+			r.outputPrinter.Println("    - " + r.outputPrinter.WarnMsg("Result sink: " + err.Error()))
+		}
+	}
+}
+
+// redactResult returns a copy of the result with the secrets masked in the
+// request, the response, the errors and the warnings.
+func redactResult(result *engine.ExaminationResult, secrets []string) *engine.ExaminationResult {
+	if result == nil {
+		return nil
+	}
+	redacted := *result
+	redacted.Request = result.Request.Redact(secrets)
+	redacted.Response = result.Response.Redact(secrets)
+	if result.Errors != nil {
+		redacted.Errors = make(map[string]error, len(result.Errors))
+		for key, err := range result.Errors {
+			if mismatch, ok := err.(*engine.MismatchError); ok {
+				redacted.Errors[key] = &engine.MismatchError{
+					Summary: utils.Redact(mismatch.Summary, secrets, client.SENSITIVE_MASK),
+					Diff: utils.Redact(mismatch.Diff, secrets, client.SENSITIVE_MASK),
+				}
+				continue
 			}
-			r.outputPrinter.Println(r.outputPrinter.Success(testcase.Title), tagstr, exectime)
-			r.counter.Success += 1
-		},
+			redacted.Errors[key] = errors.New(utils.Redact(err.Error(), secrets, client.SENSITIVE_MASK))
+		}
 	}
+	if result.Warnings != nil {
+		redacted.Warnings = make([]engine.Warning, len(result.Warnings))
+		for i, warning := range result.Warnings {
+			warning.Message = utils.Redact(warning.Message, secrets, client.SENSITIVE_MASK)
+			redacted.Warnings[i] = warning
+		}
+	}
+	return &redacted
+}
+
+func collectSensitiveValues(testcase *engine.TestCase, result *engine.ExaminationResult) []string {
+	secrets := testcase.Request.GetSensitiveValues()
+	if result != nil && result.Request != nil {
+		secrets = append(secrets, result.Request.GetSensitiveValues()...)
+	}
+	return secrets
 }
 
-func (r *RunController) printErrorMap(errorKV map[string]error) {
+func (r *RunController) printWarnings(warnings []engine.Warning, secrets []string) {
+	for _, warning := range warnings {
+		r.outputPrinter.Println(r.outputPrinter.Warning(utils.Redact(warning.String(), secrets, client.SENSITIVE_MASK)))
+		r.warnings[warning.Category] += 1
+	}
+}
+
+func (r *RunController) printErrorMap(errorKV map[string]error, secrets []string) {
 	for key, err := range errorKV {
 		r.outputPrinter.Printf(r.outputPrinter.SectionTitle(key))
-		r.outputPrinter.Printf(r.outputPrinter.Section(err.Error()))
+		text := utils.Redact(err.Error(), secrets, client.SENSITIVE_MASK)
+		if _, ok := err.(*engine.MismatchError); ok {
+			text = r.outputPrinter.Diff(text)
+		}
+		r.outputPrinter.Printf("%s", r.outputPrinter.Section(text))
 		r.outputPrinter.Println()
 	}
 }
\ No newline at end of file
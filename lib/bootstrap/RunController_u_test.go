@@ -0,0 +1,524 @@
+package bootstrap
+
+import(
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+	"github.com/stretchr/testify/assert"
+	"github.com/opwire/opwire-testa/lib/engine"
+)
+
+type runOptions struct {
+	ConfigPath string
+	TestDirs []string
+	RateLimit float64
+	Concurrency int
+	RetryFailed int
+	DryRun bool
+	ReportPath string
+}
+
+func (o *runOptions) GetConfigPath() string { return o.ConfigPath }
+func (o *runOptions) GetTestDirs() []string { return o.TestDirs }
+func (o *runOptions) GetInclFiles() []string { return nil }
+func (o *runOptions) GetExclFiles() []string { return nil }
+func (o *runOptions) GetTestName() string { return "" }
+func (o *runOptions) GetConditionalTags() []string { return nil }
+func (o *runOptions) GetNoColor() bool { return true }
+func (o *runOptions) GetRateLimit() float64 { return o.RateLimit }
+func (o *runOptions) GetRequestDelay() time.Duration { return 0 }
+func (o *runOptions) GetMaxResponseSize() int64 { return 0 }
+func (o *runOptions) GetClockSkew() time.Duration { return 0 }
+func (o *runOptions) GetHttp3() bool { return false }
+func (o *runOptions) GetSoftAssertions() bool { return false }
+func (o *runOptions) GetStrictDeprecations() bool { return false }
+func (o *runOptions) GetBreakerThreshold() int { return 0 }
+func (o *runOptions) GetSlowThreshold() time.Duration { return 0 }
+func (o *runOptions) GetMaxWarnings() int { return -1 }
+func (o *runOptions) GetAllowDestructive() bool { return false }
+func (o *runOptions) GetForceDestructive() bool { return false }
+func (o *runOptions) GetMatcherPlugins() []string { return nil }
+func (o *runOptions) GetConcurrency() int { return o.Concurrency }
+func (o *runOptions) GetEnvironment() string { return "" }
+func (o *runOptions) GetFakerSeed() int64 { return 0 }
+func (o *runOptions) GetRetryFailed() int { return o.RetryFailed }
+func (o *runOptions) GetShuffle() bool { return false }
+func (o *runOptions) GetSeed() int64 { return 0 }
+func (o *runOptions) GetShardIndex() int { return 0 }
+func (o *runOptions) GetShardTotal() int { return 0 }
+func (o *runOptions) GetReportPath() string { return o.ReportPath }
+func (o *runOptions) GetVersion() string { return "1.0.0" }
+func (o *runOptions) GetDryRun() bool { return o.DryRun }
+func (o *runOptions) GetWatch() bool { return false }
+
+// writeTestSuites writes the test suite files (by their names) into a
+// temporary directory, the baseUrl replaces the "{BASE_URL}" of the contents.
+func writeTestSuites(t *testing.T, baseUrl string, suites map[string]string) string {
+	dir, err := ioutil.TempDir("", "opwire-testa-run-")
+	assert.Nil(t, err)
+	for name, content := range suites {
+		content = strings.Replace(content, "{BASE_URL}", baseUrl, -1)
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	return dir
+}
+
+// executeRun examines the test suites of the directory, and returns the output.
+func executeRun(t *testing.T, opts *runOptions) string {
+	ctl, err := NewRunController(opts)
+	assert.Nil(t, err)
+	var output bytes.Buffer
+	ctl.GetOutputPrinter().SetWriter(&output)
+	ctl.SetT(t)
+	assert.Nil(t, ctl.Execute(nil))
+	return output.String()
+}
+
+func TestRunController_Execute_Concurrency(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		// the first file is finished after the second one
+		if strings.HasPrefix(req.URL.Path, "/slow") {
+			time.Sleep(100 * time.Millisecond)
+		}
+		w.WriteHeader(200)
+	}))
+	defer server.Close()
+
+	dir := writeTestSuites(t, server.URL, map[string]string{
+		"a.yml": `testcases:
+- title: First of a
+  request:
+    url: {BASE_URL}/slow/1
+  expectation:
+    status-code:
+      is:
+        equal-to: 200
+- title: Second of a
+  request:
+    url: {BASE_URL}/slow/2
+  expectation:
+    status-code:
+      is:
+        equal-to: 200
+`,
+		"b.yml": `testcases:
+- title: First of b
+  request:
+    url: {BASE_URL}/fast/1
+  expectation:
+    status-code:
+      is:
+        equal-to: 200
+- title: Second of b
+  pending: true
+  request:
+    url: {BASE_URL}/fast/2
+`,
+		"c.yml": `testcases:
+- title: First of c
+  request:
+    url: {BASE_URL}/fast/3
+  expectation:
+    status-code:
+      is:
+        equal-to: 200
+`,
+	})
+	defer os.RemoveAll(dir)
+
+	output := executeRun(t, &runOptions{ TestDirs: []string{ dir }, Concurrency: 2 })
+
+	assert.Equal(t, int32(4), atomic.LoadInt32(&requests))
+	titles := []string{ "First of a", "Second of a", "First of b", "Second of b", "First of c" }
+	last := -1
+	for _, title := range titles {
+		position := strings.Index(output, title)
+		assert.True(t, position > last, fmt.Sprintf("[%s] must be reported in the order of the files", title))
+		last = position
+	}
+	assert.Contains(t, output, "[*] Total: 5 test case(s), in 3 file(s)")
+	assert.Contains(t, output, "[*] Pending: 1, Skipped: 0, Cracked: 0, Failed: 0, Passed: 4")
+}
+
+func TestRunController_Execute_ConcurrencySharesRateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(200)
+	}))
+	defer server.Close()
+
+	suite := `testcases:
+- title: First
+  request:
+    url: {BASE_URL}/1
+- title: Second
+  request:
+    url: {BASE_URL}/2
+`
+	dir := writeTestSuites(t, server.URL, map[string]string{ "a.yml": suite, "b.yml": suite })
+	defer os.RemoveAll(dir)
+
+	// 4 requests at 10 per second take 300ms at least if the workers share the
+	// limit, 100ms if each worker has its own limiter
+	startTime := time.Now()
+	output := executeRun(t, &runOptions{ TestDirs: []string{ dir }, Concurrency: 2, RateLimit: 10 })
+	assert.True(t, time.Since(startTime) >= 250 * time.Millisecond)
+	assert.Contains(t, output, "Passed: 4")
+}
+
+func TestRunController_Execute_SignedReport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(200)
+	}))
+	defer server.Close()
+
+	dir := writeTestSuites(t, server.URL, map[string]string{
+		"a.yml": `testcases:
+- title: First
+  request:
+    url: {BASE_URL}/1
+`,
+		"opwire-testa.yml": `report-signing:
+  key-file: report.key
+`,
+		"report.key": "s3cr3t",
+	})
+	defer os.RemoveAll(dir)
+
+	reportPath := filepath.Join(dir, "report.json")
+	executeRun(t, &runOptions{ ConfigPath: filepath.Join(dir, "opwire-testa.yml"), TestDirs: []string{ dir }, ReportPath: reportPath })
+
+	report, err := readJsonReport(reportPath)
+	assert.Nil(t, err)
+	assert.Nil(t, verifyJsonReport(report, []byte("s3cr3t")))
+	assert.Equal(t, "1.0.0", report.Provenance.Version)
+	assert.Equal(t, 1, report.Summary.Passed)
+}
+
+func TestRunController_Execute_RetryFailed(t *testing.T) {
+	var attempts sync.Map
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		count, _ := attempts.LoadOrStore(req.Method + " " + req.URL.Path, new(int32))
+		// every request fails the first time
+		if atomic.AddInt32(count.(*int32), 1) == 1 {
+			w.WriteHeader(503)
+			return
+		}
+		w.WriteHeader(200)
+	}))
+	defer server.Close()
+
+	dir := writeTestSuites(t, server.URL, map[string]string{
+		"a.yml": `testcases:
+- title: Read the orders
+  request:
+    method: GET
+    url: {BASE_URL}/orders
+  expectation:
+    status-code:
+      is:
+        equal-to: 200
+- title: Create an order
+  request:
+    method: POST
+    url: {BASE_URL}/orders
+  expectation:
+    status-code:
+      is:
+        equal-to: 200
+- title: Create a refund
+  retry-failed: true
+  request:
+    method: POST
+    url: {BASE_URL}/refunds
+  expectation:
+    status-code:
+      is:
+        equal-to: 200
+`,
+	})
+	defer os.RemoveAll(dir)
+
+	reportPath := filepath.Join(dir, "report.json")
+	output := executeRun(t, &runOptions{ TestDirs: []string{ dir }, RetryFailed: 2, ReportPath: reportPath })
+
+	count := func(key string) int32 {
+		value, _ := attempts.Load(key)
+		return atomic.LoadInt32(value.(*int32))
+	}
+	assert.Equal(t, int32(2), count("GET /orders"))
+	assert.Equal(t, int32(1), count("POST /orders"))
+	assert.Equal(t, int32(2), count("POST /refunds"))
+
+	assert.Contains(t, output, "[*] Pending: 0, Skipped: 0, Cracked: 0, Failed: 1, Passed: 0")
+	assert.Contains(t, output, "[*] Flaky: 2 test case(s) passed after failing, quarantine them")
+
+	report, err := readJsonReport(reportPath)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, report.Summary.Flaky)
+	assert.Equal(t, 1, report.Summary.Failed)
+	statuses := make(map[string]string, 0)
+	for _, testcase := range report.TestCases {
+		statuses[testcase.Title] = testcase.Status
+	}
+	assert.Equal(t, map[string]string{
+		"Read the orders": RESULT_FLAKY,
+		"Create an order": RESULT_FAILED,
+		"Create a refund": RESULT_FLAKY,
+	}, statuses)
+}
+
+func TestRunController_Execute_DryRun(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(200)
+	}))
+	defer server.Close()
+
+	dir := writeTestSuites(t, server.URL, map[string]string{
+		"a.yml": `testcases:
+- title: List the items
+  request:
+    url: {BASE_URL}/items?api_key=k3y-s3cr3t
+    headers:
+    - name: X-Api-Key
+      value: k3y-s3cr3t
+      sensitive: true
+  expectation:
+    status-code:
+      is:
+        equal-to: 200
+- title: Create an item
+  request:
+    method: POST
+    url: {BASE_URL}/items
+    body: '{"name": "first"}'
+`,
+	})
+	defer os.RemoveAll(dir)
+
+	output := executeRun(t, &runOptions{ TestDirs: []string{ dir }, DryRun: true })
+
+	assert.Equal(t, int32(0), atomic.LoadInt32(&requests))
+	assert.Contains(t, output, "GET " + server.URL + "/items?api_key=******")
+	assert.Contains(t, output, "POST " + server.URL + "/items")
+	assert.NotContains(t, output, "k3y-s3cr3t")
+	assert.Contains(t, output, "[*] Planned: 2, Pending: 0, Skipped: 0")
+}
+
+func TestRunController_Execute_UnresolvedExpressions(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(200)
+		w.Write([]byte(`{"data": {}}`))
+	}))
+	defer server.Close()
+
+	dir := writeTestSuites(t, server.URL, map[string]string{
+		"a.yml": `testcases:
+- title: Create an order
+  request:
+    method: POST
+    url: {BASE_URL}/orders
+  capture:
+    variables:
+    - name: orderId
+      json-path: $.data.id
+- title: Read the order
+  request:
+    url: {BASE_URL}/orders/${{vars.orderId}}
+- title: List the orders
+  request:
+    url: {BASE_URL}/orders
+`,
+	})
+	defer os.RemoveAll(dir)
+
+	output := executeRun(t, &runOptions{ TestDirs: []string{ dir } })
+
+	// the dependent test case is reported without a request, the run goes on
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+	assert.Contains(t, output, "Capture/Variables/orderId")
+	assert.Contains(t, output, "Request/Expressions")
+	assert.Contains(t, output, "[*] Pending: 0, Skipped: 0, Cracked: 0, Failed: 2, Passed: 1")
+}
+
+// recordingSink keeps the records and the summary of a run.
+type recordingSink struct {
+	records []*TestRecord
+	summary *RunSummary
+	// the number of records received before Close
+	closedAfter int
+}
+
+func (s *recordingSink) Record(record *TestRecord) error {
+	s.records = append(s.records, record)
+	return nil
+}
+
+func (s *recordingSink) Close(summary *RunSummary) error {
+	s.summary = summary
+	s.closedAfter = len(s.records)
+	return nil
+}
+
+func TestRunController_Execute_ResultSink(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		// the first files are the slowest ones, the workers finish out of order
+		delay, _ := strconv.Atoi(req.URL.Query().Get("delay"))
+		time.Sleep(time.Duration(delay) * time.Millisecond)
+		w.WriteHeader(200)
+	}))
+	defer server.Close()
+
+	suite := func(name string, delay int) string {
+		return fmt.Sprintf(`testcases:
+- title: %s first
+  request:
+    method: GET
+    url: {BASE_URL}/?delay=%d
+  expectation:
+    status-code:
+      is-one-of: [ 200 ]
+- title: %s second
+  pending: true
+  request:
+    method: GET
+    url: {BASE_URL}/
+- title: %s third
+  request:
+    method: GET
+    url: {BASE_URL}/
+  expectation:
+    status-code:
+      is-one-of: [ 404 ]
+`, name, delay, name, name)
+	}
+	dir := writeTestSuites(t, server.URL, map[string]string{
+		"a.yml": suite("a", 60),
+		"b.yml": suite("b", 30),
+		"c.yml": suite("c", 0),
+	})
+	defer os.RemoveAll(dir)
+
+	expected := []string{
+		"a first:passed", "a second:pending", "a third:failed",
+		"b first:passed", "b second:pending", "b third:failed",
+		"c first:passed", "c second:pending", "c third:failed",
+	}
+	for _, concurrency := range []int{ 0, 3 } {
+		ctl, err := NewRunController(&runOptions{ TestDirs: []string{ dir }, Concurrency: concurrency })
+		assert.Nil(t, err)
+		var output bytes.Buffer
+		ctl.GetOutputPrinter().SetWriter(&output)
+		ctl.SetT(t)
+		sink := &recordingSink{}
+		ctl.AddResultSink(sink)
+		assert.Nil(t, ctl.Execute(nil))
+
+		records := make([]string, 0)
+		for _, record := range sink.records {
+			records = append(records, record.TestCase.Title + ":" + record.Status)
+		}
+		assert.Equal(t, expected, records, "concurrency: %d", concurrency)
+		assert.NotNil(t, sink.summary, "concurrency: %d", concurrency)
+		assert.Equal(t, len(expected), sink.closedAfter, "concurrency: %d", concurrency)
+		assert.Equal(t, 9, sink.summary.Total, "concurrency: %d", concurrency)
+		assert.Equal(t, 3, sink.summary.Passed, "concurrency: %d", concurrency)
+		assert.Equal(t, 3, sink.summary.Failed, "concurrency: %d", concurrency)
+		assert.Equal(t, 3, sink.summary.Pending, "concurrency: %d", concurrency)
+	}
+}
+
+func TestRunController_Execute_SensitiveFields(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(200)
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	dir := writeTestSuites(t, server.URL, map[string]string{
+		"a.yml": `testcases:
+- title: Log in
+  request:
+    method: POST
+    url: {BASE_URL}/login?api_key=k3y-s3cr3t
+    body: '{"user": "alice", "password": "pa55-s3cr3t"}'
+    sensitive:
+      query: [ api_key ]
+      body: [ password ]
+  expectation:
+    body:
+      has-format: json
+      includes: '{"user": "bob"}'
+`,
+	})
+	defer os.RemoveAll(dir)
+
+	ctl, err := NewRunController(&runOptions{ TestDirs: []string{ dir } })
+	assert.Nil(t, err)
+	var output bytes.Buffer
+	ctl.GetOutputPrinter().SetWriter(&output)
+	ctl.SetT(t)
+	sink := &recordingSink{}
+	ctl.AddResultSink(sink)
+	assert.Nil(t, ctl.Execute(nil))
+
+	assert.NotContains(t, output.String(), "s3cr3t")
+	assert.Equal(t, 1, len(sink.records))
+	result := sink.records[0].Result
+	assert.Equal(t, RESULT_FAILED, sink.records[0].Status)
+	assert.NotContains(t, result.Request.Url, "k3y-s3cr3t")
+	assert.NotContains(t, result.Request.Body, "pa55-s3cr3t")
+	assert.NotContains(t, string(result.Response.Body), "pa55-s3cr3t")
+	assert.True(t, len(result.Errors) > 0)
+	for _, err := range result.Errors {
+		assert.NotContains(t, err.Error(), "s3cr3t")
+	}
+}
+
+func Test_getShardKeys(t *testing.T) {
+	testsuite := &engine.TestSuite{
+		TestCases: []*engine.TestCase{
+			{ Title: "Create a user" },
+			{ Title: "Create a group" },
+			{ Title: "List the users" },
+			{ Title: "Add the user to the group", DependsOn: []string{ "Create a user", "Create a group" } },
+			{ Title: "Remove the user", DependsOn: []string{ "Add the user to the group" } },
+		},
+	}
+	_, err := testsuite.Schedule()
+	assert.Nil(t, err)
+	keys := getShardKeys(testsuite.TestCases)
+	assert.Equal(t, []string{ "Create a user", "Create a user", "List the users", "Create a user", "Create a user" }, keys)
+
+	// the whole component is examined by one shard, whatever the number of shards
+	for total := 2; total <= 5; total++ {
+		shards := make(map[int]bool, 0)
+		for index := 1; index <= total; index++ {
+			r := &RunController{ shardIndex: index, shardTotal: total }
+			for i, key := range keys {
+				if i != 2 && r.isInShard("users.yml", key) {
+					shards[index] = true
+				}
+			}
+		}
+		assert.Equal(t, 1, len(shards), "%d shards", total)
+	}
+}
@@ -1,8 +1,10 @@
 package bootstrap
 
 import (
+	"fmt"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 	"github.com/opwire/opwire-testa/lib/engine"
@@ -84,6 +86,10 @@ func printScriptSourceArgs(outputPrinter *format.OutputPrinter, scriptSource scr
 		outputPrinter.Println(outputPrinter.ContextInfo("Excluded tags", strings.Join(exclTags, ", ")))
 	}
 
+	for _, expression := range tagManager.GetExpressions() {
+		outputPrinter.Println(outputPrinter.ContextInfo("Tag expression", expression.String()))
+	}
+
 	testName := scriptSelector.GetTestNameFilter()
 	if len(testName) > 0 {
 		outputPrinter.Println(outputPrinter.ContextInfo("Name filter (" + scriptSelector.TypeOfTestNameFilter() + ")", testName))
@@ -153,6 +159,13 @@ func checkFilePathMatchPattern(fullPath string, pattern string) bool {
 		return true
 	}
 
+	// try matching as a glob with "**" (any number of directories)
+	if strings.Contains(pattern, "**") {
+		if reg, err := regexp.Compile(globstarToRegexp(pattern)); err == nil && reg.MatchString(filepath.ToSlash(srcPath)) {
+			return true
+		}
+	}
+
 	// try matching with a regexp
 	if reg, err := regexp.Compile(pattern); err == nil {
 		if reg.MatchString(srcPath) {
@@ -163,6 +176,68 @@ func checkFilePathMatchPattern(fullPath string, pattern string) bool {
 	return false
 }
 
+func globstarToRegexp(pattern string) string {
+	var builder strings.Builder
+	builder.WriteString("(^|/)")
+	pattern = filepath.ToSlash(pattern)
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			builder.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			builder.WriteString(".*")
+			i += 1
+		case pattern[i] == '*':
+			builder.WriteString("[^/]*")
+		case pattern[i] == '?':
+			builder.WriteString("[^/]")
+		default:
+			builder.WriteString(regexp.QuoteMeta(pattern[i:i+1]))
+		}
+	}
+	builder.WriteString("$")
+	return builder.String()
+}
+
+func collectDeprecations(descriptors map[string]*script.Descriptor) []string {
+	deprecations := make([]string, 0)
+	for _, d := range descriptors {
+		for _, deprecation := range d.Deprecations {
+			title := ""
+			if d.TestSuite != nil && deprecation.TestCaseIndex < len(d.TestSuite.TestCases) {
+				title = d.TestSuite.TestCases[deprecation.TestCaseIndex].Title
+			}
+			deprecations = append(deprecations, fmt.Sprintf("%s [%s]: %s", d.Locator.RelativePath, title, deprecation.String()))
+		}
+	}
+	sort.Strings(deprecations)
+	return deprecations
+}
+
+func summarizeWarnings(warnings map[string]int) (int, string) {
+	total := 0
+	categories := make([]string, 0)
+	for category, count := range warnings {
+		if count > 0 {
+			total += count
+			categories = append(categories, fmt.Sprintf("%s: %d", category, count))
+		}
+	}
+	sort.Strings(categories)
+	return total, strings.Join(categories, ", ")
+}
+
+func filterDeprecationsByIndex(deprecations []script.Deprecation, index int) []script.Deprecation {
+	selected := make([]script.Deprecation, 0)
+	for _, deprecation := range deprecations {
+		if deprecation.TestCaseIndex == index {
+			selected = append(selected, deprecation)
+		}
+	}
+	return selected
+}
+
 func filterTestCasesByTags(tagManager *tag.Manager, testcases []*engine.TestCase) (accepted []*engine.TestCase, rejected []*engine.TestCase) {
 	accepted = make([]*engine.TestCase, 0)
 	rejected = make([]*engine.TestCase, 0)
@@ -51,6 +51,27 @@ func Test_checkFilePathMatchPattern(t *testing.T) {
 			pattern: "test/relative/path/*/file.yml",
 			matched: true,
 		},
+		// try matching as a glob with "**"
+		{
+			filePath: filepath.Join(cwd, "test/relative/auth/login/file.yml"),
+			pattern: "auth/**",
+			matched: true,
+		},
+		{
+			filePath: filepath.Join(cwd, "test/relative/auth/login/file.yml"),
+			pattern: "relative/**/file.yml",
+			matched: true,
+		},
+		{
+			filePath: filepath.Join(cwd, "test/relative/oauth/file.yml"),
+			pattern: "auth/**",
+			matched: false,
+		},
+		{
+			filePath: filepath.Join(cwd, "test/relative/auth/file.json"),
+			pattern: "auth/**/*.yml",
+			matched: false,
+		},
 		// try matching with a regexp
 		{
 			filePath: filepath.Join(cwd, "test/relative/path/to/file.yml"),
@@ -62,3 +83,12 @@ func Test_checkFilePathMatchPattern(t *testing.T) {
 		assert.Equal(t, checkFilePathMatchPattern(TEST.filePath, TEST.pattern), TEST.matched)
 	}
 }
+
+func Test_summarizeWarnings(t *testing.T) {
+	total, summary := summarizeWarnings(map[string]int{ "slow": 2, "deprecation": 1, "security": 0 })
+	assert.Equal(t, 3, total)
+	assert.Equal(t, "deprecation: 1, slow: 2", summary)
+	total, summary = summarizeWarnings(map[string]int{})
+	assert.Equal(t, 0, total)
+	assert.Equal(t, "", summary)
+}
@@ -0,0 +1,250 @@
+package bootstrap
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"github.com/opwire/opwire-testa/lib/client"
+	"github.com/opwire/opwire-testa/lib/engine"
+	"github.com/opwire/opwire-testa/lib/format"
+	"github.com/opwire/opwire-testa/lib/script"
+	"github.com/opwire/opwire-testa/lib/tag"
+	"github.com/opwire/opwire-testa/lib/utils"
+)
+
+type SnpControllerOptions interface {
+	script.Source
+	engine.SpecHandlerOptions
+	GetNoColor() bool
+	GetMatcherPlugins() []string
+}
+
+type SnpController struct {
+	scriptLoader *script.Loader
+	scriptSelector *script.Selector
+	scriptSource script.Source
+	tagManager *tag.Manager
+	specHandler *engine.SpecHandler
+	outputPrinter *format.OutputPrinter
+}
+
+func NewSnpController(opts SnpControllerOptions) (ref *SnpController, err error) {
+	ref = &SnpController{}
+
+	// testing temporary storage
+	ref.scriptSource, err = script.NewSource(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// create a Script Loader instance
+	ref.scriptLoader, err = script.NewLoader(ref.scriptSource)
+	if err != nil {
+		return nil, err
+	}
+
+	// create a Script Selector instance
+	ref.scriptSelector, err = script.NewSelector(ref.scriptSource)
+	if err != nil {
+		return nil, err
+	}
+
+	// create a Manager instance
+	ref.tagManager, err = tag.NewManager(ref.scriptSource)
+	if err != nil {
+		return nil, err
+	}
+
+	// create a Spec Handler instance
+	ref.specHandler, err = engine.NewSpecHandler(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// register the custom matchers of the plugins
+	if opts != nil {
+		if err = engine.LoadMatcherPlugins(opts.GetMatcherPlugins()); err != nil {
+			return nil, err
+		}
+	}
+
+	// create a OutputPrinter instance
+	ref.outputPrinter, err = format.NewOutputPrinter(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return ref, err
+}
+
+type SnpDriftArguments interface {
+	GetPrune() bool
+	GetDryRun() bool
+}
+
+type snapshotDrift struct {
+	locator *script.Locator
+	index int
+	title string
+	keys []string
+	gone bool
+}
+
+// Drift re-executes the snapshot test cases and reports the drifted
+// expectations by endpoint, the test cases of the endpoints which respond
+// 404 are removed from their files on demand.
+func (r *SnpController) Drift(args SnpDriftArguments) error {
+	prune := args != nil && args.GetPrune()
+	dryRun := args != nil && args.GetDryRun()
+
+	r.outputPrinter.Println()
+	r.outputPrinter.Println(r.outputPrinter.Heading("Context"))
+	printScriptSourceArgs(r.outputPrinter, r.scriptSource, r.scriptSelector, r.tagManager)
+	if prune && dryRun {
+		r.outputPrinter.Println(r.outputPrinter.ContextInfo("Dry run", "enabled"))
+	}
+
+	// Load testing script files from "test-dirs", invalid ones skipped
+	descriptors, _ := filterInvalidDescriptors(r.scriptLoader.Load())
+	descriptors = filterDescriptorsByInclusivePatterns(descriptors, r.scriptSource.GetInclFiles())
+	descriptors = filterDescriptorsByExclusivePatterns(descriptors, r.scriptSource.GetExclFiles())
+
+	paths := make([]string, 0, len(descriptors))
+	for key := range descriptors {
+		paths = append(paths, key)
+	}
+	sort.Strings(paths)
+
+	r.outputPrinter.Println()
+	r.outputPrinter.Println(r.outputPrinter.Heading("Checking"))
+
+	checked := 0
+	endpoints := make(map[string][]*snapshotDrift)
+	for _, key := range paths {
+		d := descriptors[key]
+		for i, testcase := range d.TestSuite.TestCases {
+			if !r.isSelected(testcase) {
+				continue
+			}
+			checked += 1
+			result, err := r.specHandler.Examine(testcase, d.TestSuite.GetResultCache(), d.TestSuite.GetSession())
+			if err == nil && len(result.Errors) == 0 {
+				continue
+			}
+			drift := &snapshotDrift{ locator: d.Locator, index: i, title: testcase.Title }
+			for name := range result.Errors {
+				drift.keys = append(drift.keys, name)
+			}
+			sort.Strings(drift.keys)
+			_, statusDrifted := result.Errors["StatusCode"]
+			drift.gone = err == nil && statusDrifted && result.Response != nil && result.Response.StatusCode == http.StatusNotFound
+			endpoint := describeEndpoint(testcase.Request, result.Request)
+			endpoints[endpoint] = append(endpoints[endpoint], drift)
+		}
+	}
+
+	names := make([]string, 0, len(endpoints))
+	for endpoint := range endpoints {
+		names = append(names, endpoint)
+	}
+	sort.Strings(names)
+
+	drifted := 0
+	gone := make(map[*script.Locator][]*snapshotDrift)
+	for _, endpoint := range names {
+		r.outputPrinter.Println(r.outputPrinter.TestSuiteTitle(endpoint))
+		for _, drift := range endpoints[endpoint] {
+			drifted += 1
+			label := strings.Join(drift.keys, ", ")
+			if drift.gone {
+				label = "endpoint not found (404)"
+				gone[drift.locator] = append(gone[drift.locator], drift)
+			}
+			r.outputPrinter.Println(r.outputPrinter.Section(fmt.Sprintf("%s [%s]: %s", drift.locator.RelativePath, drift.title, label)))
+		}
+	}
+
+	pruned := 0
+	if prune && len(gone) > 0 {
+		r.outputPrinter.Println()
+		r.outputPrinter.Println(r.outputPrinter.Heading("Pruning"))
+		for locator, drifts := range gone {
+			indexes := make([]int, 0, len(drifts))
+			titles := make([]string, 0, len(drifts))
+			for _, drift := range drifts {
+				indexes = append(indexes, drift.index)
+				titles = append(titles, drift.title)
+			}
+			r.outputPrinter.Println(r.outputPrinter.TestSuiteTitle(locator.RelativePath))
+			r.outputPrinter.Println(r.outputPrinter.Section(strings.Join(titles, "\n")))
+			pruned += len(drifts)
+			if dryRun {
+				continue
+			}
+			if err := pruneScriptFile(locator, indexes); err != nil {
+				r.outputPrinter.Println(r.outputPrinter.Section(err.Error()))
+			}
+		}
+	}
+
+	r.outputPrinter.Println()
+	r.outputPrinter.Println(r.outputPrinter.Heading("Summary"))
+	r.outputPrinter.Printf("[*] Checked: %d snapshot test case(s), drifted: %d, in %d endpoint(s)", checked, drifted, len(names))
+	r.outputPrinter.Println()
+	if prune {
+		r.outputPrinter.Printf("[*] Pruned: %d test case(s)", pruned)
+		r.outputPrinter.Println()
+	}
+	r.outputPrinter.Println()
+	return nil
+}
+
+func (r *SnpController) isSelected(testcase *engine.TestCase) bool {
+	if !utils.Contains(testcase.Tags, engine.SNAPSHOT_TAG) {
+		return false
+	}
+	if testcase.Pending != nil && *testcase.Pending {
+		return false
+	}
+	if testcase.IsDestructive() {
+		return false
+	}
+	if !r.scriptSelector.IsMatched(testcase.Title) {
+		return false
+	}
+	active, _ := r.tagManager.IsActive(testcase.Tags)
+	return active
+}
+
+func describeEndpoint(original *client.HttpRequest, applied *client.HttpRequest) string {
+	req := applied
+	if req == nil {
+		req = original
+	}
+	if req == nil {
+		return "<unknown>"
+	}
+	path := client.BuildUrl(req)
+	if u, err := url.Parse(path); err == nil && len(u.Path) > 0 {
+		path = u.Path
+	}
+	method := strings.ToUpper(req.Method)
+	if len(method) == 0 {
+		method = http.MethodGet
+	}
+	return method + " " + path
+}
+
+func pruneScriptFile(locator *script.Locator, indexes []int) error {
+	content, err := readScriptFile(locator)
+	if err != nil {
+		return err
+	}
+	output, err := script.RemoveTestCases(content, indexes)
+	if err != nil {
+		return err
+	}
+	return writeScriptFile(locator, output)
+}
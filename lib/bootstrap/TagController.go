@@ -0,0 +1,210 @@
+package bootstrap
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"github.com/opwire/opwire-testa/lib/format"
+	"github.com/opwire/opwire-testa/lib/script"
+	"github.com/opwire/opwire-testa/lib/storage"
+	"github.com/opwire/opwire-testa/lib/tag"
+)
+
+type TagControllerOptions interface {
+	script.Source
+	GetNoColor() bool
+}
+
+type TagController struct {
+	scriptLoader *script.Loader
+	scriptSelector *script.Selector
+	scriptSource script.Source
+	tagEditor *tag.Editor
+	tagManager *tag.Manager
+	outputPrinter *format.OutputPrinter
+}
+
+func NewTagController(opts TagControllerOptions) (ref *TagController, err error) {
+	ref = &TagController{}
+
+	// testing temporary storage
+	ref.scriptSource, err = script.NewSource(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// create a Script Loader instance
+	ref.scriptLoader, err = script.NewLoader(ref.scriptSource)
+	if err != nil {
+		return nil, err
+	}
+
+	// create a Script Selector instance
+	ref.scriptSelector, err = script.NewSelector(ref.scriptSource)
+	if err != nil {
+		return nil, err
+	}
+
+	// create a Editor instance
+	ref.tagEditor, err = tag.NewEditor()
+	if err != nil {
+		return nil, err
+	}
+
+	// create a Manager instance
+	ref.tagManager, err = tag.NewManager(ref.scriptSource)
+	if err != nil {
+		return nil, err
+	}
+
+	// create a OutputPrinter instance
+	ref.outputPrinter, err = format.NewOutputPrinter(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return ref, err
+}
+
+type TagAddArguments interface {
+	GetTag() string
+	GetDryRun() bool
+}
+
+func (r *TagController) Add(args TagAddArguments) error {
+	var newTag string
+	if args != nil {
+		newTag = args.GetTag()
+	}
+	if err := tag.ValidateTag(newTag); err != nil {
+		return err
+	}
+
+	r.outputPrinter.Println()
+	r.outputPrinter.Println(r.outputPrinter.Heading("Context"))
+	printScriptSourceArgs(r.outputPrinter, r.scriptSource, r.scriptSelector, r.tagManager)
+
+	dryRun := args.GetDryRun()
+	if dryRun {
+		r.outputPrinter.Println(r.outputPrinter.ContextInfo("Dry run", "enabled"))
+	}
+
+	r.outputPrinter.Println()
+	r.outputPrinter.Println(r.outputPrinter.Heading("Tagging"))
+
+	total := 0
+	for _, d := range r.loadDescriptors() {
+		content, err := readScriptFile(d.Locator)
+		if err != nil {
+			return err
+		}
+		output, updated, err := r.tagEditor.AddTag(content, newTag, r.isSelected)
+		if err != nil {
+			r.outputPrinter.Println(r.outputPrinter.TestSuiteTitle(d.Locator.RelativePath))
+			r.outputPrinter.Println(r.outputPrinter.Section(err.Error()))
+			continue
+		}
+		if len(updated) == 0 {
+			continue
+		}
+		r.outputPrinter.Println(r.outputPrinter.TestSuiteTitle(d.Locator.RelativePath))
+		r.outputPrinter.Println(r.outputPrinter.Section(strings.Join(updated, "\n")))
+		total += len(updated)
+		if dryRun {
+			continue
+		}
+		if err := writeScriptFile(d.Locator, output); err != nil {
+			return err
+		}
+	}
+
+	r.outputPrinter.Println()
+	r.outputPrinter.Println(r.outputPrinter.ContextInfo("Tagged", fmt.Sprintf("%d test case(s) with [%s]", total, newTag)))
+	r.outputPrinter.Println()
+	return nil
+}
+
+type TagListArguments interface {
+	GetCount() bool
+}
+
+func (r *TagController) List(args TagListArguments) error {
+	counter := make(map[string]int)
+	for _, d := range r.loadDescriptors() {
+		content, err := readScriptFile(d.Locator)
+		if err != nil {
+			return err
+		}
+		counts, err := r.tagEditor.CountTags(content, r.isSelected)
+		if err != nil {
+			r.outputPrinter.Println(r.outputPrinter.TestSuiteTitle(d.Locator.RelativePath))
+			r.outputPrinter.Println(r.outputPrinter.Section(err.Error()))
+			continue
+		}
+		for name, count := range counts {
+			counter[name] += count
+		}
+	}
+
+	names := make([]string, 0, len(counter))
+	for name := range counter {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if args != nil && args.GetCount() {
+			r.outputPrinter.Println(fmt.Sprintf("%s\t%d", name, counter[name]))
+		} else {
+			r.outputPrinter.Println(name)
+		}
+	}
+	return nil
+}
+
+func (r *TagController) loadDescriptors() []*script.Descriptor {
+	// Load testing script files from "test-dirs", invalid ones skipped
+	descriptors, _ := filterInvalidDescriptors(r.scriptLoader.Load())
+
+	// filter testing script files by "inclusive-files"
+	descriptors = filterDescriptorsByInclusivePatterns(descriptors, r.scriptSource.GetInclFiles())
+
+	// filter testing script files by "exclusive-files"
+	descriptors = filterDescriptorsByExclusivePatterns(descriptors, r.scriptSource.GetExclFiles())
+
+	selected := make([]*script.Descriptor, 0, len(descriptors))
+	for _, d := range descriptors {
+		selected = append(selected, d)
+	}
+	sort.Slice(selected, func(i, j int) bool {
+		return selected[i].Locator.RelativePath < selected[j].Locator.RelativePath
+	})
+	return selected
+}
+
+func (r *TagController) isSelected(title string, tags []string) bool {
+	if !r.scriptSelector.IsMatched(title) {
+		return false
+	}
+	active, _ := r.tagManager.IsActive(tags)
+	return active
+}
+
+func readScriptFile(locator *script.Locator) ([]byte, error) {
+	file, err := storage.GetFs().Open(locator.AbsolutePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ioutil.ReadAll(file)
+}
+
+func writeScriptFile(locator *script.Locator, content []byte) error {
+	target, err := storage.GetFs().Create(locator.AbsolutePath)
+	if err != nil {
+		return err
+	}
+	defer target.Close()
+	_, err = target.Write(content)
+	return err
+}
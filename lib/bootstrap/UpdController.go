@@ -0,0 +1,90 @@
+package bootstrap
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"github.com/opwire/opwire-testa/lib/format"
+	"github.com/opwire/opwire-testa/lib/update"
+)
+
+type UpdControllerOptions interface {
+	GetVersion() string
+	GetNoColor() bool
+}
+
+type UpdController struct {
+	updater *update.Updater
+	outputPrinter *format.OutputPrinter
+	errWriter io.Writer
+}
+
+func NewUpdController(opts UpdControllerOptions) (ref *UpdController, err error) {
+	ref = &UpdController{}
+
+	// create an Updater instance
+	ref.updater, err = update.NewUpdater(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// create a OutputPrinter instance
+	ref.outputPrinter, err = format.NewOutputPrinter(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return ref, err
+}
+
+type UpdArguments interface {
+	GetCheckOnly() bool
+}
+
+func (r *UpdController) GetErrWriter() io.Writer {
+	if r.errWriter == nil {
+		return os.Stderr
+	}
+	return r.errWriter
+}
+
+func (r *UpdController) SetErrWriter(writer io.Writer) {
+	r.errWriter = writer
+}
+
+func (r *UpdController) Execute(args UpdArguments) error {
+	current := r.updater.GetCurrentVersion()
+	if len(current) == 0 {
+		current = "unknown"
+	}
+	r.outputPrinter.Println(r.outputPrinter.ContextInfo("Current version", current))
+
+	if args != nil && args.GetCheckOnly() {
+		release, err := r.updater.GetLatestRelease()
+		if err != nil {
+			r.outputPrinter.Println(r.outputPrinter.ContextInfo("Error", err.Error()))
+			return err
+		}
+		r.outputPrinter.Println(r.outputPrinter.ContextInfo("Latest version", release.TagName))
+		return nil
+	}
+
+	release, updated, err := r.updater.Update()
+	if err != nil {
+		r.outputPrinter.Println(r.outputPrinter.ContextInfo("Error", err.Error()))
+		return err
+	}
+	if !updated {
+		r.outputPrinter.Println(r.outputPrinter.ContextInfo("Latest version", release.TagName + " (already up to date)"))
+		return nil
+	}
+	r.outputPrinter.Println(r.outputPrinter.ContextInfo("Updated to", release.TagName))
+	return nil
+}
+
+// Notify prints a short notice when a newer release is available.
+func (r *UpdController) Notify() {
+	if latest, ok := r.updater.CheckNewVersion(); ok {
+		fmt.Fprintf(r.GetErrWriter(), "* A new version %s is available, run `opwire-testa self-update` to upgrade\n", r.outputPrinter.InfoMsg(latest))
+	}
+}
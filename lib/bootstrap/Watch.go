@@ -0,0 +1,109 @@
+package bootstrap
+
+import (
+	"os"
+	"os/signal"
+	"path/filepath"
+	"testing"
+	"time"
+	"github.com/opwire/opwire-testa/lib/script"
+	"github.com/opwire/opwire-testa/lib/storage"
+	"github.com/opwire/opwire-testa/lib/utils"
+)
+
+const WATCH_POLL_INTERVAL time.Duration = 500 * time.Millisecond
+
+// wrapWatch examines all of the test suites, then polls the test directories
+// and examines again the test suites of the files which are added or modified,
+// until the process is interrupted. The runs share the test of the watching,
+// the cleanup functions are called when it stops.
+func (r *RunController) wrapWatch() (testing.InternalTest) {
+	return testing.InternalTest{
+		Name: "Watch",
+		F: func(t *testing.T) {
+			cleanups := r.cleanups
+			r.cleanups = nil
+			defer func() {
+				for _, cleanup := range cleanups {
+					cleanup()
+				}
+			}()
+
+			watcher, err := storage.NewWatcher(r.scriptSource.GetTestDirs())
+			if err != nil {
+				r.outputPrinter.Println(r.outputPrinter.Warning(err.Error()))
+				t.Fail()
+				return
+			}
+			r.runWatched(t, nil)
+
+			interrupt := make(chan os.Signal, 1)
+			signal.Notify(interrupt, os.Interrupt)
+			defer signal.Stop(interrupt)
+			ticker := time.NewTicker(WATCH_POLL_INTERVAL)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-interrupt:
+					r.outputPrinter.Println()
+					return
+				case <-ticker.C:
+					changes, err := watcher.Poll()
+					if err != nil {
+						r.outputPrinter.Println(r.outputPrinter.Warning(err.Error()))
+						continue
+					}
+					files := r.selectTestSuiteFiles(changes.Modified)
+					removed := r.selectTestSuiteFiles(changes.Removed)
+					if len(files) == 0 && len(removed) == 0 {
+						continue
+					}
+					r.outputPrinter.Println()
+					r.outputPrinter.Println(r.outputPrinter.Heading("Changes"))
+					if len(files) > 0 {
+						r.outputPrinter.Println(r.outputPrinter.ContextInfo("Modified", "", utils.DetectRelativePaths(files)...))
+					}
+					if len(removed) > 0 {
+						r.outputPrinter.Println(r.outputPrinter.ContextInfo("Removed", "", utils.DetectRelativePaths(removed)...))
+					}
+					if len(files) > 0 {
+						selected := make(map[string]bool, len(files))
+						for _, file := range files {
+							selected[file] = true
+						}
+						r.runWatched(t, selected)
+					} else {
+						r.printWaiting()
+					}
+				}
+			}
+		},
+	}
+}
+
+// runWatched examines the test suites of the given files (all of them if nil).
+func (r *RunController) runWatched(t *testing.T, files map[string]bool) {
+	internalTests, err := r.wrapRun(files)
+	if err != nil {
+		r.outputPrinter.Println(r.outputPrinter.Warning(err.Error()))
+	}
+	runTests(t, internalTests)
+	r.printWaiting()
+}
+
+// selectTestSuiteFiles keeps the paths of the test suite files, selected as the
+// loader does, the other files of the test directories are ignored.
+func (r *RunController) selectTestSuiteFiles(paths []string) []string {
+	selected := make([]string, 0)
+	for _, path := range paths {
+		if script.IsScriptFile(filepath.Base(path)) {
+			selected = append(selected, path)
+		}
+	}
+	return selected
+}
+
+func (r *RunController) printWaiting() {
+	r.outputPrinter.Println(r.outputPrinter.InfoMsg("Waiting for changes of the test suites (Ctrl-C to stop)"))
+}
@@ -0,0 +1,363 @@
+package bundle
+
+import(
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"gopkg.in/yaml.v2"
+	"github.com/opwire/opwire-testa/lib/script"
+	"github.com/opwire/opwire-testa/lib/storage"
+	"github.com/opwire/opwire-testa/lib/utils"
+)
+
+const MANIFEST_NAME string = `bundle.json`
+
+type PackerOptions interface {
+	GetVersion() string
+}
+
+type Packer struct {
+	version string
+}
+
+func NewPacker(opts PackerOptions) (ref *Packer, err error) {
+	ref = &Packer{}
+	if opts != nil {
+		ref.version = opts.GetVersion()
+	}
+	return ref, err
+}
+
+// Manifest describes the content of a bundle. The files are stored by their
+// paths relative to the common directory of the test directories, the
+// configuration file, the plugins and the files which the scripts refer to,
+// so that the relative references are kept.
+type Manifest struct {
+	Version string `json:"version"`
+	CreatedAt time.Time `json:"created-at"`
+	TestDirs []string `json:"test-dirs"`
+	Config string `json:"config,omitempty"`
+	Plugins []string `json:"plugins,omitempty"`
+	Files int `json:"files"`
+}
+
+// Sources lists what is packed into a bundle, the configuration file and the
+// plugins are optional.
+type Sources struct {
+	TestDirs []string
+	ConfigPath string
+	Plugins []string
+}
+
+// Pack stores every file of the test directories (scripts, schemas, fixtures),
+// the data files and schemas which the scripts refer to outside of the test
+// directories, the configuration file and the plugins into a single zip
+// archive.
+func (p *Packer) Pack(sources *Sources, target string) (*Manifest, error) {
+	fs := storage.GetFs()
+	absTarget, _ := filepath.Abs(target)
+
+	testDirs := make([]string, 0)
+	files := make([]string, 0)
+	for _, testDir := range sources.TestDirs {
+		absDir, err := filepath.Abs(testDir)
+		if err != nil {
+			return nil, err
+		}
+		testDirs = append(testDirs, absDir)
+		err = fs.Walk(absDir, func(file string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() || file == absTarget {
+				return nil
+			}
+			files = append(files, file)
+			if script.IsScriptFile(file) {
+				references, err := collectReferences(file)
+				if err != nil {
+					return err
+				}
+				files = append(files, references...)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	var config string
+	if len(sources.ConfigPath) > 0 {
+		config, _ = filepath.Abs(sources.ConfigPath)
+		files = append(files, config)
+	}
+	plugins := make([]string, 0)
+	for _, plugin := range sources.Plugins {
+		absPlugin, _ := filepath.Abs(plugin)
+		plugins = append(plugins, absPlugin)
+		files = append(files, absPlugin)
+	}
+
+	dirs := append([]string{}, testDirs...)
+	for _, file := range files {
+		dirs = append(dirs, filepath.Dir(file))
+	}
+	root := findCommonDir(dirs)
+
+	manifest := &Manifest{ Version: p.version, CreatedAt: time.Now().UTC(), TestDirs: make([]string, 0) }
+	for _, testDir := range testDirs {
+		name, err := getEntryName(root, testDir)
+		if err != nil {
+			return nil, err
+		}
+		manifest.TestDirs = append(manifest.TestDirs, name)
+	}
+	if len(config) > 0 {
+		name, err := getEntryName(root, config)
+		if err != nil {
+			return nil, err
+		}
+		manifest.Config = name
+	}
+	for _, plugin := range plugins {
+		name, err := getEntryName(root, plugin)
+		if err != nil {
+			return nil, err
+		}
+		manifest.Plugins = append(manifest.Plugins, name)
+	}
+
+	output, err := fs.Create(target)
+	if err != nil {
+		return nil, err
+	}
+	defer output.Close()
+
+	archive := zip.NewWriter(output)
+	packed := make(map[string]bool, 0)
+	for _, file := range files {
+		name, err := getEntryName(root, file)
+		if err != nil {
+			archive.Close()
+			return nil, err
+		}
+		if packed[name] {
+			continue
+		}
+		packed[name] = true
+		manifest.Files += 1
+		if err := addFile(archive, file, name); err != nil {
+			archive.Close()
+			return nil, err
+		}
+	}
+
+	writer, err := archive.Create(MANIFEST_NAME)
+	if err != nil {
+		archive.Close()
+		return nil, err
+	}
+	if err := json.NewEncoder(writer).Encode(manifest); err != nil {
+		archive.Close()
+		return nil, err
+	}
+	return manifest, archive.Close()
+}
+
+// collectReferences returns the files (data files, schemas) which a script
+// refers to with relative paths, resolved against the directory of the script.
+func collectReferences(file string) ([]string, error) {
+	input, err := storage.GetFs().Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer input.Close()
+	content, err := ioutil.ReadAll(input)
+	if err != nil {
+		return nil, err
+	}
+	var document interface{}
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return nil, fmt.Errorf("Parsing [%s] failed: %s", file, err.Error())
+	}
+	references := make([]string, 0)
+	var walk func(node interface{}) error
+	walk = func(node interface{}) error {
+		switch value := node.(type) {
+		case map[interface{}]interface{}:
+			for key, child := range value {
+				if ref, ok := child.(string); ok && (key == "data-file" || key == "has-schema") {
+					if strings.HasPrefix(strings.TrimSpace(ref), "{") || filepath.IsAbs(ref) {
+						continue
+					}
+					path := filepath.Join(filepath.Dir(file), ref)
+					if !utils.IsFile(path) {
+						return fmt.Errorf("[%s] referred by [%s] is not found", ref, file)
+					}
+					references = append(references, path)
+					continue
+				}
+				if err := walk(child); err != nil {
+					return err
+				}
+			}
+		case []interface{}:
+			for _, child := range value {
+				if err := walk(child); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	return references, walk(document)
+}
+
+// findCommonDir returns the deepest directory containing all of the given
+// directories.
+func findCommonDir(dirs []string) string {
+	var root string
+	for i, dir := range dirs {
+		if i == 0 {
+			root = dir
+			continue
+		}
+		for !isWithin(dir, root) {
+			parent := filepath.Dir(root)
+			if parent == root {
+				break
+			}
+			root = parent
+		}
+	}
+	return root
+}
+
+func isWithin(path string, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".." + string(os.PathSeparator))
+}
+
+func getEntryName(root string, path string) (string, error) {
+	if !isWithin(path, root) {
+		return "", fmt.Errorf("[%s] cannot be packed with the other files of the bundle", path)
+	}
+	rel, _ := filepath.Rel(root, path)
+	return filepath.ToSlash(rel), nil
+}
+
+func addFile(archive *zip.Writer, file string, name string) error {
+	input, err := storage.GetFs().Open(file)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	writer, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(writer, input)
+	return err
+}
+
+// Unpack extracts a bundle into the target directory and returns its
+// manifest, the test directories are resolved to absolute paths.
+func (p *Packer) Unpack(source string, targetDir string) (*Manifest, error) {
+	archive, err := zip.OpenReader(source)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+
+	var manifest *Manifest
+	for _, entry := range archive.File {
+		if entry.Name == MANIFEST_NAME {
+			manifest, err = readManifest(entry)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err := extractFile(entry, targetDir); err != nil {
+			return nil, err
+		}
+	}
+	if manifest == nil {
+		return nil, fmt.Errorf("[%s] is not a bundle, the %s file is missing", source, MANIFEST_NAME)
+	}
+	for i, folder := range manifest.TestDirs {
+		if manifest.TestDirs[i], err = resolveEntry(targetDir, folder); err != nil {
+			return nil, err
+		}
+	}
+	if len(manifest.Config) > 0 {
+		if manifest.Config, err = resolveEntry(targetDir, manifest.Config); err != nil {
+			return nil, err
+		}
+	}
+	for i, plugin := range manifest.Plugins {
+		if manifest.Plugins[i], err = resolveEntry(targetDir, plugin); err != nil {
+			return nil, err
+		}
+	}
+	return manifest, nil
+}
+
+// resolveEntry returns the path of an entry of the bundle in the target
+// directory, the entries must not escape from it.
+func resolveEntry(targetDir string, name string) (string, error) {
+	target := filepath.Join(targetDir, filepath.FromSlash(name))
+	if target != filepath.Clean(targetDir) && !strings.HasPrefix(target, filepath.Clean(targetDir) + string(os.PathSeparator)) {
+		return "", fmt.Errorf("Illegal file path [%s] in the bundle", name)
+	}
+	return target, nil
+}
+
+func readManifest(entry *zip.File) (*Manifest, error) {
+	reader, err := entry.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	content, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	manifest := &Manifest{}
+	if err := json.Unmarshal(content, manifest); err != nil {
+		return nil, fmt.Errorf("Invalid %s file: %s", MANIFEST_NAME, err.Error())
+	}
+	return manifest, nil
+}
+
+func extractFile(entry *zip.File, targetDir string) error {
+	target, err := resolveEntry(targetDir, entry.Name)
+	if err != nil {
+		return err
+	}
+	if entry.FileInfo().IsDir() {
+		return nil
+	}
+	fs := storage.GetFs()
+	if err := fs.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	reader, err := entry.Open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	output, err := fs.Create(target)
+	if err != nil {
+		return err
+	}
+	defer output.Close()
+	_, err = io.Copy(output, reader)
+	return err
+}
@@ -0,0 +1,112 @@
+package bundle
+
+import(
+	"archive/zip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPacker_PackAndUnpack(t *testing.T) {
+	root, err := ioutil.TempDir("", "opwire-testa-bundle-")
+	assert.Nil(t, err)
+	defer os.RemoveAll(root)
+
+	files := map[string]string{
+		"tests/auth/login.yml": "testcases: []\n",
+		"tests/schemas/user.json": "{}",
+		"more/tests/echo.yml": "testcases:\n- title: Echo\n  data-file: ../../data/users.csv\n  expectation:\n    body:\n      has-schema: '{\"type\": \"object\"}'\n",
+		"data/users.csv": "name\nalice\n",
+		"opwire-testa.yml": "test-dirs: [tests, more/tests]\n",
+		"plugins/matcher.so": "ELF",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		assert.Nil(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.Nil(t, ioutil.WriteFile(path, []byte(content), 0644))
+	}
+
+	p, _ := NewPacker(nil)
+	archive := filepath.Join(root, "bundle.zip")
+	manifest, err := p.Pack(&Sources{
+		TestDirs: []string{ filepath.Join(root, "tests"), filepath.Join(root, "more/tests") },
+		ConfigPath: filepath.Join(root, "opwire-testa.yml"),
+		Plugins: []string{ filepath.Join(root, "plugins/matcher.so") },
+	}, archive)
+	assert.Nil(t, err)
+	assert.Equal(t, 6, manifest.Files)
+	assert.Equal(t, []string{ "tests", "more/tests" }, manifest.TestDirs)
+	assert.Equal(t, "opwire-testa.yml", manifest.Config)
+	assert.Equal(t, []string{ "plugins/matcher.so" }, manifest.Plugins)
+
+	target := filepath.Join(root, "extracted")
+	unpacked, err := p.Unpack(archive, target)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{ filepath.Join(target, "tests"), filepath.Join(target, "more", "tests") }, unpacked.TestDirs)
+	assert.Equal(t, filepath.Join(target, "opwire-testa.yml"), unpacked.Config)
+	assert.Equal(t, []string{ filepath.Join(target, "plugins", "matcher.so") }, unpacked.Plugins)
+	content, err := ioutil.ReadFile(filepath.Join(target, "tests", "schemas", "user.json"))
+	assert.Nil(t, err)
+	assert.Equal(t, "{}", string(content))
+	_, err = os.Stat(filepath.Join(target, "more", "tests", "echo.yml"))
+	assert.Nil(t, err)
+	// the data file is found by the relative path of the script
+	content, err = ioutil.ReadFile(filepath.Join(target, "more", "tests", "..", "..", "data", "users.csv"))
+	assert.Nil(t, err)
+	assert.Equal(t, "name\nalice\n", string(content))
+}
+
+func TestPacker_PackMissingReference(t *testing.T) {
+	root, err := ioutil.TempDir("", "opwire-testa-bundle-")
+	assert.Nil(t, err)
+	defer os.RemoveAll(root)
+
+	assert.Nil(t, os.MkdirAll(filepath.Join(root, "tests"), 0755))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(root, "tests", "users.yml"), []byte("testcases:\n- title: Users\n  data-file: users.csv\n"), 0644))
+
+	p, _ := NewPacker(nil)
+	_, err = p.Pack(&Sources{ TestDirs: []string{ filepath.Join(root, "tests") } }, filepath.Join(root, "bundle.zip"))
+	assert.NotNil(t, err)
+}
+
+func TestPacker_UnpackIllegalPath(t *testing.T) {
+	root, err := ioutil.TempDir("", "opwire-testa-bundle-")
+	assert.Nil(t, err)
+	defer os.RemoveAll(root)
+
+	archive := filepath.Join(root, "evil.zip")
+	output, err := os.Create(archive)
+	assert.Nil(t, err)
+	writer := zip.NewWriter(output)
+	entry, _ := writer.Create("../escaped.yml")
+	entry.Write([]byte("testcases: []"))
+	writer.Close()
+	output.Close()
+
+	p, _ := NewPacker(nil)
+	_, err = p.Unpack(archive, filepath.Join(root, "extracted"))
+	assert.NotNil(t, err)
+	_, err = os.Stat(filepath.Join(root, "escaped.yml"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestPacker_UnpackIllegalTestDir(t *testing.T) {
+	root, err := ioutil.TempDir("", "opwire-testa-bundle-")
+	assert.Nil(t, err)
+	defer os.RemoveAll(root)
+
+	archive := filepath.Join(root, "evil.zip")
+	output, err := os.Create(archive)
+	assert.Nil(t, err)
+	writer := zip.NewWriter(output)
+	entry, _ := writer.Create(MANIFEST_NAME)
+	entry.Write([]byte(`{"test-dirs": ["../../etc"]}`))
+	writer.Close()
+	output.Close()
+
+	p, _ := NewPacker(nil)
+	_, err = p.Unpack(archive, filepath.Join(root, "extracted"))
+	assert.EqualError(t, err, "Illegal file path [../../etc] in the bundle")
+}
@@ -0,0 +1,90 @@
+package client
+
+import(
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+var gzipMagic = []byte{ 0x1f, 0x8b }
+
+// GetContentEncodings lists the codings of the Content-Encoding header, in the
+// order they have been applied; "identity" is left out.
+func (r *HttpResponse) GetContentEncodings() []string {
+	codings := make([]string, 0)
+	for _, value := range r.Header[http.CanonicalHeaderKey("Content-Encoding")] {
+		for _, coding := range strings.Split(value, ",") {
+			coding = strings.ToLower(strings.TrimSpace(coding))
+			if len(coding) > 0 && coding != "identity" {
+				codings = append(codings, coding)
+			}
+		}
+	}
+	return codings
+}
+
+// DecodeContent reverts the content codings of the body, the decoded size is
+// recorded in DecodedSize. A body which is still gzip compressed afterwards is
+// reported as compressed twice, unless the resource is itself a gzip file.
+func (r *HttpResponse) DecodeContent() ([]byte, error) {
+	codings := r.GetContentEncodings()
+	content := r.Body
+	for i := len(codings) - 1; i >= 0; i-- {
+		decoded, err := decodeCoding(codings[i], content)
+		if err != nil {
+			if _, unsupported := err.(*UnsupportedCodingError); unsupported {
+				return r.Body, err
+			}
+			return r.Body, fmt.Errorf("Response body does not decompress as [%s]: %s", codings[i], err.Error())
+		}
+		content = decoded
+	}
+	r.DecodedSize = int64(len(content))
+	if len(codings) > 0 && bytes.HasPrefix(content, gzipMagic) && !isGzipMediaType(r.Header.Get("Content-Type")) {
+		return content, fmt.Errorf("Response body is still gzip compressed after decoding [%s], it has been compressed twice", strings.Join(codings, ", "))
+	}
+	return content, nil
+}
+
+func decodeCoding(coding string, content []byte) ([]byte, error) {
+	switch coding {
+	case "gzip", "x-gzip":
+		reader, err := gzip.NewReader(bytes.NewReader(content))
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+		return ioutil.ReadAll(reader)
+	case "deflate":
+		// "deflate" is zlib wrapped, some servers send the raw stream though
+		if reader, err := zlib.NewReader(bytes.NewReader(content)); err == nil {
+			defer reader.Close()
+			return ioutil.ReadAll(reader)
+		}
+		reader := flate.NewReader(bytes.NewReader(content))
+		defer reader.Close()
+		return ioutil.ReadAll(reader)
+	}
+	return nil, &UnsupportedCodingError{ Coding: coding }
+}
+
+// UnsupportedCodingError is returned for the content codings which cannot be
+// decoded (e.g. br), the body is left undecoded.
+type UnsupportedCodingError struct {
+	Coding string
+}
+
+func (e *UnsupportedCodingError) Error() string {
+	return fmt.Sprintf("Content coding [%s] is unsupported", e.Coding)
+}
+
+func isGzipMediaType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (mediaType == "application/gzip" || mediaType == "application/x-gzip")
+}
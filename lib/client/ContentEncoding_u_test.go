@@ -0,0 +1,87 @@
+package client
+
+import(
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func gzipped(content []byte) []byte {
+	buf := &bytes.Buffer{}
+	writer := gzip.NewWriter(buf)
+	writer.Write(content)
+	writer.Close()
+	return buf.Bytes()
+}
+
+func TestHttpResponse_DecodeContent(t *testing.T) {
+	text := []byte(`{"message":"hello"}`)
+	zlibBuf := &bytes.Buffer{}
+	zw := zlib.NewWriter(zlibBuf)
+	zw.Write(text)
+	zw.Close()
+	flateBuf := &bytes.Buffer{}
+	fw, _ := flate.NewWriter(flateBuf, flate.DefaultCompression)
+	fw.Write(text)
+	fw.Close()
+
+	TESTCASES := []struct {
+		encoding string
+		contentType string
+		body []byte
+		decoded []byte
+		failed bool
+	}{
+		{ encoding: "gzip", body: gzipped(text), decoded: text },
+		{ encoding: "deflate", body: zlibBuf.Bytes(), decoded: text },
+		{ encoding: "deflate", body: flateBuf.Bytes(), decoded: text },
+		{ encoding: "gzip", body: gzipped(gzipped(text)), failed: true },
+		{ encoding: "gzip, gzip", body: gzipped(gzipped(text)), decoded: text },
+		{ encoding: "gzip", contentType: "application/gzip", body: gzipped(gzipped(text)), decoded: gzipped(text) },
+		{ encoding: "gzip", body: text, failed: true },
+		{ encoding: "identity", body: text, decoded: text },
+	}
+	for i, c := range TESTCASES {
+		res := &HttpResponse{ Header: http.Header{ "Content-Encoding": []string{ c.encoding } }, Body: c.body }
+		if len(c.contentType) > 0 {
+			res.Header.Set("Content-Type", c.contentType)
+		}
+		decoded, err := res.DecodeContent()
+		assert.Equal(t, c.failed, err != nil, "testcase #%d", i)
+		if c.decoded != nil {
+			assert.Equal(t, c.decoded, decoded, "testcase #%d", i)
+			assert.Equal(t, int64(len(c.decoded)), res.DecodedSize, "testcase #%d", i)
+		}
+	}
+
+	res := &HttpResponse{ Header: http.Header{ "Content-Encoding": []string{ "br" } }, Body: text }
+	_, err := res.DecodeContent()
+	_, unsupported := err.(*UnsupportedCodingError)
+	assert.True(t, unsupported)
+}
+
+func TestHttpInvoker_DecodedSize(t *testing.T) {
+	text := bytes.Repeat([]byte("opwire "), 100)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(gzipped(text))
+	}))
+	defer server.Close()
+
+	invoker, err := NewHttpInvoker(&HttpInvokerOptions{})
+	assert.Nil(t, err)
+
+	res, err := invoker.Do(&HttpRequest{ Method: "GET", Url: server.URL, Headers: []HttpHeader{{ Name: "Accept-Encoding", Value: "gzip" }} })
+	assert.Nil(t, err)
+	assert.Equal(t, []string{ "gzip" }, res.GetContentEncodings())
+	assert.Equal(t, res.BodySize, res.DecodedSize)
+	decoded, err := res.DecodeContent()
+	assert.Nil(t, err)
+	assert.Equal(t, text, decoded)
+	assert.Equal(t, int64(len(text)), res.DecodedSize)
+}
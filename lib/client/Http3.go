@@ -0,0 +1,11 @@
+package client
+
+import(
+	"crypto/tls"
+	"net/http"
+)
+
+// newHttp3Transport is provided by the builds tagged with "http3",
+// keeping the QUIC dependencies out of the default binary. The TLS settings
+// may be nil.
+var newHttp3Transport func(tlsConfig *tls.Config) http.RoundTripper
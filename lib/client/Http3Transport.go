@@ -0,0 +1,15 @@
+// +build http3
+
+package client
+
+import(
+	"crypto/tls"
+	"net/http"
+	"github.com/quic-go/quic-go/http3"
+)
+
+func init() {
+	newHttp3Transport = func(tlsConfig *tls.Config) http.RoundTripper {
+		return &http3.Transport{ TLSClientConfig: tlsConfig }
+	}
+}
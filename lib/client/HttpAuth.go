@@ -0,0 +1,69 @@
+package client
+
+import(
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"github.com/Azure/go-ntlmssp"
+	krb5client "github.com/jcmturner/gokrb5/v8/client"
+	krb5config "github.com/jcmturner/gokrb5/v8/config"
+	"github.com/jcmturner/gokrb5/v8/credentials"
+	"github.com/jcmturner/gokrb5/v8/spnego"
+)
+
+const AUTH_TYPE_NTLM string = "ntlm"
+const AUTH_TYPE_NEGOTIATE string = "negotiate"
+
+type HttpAuth struct {
+	Type string `yaml:"type" json:"type"`
+	Username string `yaml:"username,omitempty" json:"username"`
+	Password string `yaml:"password,omitempty" json:"password"`
+	ServicePrincipal string `yaml:"service-principal,omitempty" json:"service-principal"`
+}
+
+// decorate prepares the request and wraps the transport with the handshake
+// required by the authentication scheme.
+func (a *HttpAuth) decorate(transport http.RoundTripper, req *http.Request) (http.RoundTripper, error) {
+	switch a.Type {
+	case AUTH_TYPE_NTLM:
+		// the negotiator converts the basic credentials into the NTLM handshake
+		req.SetBasicAuth(a.Username, a.Password)
+		return ntlmssp.Negotiator{ RoundTripper: transport }, nil
+	case AUTH_TYPE_NEGOTIATE:
+		return transport, a.setNegotiateHeader(req)
+	}
+	return transport, fmt.Errorf("Unsupported authentication type [%s]", a.Type)
+}
+
+// setNegotiateHeader builds the SPNEGO token from the Kerberos tickets of the host.
+func (a *HttpAuth) setNegotiateHeader(req *http.Request) error {
+	cfg, err := krb5config.Load(getKrb5ConfigPath())
+	if err != nil {
+		return fmt.Errorf("Loading Kerberos configuration failed: %s", err.Error())
+	}
+	ccache, err := credentials.LoadCCache(getKrb5CCachePath())
+	if err != nil {
+		return fmt.Errorf("Loading Kerberos credentials cache failed: %s", err.Error())
+	}
+	cl, err := krb5client.NewFromCCache(ccache, cfg, krb5client.DisablePAFXFAST(true))
+	if err != nil {
+		return err
+	}
+	defer cl.Destroy()
+	return spnego.SetSPNEGOHeader(cl, req, a.ServicePrincipal)
+}
+
+func getKrb5ConfigPath() string {
+	if path := os.Getenv("KRB5_CONFIG"); len(path) > 0 {
+		return path
+	}
+	return "/etc/krb5.conf"
+}
+
+func getKrb5CCachePath() string {
+	if path := os.Getenv("KRB5CCNAME"); len(path) > 0 {
+		return strings.TrimPrefix(path, "FILE:")
+	}
+	return fmt.Sprintf("/tmp/krb5cc_%d", os.Getuid())
+}
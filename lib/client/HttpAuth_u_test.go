@@ -0,0 +1,65 @@
+package client
+
+import(
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHttpAuth_NTLM(t *testing.T) {
+	authorizations := make([]string, 0)
+	invoker, err := NewHttpInvoker(&HttpInvokerOptions{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			authorizations = append(authorizations, req.Header.Get("Authorization"))
+			res := &http.Response{
+				StatusCode: 401,
+				Header: http.Header{ "Www-Authenticate": []string{"NTLM"} },
+				Body: ioutil.NopCloser(strings.NewReader("")),
+				Request: req,
+			}
+			return res, nil
+		}),
+	})
+	assert.Nil(t, err)
+
+	res, err := invoker.Do(&HttpRequest{
+		Method: "GET",
+		Url: "http://example.test/",
+		Auth: &HttpAuth{ Type: AUTH_TYPE_NTLM, Username: `DOMAIN\user`, Password: "secret" },
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 401, res.StatusCode)
+	// anonymous attempt, then the NTLM negotiate message
+	assert.True(t, len(authorizations) >= 2)
+	assert.Equal(t, "", authorizations[0])
+	assert.True(t, strings.HasPrefix(authorizations[1], "NTLM "))
+}
+
+func TestHttpAuth_Unsupported(t *testing.T) {
+	invoker, _ := NewHttpInvoker(nil)
+	_, err := invoker.Do(&HttpRequest{
+		Url: "http://example.test/",
+		Auth: &HttpAuth{ Type: "digest" },
+	})
+	assert.NotNil(t, err)
+}
+
+func TestHttpAuth_NegotiateWithoutCredentials(t *testing.T) {
+	os.Setenv("KRB5_CONFIG", "/nonexistent/krb5.conf")
+	defer os.Unsetenv("KRB5_CONFIG")
+	invoker, _ := NewHttpInvoker(nil)
+	_, err := invoker.Do(&HttpRequest{
+		Url: "http://example.test/",
+		Auth: &HttpAuth{ Type: AUTH_TYPE_NEGOTIATE },
+	})
+	assert.NotNil(t, err)
+}
+
+func TestGetKrb5CCachePath(t *testing.T) {
+	os.Setenv("KRB5CCNAME", "FILE:/tmp/krb5cc_test")
+	defer os.Unsetenv("KRB5CCNAME")
+	assert.Equal(t, "/tmp/krb5cc_test", getKrb5CCachePath())
+}
@@ -2,9 +2,16 @@ package client
 
 import (
 	"bytes"
+	"crypto/tls"
+	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 	"github.com/opwire/opwire-testa/lib/utils"
 )
@@ -13,30 +20,80 @@ type HttpInvoker interface {
 	Do(req *HttpRequest, interceptors ...Interceptor) (res *HttpResponse, err error)
 }
 
+// RequestPreparer completes the requests with the defaults of an invoker,
+// without sending them.
+type RequestPreparer interface {
+	Prepare(req *HttpRequest)
+}
+
 type HttpInvokerOptions struct {
 	PDP string
+	DefaultHeaders []HttpHeader
+	RateLimit float64
+	RequestDelay time.Duration
+	// RateLimiter is shared by the invokers of a run (replacing the limiter
+	// built from RateLimit and RequestDelay), so that their requests together
+	// respect the limits.
+	RateLimiter *RateLimiter
+	MaxResponseSize int64
+	Transport http.RoundTripper
+	Http3 bool
 }
 
 type HttpInvokerImpl struct {
 	pdp string
+	defaultHeaders []HttpHeader
+	limiter *RateLimiter
+	maxResponseSize int64
+	transport http.RoundTripper
 }
 
 func NewHttpInvoker(opts *HttpInvokerOptions) (c *HttpInvokerImpl, err error) {
 	c = &HttpInvokerImpl{}
 	if opts != nil {
 		c.pdp = opts.PDP
+		c.defaultHeaders = opts.DefaultHeaders
+		c.limiter = opts.RateLimiter
+		if c.limiter == nil {
+			c.limiter = NewRateLimiter(opts.RateLimit, opts.RequestDelay)
+		}
+		c.maxResponseSize = opts.MaxResponseSize
+		c.transport = opts.Transport
+		if opts.Http3 {
+			if newHttp3Transport == nil {
+				return nil, fmt.Errorf("HTTP/3 is not supported by this build, rebuild with the [http3] tag")
+			}
+			// the HTTP/3 transport takes over the TLS settings of the given
+			// transport, other kinds of transport cannot be carried over QUIC
+			var tlsConfig *tls.Config
+			switch transport := opts.Transport.(type) {
+			case nil:
+			case *http.Transport:
+				tlsConfig = transport.TLSClientConfig
+			default:
+				return nil, fmt.Errorf("HTTP/3 cannot be combined with a custom transport [%T]", opts.Transport)
+			}
+			c.transport = newHttp3Transport(tlsConfig)
+		}
 	}
 	return c, nil
 }
 
+// Prepare sets the PDP and the default headers of the invoker which the
+// request does not define.
+func (c *HttpInvokerImpl) Prepare(req *HttpRequest) {
+	if len(req.PDP) == 0 {
+		req.PDP = c.pdp
+	}
+	req.addDefaultHeaders(c.defaultHeaders)
+}
+
 func (c *HttpInvokerImpl) Do(req *HttpRequest, interceptors ...Interceptor) (*HttpResponse, error) {
 	if req == nil {
 		return nil, fmt.Errorf("Request must not be nil")
 	}
 
-	if len(req.PDP) == 0 {
-		req.PDP = c.pdp
-	}
+	c.Prepare(req)
 
 	var reqTimeout time.Duration
 	if req.Timeout != nil {
@@ -47,14 +104,61 @@ func (c *HttpInvokerImpl) Do(req *HttpRequest, interceptors ...Interceptor) (*Ht
 		}
 	}
 
+	lowReq, err := req.GetRawRequest()
+	if err != nil {
+		return nil, err
+	}
+
+	transport := c.transport
+	if req.Auth != nil {
+		transport, err = req.Auth.decorate(transport, lowReq)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var redirects []RedirectHop
 	var httpClient *http.Client = &http.Client{
 		Timeout: reqTimeout,
+		Transport: transport,
+		CheckRedirect: func(next *http.Request, via []*http.Request) error {
+			if len(via) >= MAX_REDIRECTS {
+				return fmt.Errorf("stopped after %d redirects", MAX_REDIRECTS)
+			}
+			if next.Response != nil {
+				redirects = append(redirects, RedirectHop{
+					Url: via[len(via) - 1].URL.String(),
+					StatusCode: next.Response.StatusCode,
+					Location: next.Response.Header.Get("Location"),
+				})
+			}
+			return nil
+		},
 	}
 
-	lowReq, err := req.GetRawRequest()
+	// Attach the Idempotency-Key header
+	idempotencyKey, err := req.resolveIdempotencyKey()
 	if err != nil {
 		return nil, err
 	}
+	if len(idempotencyKey) > 0 {
+		lowReq.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+
+	// Trace the connection and the interim 100 Continue response
+	var gotContinue bool
+	var network NetworkInfo
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			network.setAddresses(info.Conn)
+		},
+	}
+	if req.ExpectContinue != nil && *req.ExpectContinue {
+		trace.Got100Continue = func() {
+			gotContinue = true
+		}
+	}
+	lowReq = lowReq.WithContext(httptrace.WithClientTrace(lowReq.Context(), trace))
 
 	// Pre-processing
 	for _, interceptor := range interceptors {
@@ -63,6 +167,9 @@ func (c *HttpInvokerImpl) Do(req *HttpRequest, interceptors ...Interceptor) (*Ht
 		}
 	}
 
+	// Wait for the rate limiter
+	c.limiter.Wait()
+
 	// Make HTTP request
 	lowRes, err := httpClient.Do(lowReq)
 	if lowRes != nil && lowRes.Body != nil {
@@ -72,10 +179,15 @@ func (c *HttpInvokerImpl) Do(req *HttpRequest, interceptors ...Interceptor) (*Ht
 		return nil, err
 	}
 
-	res, err := NewHttpResponse(lowRes)
+	res, err := NewLimitedHttpResponse(lowRes, c.maxResponseSize)
 	if err != nil {
 		return nil, err
 	}
+	res.GotContinue = gotContinue
+	network.setTLS(lowRes.TLS)
+	res.Network = network
+	res.IdempotencyKey = idempotencyKey
+	res.Redirects = redirects
 
 	// Post-processing
 	for _, interceptor := range interceptors {
@@ -103,9 +215,231 @@ func BuildUrl(req *HttpRequest) string {
 	return url
 }
 
+// HasNoBody reports whether a response has no body by definition (RFC 7230, section 3.3.3)
+func HasNoBody(method string, statusCode int) bool {
+	if strings.ToUpper(method) == http.MethodHead {
+		return true
+	}
+	return (statusCode >= 100 && statusCode < 200) || statusCode == http.StatusNoContent || statusCode == http.StatusNotModified
+}
+
+// ParseAllowedMethods collects the methods listed in the Allow header,
+// or in the Access-Control-Allow-Methods header of a CORS preflight response.
+func ParseAllowedMethods(header http.Header) []string {
+	values := header[http.CanonicalHeaderKey("Allow")]
+	if len(values) == 0 {
+		values = header[http.CanonicalHeaderKey("Access-Control-Allow-Methods")]
+	}
+	methods := make([]string, 0)
+	for _, value := range values {
+		for _, method := range utils.Split(value, ",") {
+			methods = append(methods, strings.ToUpper(method))
+		}
+	}
+	return methods
+}
+
 type HttpHeader struct {
 	Name string `yaml:"name" json:"name"`
 	Value string `yaml:"value" json:"value"`
+	Sensitive bool `yaml:"sensitive,omitempty" json:"sensitive"`
+}
+
+const SENSITIVE_MASK string = "******"
+
+// GetSensitiveValues collects the values which must be masked in the outputs.
+func (r *HttpRequest) GetSensitiveValues() []string {
+	values := make([]string, 0)
+	if r == nil {
+		return values
+	}
+	for _, header := range r.Headers {
+		if header.Sensitive && len(header.Value) > 0 {
+			values = append(values, header.Value)
+		}
+	}
+	if r.Auth != nil && len(r.Auth.Password) > 0 {
+		values = append(values, r.Auth.Password)
+	}
+	if r.Sensitive != nil {
+		values = append(values, r.Sensitive.collectQueryValues(BuildUrl(r))...)
+		values = append(values, r.Sensitive.collectBodyValues(r.Body)...)
+	}
+	return values
+}
+
+// Redact returns a copy of the request with the secrets masked in its URL,
+// path, headers, body and password.
+func (r *HttpRequest) Redact(secrets []string) *HttpRequest {
+	if r == nil {
+		return nil
+	}
+	clone := *r
+	clone.Url = utils.Redact(r.Url, secrets, SENSITIVE_MASK)
+	clone.Path = utils.Redact(r.Path, secrets, SENSITIVE_MASK)
+	clone.Body = utils.Redact(r.Body, secrets, SENSITIVE_MASK)
+	if r.Headers != nil {
+		clone.Headers = make([]HttpHeader, len(r.Headers))
+		for i, header := range r.Headers {
+			header.Value = utils.Redact(header.Value, secrets, SENSITIVE_MASK)
+			clone.Headers[i] = header
+		}
+	}
+	if r.Auth != nil {
+		auth := *r.Auth
+		auth.Password = utils.Redact(auth.Password, secrets, SENSITIVE_MASK)
+		clone.Auth = &auth
+	}
+	clone.request = nil
+	return &clone
+}
+
+// SensitiveFields names the fields of the request whose values must be masked
+// in the outputs: the parameters of the query, and the fields of the body
+// (dot separated paths of a JSON body, or the names of a form body).
+type SensitiveFields struct {
+	Query []string `yaml:"query,omitempty" json:"query"`
+	Body []string `yaml:"body,omitempty" json:"body"`
+}
+
+func (f *SensitiveFields) collectQueryValues(rawUrl string) []string {
+	values := make([]string, 0)
+	if len(f.Query) == 0 {
+		return values
+	}
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return values
+	}
+	query := u.Query()
+	for _, name := range f.Query {
+		for _, value := range query[name] {
+			values = appendEncodedValue(values, value, url.QueryEscape(value))
+		}
+	}
+	return values
+}
+
+func (f *SensitiveFields) collectBodyValues(body string) []string {
+	values := make([]string, 0)
+	if len(f.Body) == 0 || len(body) == 0 {
+		return values
+	}
+	var document interface{}
+	decoder := json.NewDecoder(strings.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&document); err == nil {
+		for _, path := range f.Body {
+			values = collectJsonLeaves(values, findJsonField(document, strings.Split(path, ".")))
+		}
+		return values
+	}
+	if form, err := url.ParseQuery(body); err == nil {
+		for _, name := range f.Body {
+			for _, value := range form[name] {
+				values = appendEncodedValue(values, value, url.QueryEscape(value))
+			}
+		}
+	}
+	return values
+}
+
+func findJsonField(node interface{}, path []string) interface{} {
+	for _, key := range path {
+		switch value := node.(type) {
+		case map[string]interface{}:
+			node = value[key]
+		case []interface{}:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(value) {
+				return nil
+			}
+			node = value[index]
+		default:
+			return nil
+		}
+	}
+	return node
+}
+
+// collectJsonLeaves appends the scalar values of a field, as they are written
+// in the body and as they are decoded.
+func collectJsonLeaves(values []string, node interface{}) []string {
+	switch value := node.(type) {
+	case map[string]interface{}:
+		for _, child := range value {
+			values = collectJsonLeaves(values, child)
+		}
+	case []interface{}:
+		for _, child := range value {
+			values = collectJsonLeaves(values, child)
+		}
+	case string:
+		encoded, _ := json.Marshal(value)
+		values = appendEncodedValue(values, value, strings.Trim(string(encoded), `"`))
+	case json.Number:
+		values = append(values, value.String())
+	}
+	return values
+}
+
+func appendEncodedValue(values []string, value string, encoded string) []string {
+	if len(value) == 0 {
+		return values
+	}
+	values = append(values, value)
+	if encoded != value {
+		values = append(values, encoded)
+	}
+	return values
+}
+
+// addDefaultHeaders appends the headers which the request does not define.
+func (r *HttpRequest) addDefaultHeaders(headers []HttpHeader) {
+	for _, header := range headers {
+		found := false
+		for _, existing := range r.Headers {
+			if strings.EqualFold(existing.Name, header.Name) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			r.Headers = append(r.Headers, header)
+			r.request = nil
+		}
+	}
+}
+
+// IsIdempotent reports whether sending the request several times has the
+// same effect as sending it once (RFC 7231), an empty method is a GET.
+func (r *HttpRequest) IsIdempotent() bool {
+	switch strings.ToUpper(r.Method) {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// WithMethod returns a copy of the request to be sent with another method.
+func (r *HttpRequest) WithMethod(method string) *HttpRequest {
+	clone := *r
+	clone.Method = method
+	clone.request = nil
+	return &clone
+}
+
+// WithHeader returns a copy of the request with a header added or replaced.
+func (r *HttpRequest) WithHeader(name string, value string) *HttpRequest {
+	clone := r.WithMethod(r.Method)
+	clone.Headers = make([]HttpHeader, 0, len(r.Headers) + 1)
+	for _, header := range r.Headers {
+		if !strings.EqualFold(header.Name, name) {
+			clone.Headers = append(clone.Headers, header)
+		}
+	}
+	clone.Headers = append(clone.Headers, HttpHeader{ Name: name, Value: value })
+	return clone
 }
 
 type HttpRequest struct {
@@ -116,9 +450,36 @@ type HttpRequest struct {
 	Headers []HttpHeader `yaml:"headers,omitempty" json:"headers"`
 	Body string `yaml:"body,omitempty" json:"body"`
 	Timeout *string `yaml:"timeout,omitempty" json:"timeout"`
+	ExpectContinue *bool `yaml:"expect-continue,omitempty" json:"expect-continue"`
+	IdempotencyKey string `yaml:"idempotency-key,omitempty" json:"idempotency-key"`
+	ConditionalOn string `yaml:"conditional-on,omitempty" json:"conditional-on"`
+	Auth *HttpAuth `yaml:"auth,omitempty" json:"auth"`
+	Sensitive *SensitiveFields `yaml:"sensitive,omitempty" json:"sensitive"`
+	idempotencyKey string
 	request *http.Request
 }
 
+const IDEMPOTENCY_KEY_AUTO string = "auto"
+const IDEMPOTENCY_KEY_AUTO_PER_ATTEMPT string = "auto-per-attempt"
+
+func (r *HttpRequest) resolveIdempotencyKey() (string, error) {
+	switch r.IdempotencyKey {
+	case IDEMPOTENCY_KEY_AUTO:
+		if len(r.idempotencyKey) > 0 {
+			return r.idempotencyKey, nil
+		}
+	case IDEMPOTENCY_KEY_AUTO_PER_ATTEMPT:
+	default:
+		return r.IdempotencyKey, nil
+	}
+	key, err := utils.GenerateUUID()
+	if err != nil {
+		return utils.BLANK, err
+	}
+	r.idempotencyKey = key
+	return key, nil
+}
+
 func (r *HttpRequest) GetRawRequest() (req *http.Request, err error) {
 	if r.request == nil {
 		url := BuildUrl(r)
@@ -147,6 +508,10 @@ func (r *HttpRequest) GetRawRequest() (req *http.Request, err error) {
 			}
 		}
 
+		if r.ExpectContinue != nil && *r.ExpectContinue {
+			req.Header.Set("Expect", "100-continue")
+		}
+
 		r.request = req
 	}
 	return r.request, nil
@@ -157,12 +522,68 @@ type HttpResponse struct {
 	StatusCode int
 	Version string
 	Header http.Header
+	Trailer http.Header
 	ContentLength int64
 	Body []byte
+	BodySize int64
+	BodyTruncated bool
+	DecodedSize int64
+	GotContinue bool
+	IdempotencyKey string
+	Network NetworkInfo
+	Redirects []RedirectHop
 	response *http.Response
 }
 
+// Redact returns a copy of the response with the secrets masked in its
+// headers and body (a server may echo them).
+func (r *HttpResponse) Redact(secrets []string) *HttpResponse {
+	if r == nil {
+		return nil
+	}
+	clone := *r
+	clone.Header = redactHeader(r.Header, secrets)
+	clone.Trailer = redactHeader(r.Trailer, secrets)
+	clone.Body = []byte(utils.Redact(string(r.Body), secrets, SENSITIVE_MASK))
+	clone.response = nil
+	return &clone
+}
+
+func redactHeader(header http.Header, secrets []string) http.Header {
+	if header == nil {
+		return nil
+	}
+	clone := make(http.Header, len(header))
+	for name, values := range header {
+		for _, value := range values {
+			clone[name] = append(clone[name], utils.Redact(value, secrets, SENSITIVE_MASK))
+		}
+	}
+	return clone
+}
+
+const MAX_REDIRECTS int = 10
+
+// RedirectHop is an intermediate response of a followed redirect chain.
+type RedirectHop struct {
+	Url string
+	StatusCode int
+	Location string
+}
+
+// Cookies parses the Set-Cookie headers of the response.
+func (r *HttpResponse) Cookies() []*http.Cookie {
+	return (&http.Response{ Header: r.Header }).Cookies()
+}
+
 func NewHttpResponse(lowRes *http.Response) (res *HttpResponse, err error) {
+	return NewLimitedHttpResponse(lowRes, 0)
+}
+
+// NewLimitedHttpResponse keeps at most maxBodySize bytes of the body (0 means unlimited),
+// the remaining content is not read (an endless stream would never end), the
+// BodySize of a truncated body is the Content-Length if the server sent one.
+func NewLimitedHttpResponse(lowRes *http.Response, maxBodySize int64) (res *HttpResponse, err error) {
 	if lowRes == nil {
 		return nil, fmt.Errorf("The source http.Reponse must not be nil")
 	}
@@ -175,9 +596,39 @@ func NewHttpResponse(lowRes *http.Response) (res *HttpResponse, err error) {
 	res.Header = lowRes.Header
 
 	res.ContentLength = lowRes.ContentLength
-	res.Body, err = ioutil.ReadAll(lowRes.Body)
-	if err != nil {
-		return nil, err
+	if maxBodySize > 0 {
+		// one more byte tells whether the body exceeds the limit
+		res.Body, err = ioutil.ReadAll(io.LimitReader(lowRes.Body, maxBodySize + 1))
+		if err != nil {
+			return nil, err
+		}
+		res.BodySize = int64(len(res.Body))
+		if res.BodySize > maxBodySize {
+			lowRes.Body.Close()
+			res.Body = res.Body[:maxBodySize]
+			res.BodyTruncated = true
+			if lowRes.ContentLength > res.BodySize {
+				res.BodySize = lowRes.ContentLength
+			}
+		}
+	} else {
+		res.Body, err = ioutil.ReadAll(lowRes.Body)
+		if err != nil {
+			return nil, err
+		}
+		res.BodySize = int64(len(res.Body))
+	}
+
+	// updated by DecodeContent when the body is compressed
+	res.DecodedSize = res.BodySize
+
+	// the trailers are known once the body has been read, the announced
+	// ones which have not been sent are left out
+	res.Trailer = make(http.Header)
+	for name, values := range lowRes.Trailer {
+		if len(values) > 0 {
+			res.Trailer[name] = values
+		}
 	}
 
 	res.response = lowRes
@@ -0,0 +1,321 @@
+package client
+
+import(
+	"crypto/tls"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"github.com/stretchr/testify/assert"
+)
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestHttpInvoker_Transport(t *testing.T) {
+	var received *http.Request
+	invoker, err := NewHttpInvoker(&HttpInvokerOptions{
+		PDP: "http://example.test",
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			received = req
+			return &http.Response{
+				StatusCode: 201,
+				Header: http.Header{ "Content-Type": []string{"text/plain"} },
+				Body: ioutil.NopCloser(strings.NewReader("from transport")),
+				Request: req,
+			}, nil
+		}),
+	})
+	assert.Nil(t, err)
+
+	res, err := invoker.Do(&HttpRequest{ Method: "GET", Path: "/hello" })
+	assert.Nil(t, err)
+	assert.NotNil(t, received)
+	assert.Equal(t, "http://example.test/hello", received.URL.String())
+	assert.Equal(t, 201, res.StatusCode)
+	assert.Equal(t, "from transport", string(res.Body))
+}
+
+func TestHttpInvoker_DefaultHeaders(t *testing.T) {
+	var received *http.Request
+	invoker, err := NewHttpInvoker(&HttpInvokerOptions{
+		PDP: "http://example.test",
+		DefaultHeaders: []HttpHeader{
+			{ Name: "Authorization", Value: "Bearer default", Sensitive: true },
+			{ Name: "X-Tenant", Value: "acme" },
+		},
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			received = req
+			return &http.Response{ StatusCode: 200, Header: http.Header{}, Body: ioutil.NopCloser(strings.NewReader("")), Request: req }, nil
+		}),
+	})
+	assert.Nil(t, err)
+
+	req := &HttpRequest{ Method: "GET", Path: "/hello", Headers: []HttpHeader{ { Name: "x-tenant", Value: "other" } } }
+	_, err = invoker.Do(req)
+	assert.Nil(t, err)
+	assert.Equal(t, "Bearer default", received.Header.Get("Authorization"))
+	assert.Equal(t, "other", received.Header.Get("X-Tenant"))
+	assert.Equal(t, []string{ "Bearer default" }, req.GetSensitiveValues())
+
+	_, err = invoker.Do(req)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(req.Headers))
+}
+
+func TestHttpInvoker_Redirects(t *testing.T) {
+	invoker, err := NewHttpInvoker(&HttpInvokerOptions{
+		PDP: "http://example.test",
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			res := &http.Response{ StatusCode: 200, Header: http.Header{}, Body: ioutil.NopCloser(strings.NewReader("")), Request: req }
+			switch req.URL.Path {
+			case "/old":
+				res.StatusCode = 301
+				res.Header.Set("Location", "/login?next=/home")
+			case "/login":
+				res.StatusCode = 302
+				res.Header.Set("Location", "http://example.test/home")
+			case "/loop":
+				res.StatusCode = 307
+				res.Header.Set("Location", "/loop")
+			}
+			return res, nil
+		}),
+	})
+	assert.Nil(t, err)
+
+	res, err := invoker.Do(&HttpRequest{ Method: "GET", Path: "/old" })
+	assert.Nil(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+	assert.Equal(t, []RedirectHop{
+		{ Url: "http://example.test/old", StatusCode: 301, Location: "/login?next=/home" },
+		{ Url: "http://example.test/login?next=/home", StatusCode: 302, Location: "http://example.test/home" },
+	}, res.Redirects)
+
+	res, err = invoker.Do(&HttpRequest{ Method: "GET", Path: "/home" })
+	assert.Nil(t, err)
+	assert.Nil(t, res.Redirects)
+
+	_, err = invoker.Do(&HttpRequest{ Method: "GET", Path: "/loop" })
+	assert.NotNil(t, err)
+}
+
+func TestHttpInvoker_Trailers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Trailer", "Grpc-Status, X-Checksum, X-Unsent")
+		w.Write([]byte("streamed"))
+		w.Header().Set("Grpc-Status", "0")
+		w.Header().Set("X-Checksum", "abc123")
+	}))
+	defer server.Close()
+
+	invoker, err := NewHttpInvoker(&HttpInvokerOptions{ PDP: server.URL })
+	assert.Nil(t, err)
+	res, err := invoker.Do(&HttpRequest{ Method: "GET", Path: "/" })
+	assert.Nil(t, err)
+	assert.Equal(t, "streamed", string(res.Body))
+	assert.Equal(t, http.Header{ "Grpc-Status": []string{"0"}, "X-Checksum": []string{"abc123"} }, res.Trailer)
+}
+
+func TestHttpInvoker_ExpectContinue(t *testing.T) {
+	expects := make([]string, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		expects = append(expects, r.Header.Get("Expect"))
+		if r.URL.Path == "/reject" {
+			// answers without reading the body, the interim response is not sent
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	invoker, err := NewHttpInvoker(&HttpInvokerOptions{ PDP: server.URL })
+	assert.Nil(t, err)
+	expectContinue := true
+
+	TESTCASES := []struct {
+		path string
+		expectContinue *bool
+		header string
+		gotContinue bool
+		statusCode int
+	}{
+		{ path: "/accept", expectContinue: &expectContinue, header: "100-continue", gotContinue: true, statusCode: 200 },
+		{ path: "/reject", expectContinue: &expectContinue, header: "100-continue", gotContinue: false, statusCode: 413 },
+		{ path: "/accept", expectContinue: nil, header: "", gotContinue: false, statusCode: 200 },
+	}
+	for i, tc := range TESTCASES {
+		expects = expects[:0]
+		res, err := invoker.Do(&HttpRequest{ Method: "POST", Path: tc.path, Body: "payload", ExpectContinue: tc.expectContinue })
+		assert.Nil(t, err, "case #%d", i)
+		assert.Equal(t, tc.statusCode, res.StatusCode, "case #%d", i)
+		assert.Equal(t, tc.gotContinue, res.GotContinue, "case #%d", i)
+		assert.Equal(t, []string{ tc.header }, expects, "case #%d", i)
+		if tc.statusCode == 200 {
+			assert.Equal(t, "payload", string(res.Body), "case #%d", i)
+		}
+	}
+}
+
+func TestHttpInvoker_Http3(t *testing.T) {
+	invoker, err := NewHttpInvoker(&HttpInvokerOptions{ Http3: true })
+	if newHttp3Transport == nil {
+		assert.Nil(t, invoker)
+		assert.NotNil(t, err)
+	} else {
+		assert.Nil(t, err)
+		assert.NotNil(t, invoker.transport)
+	}
+}
+
+func TestHttpInvoker_Http3_Transport(t *testing.T) {
+	original := newHttp3Transport
+	defer func() { newHttp3Transport = original }()
+	var given *tls.Config
+	newHttp3Transport = func(tlsConfig *tls.Config) http.RoundTripper {
+		given = tlsConfig
+		return roundTripperFunc(nil)
+	}
+
+	t.Run("TLS settings of the environment are kept", func(t *testing.T) {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = &tls.Config{ ServerName: "api.internal" }
+		_, err := NewHttpInvoker(&HttpInvokerOptions{ Http3: true, Transport: transport })
+		assert.Nil(t, err)
+		assert.Equal(t, transport.TLSClientConfig, given)
+	})
+
+	t.Run("Custom transports are refused", func(t *testing.T) {
+		invoker, err := NewHttpInvoker(&HttpInvokerOptions{ Http3: true, Transport: roundTripperFunc(nil) })
+		assert.Nil(t, invoker)
+		assert.EqualError(t, err, "HTTP/3 cannot be combined with a custom transport [client.roundTripperFunc]")
+	})
+}
+
+func TestHttpRequest_GetSensitiveValues(t *testing.T) {
+	req := &HttpRequest{
+		Headers: []HttpHeader{
+			{ Name: "Accept", Value: "application/json" },
+			{ Name: "Authorization", Value: "Bearer abc123", Sensitive: true },
+		},
+		Auth: &HttpAuth{ Type: AUTH_TYPE_NTLM, Username: "user", Password: "secret" },
+	}
+	assert.Equal(t, []string{"Bearer abc123", "secret"}, req.GetSensitiveValues())
+	assert.Equal(t, 0, len((*HttpRequest)(nil).GetSensitiveValues()))
+}
+
+func TestHttpRequest_GetSensitiveValues_Fields(t *testing.T) {
+	t.Run("Query parameters and JSON body fields", func(t *testing.T) {
+		req := &HttpRequest{
+			Url: "http://example.test/login?api_key=k%2B1&page=2",
+			Body: `{"user":"alice","password":"p\"w","card":{"number":4111,"cvc":"123"},"tokens":["t1","t2"]}`,
+			Sensitive: &SensitiveFields{
+				Query: []string{ "api_key" },
+				Body: []string{ "password", "card", "tokens.1", "missing.field" },
+			},
+		}
+		values := req.GetSensitiveValues()
+		assert.ElementsMatch(t, []string{ "k+1", "k%2B1", `p"w`, `p\"w`, "4111", "123", "t2" }, values)
+	})
+
+	t.Run("Form body fields", func(t *testing.T) {
+		req := &HttpRequest{
+			Body: "user=alice&password=s%26cret",
+			Sensitive: &SensitiveFields{ Body: []string{ "password" } },
+		}
+		assert.Equal(t, []string{ "s&cret", "s%26cret" }, req.GetSensitiveValues())
+	})
+}
+
+func TestHttpRequest_WithMethod(t *testing.T) {
+	head := &HttpRequest{ Method: http.MethodHead, Url: "http://localhost:17779/echo" }
+	built, err := head.GetRawRequest()
+	assert.Nil(t, err)
+	assert.Equal(t, http.MethodHead, built.Method)
+	get := head.WithMethod(http.MethodGet)
+	assert.Equal(t, http.MethodHead, head.Method)
+	built, err = get.GetRawRequest()
+	assert.Nil(t, err)
+	assert.Equal(t, http.MethodGet, built.Method)
+	assert.Equal(t, head.Url, built.URL.String())
+}
+
+func TestHttpRequest_IsIdempotent(t *testing.T) {
+	TESTCASES := []struct {
+		method string
+		idempotent bool
+	}{
+		{ method: "", idempotent: true },
+		{ method: "get", idempotent: true },
+		{ method: http.MethodPut, idempotent: true },
+		{ method: http.MethodDelete, idempotent: true },
+		{ method: http.MethodPost, idempotent: false },
+		{ method: http.MethodPatch, idempotent: false },
+	}
+	for i, c := range TESTCASES {
+		req := &HttpRequest{ Method: c.method }
+		assert.Equal(t, c.idempotent, req.IsIdempotent(), "testcase #%d", i)
+	}
+}
+
+func TestHttpInvoker_MaxResponseSize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/sized" {
+			w.Header().Set("Content-Length", "1000")
+			w.Write([]byte(strings.Repeat("x", 1000)))
+			return
+		}
+		if req.URL.Path == "/small" {
+			w.Write([]byte("0123456789"))
+			return
+		}
+		// an endless stream, until the client goes away
+		for {
+			if _, err := w.Write([]byte(strings.Repeat("x", 100))); err != nil {
+				return
+			}
+			w.(http.Flusher).Flush()
+			select {
+			case <-req.Context().Done():
+				return
+			case <-time.After(time.Millisecond):
+			}
+		}
+	}))
+	defer server.Close()
+
+	invoker, err := NewHttpInvoker(&HttpInvokerOptions{ PDP: server.URL, MaxResponseSize: 10 })
+	assert.Nil(t, err)
+
+	done := make(chan *HttpResponse, 1)
+	go func() {
+		res, err := invoker.Do(&HttpRequest{ Method: "GET", Path: "/stream" })
+		assert.Nil(t, err)
+		done <- res
+	}()
+	select {
+	case res := <-done:
+		assert.True(t, res.BodyTruncated)
+		assert.Equal(t, "xxxxxxxxxx", string(res.Body))
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "The endless response must be stopped at the limit")
+	}
+
+	res, err := invoker.Do(&HttpRequest{ Method: "GET", Path: "/sized" })
+	assert.Nil(t, err)
+	assert.True(t, res.BodyTruncated)
+	assert.Equal(t, int64(1000), res.BodySize)
+	assert.Equal(t, 10, len(res.Body))
+
+	res, err = invoker.Do(&HttpRequest{ Method: "GET", Path: "/small" })
+	assert.Nil(t, err)
+	assert.False(t, res.BodyTruncated)
+	assert.Equal(t, int64(10), res.BodySize)
+}
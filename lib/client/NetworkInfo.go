@@ -0,0 +1,64 @@
+package client
+
+import(
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"strconv"
+)
+
+// NetworkInfo describes the connection which has served a response.
+type NetworkInfo struct {
+	RemoteIP string
+	RemotePort int
+	LocalPort int
+	TLSVersion string
+	CipherSuite string
+	ALPN string
+	Certificates []*x509.Certificate
+}
+
+func (n *NetworkInfo) setAddresses(conn net.Conn) {
+	if conn == nil {
+		return
+	}
+	if conn.RemoteAddr() != nil {
+		n.RemoteIP, n.RemotePort = splitHostPort(conn.RemoteAddr().String())
+	}
+	if conn.LocalAddr() != nil {
+		_, n.LocalPort = splitHostPort(conn.LocalAddr().String())
+	}
+}
+
+func (n *NetworkInfo) setTLS(state *tls.ConnectionState) {
+	if state == nil {
+		return
+	}
+	n.TLSVersion = GetTLSVersionName(state.Version)
+	n.CipherSuite = tls.CipherSuiteName(state.CipherSuite)
+	n.ALPN = state.NegotiatedProtocol
+	n.Certificates = state.PeerCertificates
+}
+
+func splitHostPort(addr string) (string, int) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr, 0
+	}
+	number, _ := strconv.Atoi(port)
+	return host, number
+}
+
+var tlsVersionNames = map[uint16]string{
+	tls.VersionTLS10: "1.0",
+	tls.VersionTLS11: "1.1",
+	tls.VersionTLS12: "1.2",
+	tls.VersionTLS13: "1.3",
+}
+
+func GetTLSVersionName(version uint16) string {
+	if name, ok := tlsVersionNames[version]; ok {
+		return name
+	}
+	return "0x" + strconv.FormatUint(uint64(version), 16)
+}
@@ -0,0 +1,35 @@
+package client
+
+import(
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetTLSVersionName(t *testing.T) {
+	assert.Equal(t, "1.2", GetTLSVersionName(tls.VersionTLS12))
+	assert.Equal(t, "1.3", GetTLSVersionName(tls.VersionTLS13))
+	assert.Equal(t, "0x1", GetTLSVersionName(1))
+}
+
+func TestHttpInvoker_NetworkInfo(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(204)
+	}))
+	defer server.Close()
+
+	invoker, err := NewHttpInvoker(&HttpInvokerOptions{ Transport: server.Client().Transport })
+	assert.Nil(t, err)
+
+	res, err := invoker.Do(&HttpRequest{ Method: "GET", Url: server.URL })
+	assert.Nil(t, err)
+	assert.Equal(t, "127.0.0.1", res.Network.RemoteIP)
+	assert.True(t, res.Network.RemotePort > 0)
+	assert.True(t, res.Network.LocalPort > 0)
+	assert.NotEqual(t, "", res.Network.TLSVersion)
+	assert.NotEqual(t, "", res.Network.CipherSuite)
+	assert.Equal(t, 1, len(res.Network.Certificates))
+	assert.Nil(t, res.Network.Certificates[0].VerifyHostname("example.com"))
+}
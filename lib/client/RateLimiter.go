@@ -0,0 +1,46 @@
+package client
+
+import (
+	"sync"
+	"time"
+)
+
+type RateLimiter struct {
+	interval time.Duration
+	lastTime time.Time
+	mutex sync.Mutex
+}
+
+func NewRateLimiter(rate float64, delay time.Duration) *RateLimiter {
+	interval := delay
+	if rate > 0 {
+		if byRate := time.Duration(float64(time.Second) / rate); byRate > interval {
+			interval = byRate
+		}
+	}
+	if interval <= 0 {
+		return nil
+	}
+	return &RateLimiter{ interval: interval }
+}
+
+func (l *RateLimiter) GetInterval() time.Duration {
+	if l == nil {
+		return 0
+	}
+	return l.interval
+}
+
+func (l *RateLimiter) Wait() {
+	if l == nil {
+		return
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if !l.lastTime.IsZero() {
+		if remaining := l.interval - time.Since(l.lastTime); remaining > 0 {
+			time.Sleep(remaining)
+		}
+	}
+	l.lastTime = time.Now()
+}
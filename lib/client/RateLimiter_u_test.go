@@ -0,0 +1,20 @@
+package client
+
+import(
+	"testing"
+	"time"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRateLimiter(t *testing.T) {
+	t.Run("Disabled", func(t *testing.T) {
+		assert.Nil(t, NewRateLimiter(0, 0))
+		assert.Equal(t, time.Duration(0), NewRateLimiter(0, 0).GetInterval())
+	})
+
+	t.Run("The longest interval wins", func(t *testing.T) {
+		assert.Equal(t, 200 * time.Millisecond, NewRateLimiter(5, 0).GetInterval())
+		assert.Equal(t, 300 * time.Millisecond, NewRateLimiter(5, 300 * time.Millisecond).GetInterval())
+		assert.Equal(t, 500 * time.Millisecond, NewRateLimiter(2, 100 * time.Millisecond).GetInterval())
+	})
+}
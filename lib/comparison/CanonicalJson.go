@@ -0,0 +1,65 @@
+package comparison
+
+import(
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// CanonicalizeJson decodes a JSON document keeping the exact value of its
+// numbers, which are rewritten in a canonical form (1.0, 1e0 and 1 are the
+// same number); the escapes of the strings are resolved by the decoding.
+func CanonicalizeJson(source []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(source))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return canonicalizeValue(value), nil
+}
+
+// RenderCanonicalJson serializes a canonicalized value with the keys sorted
+// and one member per line, so that two renderings are compared as texts.
+func RenderCanonicalJson(value interface{}) (string, error) {
+	buf := &bytes.Buffer{}
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+func canonicalizeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = canonicalizeValue(item)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = canonicalizeValue(item)
+		}
+		return v
+	case json.Number:
+		return canonicalizeNumber(v)
+	}
+	return value
+}
+
+// canonicalizeNumber renders the integers with all of their digits and the
+// other numbers with the shortest representation of their float64 value.
+func canonicalizeNumber(num json.Number) json.Number {
+	if r, ok := new(big.Rat).SetString(num.String()); ok && r.IsInt() {
+		return json.Number(r.Num().String())
+	}
+	if f, err := strconv.ParseFloat(num.String(), 64); err == nil {
+		return json.Number(strconv.FormatFloat(f, 'g', -1, 64))
+	}
+	return num
+}
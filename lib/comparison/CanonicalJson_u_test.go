@@ -0,0 +1,35 @@
+package comparison
+
+import(
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonicalizeJson(t *testing.T) {
+	TESTCASES := []struct {
+		x string
+		y string
+		equal bool
+	}{
+		{ x: `{"a": 1, "b": 2}`, y: `{"b":2,"a":1}`, equal: true },
+		{ x: `{"price": 10}`, y: `{"price": 10.0}`, equal: true },
+		{ x: `{"price": 1e3}`, y: `{"price": 1000}`, equal: true },
+		{ x: `{"rate": 0.50}`, y: `{"rate": 5e-1}`, equal: true },
+		{ x: `{"name": "caf\u00e9"}`, y: `{"name": "café"}`, equal: true },
+		{ x: `{"html": "\u003cb\u003e"}`, y: `{"html": "<b>"}`, equal: true },
+		{ x: `{"id": 12345678901234567890}`, y: `{"id": 12345678901234567891}`, equal: false },
+		{ x: `[1, 2]`, y: `[2, 1]`, equal: false },
+		{ x: `{"a": "1"}`, y: `{"a": 1}`, equal: false },
+	}
+	for i, c := range TESTCASES {
+		x, err := CanonicalizeJson([]byte(c.x))
+		assert.Nil(t, err, "testcase #%d", i)
+		y, err := CanonicalizeJson([]byte(c.y))
+		assert.Nil(t, err, "testcase #%d", i)
+		xText, _ := RenderCanonicalJson(x)
+		yText, _ := RenderCanonicalJson(y)
+		assert.Equal(t, c.equal, xText == yText, "testcase #%d", i)
+	}
+	_, err := CanonicalizeJson([]byte(`{"a":`))
+	assert.NotNil(t, err)
+}
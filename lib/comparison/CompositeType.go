@@ -12,10 +12,37 @@ func DeepDiff(x interface{}, y interface{}) (bool, string) {
 	return diff != "", diff
 }
 
+// IsPartOf reports whether the decoded part is a structural subset of the
+// whole: the key order and the formatting do not matter, the extra fields of
+// the whole are ignored and array elements are matched in order.
 func IsPartOf(part interface{}, whole interface{}) (bool, string) {
 	var r DiffReporter
-	diff := cmp.Diff(part, whole, cmp.Reporter(&r))
-	return !r.HasDiffs(), diff
+	cmp.Equal(part, whole, cmp.Reporter(&r))
+	if !r.HasDiffs() {
+		return true, ""
+	}
+	// compare with the projection of the whole, to hide the ignored fields
+	return false, cmp.Diff(part, project(part, whole))
+}
+
+// project keeps only the fields of the whole which are present in the part,
+// arrays are kept as is because their elements are matched as a subsequence.
+func project(part interface{}, whole interface{}) interface{} {
+	switch p := part.(type) {
+	case map[string]interface{}:
+		w, ok := whole.(map[string]interface{})
+		if !ok {
+			return whole
+		}
+		result := make(map[string]interface{}, len(p))
+		for key, value := range p {
+			if wValue, found := w[key]; found {
+				result[key] = project(value, wValue)
+			}
+		}
+		return result
+	}
+	return whole
 }
 
 type DiffReporter struct {
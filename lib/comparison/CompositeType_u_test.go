@@ -1,6 +1,7 @@
 package comparison
 
 import(
+	"encoding/json"
 	"testing"
 	"reflect"
 	"github.com/stretchr/testify/assert"
@@ -30,3 +31,40 @@ func TestIsZero(t *testing.T) {
 		assert.False(t, IsZero(reflect.ValueOf(v)))
 	})
 }
+
+func TestIsPartOf(t *testing.T) {
+	TESTCASES := []struct {
+		part string
+		whole string
+		ok bool
+	}{
+		{ part: `{"b": 2, "a": 1}`, whole: `{"a":1,"b":2,"c":3}`, ok: true },
+		{ part: "{\n  \"a\": {\"x\": 1}\n}", whole: `{"a":{"x":1,"y":2}}`, ok: true },
+		{ part: `{"items": [2]}`, whole: `{"items":[1,2,3]}`, ok: true },
+		{ part: `{"items": [{"id": 2}]}`, whole: `{"items":[{"id":1,"n":"x"},{"id":2,"n":"y"}]}`, ok: true },
+		{ part: `{"items": [3, 1]}`, whole: `{"items":[1,2,3]}`, ok: false },
+		{ part: `{"a": 1}`, whole: `{"a":"1"}`, ok: false },
+		{ part: `{"a": null}`, whole: `{"a":1}`, ok: false },
+		{ part: `{"z": 1}`, whole: `{"a":1}`, ok: false },
+	}
+	for i, c := range TESTCASES {
+		var part, whole map[string]interface{}
+		assert.Nil(t, json.Unmarshal([]byte(c.part), &part))
+		assert.Nil(t, json.Unmarshal([]byte(c.whole), &whole))
+		ok, _ := IsPartOf(part, whole)
+		assert.Equal(t, c.ok, ok, "testcase #%d", i)
+	}
+}
+
+func TestIsPartOf_Diff(t *testing.T) {
+	part := map[string]interface{}{ "a": 1.0 }
+	whole := map[string]interface{}{ "a": 2.0, "b": "ignored" }
+	ok, diff := IsPartOf(part, whole)
+	assert.False(t, ok)
+	assert.Contains(t, diff, `"a"`)
+	assert.NotContains(t, diff, `"b"`)
+
+	ok, diff = IsPartOf(part, map[string]interface{}{ "a": 1.0, "b": "ignored" })
+	assert.True(t, ok)
+	assert.Equal(t, "", diff)
+}
@@ -2,6 +2,8 @@ package comparison
 
 import (
 	"fmt"
+	"math"
+	"strconv"
 )
 
 func IsEqualTo(rVal, eVal interface{}) (bool, error) {
@@ -22,3 +24,63 @@ func BelongsTo(val interface{}, list []interface{}) bool {
 	}
 	return false
 }
+
+func CompareNumbers(rVal, eVal interface{}) (int, error) {
+	rNum, err := ToFloat64(rVal)
+	if err != nil {
+		return 0, err
+	}
+	eNum, err := ToFloat64(eVal)
+	if err != nil {
+		return 0, err
+	}
+	if rNum < eNum {
+		return -1, nil
+	}
+	if rNum > eNum {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// IsCloseTo reports whether the distance between the numbers does not
+// exceed the delta.
+func IsCloseTo(rVal interface{}, eVal float64, delta float64) (bool, error) {
+	rNum, err := ToFloat64(rVal)
+	if err != nil {
+		return false, err
+	}
+	return math.Abs(rNum - eVal) <= delta, nil
+}
+
+func ToFloat64(val interface{}) (float64, error) {
+	switch v := val.(type) {
+	case int:
+		return float64(v), nil
+	case int8:
+		return float64(v), nil
+	case int16:
+		return float64(v), nil
+	case int32:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case uint:
+		return float64(v), nil
+	case uint8:
+		return float64(v), nil
+	case uint16:
+		return float64(v), nil
+	case uint32:
+		return float64(v), nil
+	case uint64:
+		return float64(v), nil
+	case float32:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	case string:
+		return strconv.ParseFloat(v, 64)
+	}
+	return 0, fmt.Errorf("Value [%v] is not a number", val)
+}
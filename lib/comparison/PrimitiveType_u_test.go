@@ -18,3 +18,31 @@ func TestIsEqualTo(t *testing.T) {
 		assert.True(t, testutils.GetFirstResult_bool(IsEqualTo(x, y)))
 	})
 }
+
+func TestCompareNumbers(t *testing.T) {
+	t.Run("Numbers comparison", func(t *testing.T) {
+		assert.Equal(t, 0, testutils.GetFirstResult_int(CompareNumbers(int64(1024), 1024)))
+		assert.Equal(t, -1, testutils.GetFirstResult_int(CompareNumbers(1, 1.5)))
+		assert.Equal(t, 1, testutils.GetFirstResult_int(CompareNumbers("2.5", uint8(2))))
+	})
+
+	t.Run("Invalid values", func(t *testing.T) {
+		assert.NotNil(t, testutils.GetError(CompareNumbers("abc", 1)))
+		assert.NotNil(t, testutils.GetError(CompareNumbers(1, true)))
+	})
+}
+
+func TestIsCloseTo(t *testing.T) {
+	t.Run("Numbers comparison", func(t *testing.T) {
+		assert.True(t, testutils.GetFirstResult_bool(IsCloseTo(3.1415926, 3.14, 0.002)))
+		assert.True(t, testutils.GetFirstResult_bool(IsCloseTo(0.1 + 0.2, 0.3, 1e-9)))
+		assert.True(t, testutils.GetFirstResult_bool(IsCloseTo(10, 10.5, 0.5)))
+		assert.True(t, testutils.GetFirstResult_bool(IsCloseTo("2.5", 2.5, 0)))
+		assert.False(t, testutils.GetFirstResult_bool(IsCloseTo(3.15, 3.14, 0.001)))
+	})
+
+	t.Run("Invalid values", func(t *testing.T) {
+		assert.NotNil(t, testutils.GetError(IsCloseTo("abc", 1, 0.1)))
+		assert.NotNil(t, testutils.GetError(IsCloseTo(nil, 1, 0.1)))
+	})
+}
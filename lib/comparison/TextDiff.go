@@ -0,0 +1,37 @@
+package comparison
+
+import(
+	"strings"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// LineDiff compares the texts line by line, the diff is given in the unified
+// format (-expected +received).
+func LineDiff(expected string, received string) (bool, string) {
+	if expected == received {
+		return false, ""
+	}
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A: splitLines(expected),
+		B: splitLines(received),
+		FromFile: "expected",
+		ToFile: "received",
+		Context: 3,
+	})
+	if err != nil {
+		return true, ""
+	}
+	if len(diff) == 0 {
+		// the texts differ only by the trailing line break
+		diff = "--- expected\n+++ received\n@@ trailing line break @@\n"
+	}
+	return true, strings.TrimSuffix(diff, "\n")
+}
+
+func splitLines(text string) []string {
+	lines := difflib.SplitLines(text)
+	if n := len(lines); n > 0 && lines[n - 1] == "\n" {
+		lines = lines[:n - 1]
+	}
+	return lines
+}
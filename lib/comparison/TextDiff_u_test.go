@@ -0,0 +1,34 @@
+package comparison
+
+import(
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLineDiff(t *testing.T) {
+	TESTCASES := []struct {
+		expected string
+		received string
+		different bool
+		diff string
+	}{
+		{ expected: "a\nb\n", received: "a\nb\n", different: false, diff: "" },
+		{
+			expected: "a\nb\nc",
+			received: "a\nx\nc",
+			different: true,
+			diff: "--- expected\n+++ received\n@@ -1,3 +1,3 @@\n a\n-b\n+x\n c",
+		},
+		{
+			expected: "a\n",
+			received: "a",
+			different: true,
+			diff: "--- expected\n+++ received\n@@ trailing line break @@",
+		},
+	}
+	for i, c := range TESTCASES {
+		different, diff := LineDiff(c.expected, c.received)
+		assert.Equal(t, c.different, different, "testcase #%d", i)
+		assert.Equal(t, c.diff, diff, "testcase #%d", i)
+	}
+}
@@ -0,0 +1,50 @@
+package comparison
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ParseTimestamp reads a time in the layout, an empty layout accepts the
+// RFC3339 format and the formats of the HTTP dates.
+func ParseTimestamp(value string, layout string) (time.Time, error) {
+	if len(layout) > 0 {
+		return time.Parse(layout, value)
+	}
+	if moment, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return moment, nil
+	}
+	if moment, err := http.ParseTime(value); err == nil {
+		return moment, nil
+	}
+	return time.Time{}, fmt.Errorf("Unknown time format: [%s]", value)
+}
+
+// IsRFC3339 reports whether the text is a valid RFC3339 timestamp.
+func IsRFC3339(value string) bool {
+	_, err := time.Parse(time.RFC3339Nano, value)
+	return err == nil
+}
+
+// ResolveTimeBound evaluates the bound of a time comparison, either a
+// timestamp or an expression relative to the current time (now, now+1h, now-15m).
+func ResolveTimeBound(expr string, now time.Time) (time.Time, error) {
+	expr = strings.TrimSpace(expr)
+	if !strings.HasPrefix(expr, "now") {
+		return ParseTimestamp(expr, "")
+	}
+	offset := strings.TrimSpace(strings.TrimPrefix(expr, "now"))
+	if len(offset) == 0 {
+		return now, nil
+	}
+	if offset[0] != '+' && offset[0] != '-' {
+		return time.Time{}, fmt.Errorf("Invalid time expression: [%s]", expr)
+	}
+	duration, err := time.ParseDuration(strings.Replace(offset, " ", "", -1))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Invalid time expression: [%s], error: %s", expr, err.Error())
+	}
+	return now.Add(duration), nil
+}
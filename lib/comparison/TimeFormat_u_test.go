@@ -0,0 +1,50 @@
+package comparison
+
+import(
+	"testing"
+	"time"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTimestamp(t *testing.T) {
+	TESTCASES := []struct {
+		value string
+		layout string
+		ok bool
+	}{
+		{ value: "2019-05-20T10:30:00Z", ok: true },
+		{ value: "2019-05-20T10:30:00.123+07:00", ok: true },
+		{ value: "Mon, 20 May 2019 10:30:00 GMT", ok: true },
+		{ value: "2019-05-20", ok: false },
+		{ value: "2019-05-20", layout: "2006-01-02", ok: true },
+		{ value: "20/05/2019", layout: "2006-01-02", ok: false },
+	}
+	for i, c := range TESTCASES {
+		_, err := ParseTimestamp(c.value, c.layout)
+		assert.Equal(t, c.ok, err == nil, "testcase #%d", i)
+	}
+}
+
+func TestResolveTimeBound(t *testing.T) {
+	now := time.Date(2019, 5, 20, 10, 30, 0, 0, time.UTC)
+	TESTCASES := []struct {
+		expr string
+		expected time.Time
+		ok bool
+	}{
+		{ expr: "now", expected: now, ok: true },
+		{ expr: "now+1h", expected: now.Add(time.Hour), ok: true },
+		{ expr: "now - 15m", expected: now.Add(-15 * time.Minute), ok: true },
+		{ expr: "2019-01-01T00:00:00Z", expected: time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC), ok: true },
+		{ expr: "now*2", ok: false },
+		{ expr: "now+1d", ok: false },
+		{ expr: "tomorrow", ok: false },
+	}
+	for i, c := range TESTCASES {
+		bound, err := ResolveTimeBound(c.expr, now)
+		assert.Equal(t, c.ok, err == nil, "testcase #%d", i)
+		if c.ok {
+			assert.True(t, c.expected.Equal(bound), "testcase #%d", i)
+		}
+	}
+}
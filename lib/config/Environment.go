@@ -0,0 +1,99 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"github.com/opwire/opwire-testa/lib/client"
+)
+
+// Environment describes a deployment target (dev, staging, prod), the same
+// testing scripts are run against any of them.
+type Environment struct {
+	PDP string `yaml:"pdp,omitempty" json:"pdp"`
+	Headers []client.HttpHeader `yaml:"headers,omitempty" json:"headers"`
+	TLS *TlsSettings `yaml:"tls,omitempty" json:"tls"`
+	Variables map[string]string `yaml:"variables,omitempty" json:"variables"`
+	home string
+}
+
+type TlsSettings struct {
+	InsecureSkipVerify *bool `yaml:"insecure-skip-verify,omitempty" json:"insecure-skip-verify"`
+	ServerName *string `yaml:"server-name,omitempty" json:"server-name"`
+	CaFile *string `yaml:"ca-file,omitempty" json:"ca-file"`
+	CertFile *string `yaml:"cert-file,omitempty" json:"cert-file"`
+	KeyFile *string `yaml:"key-file,omitempty" json:"key-file"`
+}
+
+// GetEnvironment returns the environment of the given name, nil if the name
+// is empty.
+func (c *Configuration) GetEnvironment(name string) (*Environment, error) {
+	if len(name) == 0 {
+		return nil, nil
+	}
+	if c != nil {
+		if env, ok := c.Environments[name]; ok && env != nil {
+			env.home = c.home
+			return env, nil
+		}
+	}
+	names := make([]string, 0)
+	if c != nil {
+		for key := range c.Environments {
+			names = append(names, key)
+		}
+	}
+	sort.Strings(names)
+	return nil, fmt.Errorf("Unknown environment [%s], expected one of: [%s]", name, strings.Join(names, ", "))
+}
+
+// NewTransport creates the transport of the TLS settings, nil if the
+// environment has none. The paths of the files are resolved against the
+// directory of the configuration file.
+func (e *Environment) NewTransport() (http.RoundTripper, error) {
+	if e == nil || e.TLS == nil {
+		return nil, nil
+	}
+	config := &tls.Config{}
+	if e.TLS.InsecureSkipVerify != nil {
+		config.InsecureSkipVerify = *e.TLS.InsecureSkipVerify
+	}
+	if e.TLS.ServerName != nil {
+		config.ServerName = *e.TLS.ServerName
+	}
+	if e.TLS.CaFile != nil {
+		pem, err := ioutil.ReadFile(e.resolvePath(*e.TLS.CaFile))
+		if err != nil {
+			return nil, fmt.Errorf("Loading ca-file [%s] failed: %s", *e.TLS.CaFile, err.Error())
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("ca-file [%s] has no PEM certificate", *e.TLS.CaFile)
+		}
+	}
+	if e.TLS.CertFile != nil || e.TLS.KeyFile != nil {
+		if e.TLS.CertFile == nil || e.TLS.KeyFile == nil {
+			return nil, fmt.Errorf("Both cert-file and key-file must be given for a client certificate")
+		}
+		cert, err := tls.LoadX509KeyPair(e.resolvePath(*e.TLS.CertFile), e.resolvePath(*e.TLS.KeyFile))
+		if err != nil {
+			return nil, fmt.Errorf("Loading the client certificate failed: %s", err.Error())
+		}
+		config.Certificates = []tls.Certificate{ cert }
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = config
+	return transport, nil
+}
+
+func (e *Environment) resolvePath(path string) string {
+	if !filepath.IsAbs(path) && len(e.home) > 0 {
+		return filepath.Join(e.home, path)
+	}
+	return path
+}
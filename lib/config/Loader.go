@@ -1,9 +1,21 @@
 package config
 
 import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"strings"
+	"gopkg.in/yaml.v2"
+	"github.com/opwire/opwire-testa/lib/client"
+	"github.com/opwire/opwire-testa/lib/engine"
 	"github.com/opwire/opwire-testa/lib/schema"
+	"github.com/opwire/opwire-testa/lib/storage"
+	"github.com/opwire/opwire-testa/lib/utils"
 )
 
+const DEFAULT_CONFIG_FILE string = "opwire-testa.yml"
+
 type LoaderOptions interface {}
 
 type Loader struct {
@@ -19,8 +31,515 @@ func NewLoader(opts LoaderOptions) (ref *Loader, err error) {
 	return ref, nil
 }
 
+// Load reads the configuration file, the default file of the working
+// directory is used when the path is empty (an empty configuration if the
+// default file does not exist).
+func (l *Loader) Load(configPath string) (*Configuration, error) {
+	if len(configPath) == 0 {
+		configPath = filepath.Join(utils.FindWorkingDir(), DEFAULT_CONFIG_FILE)
+		if !utils.IsFile(configPath) {
+			return &Configuration{}, nil
+		}
+	}
+	file, err := storage.GetFs().Open(configPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	content, err := ioutil.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := l.Parse(content)
+	if err != nil {
+		return nil, err
+	}
+	cfg.home = filepath.Dir(configPath)
+	return cfg, nil
+}
+
+func (l *Loader) Parse(content []byte) (*Configuration, error) {
+	cfg := &Configuration{}
+	if err := yaml.Unmarshal(content, cfg); err != nil {
+		return nil, err
+	}
+	result, err := l.validator.Validate(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if result != nil && !result.Valid() {
+		errs := make([]string, len(result.Errors()))
+		for i, arg := range result.Errors() {
+			errs[i] = arg.String()
+		}
+		return nil, utils.CombineErrors("Invalid configuration", errs)
+	}
+	for _, hook := range append(append([]*LifecycleHook{}, cfg.BeforeAll...), cfg.AfterAll...) {
+		if hook != nil && (len(hook.Exec) > 0) == (hook.Request != nil) {
+			return nil, fmt.Errorf("Invalid configuration: lifecycle hook [%s] must have either exec or request", hook.Title)
+		}
+	}
+	return cfg, nil
+}
+
+type Configuration struct {
+	TestDirs []string `yaml:"test-dirs,omitempty" json:"test-dirs"`
+	Tags map[string]*TagPolicy `yaml:"tags,omitempty" json:"tags"`
+	DestructiveTargets []string `yaml:"destructive-targets,omitempty" json:"destructive-targets"`
+	BeforeAll []*LifecycleHook `yaml:"before-all,omitempty" json:"before-all"`
+	AfterAll []*LifecycleHook `yaml:"after-all,omitempty" json:"after-all"`
+	Environments map[string]*Environment `yaml:"environments,omitempty" json:"environments"`
+	ReportSigning *ReportSigning `yaml:"report-signing,omitempty" json:"report-signing"`
+	home string
+}
+
+// LifecycleHook provisions or tears down the shared state of a run, either by
+// an HTTP request (checked by its expectation) or by a command (its exit code
+// must be zero).
+type LifecycleHook struct {
+	Title string `yaml:"title" json:"title"`
+	Exec []string `yaml:"exec,omitempty" json:"exec"`
+	Timeout *string `yaml:"timeout,omitempty" json:"timeout"`
+	Request *client.HttpRequest `yaml:"request,omitempty" json:"request"`
+	Expectation *engine.Expectation `yaml:"expectation,omitempty" json:"expectation"`
+	Capture *engine.SectionCapture `yaml:"capture,omitempty" json:"capture"`
+}
+
+// GetTestDirs returns the directories of the testing scripts, the relative
+// paths are resolved against the directory of the configuration file.
+func (c *Configuration) GetTestDirs() []string {
+	if c == nil || len(c.TestDirs) == 0 {
+		return nil
+	}
+	testDirs := make([]string, len(c.TestDirs))
+	for i, testDir := range c.TestDirs {
+		if !filepath.IsAbs(testDir) && len(c.home) > 0 {
+			testDir = filepath.Join(c.home, testDir)
+		}
+		testDirs[i] = testDir
+	}
+	return testDirs
+}
+
+// IsApprovedTarget reports whether the destructive test cases may be run
+// against the host, a pattern is either a hostname or a glob (*.staging.local).
+func (c *Configuration) IsApprovedTarget(host string) bool {
+	if c == nil || len(host) == 0 {
+		return false
+	}
+	host = strings.ToLower(host)
+	for _, pattern := range c.DestructiveTargets {
+		pattern = strings.ToLower(pattern)
+		if pattern == host {
+			return true
+		}
+		if matched, err := path.Match(pattern, host); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// TagPolicy holds the defaults applied to the test cases having the tag.
+type TagPolicy struct {
+	Timeout *string `yaml:"timeout,omitempty" json:"timeout"`
+	Retry *engine.SectionRetry `yaml:"retry,omitempty" json:"retry"`
+	Destructive *bool `yaml:"destructive,omitempty" json:"destructive"`
+}
+
+// GetTagPolicy merges the policies of the tags, the first tag (by the order
+// of the test case) defining an attribute wins.
+func (c *Configuration) GetTagPolicy(tags []string) *TagPolicy {
+	policy := &TagPolicy{}
+	if c == nil {
+		return policy
+	}
+	for _, tag := range tags {
+		p, ok := c.Tags[tag]
+		if !ok || p == nil {
+			continue
+		}
+		if policy.Timeout == nil {
+			policy.Timeout = p.Timeout
+		}
+		if policy.Retry == nil {
+			policy.Retry = p.Retry
+		}
+		if policy.Destructive == nil {
+			policy.Destructive = p.Destructive
+		}
+	}
+	return policy
+}
+
+// Apply sets the defaults of the policy to the test case, the attributes
+// given by the test case itself are kept.
+func (p *TagPolicy) Apply(testcase *engine.TestCase) {
+	if p == nil || testcase == nil {
+		return
+	}
+	if p.Timeout != nil && testcase.Request != nil && testcase.Request.Timeout == nil {
+		testcase.Request.Timeout = p.Timeout
+	}
+	if p.Retry != nil && testcase.Retry == nil {
+		testcase.Retry = p.Retry
+	}
+	if p.Destructive != nil && testcase.Destructive == nil {
+		testcase.Destructive = p.Destructive
+	}
+}
+
+func (p *TagPolicy) IsDestructive() bool {
+	return p != nil && p.Destructive != nil && *p.Destructive
+}
+
 const configSchema string = `{
 	"type": "object",
 	"properties": {
+		"environments": {
+			"oneOf": [
+				{
+					"type": "null"
+				},
+				{
+					"type": "object",
+					"additionalProperties": {
+						"$ref": "#/definitions/Environment"
+					}
+				}
+			]
+		},
+		"before-all": {
+			"oneOf": [
+				{
+					"type": "null"
+				},
+				{
+					"type": "array",
+					"items": {
+						"$ref": "#/definitions/LifecycleHook"
+					}
+				}
+			]
+		},
+		"after-all": {
+			"oneOf": [
+				{
+					"type": "null"
+				},
+				{
+					"type": "array",
+					"items": {
+						"$ref": "#/definitions/LifecycleHook"
+					}
+				}
+			]
+		},
+		"test-dirs": {
+			"oneOf": [
+				{
+					"type": "null"
+				},
+				{
+					"type": "array",
+					"items": {
+						"type": "string",
+						"minLength": 1
+					}
+				}
+			]
+		},
+		"report-signing": {
+			"oneOf": [
+				{
+					"type": "null"
+				},
+				{
+					"type": "object",
+					"properties": {
+						"key-file": {
+							"oneOf": [
+								{
+									"type": "null"
+								},
+								{
+									"type": "string",
+									"minLength": 1
+								}
+							]
+						},
+						"key-env": {
+							"oneOf": [
+								{
+									"type": "null"
+								},
+								{
+									"type": "string",
+									"minLength": 1
+								}
+							]
+						}
+					},
+					"additionalProperties": false
+				}
+			]
+		},
+		"destructive-targets": {
+			"oneOf": [
+				{
+					"type": "null"
+				},
+				{
+					"type": "array",
+					"items": {
+						"type": "string",
+						"minLength": 1
+					}
+				}
+			]
+		},
+		"tags": {
+			"oneOf": [
+				{
+					"type": "null"
+				},
+				{
+					"type": "object",
+					"additionalProperties": {
+						"oneOf": [
+							{
+								"type": "null"
+							},
+							{
+								"type": "object",
+								"properties": {
+									"timeout": {
+										"oneOf": [
+											{
+												"type": "null"
+											},
+											{
+												"type": "string",
+												"minLength": 1
+											}
+										]
+									},
+									"retry": {
+										"oneOf": [
+											{
+												"type": "null"
+											},
+											{
+												"type": "object",
+												"properties": {
+													"attempts": {
+														"type": "integer",
+														"minimum": 1
+													},
+													"backoff": {
+														"type": "string"
+													},
+													"max-wait": {
+														"type": "string"
+													}
+												}
+											}
+										]
+									},
+									"destructive": {
+										"oneOf": [
+											{
+												"type": "null"
+											},
+											{
+												"type": "boolean"
+											}
+										]
+									}
+								}
+							}
+						]
+					}
+				}
+			]
+		}
+	},
+	"definitions": {
+		"Environment": {
+			"type": "object",
+			"properties": {
+				"pdp": {
+					"type": "string"
+				},
+				"headers": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "array",
+							"items": {
+								"type": "object",
+								"properties": {
+									"name": {
+										"type": "string",
+										"minLength": 1
+									},
+									"value": {
+										"type": "string"
+									},
+									"sensitive": {
+										"type": "boolean"
+									}
+								},
+								"required": ["name"]
+							}
+						}
+					]
+				},
+				"tls": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "object",
+							"properties": {
+								"insecure-skip-verify": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "boolean"
+										}
+									]
+								},
+								"server-name": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "string"
+										}
+									]
+								},
+								"ca-file": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "string",
+											"minLength": 1
+										}
+									]
+								},
+								"cert-file": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "string",
+											"minLength": 1
+										}
+									]
+								},
+								"key-file": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "string",
+											"minLength": 1
+										}
+									]
+								}
+							}
+						}
+					]
+				},
+				"variables": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "object",
+							"additionalProperties": {
+								"type": "string"
+							}
+						}
+					]
+				}
+			}
+		},
+		"LifecycleHook": {
+			"type": "object",
+			"properties": {
+				"title": {
+					"type": "string",
+					"minLength": 1
+				},
+				"exec": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "array",
+							"minItems": 1,
+							"items": {
+								"type": "string"
+							}
+						}
+					]
+				},
+				"timeout": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "string",
+							"minLength": 1
+						}
+					]
+				},
+				"request": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "object"
+						}
+					]
+				},
+				"expectation": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "object"
+						}
+					]
+				},
+				"capture": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "object"
+						}
+					]
+				}
+			}
+		}
 	}
 }`
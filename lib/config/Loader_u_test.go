@@ -0,0 +1,211 @@
+package config
+
+import(
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/opwire/opwire-testa/lib/client"
+	"github.com/opwire/opwire-testa/lib/engine"
+)
+
+func TestLoader_Parse(t *testing.T) {
+	loader, err := NewLoader(nil)
+	assert.Nil(t, err)
+
+	t.Run("Policies of tags", func(t *testing.T) {
+		cfg, err := loader.Parse([]byte("tags:\n" +
+			"  slow:\n" +
+			"    timeout: 60s\n" +
+			"    retry:\n" +
+			"      attempts: 2\n" +
+			"  destructive:\n" +
+			"    destructive: true\n"))
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(cfg.Tags))
+		assert.Equal(t, "60s", *cfg.Tags["slow"].Timeout)
+		assert.Equal(t, 2, cfg.Tags["slow"].Retry.Attempts)
+		assert.True(t, cfg.Tags["destructive"].IsDestructive())
+	})
+
+	t.Run("Invalid policy", func(t *testing.T) {
+		_, err := loader.Parse([]byte("tags:\n  slow:\n    destructive: maybe\n"))
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Lifecycle hooks", func(t *testing.T) {
+		cfg, err := loader.Parse([]byte("before-all:\n" +
+			"- title: Seed database\n" +
+			"  exec: [ ./seed.sh, --fresh ]\n" +
+			"  timeout: 30s\n" +
+			"after-all:\n" +
+			"- title: Flush cache\n" +
+			"  request:\n" +
+			"    method: POST\n" +
+			"    url: http://localhost:17779/flush\n"))
+		assert.Nil(t, err)
+		assert.Equal(t, []string{ "./seed.sh", "--fresh" }, cfg.BeforeAll[0].Exec)
+		assert.Equal(t, "POST", cfg.AfterAll[0].Request.Method)
+
+		_, err = loader.Parse([]byte("before-all:\n- title: Nothing\n"))
+		assert.NotNil(t, err)
+		_, err = loader.Parse([]byte("before-all:\n- title: Both\n  exec: [ 'true' ]\n  request: { url: 'http://localhost' }\n"))
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Empty configuration", func(t *testing.T) {
+		cfg, err := loader.Parse([]byte(""))
+		assert.Nil(t, err)
+		assert.False(t, cfg.GetTagPolicy([]string{ "slow" }).IsDestructive())
+	})
+}
+
+func TestConfiguration_GetTagPolicy(t *testing.T) {
+	short, long := "5s", "60s"
+	truthy := true
+	cfg := &Configuration{
+		Tags: map[string]*TagPolicy{
+			"slow": &TagPolicy{ Timeout: &long, Retry: &engine.SectionRetry{ Attempts: 2 } },
+			"fast": &TagPolicy{ Timeout: &short },
+			"destructive": &TagPolicy{ Destructive: &truthy },
+		},
+	}
+
+	policy := cfg.GetTagPolicy([]string{ "fast", "slow", "destructive" })
+	assert.Equal(t, "5s", *policy.Timeout)
+	assert.Equal(t, 2, policy.Retry.Attempts)
+	assert.True(t, policy.IsDestructive())
+
+	t.Run("Attributes of the test case are kept", func(t *testing.T) {
+		timeout := "1s"
+		testcase := &engine.TestCase{ Request: &client.HttpRequest{ Timeout: &timeout } }
+		cfg.GetTagPolicy([]string{ "slow" }).Apply(testcase)
+		assert.Equal(t, "1s", *testcase.Request.Timeout)
+		assert.Equal(t, 2, testcase.Retry.Attempts)
+		cfg.GetTagPolicy([]string{ "destructive" }).Apply(testcase)
+		assert.True(t, testcase.IsDestructive())
+	})
+
+	t.Run("Nil configuration", func(t *testing.T) {
+		var empty *Configuration
+		assert.False(t, empty.GetTagPolicy([]string{ "slow" }).IsDestructive())
+	})
+}
+
+func TestConfiguration_GetTestDirs(t *testing.T) {
+	loader, err := NewLoader(nil)
+	assert.Nil(t, err)
+	cfg, err := loader.Parse([]byte("test-dirs:\n- tests\n- /opt/tests\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{ "tests", "/opt/tests" }, cfg.GetTestDirs())
+	cfg.home = "/home/project"
+	assert.Equal(t, []string{ "/home/project/tests", "/opt/tests" }, cfg.GetTestDirs())
+	var empty *Configuration
+	assert.Nil(t, empty.GetTestDirs())
+}
+
+func TestConfiguration_GetSigningKey(t *testing.T) {
+	loader, err := NewLoader(nil)
+	assert.Nil(t, err)
+	home, err := ioutil.TempDir("", "opwire-testa-config-")
+	assert.Nil(t, err)
+	defer os.RemoveAll(home)
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(home, "report.key"), []byte("s3cr3t\n"), 0600))
+	os.Setenv("OPWIRE_TESTA_TEST_REPORT_KEY", "from-env")
+	defer os.Unsetenv("OPWIRE_TESTA_TEST_REPORT_KEY")
+
+	TESTCASES := []struct {
+		content string
+		key []byte
+		ok bool
+	}{
+		{ content: "tags: {}\n", key: nil, ok: true },
+		{ content: "report-signing:\n  key-file: report.key\n", key: []byte("s3cr3t"), ok: true },
+		{ content: "report-signing:\n  key-env: OPWIRE_TESTA_TEST_REPORT_KEY\n", key: []byte("from-env"), ok: true },
+		{ content: "report-signing:\n  key-env: OPWIRE_TESTA_TEST_MISSING_KEY\n", ok: false },
+		{ content: "report-signing:\n  key-file: missing.key\n", ok: false },
+		{ content: "report-signing: {}\n", ok: false },
+	}
+	for i, c := range TESTCASES {
+		cfg, err := loader.Parse([]byte(c.content))
+		assert.Nil(t, err, "testcase #%d", i)
+		cfg.home = home
+		key, err := cfg.GetSigningKey()
+		assert.Equal(t, c.ok, err == nil, "testcase #%d", i)
+		assert.Equal(t, c.key, key, "testcase #%d", i)
+	}
+}
+
+func TestConfiguration_IsApprovedTarget(t *testing.T) {
+	cfg := &Configuration{ DestructiveTargets: []string{ "localhost", "127.0.0.1", "*.staging.example.com" } }
+	TESTCASES := []struct {
+		host string
+		approved bool
+	}{
+		{ host: "localhost", approved: true },
+		{ host: "LocalHost", approved: true },
+		{ host: "127.0.0.1", approved: true },
+		{ host: "api.staging.example.com", approved: true },
+		{ host: "api.example.com", approved: false },
+		{ host: "staging.example.com.evil.net", approved: false },
+		{ host: "", approved: false },
+	}
+	for i, c := range TESTCASES {
+		assert.Equal(t, c.approved, cfg.IsApprovedTarget(c.host), "testcase #%d", i)
+	}
+	var empty *Configuration
+	assert.False(t, empty.IsApprovedTarget("localhost"))
+}
+
+func TestConfiguration_GetEnvironment(t *testing.T) {
+	loader, _ := NewLoader(nil)
+	cfg, err := loader.Parse([]byte(`
+environments:
+  dev:
+    pdp: http://localhost:17779
+  staging:
+    pdp: https://api.staging.example.com
+    headers:
+      - name: Authorization
+        value: Bearer staging-token
+        sensitive: true
+    tls:
+      insecure-skip-verify: true
+    variables:
+      tenant: acme
+`))
+	assert.Nil(t, err)
+
+	env, err := cfg.GetEnvironment("staging")
+	assert.Nil(t, err)
+	assert.Equal(t, "https://api.staging.example.com", env.PDP)
+	assert.Equal(t, "Authorization", env.Headers[0].Name)
+	assert.True(t, env.Headers[0].Sensitive)
+	assert.Equal(t, "acme", env.Variables["tenant"])
+
+	transport, err := env.NewTransport()
+	assert.Nil(t, err)
+	assert.True(t, transport.(*http.Transport).TLSClientConfig.InsecureSkipVerify)
+
+	t.Run("Environment without TLS settings", func(t *testing.T) {
+		env, err := cfg.GetEnvironment("dev")
+		assert.Nil(t, err)
+		transport, err := env.NewTransport()
+		assert.Nil(t, err)
+		assert.Nil(t, transport)
+	})
+
+	t.Run("Unknown environment", func(t *testing.T) {
+		_, err := cfg.GetEnvironment("prod")
+		assert.EqualError(t, err, "Unknown environment [prod], expected one of: [dev, staging]")
+	})
+
+	t.Run("Missing key-file of the client certificate", func(t *testing.T) {
+		certFile := "client.pem"
+		env := &Environment{ TLS: &TlsSettings{ CertFile: &certFile } }
+		_, err := env.NewTransport()
+		assert.NotNil(t, err)
+	})
+}
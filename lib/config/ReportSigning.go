@@ -0,0 +1,48 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ReportSigning holds the key which signs the JSON reports of the runs, it is
+// read either from a file or from an environment variable (a CI secret).
+type ReportSigning struct {
+	KeyFile *string `yaml:"key-file,omitempty" json:"key-file"`
+	KeyEnv *string `yaml:"key-env,omitempty" json:"key-env"`
+}
+
+// GetSigningKey returns the key of the report signing, nil if the reports
+// are not signed. The path of the key file is resolved against the directory
+// of the configuration file.
+func (c *Configuration) GetSigningKey() ([]byte, error) {
+	if c == nil || c.ReportSigning == nil {
+		return nil, nil
+	}
+	s := c.ReportSigning
+	if (s.KeyFile != nil) == (s.KeyEnv != nil) {
+		return nil, fmt.Errorf("Either key-file or key-env must be given for the report signing")
+	}
+	var key string
+	if s.KeyFile != nil {
+		path := *s.KeyFile
+		if !filepath.IsAbs(path) && len(c.home) > 0 {
+			path = filepath.Join(c.home, path)
+		}
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("Loading key-file [%s] failed: %s", *s.KeyFile, err.Error())
+		}
+		key = string(content)
+	} else {
+		key = os.Getenv(*s.KeyEnv)
+	}
+	key = strings.TrimSpace(key)
+	if len(key) == 0 {
+		return nil, fmt.Errorf("The key of the report signing must not be empty")
+	}
+	return []byte(key), nil
+}
@@ -0,0 +1,158 @@
+package demo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const DEFAULT_HOST string = `localhost`
+const DEFAULT_PORT int = 17779
+
+const MAX_DELAY time.Duration = 30 * time.Second
+
+type ServerOptions interface {
+	GetHost() string
+	GetPort() int
+}
+
+type Server struct {
+	host string
+	port int
+	logWriter io.Writer
+}
+
+func NewServer(opts ServerOptions) (ref *Server, err error) {
+	ref = &Server{ host: DEFAULT_HOST, port: DEFAULT_PORT }
+	if opts != nil {
+		if len(opts.GetHost()) > 0 {
+			ref.host = opts.GetHost()
+		}
+		if opts.GetPort() > 0 {
+			ref.port = opts.GetPort()
+		}
+	}
+	return ref, err
+}
+
+func (s *Server) GetAddress() string {
+	return fmt.Sprintf("%s:%d", s.host, s.port)
+}
+
+func (s *Server) SetLogWriter(writer io.Writer) {
+	s.logWriter = writer
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/echo", s.handleEcho)
+	mux.HandleFunc("/status/", s.handleStatus)
+	mux.HandleFunc("/delay/", s.handleDelay)
+	mux.HandleFunc("/format/", s.handleFormat)
+	return s.logged(mux)
+}
+
+func (s *Server) ListenAndServe() error {
+	return http.ListenAndServe(s.GetAddress(), s.Handler())
+}
+
+// handleEcho returns the received request as a JSON object
+func (s *Server) handleEcho(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	headers := make(map[string]string)
+	for name, values := range r.Header {
+		headers[name] = strings.Join(values, ", ")
+	}
+	query := make(map[string]string)
+	for name, values := range r.URL.Query() {
+		query[name] = strings.Join(values, ", ")
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"method": r.Method,
+		"path": r.URL.Path,
+		"query": query,
+		"headers": headers,
+		"body": string(body),
+	})
+}
+
+// handleStatus responds with the status code given in the path, e.g. /status/404,
+// the informational (1xx) codes are not final responses and are refused.
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	code, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/status/"))
+	if err != nil || code < 200 || code > 599 {
+		http.Error(w, "Invalid status code", http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, code, map[string]interface{}{
+		"status": code,
+		"text": http.StatusText(code),
+	})
+}
+
+// handleDelay responds after the duration given in the path, e.g. /delay/500ms
+func (s *Server) handleDelay(w http.ResponseWriter, r *http.Request) {
+	delay, err := time.ParseDuration(strings.TrimPrefix(r.URL.Path, "/delay/"))
+	if err != nil || delay < 0 || delay > MAX_DELAY {
+		http.Error(w, fmt.Sprintf("Invalid delay, must be a duration up to %s", MAX_DELAY), http.StatusBadRequest)
+		return
+	}
+	time.Sleep(delay)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"delay": delay.String(),
+	})
+}
+
+// handleFormat responds with the same content in the format given in the path, e.g. /format/yaml
+func (s *Server) handleFormat(w http.ResponseWriter, r *http.Request) {
+	switch strings.TrimPrefix(r.URL.Path, "/format/") {
+	case "json":
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"name": "opwire",
+			"url": "https://opwire.org",
+		})
+	case "yaml":
+		w.Header().Set("Content-Type", "application/x-yaml")
+		fmt.Fprint(w, "name: opwire\nurl: https://opwire.org\n")
+	case "text":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprint(w, "Hello from the opwire-testa demo server")
+	default:
+		http.Error(w, "Unsupported format, must be one of: json, yaml, text", http.StatusNotFound)
+	}
+}
+
+func (s *Server) logged(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorder := &statusRecorder{ ResponseWriter: w, status: http.StatusOK }
+		next.ServeHTTP(recorder, r)
+		if s.logWriter != nil {
+			fmt.Fprintf(s.logWriter, "< %s %s %d\n", r.Method, r.URL.RequestURI(), recorder.status)
+		}
+	})
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
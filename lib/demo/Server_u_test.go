@@ -0,0 +1,58 @@
+package demo
+
+import(
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_Handler(t *testing.T) {
+	s, err := NewServer(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "localhost:17779", s.GetAddress())
+	handler := s.Handler()
+
+	t.Run("Echo", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("POST", "/echo?a=1", strings.NewReader("hello")))
+		assert.Equal(t, http.StatusOK, w.Code)
+		var echo map[string]interface{}
+		assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &echo))
+		assert.Equal(t, "POST", echo["method"])
+		assert.Equal(t, "hello", echo["body"])
+		assert.Equal(t, map[string]interface{}{"a": "1"}, echo["query"])
+	})
+
+	t.Run("Status", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", "/status/418", nil))
+		assert.Equal(t, 418, w.Code)
+
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", "/status/abc", nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		for _, path := range []string{ "/status/100", "/status/199", "/status/600" } {
+			w = httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+			assert.Equal(t, http.StatusBadRequest, w.Code, path)
+		}
+
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", "/status/200", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Format", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", "/format/yaml", nil))
+		assert.Equal(t, "name: opwire\nurl: https://opwire.org\n", w.Body.String())
+
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", "/format/xml", nil))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
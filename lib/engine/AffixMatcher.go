@@ -0,0 +1,44 @@
+package engine
+
+import(
+	"fmt"
+	"strings"
+)
+
+// examineAffixes checks the prefix and the suffix of a value, the comparison
+// optionally ignores the case.
+func examineAffixes(value string, startsWith *string, endsWith *string, ignoreCase bool) error {
+	received := value
+	if ignoreCase {
+		received = strings.ToLower(received)
+	}
+	if startsWith != nil {
+		prefix := *startsWith
+		if ignoreCase {
+			prefix = strings.ToLower(prefix)
+		}
+		if !strings.HasPrefix(received, prefix) {
+			return fmt.Errorf("Value: [%s] does not start with: [%s]", abbreviate(value), *startsWith)
+		}
+	}
+	if endsWith != nil {
+		suffix := *endsWith
+		if ignoreCase {
+			suffix = strings.ToLower(suffix)
+		}
+		if !strings.HasSuffix(received, suffix) {
+			return fmt.Errorf("Value: [%s] does not end with: [%s]", abbreviate(value), *endsWith)
+		}
+	}
+	return nil
+}
+
+// abbreviate shortens the long values (e.g. whole bodies) in the messages.
+func abbreviate(value string) string {
+	const limit = 200
+	runes := []rune(value)
+	if len(runes) <= limit {
+		return value
+	}
+	return string(runes[:limit / 2]) + " ... " + string(runes[len(runes) - limit / 2:])
+}
@@ -0,0 +1,33 @@
+package engine
+
+import(
+	"strings"
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/opwire/opwire-testa/lib/utils"
+)
+
+func TestExamineAffixes(t *testing.T) {
+	TESTCASES := []struct {
+		value string
+		startsWith *string
+		endsWith *string
+		ignoreCase bool
+		ok bool
+	}{
+		{ value: "https://api.example.com/orders/42", startsWith: utils.RefOfString("https://api.example.com/"), ok: true },
+		{ value: "http://api.example.com/orders/42", startsWith: utils.RefOfString("https://"), ok: false },
+		{ value: "report.CSV", endsWith: utils.RefOfString(".csv"), ok: false },
+		{ value: "report.CSV", endsWith: utils.RefOfString(".csv"), ignoreCase: true, ok: true },
+		{ value: "220 smtp.example.com ESMTP", startsWith: utils.RefOfString("220 "), endsWith: utils.RefOfString("ESMTP"), ok: true },
+		{ value: "", startsWith: utils.RefOfString(""), ok: true },
+	}
+	for i, c := range TESTCASES {
+		err := examineAffixes(c.value, c.startsWith, c.endsWith, c.ignoreCase)
+		assert.Equal(t, c.ok, err == nil, "testcase #%d", i)
+	}
+	long := strings.Repeat("a", 150) + strings.Repeat("b", 150)
+	err := examineAffixes(long, utils.RefOfString("b"), nil, false)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), " ... ")
+}
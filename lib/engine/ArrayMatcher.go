@@ -0,0 +1,90 @@
+package engine
+
+import(
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"github.com/opwire/opwire-testa/lib/comparison"
+)
+
+func hasArrayMatchers(eField MeasureBodyField) bool {
+	return eField.HasLength != nil || eField.HasMinLength != nil || eField.ContainsElement != nil || eField.EveryElementMatches != nil
+}
+
+// examineArrayField verifies the length and the elements of an array field,
+// an element matches an expected object if the object is a subset of it.
+func examineArrayField(eField MeasureBodyField, rValue interface{}, found bool) error {
+	if !found {
+		return fmt.Errorf("Field not found, expected an array")
+	}
+	elements, ok := toElements(rValue)
+	if !ok {
+		return fmt.Errorf("Field type mismatch expected: array / received: %s", getValueType(rValue))
+	}
+	if eField.HasLength != nil && len(elements) != *eField.HasLength {
+		return fmt.Errorf("Array length mismatch expected: %d / received: %d", *eField.HasLength, len(elements))
+	}
+	if eField.HasMinLength != nil && len(elements) < *eField.HasMinLength {
+		return fmt.Errorf("Array length %d is less than the minimum: %d", len(elements), *eField.HasMinLength)
+	}
+	if eField.ContainsElement != nil {
+		contained := false
+		for _, element := range elements {
+			if matchElement(eField.ContainsElement, element) {
+				contained = true
+				break
+			}
+		}
+		if !contained {
+			return fmt.Errorf("Array does not contain any element matching: %v", eField.ContainsElement)
+		}
+	}
+	if eField.EveryElementMatches != nil {
+		for i, element := range elements {
+			if !matchElement(eField.EveryElementMatches, element) {
+				return fmt.Errorf("Array element #%d: %v is mismatched with: %v", i, element, eField.EveryElementMatches)
+			}
+		}
+	}
+	return nil
+}
+
+func toElements(value interface{}) ([]interface{}, bool) {
+	if value == nil {
+		return nil, false
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, false
+	}
+	elements := make([]interface{}, rv.Len())
+	for i := range elements {
+		elements[i] = rv.Index(i).Interface()
+	}
+	return elements, true
+}
+
+func matchElement(expected interface{}, element interface{}) bool {
+	expected, element = canonicalValue(expected), canonicalValue(element)
+	switch expected.(type) {
+	case map[string]interface{}, []interface{}:
+		ok, _ := comparison.IsPartOf(expected, element)
+		return ok
+	}
+	eq, _ := comparison.IsEqualTo(element, expected)
+	return eq
+}
+
+// canonicalValue converts the values decoded from YAML or JSON to the same
+// representation (string keys, float64 numbers) so that they are comparable.
+func canonicalValue(value interface{}) interface{} {
+	content, err := json.Marshal(normalizeObject(value))
+	if err != nil {
+		return value
+	}
+	var result interface{}
+	if err := json.Unmarshal(content, &result); err != nil {
+		return value
+	}
+	return result
+}
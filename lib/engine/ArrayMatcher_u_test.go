@@ -0,0 +1,48 @@
+package engine
+
+import(
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExamineArrayField(t *testing.T) {
+	two, four := 2, 4
+	users := []interface{}{
+		map[string]interface{}{ "id": 1.0, "name": "alice", "active": true },
+		map[string]interface{}{ "id": 2.0, "name": "bob", "active": true },
+	}
+	TESTCASES := []struct {
+		field MeasureBodyField
+		value interface{}
+		found bool
+		ok bool
+	}{
+		{ field: MeasureBodyField{ HasLength: &two }, value: users, found: true, ok: true },
+		{ field: MeasureBodyField{ HasLength: &four }, value: users, found: true, ok: false },
+		{ field: MeasureBodyField{ HasMinLength: &two }, value: users, found: true, ok: true },
+		{ field: MeasureBodyField{ HasMinLength: &four }, value: users, found: true, ok: false },
+		{ field: MeasureBodyField{ HasLength: &two }, value: "users", found: true, ok: false },
+		{ field: MeasureBodyField{ HasLength: &two }, found: false, ok: false },
+		{
+			field: MeasureBodyField{ ContainsElement: map[interface{}]interface{}{ "id": 2, "name": "bob" } },
+			value: users, found: true, ok: true,
+		},
+		{
+			field: MeasureBodyField{ ContainsElement: map[interface{}]interface{}{ "id": 3 } },
+			value: users, found: true, ok: false,
+		},
+		{ field: MeasureBodyField{ ContainsElement: 2 }, value: []interface{}{ 1.0, 2.0, 3.0 }, found: true, ok: true },
+		{
+			field: MeasureBodyField{ EveryElementMatches: map[interface{}]interface{}{ "active": true } },
+			value: users, found: true, ok: true,
+		},
+		{
+			field: MeasureBodyField{ EveryElementMatches: map[interface{}]interface{}{ "name": "alice" } },
+			value: users, found: true, ok: false,
+		},
+	}
+	for i, c := range TESTCASES {
+		err := examineArrayField(c.field, c.value, c.found)
+		assert.Equal(t, c.ok, err == nil, "testcase #%d", i)
+	}
+}
@@ -0,0 +1,44 @@
+package engine
+
+import(
+	"fmt"
+	"github.com/opwire/opwire-testa/lib/comparison"
+)
+
+// examineCanonicalBody compares the canonical forms of the expected and the
+// received JSON documents (sorted keys, normalized numbers and escapes), the
+// ignored fields are stripped from both of them.
+func examineCanonicalBody(eb *MeasureBody, format string, body []byte) map[string]error {
+	errs := make(map[string]error, 0)
+	received, err := canonicalizeBody(eb.IgnoreFields, body)
+	if err != nil {
+		errs["Body/ReceivedObject"] = fmt.Errorf("[%s] Response body is not canonicalizable JSON: %s", format, err)
+		return errs
+	}
+	expected, err := canonicalizeBody(eb.IgnoreFields, []byte(*eb.IsEqualTo))
+	if err != nil {
+		errs["Body/ExpectedObject"] = fmt.Errorf("[%s] Expected content is not canonicalizable JSON: %s", format, err)
+		return errs
+	}
+	if different, diff := comparison.LineDiff(expected, received); different {
+		errs["Body/IsEqualTo"] = newBodyMismatch(format, diff)
+	}
+	return errs
+}
+
+func canonicalizeBody(ignoredFields []string, content []byte) (string, error) {
+	value, err := comparison.CanonicalizeJson(content)
+	if err != nil {
+		return "", err
+	}
+	if obj, ok := value.(map[string]interface{}); ok {
+		if err := removeIgnoredFields(ignoredFields, obj); err != nil {
+			return "", err
+		}
+	}
+	return comparison.RenderCanonicalJson(value)
+}
+
+func (eb *MeasureBody) isCanonical() bool {
+	return eb.Canonicalize != nil && *eb.Canonicalize
+}
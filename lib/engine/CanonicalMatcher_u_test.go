@@ -0,0 +1,51 @@
+package engine
+
+import(
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/opwire/opwire-testa/lib/utils"
+)
+
+func TestExamineCanonicalBody(t *testing.T) {
+	truthy := true
+	body := []byte(`{"total":1.50e1,"name":"café","items":[{"id":1,"at":"2019-06-01"}]}`)
+	TESTCASES := []struct {
+		body MeasureBody
+		failed []string
+	}{
+		{
+			body: MeasureBody{
+				IsEqualTo: utils.RefOfString(`{"items": [{"at": "2019-06-01", "id": 1.0}], "name": "café", "total": 15}`),
+			},
+		},
+		{
+			body: MeasureBody{
+				IsEqualTo: utils.RefOfString(`{"items": [{"id": 1}], "name": "café", "total": 15}`),
+				IgnoreFields: []string{ "$.items[*].at" },
+			},
+		},
+		{
+			body: MeasureBody{
+				IsEqualTo: utils.RefOfString(`{"items": [{"at": "2019-06-01", "id": 1}], "name": "cafe", "total": 15.01}`),
+			},
+			failed: []string{ "Body/IsEqualTo" },
+		},
+		{
+			body: MeasureBody{
+				IsEqualTo: utils.RefOfString(`total: 15`),
+			},
+			failed: []string{ "Body/ExpectedObject" },
+		},
+	}
+	for i, c := range TESTCASES {
+		c.body.Canonicalize = &truthy
+		errs := examineCanonicalBody(&c.body, utils.BODY_FORMAT_JSON, body)
+		keys := make([]string, 0)
+		for key := range errs {
+			keys = append(keys, key)
+		}
+		assert.ElementsMatch(t, c.failed, keys, "testcase #%d", i)
+	}
+	errs := examineCanonicalBody(&MeasureBody{ IsEqualTo: utils.RefOfString(`{}`) }, utils.BODY_FORMAT_FLAT, []byte(`plain text`))
+	assert.Contains(t, errs, "Body/ReceivedObject")
+}
@@ -0,0 +1,79 @@
+package engine
+
+import(
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"github.com/opwire/opwire-testa/lib/client"
+)
+
+// AssertionEvaluator evaluates the boolean expressions of the asserts list.
+type AssertionEvaluator interface {
+	Evaluate(expr string, vars map[string]interface{}) (bool, error)
+}
+
+// newCelEvaluator is provided by the builds tagged with "cel",
+// keeping the CEL dependencies out of the default binary.
+var newCelEvaluator func() (AssertionEvaluator, error)
+
+var assertionEvaluator struct {
+	sync.Once
+	evaluator AssertionEvaluator
+	err error
+}
+
+func getCelEvaluator() (AssertionEvaluator, error) {
+	assertionEvaluator.Do(func() {
+		if newCelEvaluator == nil {
+			assertionEvaluator.err = fmt.Errorf("CEL assertions are not supported by this build, rebuild with the [cel] tag")
+			return
+		}
+		assertionEvaluator.evaluator, assertionEvaluator.err = newCelEvaluator()
+	})
+	return assertionEvaluator.evaluator, assertionEvaluator.err
+}
+
+// examineAsserts evaluates the expressions against the status code, the
+// headers (lower-cased names), the body (decoded if it is JSON, the raw
+// text otherwise) and the duration in milliseconds.
+func examineAsserts(asserts []string, res *client.HttpResponse, duration time.Duration) map[string]error {
+	errs := make(map[string]error, 0)
+	evaluator, err := getCelEvaluator()
+	if err != nil {
+		errs["Asserts"] = err
+		return errs
+	}
+	vars := buildAssertionContext(res, duration)
+	for i, expr := range asserts {
+		key := fmt.Sprintf("Asserts[%d]", i)
+		ok, err := evaluator.Evaluate(expr, vars)
+		if err != nil {
+			errs[key] = fmt.Errorf("Expression [%s] is invalid, error: %s", expr, err.Error())
+			continue
+		}
+		if !ok {
+			errs[key] = fmt.Errorf("Expression [%s] is false", expr)
+		}
+	}
+	return errs
+}
+
+func buildAssertionContext(res *client.HttpResponse, duration time.Duration) map[string]interface{} {
+	headers := make(map[string]string, len(res.Header))
+	for name, values := range res.Header {
+		headers[strings.ToLower(name)] = strings.Join(values, ", ")
+	}
+	var body interface{}
+	if err := json.Unmarshal(res.Body, &body); err != nil {
+		body = string(res.Body)
+	}
+	return map[string]interface{}{
+		"status": int64(res.StatusCode),
+		"headers": headers,
+		"body": body,
+		"text": string(res.Body),
+		"duration": float64(duration) / float64(time.Millisecond),
+	}
+}
@@ -0,0 +1,58 @@
+package engine
+
+import(
+	"net/http"
+	"testing"
+	"time"
+	"github.com/stretchr/testify/assert"
+	"github.com/opwire/opwire-testa/lib/client"
+)
+
+func TestBuildAssertionContext(t *testing.T) {
+	res := &client.HttpResponse{
+		StatusCode: 200,
+		Header: http.Header{ "Content-Type": []string{"application/json"} },
+		Body: []byte(`{"items":[1,2]}`),
+	}
+	vars := buildAssertionContext(res, 1500 * time.Microsecond)
+	assert.Equal(t, int64(200), vars["status"])
+	assert.Equal(t, map[string]string{ "content-type": "application/json" }, vars["headers"])
+	assert.Equal(t, map[string]interface{}{ "items": []interface{}{ 1.0, 2.0 } }, vars["body"])
+	assert.Equal(t, `{"items":[1,2]}`, vars["text"])
+	assert.Equal(t, 1.5, vars["duration"])
+
+	res.Body = []byte("plain text")
+	vars = buildAssertionContext(res, 0)
+	assert.Equal(t, "plain text", vars["body"])
+}
+
+func TestExamineAsserts(t *testing.T) {
+	res := &client.HttpResponse{
+		StatusCode: 200,
+		Header: http.Header{ "Content-Type": []string{"application/json"} },
+		Body: []byte(`{"items":[1,2],"name":"testa"}`),
+	}
+	if newCelEvaluator == nil {
+		errs := examineAsserts([]string{ "status == 200" }, res, time.Millisecond)
+		assert.Contains(t, errs, "Asserts")
+		return
+	}
+	TESTCASES := []struct {
+		asserts []string
+		errors []string
+	}{
+		{ asserts: []string{ "body.items.size() > 0 && status == 200" }, errors: []string{} },
+		{ asserts: []string{ "headers['content-type'].startsWith('application/json')", "duration < 1000.0" }, errors: []string{} },
+		{ asserts: []string{ "status == 201", "body.name == 'testa'" }, errors: []string{ "Asserts[0]" } },
+		{ asserts: []string{ "body.name" }, errors: []string{ "Asserts[0]" } },
+		{ asserts: []string{ "status ==" }, errors: []string{ "Asserts[0]" } },
+	}
+	for _, tc := range TESTCASES {
+		errs := examineAsserts(tc.asserts, res, time.Millisecond)
+		keys := make([]string, 0)
+		for key := range errs {
+			keys = append(keys, key)
+		}
+		assert.ElementsMatch(t, tc.errors, keys)
+	}
+}
@@ -0,0 +1,65 @@
+// +build cel
+
+package engine
+
+import(
+	"fmt"
+	"sync"
+	"github.com/google/cel-go/cel"
+)
+
+func init() {
+	newCelEvaluator = func() (AssertionEvaluator, error) {
+		env, err := cel.NewEnv(
+			cel.Variable("status", cel.IntType),
+			cel.Variable("headers", cel.MapType(cel.StringType, cel.StringType)),
+			cel.Variable("body", cel.DynType),
+			cel.Variable("text", cel.StringType),
+			cel.Variable("duration", cel.DoubleType),
+		)
+		if err != nil {
+			return nil, err
+		}
+		return &celEvaluator{ env: env, programs: make(map[string]cel.Program) }, nil
+	}
+}
+
+type celEvaluator struct {
+	sync.Mutex
+	env *cel.Env
+	programs map[string]cel.Program
+}
+
+func (c *celEvaluator) Evaluate(expr string, vars map[string]interface{}) (bool, error) {
+	prg, err := c.compile(expr)
+	if err != nil {
+		return false, err
+	}
+	out, _, err := prg.Eval(vars)
+	if err != nil {
+		return false, err
+	}
+	ok, isBool := out.Value().(bool)
+	if !isBool {
+		return false, fmt.Errorf("result [%v] is not a boolean", out.Value())
+	}
+	return ok, nil
+}
+
+func (c *celEvaluator) compile(expr string) (cel.Program, error) {
+	c.Lock()
+	defer c.Unlock()
+	if prg, ok := c.programs[expr]; ok {
+		return prg, nil
+	}
+	ast, iss := c.env.Compile(expr)
+	if iss != nil && iss.Err() != nil {
+		return nil, iss.Err()
+	}
+	prg, err := c.env.Program(ast)
+	if err != nil {
+		return nil, err
+	}
+	c.programs[expr] = prg
+	return prg, nil
+}
@@ -0,0 +1,51 @@
+package engine
+
+import(
+	"crypto/x509"
+	"fmt"
+	"math"
+	"time"
+)
+
+type MeasureCertificate struct {
+	IssuerCN *string `yaml:"issuer-cn,omitempty" json:"issuer-cn"`
+	CoversHosts []string `yaml:"covers-hosts,omitempty" json:"covers-hosts"`
+	DaysUntilExpiry *MeasureTotal `yaml:"days-until-expiry,omitempty" json:"days-until-expiry"`
+}
+
+// examineCertificate verifies the leaf certificate of the chain presented by
+// the server, the days until expiry are rounded down. The days are accepted
+// if they match from either end of the clock-skew allowance.
+func examineCertificate(m *MeasureCertificate, chain []*x509.Certificate, now time.Time, skew time.Duration) map[string]error {
+	errs := make(map[string]error, 0)
+	if len(chain) == 0 {
+		errs["Certificate"] = fmt.Errorf("Response has not been served over TLS")
+		return errs
+	}
+	leaf := chain[0]
+	if m.IssuerCN != nil && leaf.Issuer.CommonName != *m.IssuerCN {
+		errs["Certificate/IssuerCN"] = fmt.Errorf("Issuer CN [%s] is mismatched with expected: [%s]", leaf.Issuer.CommonName, *m.IssuerCN)
+	}
+	for _, host := range m.CoversHosts {
+		if err := leaf.VerifyHostname(host); err != nil {
+			errs["Certificate/CoversHosts/" + host] = fmt.Errorf("Certificate does not cover [%s], names: %v", host, leaf.DNSNames)
+		}
+	}
+	if m.DaysUntilExpiry != nil && m.DaysUntilExpiry.Is != nil {
+		err := examineNumber(countDaysUntil(leaf.NotAfter, now), m.DaysUntilExpiry.Is)
+		if err != nil && skew > 0 {
+			if examineNumber(countDaysUntil(leaf.NotAfter, now.Add(-skew)), m.DaysUntilExpiry.Is) == nil ||
+					examineNumber(countDaysUntil(leaf.NotAfter, now.Add(skew)), m.DaysUntilExpiry.Is) == nil {
+				err = nil
+			}
+		}
+		if err != nil {
+			errs["Certificate/DaysUntilExpiry"] = fmt.Errorf("Certificate expires on %s, days until expiry: %s", leaf.NotAfter.Format(time.RFC3339), err.Error())
+		}
+	}
+	return errs
+}
+
+func countDaysUntil(moment time.Time, now time.Time) int {
+	return int(math.Floor(moment.Sub(now).Hours() / 24))
+}
@@ -0,0 +1,86 @@
+package engine
+
+import(
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExamineCertificate(t *testing.T) {
+	now := time.Date(2019, 5, 20, 10, 30, 0, 0, time.UTC)
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject: pkix.Name{ CommonName: "api.example.com" },
+		Issuer: pkix.Name{ CommonName: "Example CA" },
+		DNSNames: []string{ "api.example.com", "*.staging.example.com" },
+		NotBefore: now.Add(-24 * time.Hour),
+		NotAfter: now.Add(30 * 24 * time.Hour + time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.Nil(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.Nil(t, err)
+	chain := []*x509.Certificate{ cert }
+
+	issuer, other := "api.example.com", "Other CA"
+	TESTCASES := []struct {
+		measure *MeasureCertificate
+		chain []*x509.Certificate
+		skew time.Duration
+		errors []string
+	}{
+		{ measure: &MeasureCertificate{ IssuerCN: &issuer }, chain: chain },
+		{ measure: &MeasureCertificate{ IssuerCN: &other }, chain: chain, errors: []string{ "Certificate/IssuerCN" } },
+		{ measure: &MeasureCertificate{ CoversHosts: []string{ "api.example.com", "web.staging.example.com" } }, chain: chain },
+		{
+			measure: &MeasureCertificate{ CoversHosts: []string{ "www.example.com" } },
+			chain: chain,
+			errors: []string{ "Certificate/CoversHosts/www.example.com" },
+		},
+		{
+			measure: &MeasureCertificate{ DaysUntilExpiry: &MeasureTotal{ Is: &ComparisonOperators{ EqualTo: 30 } } },
+			chain: chain,
+		},
+		{
+			measure: &MeasureCertificate{ DaysUntilExpiry: &MeasureTotal{ Is: &ComparisonOperators{ GTE: 31 } } },
+			chain: chain,
+			errors: []string{ "Certificate/DaysUntilExpiry" },
+		},
+		{
+			measure: &MeasureCertificate{ DaysUntilExpiry: &MeasureTotal{ Is: &ComparisonOperators{ EqualTo: 29 } } },
+			chain: chain,
+			errors: []string{ "Certificate/DaysUntilExpiry" },
+		},
+		{
+			measure: &MeasureCertificate{ DaysUntilExpiry: &MeasureTotal{ Is: &ComparisonOperators{ EqualTo: 29 } } },
+			chain: chain,
+			skew: 2 * time.Hour,
+		},
+		{
+			measure: &MeasureCertificate{ DaysUntilExpiry: &MeasureTotal{ Is: &ComparisonOperators{ EqualTo: 28 } } },
+			chain: chain,
+			skew: 2 * time.Hour,
+			errors: []string{ "Certificate/DaysUntilExpiry" },
+		},
+		{ measure: &MeasureCertificate{}, chain: nil, errors: []string{ "Certificate" } },
+	}
+	for i, c := range TESTCASES {
+		errs := examineCertificate(c.measure, c.chain, now, c.skew)
+		keys := make([]string, 0)
+		for key := range errs {
+			keys = append(keys, key)
+		}
+		if c.errors == nil {
+			c.errors = []string{}
+		}
+		assert.Equal(t, c.errors, keys, "testcase #%d", i)
+	}
+}
@@ -0,0 +1,127 @@
+package engine
+
+import(
+	"bytes"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+	"golang.org/x/net/html/charset"
+	"github.com/opwire/opwire-testa/lib/client"
+)
+
+type MeasureCharset struct {
+	IsEqualTo *string `yaml:"is-equal-to,omitempty" json:"is-equal-to"`
+	MatchesContent *bool `yaml:"matches-content,omitempty" json:"matches-content"`
+}
+
+var byteOrderMarks = []struct {
+	label string
+	mark []byte
+}{
+	{ label: "utf-8", mark: []byte{0xEF, 0xBB, 0xBF} },
+	{ label: "utf-16be", mark: []byte{0xFE, 0xFF} },
+	{ label: "utf-16le", mark: []byte{0xFF, 0xFE} },
+}
+
+func getDeclaredCharset(header http.Header) string {
+	_, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(params["charset"])
+}
+
+func getBomCharset(body []byte) (string, int) {
+	for _, bom := range byteOrderMarks {
+		if bytes.HasPrefix(body, bom.mark) {
+			return bom.label, len(bom.mark)
+		}
+	}
+	return "", 0
+}
+
+// decodeBody converts the (decompressed) response body to UTF-8 for the body
+// matchers, the byte order mark takes precedence over the charset of the
+// Content-Type.
+func decodeBody(header http.Header, body []byte) ([]byte, error) {
+	label, size := getBomCharset(body)
+	if size == 0 {
+		label = getDeclaredCharset(header)
+	}
+	if len(label) == 0 {
+		return body, nil
+	}
+	encoding, name := charset.Lookup(label)
+	if encoding == nil {
+		return body, fmt.Errorf("Unknown charset [%s] of the response body", label)
+	}
+	if name == "utf-8" {
+		return body[size:], nil
+	}
+	decoded, err := encoding.NewDecoder().Bytes(body[size:])
+	if err != nil {
+		return body, fmt.Errorf("Decoding the response body from [%s] failed: %s", name, err.Error())
+	}
+	return decoded, nil
+}
+
+// examineCharset compares the declared charset with the expected one and,
+// with matches-content, with the byte order mark and the bytes of the body
+// (e.g. UTF-8 content served as ISO-8859-1).
+func examineCharset(m *MeasureCharset, res *client.HttpResponse) map[string]error {
+	errs := make(map[string]error, 0)
+	declared := getDeclaredCharset(res.Header)
+	_, declaredName := charset.Lookup(declared)
+	if m.IsEqualTo != nil {
+		_, expectedName := charset.Lookup(*m.IsEqualTo)
+		if len(declared) == 0 {
+			errs["Charset/IsEqualTo"] = fmt.Errorf("Content-Type declares no charset, expected: [%s]", *m.IsEqualTo)
+		} else if !strings.EqualFold(declared, *m.IsEqualTo) && (len(declaredName) == 0 || declaredName != expectedName) {
+			errs["Charset/IsEqualTo"] = fmt.Errorf("Declared charset [%s] is mismatched with expected: [%s]", declared, *m.IsEqualTo)
+		}
+	}
+	if m.MatchesContent != nil && *m.MatchesContent {
+		if err := examineCharsetContent(declared, declaredName, res.Body); err != nil {
+			errs["Charset/MatchesContent"] = err
+		}
+	}
+	return errs
+}
+
+func examineCharsetContent(declared string, declaredName string, body []byte) error {
+	bomLabel, size := getBomCharset(body)
+	if len(declared) > 0 && len(declaredName) == 0 {
+		return fmt.Errorf("Declared charset [%s] is unknown", declared)
+	}
+	if size > 0 && len(declaredName) > 0 && declaredName != bomLabel {
+		return fmt.Errorf("Byte order mark of [%s] is inconsistent with the declared charset [%s]", bomLabel, declared)
+	}
+	effective := declaredName
+	if size > 0 {
+		effective = bomLabel
+	}
+	content := body[size:]
+	switch effective {
+	case "", "utf-8":
+		if !utf8.Valid(content) {
+			return fmt.Errorf("Response body is not valid UTF-8 (declared charset: [%s])", declared)
+		}
+	case "utf-16be", "utf-16le":
+	default:
+		if !isASCII(content) && utf8.Valid(content) {
+			return fmt.Errorf("Response body looks like UTF-8 while the declared charset is [%s]", declared)
+		}
+	}
+	return nil
+}
+
+func isASCII(content []byte) bool {
+	for _, b := range content {
+		if b >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,59 @@
+package engine
+
+import(
+	"net/http"
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/opwire/opwire-testa/lib/client"
+)
+
+func TestDecodeBody(t *testing.T) {
+	TESTCASES := []struct {
+		contentType string
+		body []byte
+		decoded string
+		failed bool
+	}{
+		{ contentType: "text/plain", body: []byte("café"), decoded: "café" },
+		{ contentType: "text/plain; charset=ISO-8859-1", body: []byte{'c', 'a', 'f', 0xE9}, decoded: "café" },
+		{ contentType: "application/json; charset=utf-8", body: []byte("\xEF\xBB\xBF{\"a\":1}"), decoded: `{"a":1}` },
+		{ contentType: "text/plain", body: []byte{0xFF, 0xFE, 'h', 0, 'i', 0}, decoded: "hi" },
+		{ contentType: "text/plain; charset=x-unknown", body: []byte("raw"), decoded: "raw", failed: true },
+	}
+	for i, tc := range TESTCASES {
+		res := &client.HttpResponse{ Header: http.Header{ "Content-Type": []string{tc.contentType} }, Body: tc.body }
+		decoded, err := decodeBody(res.Header, res.Body)
+		assert.Equal(t, tc.failed, err != nil, "testcase #%d", i)
+		assert.Equal(t, tc.decoded, string(decoded), "testcase #%d", i)
+	}
+}
+
+func TestExamineCharset(t *testing.T) {
+	ref := func(s string) *string { return &s }
+	yes := true
+	TESTCASES := []struct {
+		charset MeasureCharset
+		contentType string
+		body []byte
+		errors []string
+	}{
+		{ charset: MeasureCharset{ IsEqualTo: ref("utf-8"), MatchesContent: &yes }, contentType: "text/html; charset=UTF-8", body: []byte("café"), errors: []string{} },
+		{ charset: MeasureCharset{ IsEqualTo: ref("latin1") }, contentType: "text/html; charset=ISO-8859-1", body: []byte("cafe"), errors: []string{} },
+		{ charset: MeasureCharset{ IsEqualTo: ref("utf-8") }, contentType: "text/html", body: []byte("cafe"), errors: []string{ "Charset/IsEqualTo" } },
+		{ charset: MeasureCharset{ IsEqualTo: ref("utf-8") }, contentType: "text/html; charset=ISO-8859-1", body: []byte("cafe"), errors: []string{ "Charset/IsEqualTo" } },
+		{ charset: MeasureCharset{ MatchesContent: &yes }, contentType: "text/html; charset=utf-8", body: []byte{'c', 'a', 'f', 0xE9}, errors: []string{ "Charset/MatchesContent" } },
+		{ charset: MeasureCharset{ MatchesContent: &yes }, contentType: "text/html; charset=ISO-8859-1", body: []byte("café"), errors: []string{ "Charset/MatchesContent" } },
+		{ charset: MeasureCharset{ MatchesContent: &yes }, contentType: "text/html; charset=ISO-8859-1", body: []byte{'c', 'a', 'f', 0xE9}, errors: []string{} },
+		{ charset: MeasureCharset{ MatchesContent: &yes }, contentType: "text/html; charset=ISO-8859-1", body: []byte("\xEF\xBB\xBFcafe"), errors: []string{ "Charset/MatchesContent" } },
+		{ charset: MeasureCharset{ MatchesContent: &yes }, contentType: "text/html; charset=x-unknown", body: []byte("cafe"), errors: []string{ "Charset/MatchesContent" } },
+	}
+	for i, tc := range TESTCASES {
+		res := &client.HttpResponse{ Header: http.Header{ "Content-Type": []string{tc.contentType} }, Body: tc.body }
+		errs := examineCharset(&tc.charset, res)
+		keys := make([]string, 0)
+		for key := range errs {
+			keys = append(keys, key)
+		}
+		assert.ElementsMatch(t, tc.errors, keys, "testcase #%d", i)
+	}
+}
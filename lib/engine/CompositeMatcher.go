@@ -0,0 +1,73 @@
+package engine
+
+import(
+	"fmt"
+	"sort"
+	"strings"
+	"github.com/opwire/opwire-testa/lib/utils"
+)
+
+func hasGroups(expect *Expectation) bool {
+	return len(expect.AllOf) > 0 || len(expect.AnyOf) > 0 || len(expect.NoneOf) > 0
+}
+
+// examineGroups evaluates the all-of, any-of and none-of groups, each item of
+// a group is a whole expectation matched against the same response, e.g.
+// either 200 with a body or 404 with an empty body.
+func (e *SpecHandler) examineGroups(expect *Expectation, x *examination) map[string]error {
+	errs := make(map[string]error, 0)
+	for i, group := range expect.AllOf {
+		failures, warnings := e.examineAlternative(group, x)
+		x.result.Warnings = append(x.result.Warnings, warnings...)
+		for key, err := range failures {
+			errs[fmt.Sprintf("AllOf[%d]/%s", i, key)] = err
+		}
+	}
+	if len(expect.AnyOf) > 0 {
+		mismatches := make([]string, 0)
+		for i, group := range expect.AnyOf {
+			failures, warnings := e.examineAlternative(group, x)
+			if len(failures) == 0 {
+				x.result.Warnings = append(x.result.Warnings, warnings...)
+				mismatches = nil
+				break
+			}
+			mismatches = append(mismatches, fmt.Sprintf("Alternative #%d: %s", i, describeFailures(failures)))
+		}
+		if len(mismatches) > 0 {
+			errs["AnyOf"] = utils.BuildMultilineError(append([]string{ "None of the alternatives is matched" }, mismatches...))
+		}
+	}
+	for i, group := range expect.NoneOf {
+		if failures, _ := e.examineAlternative(group, x); len(failures) == 0 {
+			errs[fmt.Sprintf("NoneOf[%d]", i)] = fmt.Errorf("Response matches all of the matchers of the excluded alternative")
+		}
+	}
+	return errs
+}
+
+// examineAlternative matches an item of a group, its warnings are returned
+// rather than reported, they are relevant only if the item is retained.
+func (e *SpecHandler) examineAlternative(expect *Expectation, x *examination) (map[string]error, []Warning) {
+	if expect == nil {
+		return map[string]error{}, nil
+	}
+	scratch := *x
+	scratch.result = &ExaminationResult{}
+	failures := e.examineExpectation(expect, &scratch)
+	downgradeFailures(failures, expect.getWarningKeys(), scratch.result)
+	return failures, scratch.result.Warnings
+}
+
+func describeFailures(failures map[string]error) string {
+	keys := make([]string, 0, len(failures))
+	for key := range failures {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	items := make([]string, 0, len(keys))
+	for _, key := range keys {
+		items = append(items, fmt.Sprintf("[%s] %s", key, strings.Replace(failures[key].Error(), "\n", " ", -1)))
+	}
+	return strings.Join(items, "; ")
+}
@@ -0,0 +1,61 @@
+package engine
+
+import(
+	"net/http"
+	"testing"
+	"time"
+	"github.com/stretchr/testify/assert"
+	"github.com/opwire/opwire-testa/lib/client"
+	"github.com/opwire/opwire-testa/lib/sieve"
+)
+
+func TestSpecHandler_examineGroups(t *testing.T) {
+	truthy, falsy := true, false
+	e := &SpecHandler{ verdicts: NewVerdictCache() }
+	cache, _ := sieve.NewRestCache()
+	status := func(code int) *Expectation {
+		return &Expectation{ StatusCode: &MeasureStatusCode{ Is: &ComparisonOperators{ EqualTo: code } } }
+	}
+	found := status(200)
+	found.Body = &MeasureBody{ IsEmpty: &falsy }
+	missing := status(404)
+	missing.Body = &MeasureBody{ IsEmpty: &truthy }
+
+	examine := func(expect *Expectation, code int, body string) map[string]error {
+		res := &client.HttpResponse{ StatusCode: code, Header: http.Header{}, Body: []byte(body), BodySize: int64(len(body)) }
+		x := &examination{
+			req: &client.HttpRequest{ Method: "GET" },
+			res: res,
+			content: res.Body,
+			cache: cache,
+			result: &ExaminationResult{},
+			startTime: time.Now(),
+		}
+		return e.examineGroups(expect, x)
+	}
+
+	either := &Expectation{ AnyOf: []*Expectation{ found, missing } }
+	assert.Equal(t, 0, len(examine(either, 200, `{"id":1}`)))
+	assert.Equal(t, 0, len(examine(either, 404, ``)))
+	errs := examine(either, 404, `Not Found`)
+	assert.Contains(t, errs, "AnyOf")
+	assert.Contains(t, errs["AnyOf"].Error(), "Alternative #1: [Body/IsEmpty]")
+
+	both := &Expectation{ AllOf: []*Expectation{ status(200), found } }
+	assert.Equal(t, 0, len(examine(both, 200, `{}`)))
+	errs = examine(both, 500, `{}`)
+	assert.Contains(t, errs, "AllOf[0]/StatusCode")
+	assert.Contains(t, errs, "AllOf[1]/StatusCode")
+
+	neither := &Expectation{ NoneOf: []*Expectation{ status(500), status(502) } }
+	assert.Equal(t, 0, len(examine(neither, 200, ``)))
+	assert.Contains(t, examine(neither, 502, ``), "NoneOf[1]")
+
+	// the severities of the matchers of an item are applied within the group
+	warning := SEVERITY_WARNING
+	lenient := status(200)
+	lenient.Body = &MeasureBody{ IsEmpty: &truthy, Severity: &warning }
+	advised := &Expectation{ AllOf: []*Expectation{ lenient } }
+	assert.Equal(t, 0, len(examine(advised, 200, `{}`)))
+	assert.Contains(t, examine(advised, 500, `{}`), "AllOf[0]/StatusCode")
+}
@@ -0,0 +1,275 @@
+package engine
+
+import(
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Condition is the skip-if expression of a test case, e.g.
+// "${env.NAME} == 'prod' || ${vars.readonly}". The references are resolved
+// when the condition is evaluated, an operand alone is true unless it is
+// empty, "false" or "0".
+type Condition struct {
+	source string
+	root *condNode
+}
+
+type condNode struct {
+	op string
+	value string
+	operands []*condNode
+}
+
+const (
+	condLiteral = "literal"
+	condReference = "reference"
+	condNot = "!"
+	condAnd = "&&"
+	condOr = "||"
+	condEqual = "=="
+	condNotEqual = "!="
+)
+
+func ParseCondition(source string) (*Condition, error) {
+	tokens, err := tokenizeCondition(source)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid condition [%s]: %s", source, err.Error())
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("Condition is empty")
+	}
+	p := &condParser{ tokens: tokens }
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("Invalid condition [%s]: %s", source, err.Error())
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("Invalid condition [%s]: unexpected [%s]", source, p.tokens[p.pos].text)
+	}
+	return &Condition{ source: source, root: root }, nil
+}
+
+// Evaluate resolves the references (the text between "${" and "}") with the
+// lookup function and reports whether the condition holds.
+func (c *Condition) Evaluate(lookup func(ref string) string) bool {
+	return isTruthy(c.root.evaluate(lookup))
+}
+
+func (c *Condition) String() string {
+	return c.source
+}
+
+func (n *condNode) evaluate(lookup func(ref string) string) string {
+	switch n.op {
+	case condLiteral:
+		return n.value
+	case condReference:
+		return lookup(n.value)
+	case condNot:
+		return formatTruth(!isTruthy(n.operands[0].evaluate(lookup)))
+	case condAnd:
+		for _, operand := range n.operands {
+			if !isTruthy(operand.evaluate(lookup)) {
+				return formatTruth(false)
+			}
+		}
+		return formatTruth(true)
+	case condOr:
+		for _, operand := range n.operands {
+			if isTruthy(operand.evaluate(lookup)) {
+				return formatTruth(true)
+			}
+		}
+		return formatTruth(false)
+	case condEqual:
+		return formatTruth(n.operands[0].evaluate(lookup) == n.operands[1].evaluate(lookup))
+	case condNotEqual:
+		return formatTruth(n.operands[0].evaluate(lookup) != n.operands[1].evaluate(lookup))
+	}
+	return ""
+}
+
+func isTruthy(value string) bool {
+	return len(value) > 0 && value != "false" && value != "0"
+}
+
+func formatTruth(value bool) string {
+	if value {
+		return "true"
+	}
+	return "false"
+}
+
+type condToken struct {
+	text string
+	kind string
+}
+
+type condParser struct {
+	tokens []condToken
+	pos int
+}
+
+func (p *condParser) peek() condToken {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return condToken{}
+}
+
+func (p *condParser) parseOr() (*condNode, error) {
+	return p.parseBinary(condOr, p.parseAnd)
+}
+
+func (p *condParser) parseAnd() (*condNode, error) {
+	return p.parseBinary(condAnd, p.parseUnary)
+}
+
+func (p *condParser) parseBinary(op string, parseOperand func() (*condNode, error)) (*condNode, error) {
+	first, err := parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	node := &condNode{ op: op, operands: []*condNode{ first } }
+	for p.peek().kind == "operator" && p.peek().text == op {
+		p.pos++
+		next, err := parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		node.operands = append(node.operands, next)
+	}
+	if len(node.operands) == 1 {
+		return first, nil
+	}
+	return node, nil
+}
+
+func (p *condParser) parseUnary() (*condNode, error) {
+	token := p.peek()
+	if token.kind == "operator" && token.text == condNot {
+		p.pos++
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &condNode{ op: condNot, operands: []*condNode{ operand } }, nil
+	}
+	if token.kind == "operator" && token.text == "(" {
+		p.pos++
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if next := p.peek(); next.kind != "operator" || next.text != ")" {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		p.pos++
+		return node, nil
+	}
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	if next := p.peek(); next.kind == "operator" && (next.text == condEqual || next.text == condNotEqual) {
+		p.pos++
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return &condNode{ op: next.text, operands: []*condNode{ left, right } }, nil
+	}
+	return left, nil
+}
+
+func (p *condParser) parseOperand() (*condNode, error) {
+	token := p.peek()
+	switch token.kind {
+	case "":
+		return nil, fmt.Errorf("unexpected end of condition")
+	case "operator":
+		return nil, fmt.Errorf("unexpected [%s]", token.text)
+	}
+	p.pos++
+	return &condNode{ op: token.kind, value: token.text }, nil
+}
+
+// tokenizeCondition splits the condition into the operators, the references
+// (${...}), the quoted strings and the bare words.
+func tokenizeCondition(source string) ([]condToken, error) {
+	tokens := make([]condToken, 0)
+	runes := []rune(source)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '$' && i + 1 < len(runes) && runes[i+1] == '{':
+			j := i + 2
+			for j < len(runes) && runes[j] != '}' {
+				j++
+			}
+			if j >= len(runes) {
+				return nil, fmt.Errorf("unterminated reference")
+			}
+			ref := strings.TrimSpace(string(runes[i+2:j]))
+			if len(ref) == 0 {
+				return nil, fmt.Errorf("empty reference")
+			}
+			tokens = append(tokens, condToken{ text: ref, kind: condReference })
+			i = j + 1
+		case r == '\'' || r == '"':
+			j := i + 1
+			for j < len(runes) && runes[j] != r {
+				j++
+			}
+			if j >= len(runes) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			tokens = append(tokens, condToken{ text: string(runes[i+1:j]), kind: condLiteral })
+			i = j + 1
+		case r == '(' || r == ')':
+			tokens = append(tokens, condToken{ text: string(r), kind: "operator" })
+			i++
+		case i + 1 < len(runes) && (string(runes[i:i+2]) == condAnd || string(runes[i:i+2]) == condOr ||
+				string(runes[i:i+2]) == condEqual || string(runes[i:i+2]) == condNotEqual):
+			tokens = append(tokens, condToken{ text: string(runes[i:i+2]), kind: "operator" })
+			i += 2
+		case r == '!':
+			tokens = append(tokens, condToken{ text: condNot, kind: "operator" })
+			i++
+		default:
+			j := i
+			for j < len(runes) && !unicode.IsSpace(runes[j]) && !strings.ContainsRune("()!=&|'\"$", runes[j]) {
+				j++
+			}
+			if j == i {
+				return nil, fmt.Errorf("unexpected [%c]", r)
+			}
+			tokens = append(tokens, condToken{ text: string(runes[i:j]), kind: condLiteral })
+			i = j
+		}
+	}
+	return tokens, nil
+}
+
+// ParseConditions parses the skip-if conditions of the test cases.
+func (r *TestSuite) ParseConditions() error {
+	for _, testcase := range r.TestCases {
+		if testcase == nil || testcase.SkipIf == nil {
+			continue
+		}
+		condition, err := ParseCondition(*testcase.SkipIf)
+		if err != nil {
+			return fmt.Errorf("Testcase [%s]: %s", testcase.Title, err.Error())
+		}
+		testcase.skipCondition = condition
+	}
+	return nil
+}
+
+// GetSkipCondition returns the condition parsed by TestSuite.ParseConditions().
+func (t *TestCase) GetSkipCondition() *Condition {
+	return t.skipCondition
+}
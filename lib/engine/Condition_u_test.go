@@ -0,0 +1,40 @@
+package engine
+
+import(
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCondition_Evaluate(t *testing.T) {
+	values := map[string]string{ "env.NAME": "prod", "vars.readonly": "true", "vars.count": "0" }
+	lookup := func(ref string) string {
+		return values[ref]
+	}
+	TESTCASES := []struct {
+		source string
+		holds bool
+	}{
+		{ source: "${env.NAME} == 'prod'", holds: true },
+		{ source: `${ env.NAME } != "prod"`, holds: false },
+		{ source: "${env.NAME} == staging", holds: false },
+		{ source: "${vars.readonly}", holds: true },
+		{ source: "${vars.count}", holds: false },
+		{ source: "${vars.missing}", holds: false },
+		{ source: "!${vars.missing} && ${env.NAME} == 'prod'", holds: true },
+		{ source: "(${env.NAME} == 'dev' || ${env.NAME} == 'staging') && ${vars.readonly}", holds: false },
+		{ source: "${env.NAME} == 'dev' || ${env.NAME} == 'prod'", holds: true },
+		{ source: "${vars.missing} == ''", holds: true },
+	}
+	for i, c := range TESTCASES {
+		condition, err := ParseCondition(c.source)
+		assert.Nil(t, err, "testcase #%d", i)
+		assert.Equal(t, c.holds, condition.Evaluate(lookup), "testcase #%d: %s", i, c.source)
+	}
+}
+
+func TestParseCondition_Invalid(t *testing.T) {
+	for _, source := range []string{ "", "${env.NAME} ==", "(${env.NAME} == 'prod'", "${env.NAME", "'prod", "== 'prod'", "${env.NAME} 'prod'" } {
+		_, err := ParseCondition(source)
+		assert.NotNil(t, err, source)
+	}
+}
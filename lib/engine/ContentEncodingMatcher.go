@@ -0,0 +1,29 @@
+package engine
+
+import(
+	"fmt"
+	"strings"
+	"github.com/opwire/opwire-testa/lib/client"
+)
+
+type MeasureContentEncoding struct {
+	IsEqualTo *string `yaml:"is-equal-to,omitempty" json:"is-equal-to"`
+	DecodedSize *MeasureSize `yaml:"decoded-size,omitempty" json:"decoded-size"`
+}
+
+// examineContentEncoding compares the codings of the Content-Encoding header
+// (e.g. "gzip") and the size of the decompressed body, the body has been
+// decoded beforehand by the built-in consistency check.
+func examineContentEncoding(m *MeasureContentEncoding, res *client.HttpResponse) map[string]error {
+	errs := make(map[string]error, 0)
+	codings := strings.Join(res.GetContentEncodings(), ", ")
+	if m.IsEqualTo != nil && !strings.EqualFold(codings, strings.TrimSpace(*m.IsEqualTo)) {
+		errs["ContentEncoding/IsEqualTo"] = fmt.Errorf("Content-Encoding [%s] is mismatched with expected: [%s]", codings, *m.IsEqualTo)
+	}
+	if m.DecodedSize != nil {
+		if err := examineNumber(res.DecodedSize, m.DecodedSize.toOperators()); err != nil {
+			errs["ContentEncoding/DecodedSize"] = fmt.Errorf("Decoded body size: %s", err.Error())
+		}
+	}
+	return errs
+}
@@ -0,0 +1,31 @@
+package engine
+
+import(
+	"net/http"
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/opwire/opwire-testa/lib/client"
+	"github.com/opwire/opwire-testa/lib/utils"
+)
+
+func TestExamineContentEncoding(t *testing.T) {
+	limit, small := int64(1024), int64(10)
+	res := &client.HttpResponse{ Header: http.Header{ "Content-Encoding": []string{ "GZIP" } }, BodySize: 120, DecodedSize: 700 }
+	TESTCASES := []struct {
+		measure MeasureContentEncoding
+		failed []string
+	}{
+		{ measure: MeasureContentEncoding{ IsEqualTo: utils.RefOfString("gzip"), DecodedSize: &MeasureSize{ IsLTE: &limit } } },
+		{ measure: MeasureContentEncoding{ IsEqualTo: utils.RefOfString("br"), DecodedSize: &MeasureSize{ IsLT: &small } }, failed: []string{ "ContentEncoding/IsEqualTo", "ContentEncoding/DecodedSize" } },
+	}
+	for i, c := range TESTCASES {
+		errs := examineContentEncoding(&c.measure, res)
+		keys := make([]string, 0)
+		for key := range errs {
+			keys = append(keys, key)
+		}
+		assert.ElementsMatch(t, c.failed, keys, "testcase #%d", i)
+	}
+	errs := examineContentEncoding(&MeasureContentEncoding{ IsEqualTo: utils.RefOfString("") }, &client.HttpResponse{ Header: http.Header{} })
+	assert.Equal(t, 0, len(errs))
+}
@@ -0,0 +1,105 @@
+package engine
+
+import(
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+type MeasureCookie struct {
+	Name *string `yaml:"name" json:"name"`
+	IsAbsent *bool `yaml:"is-absent,omitempty" json:"is-absent"`
+	Is *ComparisonOperators `yaml:"is,omitempty" json:"is"`
+	Matches *string `yaml:"matches,omitempty" json:"matches"`
+	Secure *bool `yaml:"secure,omitempty" json:"secure"`
+	HttpOnly *bool `yaml:"http-only,omitempty" json:"http-only"`
+	SameSite *string `yaml:"same-site,omitempty" json:"same-site"`
+	MaxAge *MeasureTotal `yaml:"max-age,omitempty" json:"max-age"`
+	Severity *string `yaml:"severity,omitempty" json:"severity"`
+}
+
+// examineCookies verifies the cookies set by the response, the last
+// Set-Cookie header wins when a cookie is set more than once.
+func examineCookies(expected []MeasureCookie, cookies []*http.Cookie) map[string]error {
+	errs := make(map[string]error, 0)
+	received := make(map[string]*http.Cookie, 0)
+	for _, cookie := range cookies {
+		received[cookie.Name] = cookie
+	}
+	for _, item := range expected {
+		if item.Name == nil {
+			continue
+		}
+		key := fmt.Sprintf("Cookie[%s]", *item.Name)
+		cookie, present := received[*item.Name]
+		if item.IsAbsent != nil && *item.IsAbsent {
+			if present {
+				errs[key] = fmt.Errorf("Cookie must be absent, returned value: [%s]", cookie.Value)
+			}
+			continue
+		}
+		if !present {
+			errs[key] = fmt.Errorf("Cookie must be present")
+			continue
+		}
+		if err := examineCookie(item, cookie); err != nil {
+			errs[key] = err
+		}
+	}
+	return errs
+}
+
+func examineCookie(item MeasureCookie, cookie *http.Cookie) error {
+	if item.Is != nil && item.Is.EqualTo != nil {
+		if expected := fmt.Sprintf("%v", item.Is.EqualTo); cookie.Value != expected {
+			return fmt.Errorf("Returned value: [%s] is mismatched with expected: [%s]", cookie.Value, expected)
+		}
+	}
+	if item.Matches != nil {
+		if _, err := matchHeader(cookie.Value, *item.Matches); err != nil {
+			return err
+		}
+	}
+	if item.Secure != nil && cookie.Secure != *item.Secure {
+		return fmt.Errorf("Secure attribute is [%t], expected: [%t]", cookie.Secure, *item.Secure)
+	}
+	if item.HttpOnly != nil && cookie.HttpOnly != *item.HttpOnly {
+		return fmt.Errorf("HttpOnly attribute is [%t], expected: [%t]", cookie.HttpOnly, *item.HttpOnly)
+	}
+	if item.SameSite != nil {
+		if sameSite := getCookieSameSite(cookie); sameSite != strings.ToLower(*item.SameSite) {
+			return fmt.Errorf("SameSite attribute is [%s], expected: [%s]", sameSite, *item.SameSite)
+		}
+	}
+	if item.MaxAge != nil && item.MaxAge.Is != nil {
+		if cookie.MaxAge == 0 {
+			return fmt.Errorf("Cookie has no Max-Age attribute")
+		}
+		maxAge := cookie.MaxAge
+		if maxAge < 0 {
+			maxAge = 0
+		}
+		if err := examineNumber(maxAge, item.MaxAge.Is); err != nil {
+			return fmt.Errorf("Max-Age: %s", err.Error())
+		}
+	}
+	return nil
+}
+
+// getCookieSameSite returns the SameSite attribute in lower case (an empty
+// string if the attribute is not given).
+func getCookieSameSite(cookie *http.Cookie) string {
+	switch cookie.SameSite {
+	case http.SameSiteLaxMode:
+		return "lax"
+	case http.SameSiteStrictMode:
+		return "strict"
+	}
+	for _, attr := range strings.Split(cookie.Raw, ";") {
+		parts := strings.SplitN(strings.TrimSpace(attr), "=", 2)
+		if len(parts) == 2 && strings.EqualFold(parts[0], "samesite") {
+			return strings.ToLower(strings.TrimSpace(parts[1]))
+		}
+	}
+	return ""
+}
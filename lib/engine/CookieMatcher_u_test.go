@@ -0,0 +1,47 @@
+package engine
+
+import(
+	"net/http"
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExamineCookies(t *testing.T) {
+	header := http.Header{}
+	header.Add("Set-Cookie", "session=abc123; Path=/; Max-Age=3600; Secure; HttpOnly; SameSite=Strict")
+	header.Add("Set-Cookie", "theme=dark; SameSite=None; Secure")
+	header.Add("Set-Cookie", "expired=; Max-Age=0")
+	header.Add("Set-Cookie", "plain=1")
+	cookies := (&http.Response{ Header: header }).Cookies()
+
+	ref := func(s string) *string { return &s }
+	truthy, falsy := true, false
+	TESTCASES := []struct {
+		cookie MeasureCookie
+		ok bool
+	}{
+		{ cookie: MeasureCookie{ Name: ref("session") }, ok: true },
+		{ cookie: MeasureCookie{ Name: ref("missing") }, ok: false },
+		{ cookie: MeasureCookie{ Name: ref("missing"), IsAbsent: &truthy }, ok: true },
+		{ cookie: MeasureCookie{ Name: ref("plain"), IsAbsent: &truthy }, ok: false },
+		{ cookie: MeasureCookie{ Name: ref("session"), Is: &ComparisonOperators{ EqualTo: "abc123" } }, ok: true },
+		{ cookie: MeasureCookie{ Name: ref("session"), Is: &ComparisonOperators{ EqualTo: "xyz" } }, ok: false },
+		{ cookie: MeasureCookie{ Name: ref("session"), Matches: ref("^[a-z]+[0-9]+$") }, ok: true },
+		{ cookie: MeasureCookie{ Name: ref("session"), Matches: ref("^[0-9]+$") }, ok: false },
+		{ cookie: MeasureCookie{ Name: ref("session"), Secure: &truthy, HttpOnly: &truthy }, ok: true },
+		{ cookie: MeasureCookie{ Name: ref("plain"), Secure: &truthy }, ok: false },
+		{ cookie: MeasureCookie{ Name: ref("theme"), HttpOnly: &falsy }, ok: true },
+		{ cookie: MeasureCookie{ Name: ref("session"), SameSite: ref("strict") }, ok: true },
+		{ cookie: MeasureCookie{ Name: ref("theme"), SameSite: ref("None") }, ok: true },
+		{ cookie: MeasureCookie{ Name: ref("theme"), SameSite: ref("Lax") }, ok: false },
+		{ cookie: MeasureCookie{ Name: ref("plain"), SameSite: ref("Lax") }, ok: false },
+		{ cookie: MeasureCookie{ Name: ref("session"), MaxAge: &MeasureTotal{ Is: &ComparisonOperators{ GTE: 3600 } } }, ok: true },
+		{ cookie: MeasureCookie{ Name: ref("session"), MaxAge: &MeasureTotal{ Is: &ComparisonOperators{ LT: 60 } } }, ok: false },
+		{ cookie: MeasureCookie{ Name: ref("expired"), MaxAge: &MeasureTotal{ Is: &ComparisonOperators{ EqualTo: 0 } } }, ok: true },
+		{ cookie: MeasureCookie{ Name: ref("plain"), MaxAge: &MeasureTotal{ Is: &ComparisonOperators{ GT: 0 } } }, ok: false },
+	}
+	for i, c := range TESTCASES {
+		errs := examineCookies([]MeasureCookie{ c.cookie }, cookies)
+		assert.Equal(t, c.ok, len(errs) == 0, "testcase #%d: %v", i, errs)
+	}
+}
@@ -0,0 +1,172 @@
+package engine
+
+import(
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+	"github.com/opwire/opwire-testa/lib/comparison"
+	"github.com/opwire/opwire-testa/lib/utils"
+)
+
+// examineCsv verifies a CSV response body, the path of a field is the index
+// of a data row followed by a column name (or index), e.g. "0.email"; the
+// matchers of a column are checked against every cell of the column.
+func examineCsv(eb *MeasureBody, body []byte, soft bool, skew time.Duration) map[string]error {
+	format := utils.BODY_FORMAT_CSV
+	errs := make(map[string]error, 0)
+	opts := eb.Csv
+	if opts == nil {
+		opts = &MeasureCsv{}
+	}
+	delimiter, err := opts.getDelimiter()
+	if err != nil {
+		errs["Body/Expectation"] = fmt.Errorf("[%s] %s", format, err.Error())
+		return errs
+	}
+	header, rows, err := parseCsv(body, delimiter, opts.hasHeader())
+	if err != nil {
+		errs["Body/ReceivedObject"] = fmt.Errorf("[%s] Invalid response content: %s", format, err)
+		return errs
+	}
+	if eb.IsEqualTo != nil {
+		eHeader, eRows, err := parseCsv([]byte(*eb.IsEqualTo), delimiter, opts.hasHeader())
+		if err != nil {
+			errs["Body/ExpectedObject"] = fmt.Errorf("[%s] Invalid expected content: %s", format, err)
+		} else if different, diff := comparison.LineDiff(renderCsv(eHeader, eRows), renderCsv(header, rows)); different {
+			errs["Body/IsEqualTo"] = newBodyMismatch(format, diff)
+		}
+	}
+	if eb.Includes != nil {
+		errs["Body/Includes"] = fmt.Errorf("[%s] The [includes] attribute is unsupported, please use [fields] instead", format)
+	}
+	if eb.MatchWith != nil {
+		if reg, err := regexp.Compile(*eb.MatchWith); err == nil {
+			if !reg.Match(body) {
+				errs["Body/MatchWith"] = fmt.Errorf("[%s] Response body is mismatched with the pattern.\nReceived: %s\nPattern: %s", format, string(body), *eb.MatchWith)
+			}
+		} else {
+			errs["Body/Expectation"] = fmt.Errorf("[%s] Invalid regular expression[%s], error: %s", format, *eb.MatchWith, err.Error())
+		}
+	}
+	if opts.RowCount != nil {
+		is := opts.RowCount.toOperators()
+		if err := examineNumber(len(rows), is); err != nil {
+			errs["Body/Csv/RowCount"] = fmt.Errorf("[%s] Number of rows: %s", format, err.Error())
+		}
+	}
+	for _, eField := range eb.Fields {
+		if eField.Path == nil {
+			continue
+		}
+		fieldKey := "Body/Fields/" + *eField.Path
+		parts := strings.SplitN(*eField.Path, ".", 2)
+		row, err := strconv.Atoi(parts[0])
+		if err != nil || len(parts) < 2 {
+			errs[fieldKey] = fmt.Errorf("Invalid cell path[%s], expected: <row>.<column>", *eField.Path)
+			continue
+		}
+		column, found := findCsvColumn(header, parts[1])
+		var rValue interface{}
+		if found && row >= 0 && row < len(rows) && column < len(rows[row]) {
+			rValue = rows[row][column]
+		} else {
+			found = false
+		}
+		if err := examineBodyField(eField, rValue, found, soft, skew); err != nil {
+			errs[fieldKey] = err
+		}
+	}
+	for _, eColumn := range opts.Columns {
+		if eColumn.Path == nil {
+			continue
+		}
+		columnKey := "Body/Csv/Columns/" + *eColumn.Path
+		column, found := findCsvColumn(header, *eColumn.Path)
+		if !found {
+			if eColumn.Exists == nil || *eColumn.Exists {
+				errs[columnKey] = fmt.Errorf("Column not found")
+			}
+			continue
+		}
+		if eColumn.Exists != nil && !*eColumn.Exists {
+			errs[columnKey] = fmt.Errorf("Column must not exist")
+			continue
+		}
+		for i, cells := range rows {
+			var rValue interface{}
+			if column < len(cells) {
+				rValue = cells[column]
+			}
+			if err := examineBodyField(eColumn, rValue, column < len(cells), soft, skew); err != nil {
+				addFailure(errs, columnKey, fmt.Errorf("Row #%d: %s", i, err.Error()), soft)
+				if !soft {
+					break
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// parseCsv splits the records into the header (nil if the content has no
+// header row) and the data rows.
+func parseCsv(content []byte, delimiter rune, withHeader bool) ([]string, [][]string, error) {
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, nil, err
+	}
+	if withHeader && len(records) > 0 {
+		return records[0], records[1:], nil
+	}
+	return nil, records, nil
+}
+
+func renderCsv(header []string, rows [][]string) string {
+	buf := &bytes.Buffer{}
+	writer := csv.NewWriter(buf)
+	if header != nil {
+		writer.Write(header)
+	}
+	writer.WriteAll(rows)
+	return buf.String()
+}
+
+// findCsvColumn looks a column up by its name in the header, then by its
+// (0-based) index.
+func findCsvColumn(header []string, name string) (int, bool) {
+	for i, title := range header {
+		if title == name {
+			return i, true
+		}
+	}
+	if index, err := strconv.Atoi(name); err == nil && index >= 0 {
+		return index, header == nil || index < len(header)
+	}
+	return 0, false
+}
+
+func (m *MeasureCsv) getDelimiter() (rune, error) {
+	if m.Delimiter == nil || len(*m.Delimiter) == 0 {
+		return ',', nil
+	}
+	if *m.Delimiter == `\t` {
+		return '\t', nil
+	}
+	if utf8.RuneCountInString(*m.Delimiter) != 1 {
+		return 0, fmt.Errorf("Invalid delimiter [%s], a single character is expected", *m.Delimiter)
+	}
+	r, _ := utf8.DecodeRuneInString(*m.Delimiter)
+	return r, nil
+}
+
+func (m *MeasureCsv) hasHeader() bool {
+	return m.HasHeader == nil || *m.HasHeader
+}
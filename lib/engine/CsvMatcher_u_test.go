@@ -0,0 +1,81 @@
+package engine
+
+import(
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/opwire/opwire-testa/lib/utils"
+)
+
+func TestExamineCsv(t *testing.T) {
+	falsy := false
+	rowCount := int64(3)
+	body := []byte("id;email;amount\n1;a@example.com;10\n2;b@example.com;25\n3;\"c;d@example.com\";7\n")
+	TESTCASES := []struct {
+		body MeasureBody
+		failed []string
+	}{
+		{
+			body: MeasureBody{
+				Fields: []MeasureBodyField{
+					{ Path: utils.RefOfString("0.email"), IsEqualTo: "a@example.com" },
+					{ Path: utils.RefOfString("2.email"), IsEqualTo: "c;d@example.com" },
+					{ Path: utils.RefOfString("1.2"), IsEqualTo: 25 },
+					{ Path: utils.RefOfString("3.id"), Exists: &falsy },
+				},
+				Csv: &MeasureCsv{
+					Delimiter: utils.RefOfString(";"),
+					RowCount: &MeasureSize{ IsEqualTo: &rowCount },
+					Columns: []MeasureBodyField{
+						{ Path: utils.RefOfString("amount"), MatchWith: utils.RefOfString(`^\d+$`) },
+						{ Path: utils.RefOfString("id"), IsOneOf: []interface{}{ 1, 2, 3 } },
+						{ Path: utils.RefOfString("secret"), Exists: &falsy },
+					},
+				},
+			},
+		},
+		{
+			body: MeasureBody{
+				Fields: []MeasureBodyField{
+					{ Path: utils.RefOfString("0.phone"), IsEqualTo: "" },
+					{ Path: utils.RefOfString("email"), IsEqualTo: "" },
+				},
+				Csv: &MeasureCsv{
+					Delimiter: utils.RefOfString(";"),
+					RowCount: &MeasureSize{ IsGT: &rowCount },
+					Columns: []MeasureBodyField{
+						{ Path: utils.RefOfString("amount"), MatchWith: utils.RefOfString(`^\d{2}$`) },
+						{ Path: utils.RefOfString("phone"), MatchWith: utils.RefOfString(`.`) },
+					},
+				},
+			},
+			failed: []string{ "Body/Fields/0.phone", "Body/Fields/email", "Body/Csv/RowCount", "Body/Csv/Columns/amount", "Body/Csv/Columns/phone" },
+		},
+		{
+			body: MeasureBody{
+				IsEqualTo: utils.RefOfString("id;email;amount\n1;a@example.com;10\n2;b@example.com;25\n3;c;d@example.com;7\n"),
+				Csv: &MeasureCsv{ Delimiter: utils.RefOfString(";") },
+			},
+			failed: []string{ "Body/IsEqualTo" },
+		},
+		{
+			body: MeasureBody{
+				Fields: []MeasureBodyField{
+					{ Path: utils.RefOfString("0.1"), IsEqualTo: "email" },
+				},
+				Csv: &MeasureCsv{ Delimiter: utils.RefOfString(";"), HasHeader: &falsy },
+			},
+		},
+	}
+	for i, c := range TESTCASES {
+		errs := examineCsv(&c.body, body, false, 0)
+		keys := make([]string, 0)
+		for key := range errs {
+			keys = append(keys, key)
+		}
+		assert.ElementsMatch(t, c.failed, keys, "testcase #%d", i)
+	}
+	errs := examineCsv(&MeasureBody{ Csv: &MeasureCsv{ Delimiter: utils.RefOfString("||") } }, body, false, 0)
+	assert.Contains(t, errs, "Body/Expectation")
+	errs = examineCsv(&MeasureBody{}, []byte("a,\"b\n"), false, 0)
+	assert.Contains(t, errs, "Body/ReceivedObject")
+}
@@ -0,0 +1,135 @@
+package engine
+
+import(
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"github.com/opwire/opwire-testa/lib/storage"
+)
+
+var ROW_VAR_EXPRESSION = regexp.MustCompile(`\${{\s*row\.([A-Za-z0-9_\-]+)\s*}}`)
+
+// LoadDataFiles reads the rows of the data files of the test cases, the paths
+// are resolved against the directory of the test suite file.
+func (r *TestSuite) LoadDataFiles() error {
+	for _, testcase := range r.TestCases {
+		if testcase == nil || testcase.DataFile == nil {
+			continue
+		}
+		rows, err := loadDataRows(*testcase.DataFile, testcase.home)
+		if err != nil {
+			return fmt.Errorf("Testcase [%s]: %s", testcase.Title, err.Error())
+		}
+		testcase.rows = rows
+	}
+	return nil
+}
+
+// ExpandDataRows returns one copy of the test case per row of its data file,
+// in which the ${{row.<column>}} expressions are replaced by the values of the
+// row; a test case without data file is returned unchanged.
+func (t *TestCase) ExpandDataRows() []*TestCase {
+	if t.DataFile == nil {
+		return []*TestCase{ t }
+	}
+	expanded := make([]*TestCase, 0, len(t.rows))
+	for i, row := range t.rows {
+		evaluate := func(text string) string {
+			return ROW_VAR_EXPRESSION.ReplaceAllStringFunc(text, func(exp string) string {
+				if value, found := row[ROW_VAR_EXPRESSION.FindStringSubmatch(exp)[1]]; found {
+					return value
+				}
+				return exp
+			})
+		}
+		copied := resolveValue(reflect.ValueOf(t), evaluate).Interface().(*TestCase)
+		copied.Title = fmt.Sprintf("%s [row %d]", t.Title, i + 1)
+		copied.origin = t
+		expanded = append(expanded, copied)
+	}
+	return expanded
+}
+
+// GetOrigin returns the test case which a data row is expanded from.
+func (t *TestCase) GetOrigin() *TestCase {
+	if t.origin != nil {
+		return t.origin
+	}
+	return t
+}
+
+// loadDataRows reads a CSV file (its first line holds the names of the
+// columns) or a JSON file (an array of objects).
+func loadDataRows(dataFile string, home string) ([]map[string]string, error) {
+	path := dataFile
+	if !filepath.IsAbs(path) && len(home) > 0 {
+		path = filepath.Join(home, path)
+	}
+	file, err := storage.GetFs().Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("Loading data file [%s] failed: %s", dataFile, err.Error())
+	}
+	defer file.Close()
+	content, err := ioutil.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(filepath.Ext(dataFile)) {
+	case ".csv":
+		return parseCsvRows(dataFile, content)
+	case ".json":
+		return parseJsonRows(dataFile, content)
+	}
+	return nil, fmt.Errorf("Unsupported data file [%s], expected a .csv or .json file", dataFile)
+}
+
+func parseCsvRows(dataFile string, content []byte) ([]map[string]string, error) {
+	header, records, err := parseCsv(content, ',', true)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid data file [%s]: %s", dataFile, err.Error())
+	}
+	rows := make([]map[string]string, 0, len(records))
+	for _, record := range records {
+		row := make(map[string]string, len(header))
+		for i, column := range header {
+			if i < len(record) {
+				row[strings.TrimSpace(column)] = record[i]
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func parseJsonRows(dataFile string, content []byte) ([]map[string]string, error) {
+	objects := make([]map[string]interface{}, 0)
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	if err := decoder.Decode(&objects); err != nil {
+		return nil, fmt.Errorf("Invalid data file [%s], an array of objects is expected: %s", dataFile, err.Error())
+	}
+	rows := make([]map[string]string, 0, len(objects))
+	for _, obj := range objects {
+		row := make(map[string]string, len(obj))
+		for key, value := range obj {
+			switch value := value.(type) {
+			case string:
+				row[key] = value
+			case json.Number:
+				row[key] = value.String()
+			case nil:
+				row[key] = ""
+			default:
+				encoded, _ := json.Marshal(value)
+				row[key] = string(encoded)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
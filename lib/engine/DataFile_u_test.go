@@ -0,0 +1,61 @@
+package engine
+
+import(
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/opwire/opwire-testa/lib/client"
+	"github.com/opwire/opwire-testa/lib/utils"
+)
+
+func TestTestSuite_LoadDataFiles(t *testing.T) {
+	root, err := ioutil.TempDir("", "opwire-testa-data-")
+	assert.Nil(t, err)
+	defer os.RemoveAll(root)
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(root, "users.csv"), []byte("name, age\nalice,30\n\"bob, jr\",12\n"), 0644))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(root, "users.json"), []byte(`[{"name": "carol", "age": 41, "tags": ["x"]}]`), 0644))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(root, "users.txt"), []byte("alice"), 0644))
+
+	testcase := func(dataFile string) *TestCase {
+		return &TestCase{
+			Title: "Create user",
+			DataFile: utils.RefOfString(dataFile),
+			Request: &client.HttpRequest{ Url: "/users/${{row.name}}?age=${{row.age}}&id=${{run.id}}" },
+			Expectation: &Expectation{ Body: &MeasureBody{ Includes: utils.RefOfString(`{"age": ${{row.age}}}`) } },
+		}
+	}
+
+	t.Run("CSV rows", func(t *testing.T) {
+		suite := &TestSuite{ TestCases: []*TestCase{ testcase("users.csv"), { Title: "Other" } } }
+		suite.SetHome(root)
+		assert.Nil(t, suite.LoadDataFiles())
+		rows := suite.TestCases[0].ExpandDataRows()
+		assert.Equal(t, 2, len(rows))
+		assert.Equal(t, "Create user [row 1]", rows[0].Title)
+		assert.Equal(t, "/users/alice?age=30&id=${{run.id}}", rows[0].Request.Url)
+		assert.Equal(t, `{"age": 30}`, *rows[0].Expectation.Body.Includes)
+		assert.Equal(t, "/users/bob, jr?age=12&id=${{run.id}}", rows[1].Request.Url)
+		assert.Equal(t, suite.TestCases[0], rows[1].GetOrigin())
+		assert.Equal(t, "/users/${{row.name}}?age=${{row.age}}&id=${{run.id}}", suite.TestCases[0].Request.Url)
+		assert.Equal(t, []*TestCase{ suite.TestCases[1] }, suite.TestCases[1].ExpandDataRows())
+	})
+
+	t.Run("JSON rows", func(t *testing.T) {
+		suite := &TestSuite{ TestCases: []*TestCase{ testcase("users.json") } }
+		suite.SetHome(root)
+		assert.Nil(t, suite.LoadDataFiles())
+		rows := suite.TestCases[0].ExpandDataRows()
+		assert.Equal(t, 1, len(rows))
+		assert.Equal(t, "/users/carol?age=41&id=${{run.id}}", rows[0].Request.Url)
+	})
+
+	t.Run("Invalid data files", func(t *testing.T) {
+		for _, dataFile := range []string{ "users.txt", "missing.csv" } {
+			suite := &TestSuite{ TestCases: []*TestCase{ testcase(dataFile) } }
+			suite.SetHome(root)
+			assert.NotNil(t, suite.LoadDataFiles(), dataFile)
+		}
+	})
+}
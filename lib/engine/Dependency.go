@@ -0,0 +1,141 @@
+package engine
+
+import(
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// GetPrerequisites returns the test cases listed by the depends-on field, they
+// are resolved by TestSuite.Schedule().
+func (t *TestCase) GetPrerequisites() []*TestCase {
+	return t.prerequisites
+}
+
+// Schedule resolves the depends-on fields (a title or the capture store-id of
+// a test case of the same file) and returns the indexes of the test cases in
+// the order of execution: the prerequisites first, the order of the file
+// otherwise.
+func (r *TestSuite) Schedule() ([]int, error) {
+	return r.schedule(func(ready []int, previous int) int {
+		return ready[0]
+	})
+}
+
+// Shuffle resolves the depends-on fields as Schedule does, but the test cases
+// are picked randomly among those whose prerequisites are scheduled. The
+// test cases sharing a barrier are kept together as far as possible.
+func (r *TestSuite) Shuffle(random *rand.Rand) ([]int, error) {
+	return r.schedule(func(ready []int, previous int) int {
+		if previous >= 0 {
+			if barrier := r.TestCases[previous].GetBarrier(); len(barrier) > 0 {
+				for _, i := range ready {
+					if r.TestCases[i].GetBarrier() == barrier {
+						return i
+					}
+				}
+			}
+		}
+		return ready[random.Intn(len(ready))]
+	})
+}
+
+// schedule sorts the test cases topologically, pick chooses the next one among
+// the ready test cases (in the order of the file).
+func (r *TestSuite) schedule(pick func(ready []int, previous int) int) ([]int, error) {
+	cases := r.TestCases
+	dependents := make([][]int, len(cases))
+	blockers := make([]int, len(cases))
+	for i, testcase := range cases {
+		testcase.prerequisites = nil
+		for _, ref := range testcase.DependsOn {
+			j := findTestCase(cases, ref)
+			if j < 0 {
+				return nil, fmt.Errorf("Testcase [%s] depends on an unknown testcase [%s]", testcase.Title, ref)
+			}
+			if j == i {
+				return nil, fmt.Errorf("Testcase [%s] depends on itself", testcase.Title)
+			}
+			if barrier := testcase.GetBarrier(); len(barrier) > 0 && cases[j].GetBarrier() == barrier {
+				return nil, fmt.Errorf("Testcase [%s] depends on [%s] of the same barrier [%s]", testcase.Title, cases[j].Title, barrier)
+			}
+			testcase.prerequisites = append(testcase.prerequisites, cases[j])
+			dependents[j] = append(dependents[j], i)
+			blockers[i]++
+		}
+	}
+	order := make([]int, 0, len(cases))
+	done := make([]bool, len(cases))
+	previous := -1
+	for len(order) < len(cases) {
+		ready := make([]int, 0)
+		for i := range cases {
+			if !done[i] && blockers[i] == 0 {
+				ready = append(ready, i)
+			}
+		}
+		if len(ready) == 0 {
+			return nil, fmt.Errorf("Cyclic dependency: %s", describeCycle(cases, done))
+		}
+		next := pick(ready, previous)
+		previous = next
+		done[next] = true
+		order = append(order, next)
+		for _, k := range dependents[next] {
+			blockers[k]--
+		}
+	}
+	return order, nil
+}
+
+func findTestCase(cases []*TestCase, ref string) int {
+	for i, testcase := range cases {
+		if testcase.Title == ref {
+			return i
+		}
+	}
+	for i, testcase := range cases {
+		if testcase.Capture != nil && testcase.Capture.StoreID == ref {
+			return i
+		}
+	}
+	return -1
+}
+
+// describeCycle follows the prerequisites of the unscheduled test cases until
+// one of them is visited twice.
+func describeCycle(cases []*TestCase, done []bool) string {
+	var current *TestCase
+	for i, testcase := range cases {
+		if !done[i] {
+			current = testcase
+			break
+		}
+	}
+	path := make([]*TestCase, 0)
+	for current != nil {
+		for k, visited := range path {
+			if visited == current {
+				titles := make([]string, 0)
+				for _, testcase := range append(path[k:], current) {
+					titles = append(titles, "[" + testcase.Title + "]")
+				}
+				return strings.Join(titles, " -> ")
+			}
+		}
+		path = append(path, current)
+		var next *TestCase
+		for _, prerequisite := range current.prerequisites {
+			for i, testcase := range cases {
+				if testcase == prerequisite && !done[i] {
+					next = prerequisite
+				}
+			}
+			if next != nil {
+				break
+			}
+		}
+		current = next
+	}
+	return ""
+}
@@ -0,0 +1,87 @@
+package engine
+
+import(
+	"fmt"
+	"math/rand"
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/opwire/opwire-testa/lib/utils"
+)
+
+func TestTestSuite_Schedule(t *testing.T) {
+	login := &TestCase{ Title: "Login", Capture: &SectionCapture{ StoreID: "token" } }
+	profile := &TestCase{ Title: "Profile", DependsOn: []string{ "token" } }
+	update := &TestCase{ Title: "Update", DependsOn: []string{ "Profile", "Login" } }
+	other := &TestCase{ Title: "Other" }
+	suite := &TestSuite{ TestCases: []*TestCase{ update, other, profile, login } }
+	order, err := suite.Schedule()
+	assert.Nil(t, err)
+	assert.Equal(t, []int{ 1, 3, 2, 0 }, order)
+	assert.Equal(t, []*TestCase{ profile, login }, update.GetPrerequisites())
+
+	suite = &TestSuite{ TestCases: []*TestCase{ other, login } }
+	order, err = suite.Schedule()
+	assert.Nil(t, err)
+	assert.Equal(t, []int{ 0, 1 }, order)
+
+	TESTCASES := []struct {
+		cases []*TestCase
+		message string
+	}{
+		{
+			cases: []*TestCase{ { Title: "A", DependsOn: []string{ "B" } }, { Title: "B", DependsOn: []string{ "C" } }, { Title: "C", DependsOn: []string{ "B" } } },
+			message: "Cyclic dependency: [B] -> [C] -> [B]",
+		},
+		{
+			cases: []*TestCase{ { Title: "A", DependsOn: []string{ "X" } } },
+			message: "Testcase [A] depends on an unknown testcase [X]",
+		},
+		{
+			cases: []*TestCase{ { Title: "A", DependsOn: []string{ "A" } } },
+			message: "Testcase [A] depends on itself",
+		},
+		{
+			cases: []*TestCase{ { Title: "A", Barrier: utils.RefOfString("b") }, { Title: "B", Barrier: utils.RefOfString("b"), DependsOn: []string{ "A" } } },
+			message: "Testcase [B] depends on [A] of the same barrier [b]",
+		},
+	}
+	for i, c := range TESTCASES {
+		_, err := (&TestSuite{ TestCases: c.cases }).Schedule()
+		if assert.NotNil(t, err, "testcase #%d", i) {
+			assert.Equal(t, c.message, err.Error(), "testcase #%d", i)
+		}
+	}
+}
+
+func TestTestSuite_Shuffle(t *testing.T) {
+	barrier := "parallel"
+	login := &TestCase{ Title: "Login" }
+	profile := &TestCase{ Title: "Profile", DependsOn: []string{ "Login" } }
+	cases := []*TestCase{ profile, login, { Title: "A" }, { Title: "B" }, { Title: "C", Barrier: &barrier }, { Title: "D", Barrier: &barrier } }
+	suite := &TestSuite{ TestCases: cases }
+
+	orders := make(map[string]bool, 0)
+	for seed := int64(1); seed <= 20; seed++ {
+		order, err := suite.Shuffle(rand.New(rand.NewSource(seed)))
+		assert.Nil(t, err)
+		assert.Equal(t, len(cases), len(order))
+		position := make(map[int]int, 0)
+		for i, k := range order {
+			position[k] = i
+		}
+		assert.True(t, position[1] < position[0], "Login must precede Profile")
+		assert.Equal(t, 1, abs(position[4] - position[5]), "the barrier must be kept together")
+		orders[fmt.Sprint(order)] = true
+
+		again, _ := suite.Shuffle(rand.New(rand.NewSource(seed)))
+		assert.Equal(t, order, again)
+	}
+	assert.True(t, len(orders) > 1)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
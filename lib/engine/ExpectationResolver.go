@@ -0,0 +1,77 @@
+package engine
+
+import(
+	"reflect"
+	"strings"
+	"github.com/opwire/opwire-testa/lib/sieve"
+)
+
+// resolveExpectation returns a copy of the expectation in which the ${{...}}
+// expressions of the string values (e.g. ${{vars.order_id}}) are replaced by
+// the values captured by the previous test cases, the testcase itself is left
+// unchanged.
+func resolveExpectation(expect *Expectation, cache *sieve.RestCache) (*Expectation, []string) {
+	if expect == nil || cache == nil {
+		return expect, nil
+	}
+	var explanation []string
+	evaluate := func(text string) string {
+		if !strings.Contains(text, "${{") {
+			return text
+		}
+		output, errs := cache.EvaluateWithExplanation(text)
+		explanation = append(explanation, errs...)
+		return output
+	}
+	resolved := resolveValue(reflect.ValueOf(expect), evaluate)
+	return resolved.Interface().(*Expectation), explanation
+}
+
+func resolveValue(v reflect.Value, evaluate func(string) string) reflect.Value {
+	switch v.Kind() {
+	case reflect.String:
+		return reflect.ValueOf(evaluate(v.String())).Convert(v.Type())
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.New(v.Type().Elem())
+		copied.Elem().Set(resolveValue(v.Elem(), evaluate))
+		return copied
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(resolveValue(v.Elem(), evaluate))
+		return copied
+	case reflect.Struct:
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if field := copied.Field(i); field.CanSet() {
+				field.Set(resolveValue(v.Field(i), evaluate))
+			}
+		}
+		return copied
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			copied.Index(i).Set(resolveValue(v.Index(i), evaluate))
+		}
+		return copied
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for _, key := range v.MapKeys() {
+			copied.SetMapIndex(key, resolveValue(v.MapIndex(key), evaluate))
+		}
+		return copied
+	}
+	return v
+}
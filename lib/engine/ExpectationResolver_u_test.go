@@ -0,0 +1,50 @@
+package engine
+
+import(
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/opwire/opwire-testa/lib/sieve"
+)
+
+func TestResolveExpectation(t *testing.T) {
+	ref := func(s string) *string { return &s }
+	cache, err := sieve.NewRestCache()
+	assert.Nil(t, err)
+	cache.StoreVariable("order_id", "A-42")
+
+	expect := &Expectation{
+		Headers: &MeasureHeaders{
+			Items: []MeasureHeader{
+				{ Name: ref("X-Order"), Is: &ComparisonOperators{ EqualTo: "${{vars.order_id}}" } },
+				{ Name: ref("Link"), HasValues: []string{ "/orders/${{vars.order_id}}" } },
+			},
+		},
+		Body: &MeasureBody{
+			HasFormat: ref("json"),
+			Fields: []MeasureBodyField{
+				{ Path: ref("id"), IsEqualTo: "${{vars.order_id}}" },
+				{ Path: ref("ref"), IsOneOf: []interface{}{ "${{vars.order_id}}", 42 } },
+				{ Path: ref("total"), IsEqualTo: 12.5 },
+			},
+		},
+		Custom: &MeasureCustom{ Name: ref("echo"), Args: map[string]interface{}{ "id": "${{vars.order_id}}" } },
+	}
+	resolved, unresolved := resolveExpectation(expect, cache)
+	assert.Equal(t, 0, len(unresolved))
+	assert.Equal(t, "A-42", resolved.Headers.Items[0].Is.EqualTo)
+	assert.Equal(t, []string{ "/orders/A-42" }, resolved.Headers.Items[1].HasValues)
+	assert.Equal(t, "A-42", resolved.Body.Fields[0].IsEqualTo)
+	assert.Equal(t, []interface{}{ "A-42", 42 }, resolved.Body.Fields[1].IsOneOf)
+	assert.Equal(t, 12.5, resolved.Body.Fields[2].IsEqualTo)
+	assert.Equal(t, "A-42", resolved.Custom.Args["id"])
+
+	assert.Equal(t, "${{vars.order_id}}", expect.Headers.Items[0].Is.EqualTo)
+	assert.Equal(t, "${{vars.order_id}}", expect.Body.Fields[0].IsEqualTo)
+
+	_, unresolved = resolveExpectation(&Expectation{ Body: &MeasureBody{ Includes: ref("${{vars.missing}}") } }, cache)
+	assert.Equal(t, 1, len(unresolved))
+
+	resolved, unresolved = resolveExpectation(nil, cache)
+	assert.Nil(t, resolved)
+	assert.Equal(t, 0, len(unresolved))
+}
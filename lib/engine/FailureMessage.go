@@ -0,0 +1,91 @@
+package engine
+
+import(
+	"bytes"
+	"fmt"
+	"text/template"
+)
+
+type failureContext struct {
+	Name string
+	Expected interface{}
+	Actual interface{}
+	Failure string
+}
+
+// withMessage prefixes the failure of a matcher with its message, which is
+// a text/template of the failureContext, e.g.:
+//   message: "VAT must be {{.Expected}} for UK customers, received {{.Actual}}"
+func withMessage(message *string, failure error, ctx failureContext) error {
+	if message == nil || failure == nil {
+		return failure
+	}
+	ctx.Failure = failure.Error()
+	tmpl, err := template.New(ctx.Name).Option("missingkey=zero").Parse(*message)
+	if err != nil {
+		return fmt.Errorf("%s\n(invalid message template [%s], error: %s)", ctx.Failure, *message, err.Error())
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, ctx); err != nil {
+		return fmt.Errorf("%s\n(invalid message template [%s], error: %s)", ctx.Failure, *message, err.Error())
+	}
+	return fmt.Errorf("%s\n%s", buf.String(), ctx.Failure)
+}
+
+func (f MeasureBodyField) getExpected() interface{} {
+	switch {
+	case f.Is != nil && f.Is.EqualTo != nil:
+		return f.Is.EqualTo
+	case f.IsEqualTo != nil:
+		return f.IsEqualTo
+	case len(f.IsOneOf) > 0:
+		return f.IsOneOf
+	case f.IsCloseTo != nil:
+		return f.IsCloseTo.Value
+	case f.MatchWith != nil:
+		return *f.MatchWith
+	case f.TextIncludes != nil:
+		return *f.TextIncludes
+	case f.StartsWith != nil:
+		return *f.StartsWith
+	case f.EndsWith != nil:
+		return *f.EndsWith
+	case f.IsType != nil:
+		return *f.IsType
+	case f.HasLength != nil:
+		return *f.HasLength
+	}
+	return nil
+}
+
+func (h MeasureHeader) getExpected() interface{} {
+	switch {
+	case h.Is != nil && h.Is.EqualTo != nil:
+		return h.Is.EqualTo
+	case len(h.IsOneOf) > 0:
+		return h.IsOneOf
+	case len(h.HasValues) > 0:
+		return h.HasValues
+	case h.ContainsValue != nil:
+		return *h.ContainsValue
+	case h.Matches != nil:
+		return *h.Matches
+	case h.StartsWith != nil:
+		return *h.StartsWith
+	case h.EndsWith != nil:
+		return *h.EndsWith
+	}
+	return nil
+}
+
+func (sc MeasureStatusCode) getExpected() interface{} {
+	switch {
+	case sc.Is != nil && sc.Is.EqualTo != nil:
+		return sc.Is.EqualTo
+	case len(sc.IsOneOf) > 0:
+		return sc.IsOneOf
+	case len(sc.BelongsTo) > 0:
+		return sc.BelongsTo
+	}
+	return nil
+}
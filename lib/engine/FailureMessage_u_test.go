@@ -0,0 +1,47 @@
+package engine
+
+import(
+	"fmt"
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithMessage(t *testing.T) {
+	message := "VAT must be {{.Expected}} for UK customers, received {{.Actual}}"
+	invalid := "VAT {{.Expected"
+	failure := fmt.Errorf("Field mismatch expected: 20 / received: 19")
+	TESTCASES := []struct{
+		message *string
+		failure error
+		expected string
+	}{
+		{
+			message: nil,
+			failure: failure,
+			expected: "Field mismatch expected: 20 / received: 19",
+		},
+		{
+			message: &message,
+			failure: failure,
+			expected: "VAT must be 20 for UK customers, received 19\nField mismatch expected: 20 / received: 19",
+		},
+		{
+			message: &invalid,
+			failure: failure,
+			expected: "Field mismatch expected: 20 / received: 19\n(invalid message template",
+		},
+	}
+	for _, c := range TESTCASES {
+		err := withMessage(c.message, c.failure, failureContext{ Name: "vat", Expected: 20, Actual: 19 })
+		assert.Contains(t, err.Error(), c.expected)
+	}
+	assert.Nil(t, withMessage(&message, nil, failureContext{}))
+}
+
+func TestExamineBodyField_Message(t *testing.T) {
+	path := "vat"
+	message := "VAT of {{.Name}} must be {{.Expected}}%"
+	field := MeasureBodyField{ Path: &path, IsEqualTo: 20, Message: &message }
+	assert.Nil(t, examineBodyField(field, 20, true, false, 0))
+	assert.Equal(t, "VAT of vat must be 20%\nField mismatch expected: 20 / received: 19", examineBodyField(field, 19, true, false, 0).Error())
+}
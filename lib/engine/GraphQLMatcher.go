@@ -0,0 +1,93 @@
+package engine
+
+import(
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"github.com/opwire/opwire-testa/lib/utils"
+)
+
+type MeasureGraphQL struct {
+	NoErrors *bool `yaml:"no-errors,omitempty" json:"no-errors"`
+	ErrorIncludes *string `yaml:"error-includes,omitempty" json:"error-includes"`
+	DataPath *string `yaml:"data-path,omitempty" json:"data-path"`
+	IsEqualTo interface{} `yaml:"is-equal-to,omitempty" json:"is-equal-to"`
+	Exists *bool `yaml:"exists,omitempty" json:"exists"`
+	Fields []MeasureBodyField `yaml:"fields,omitempty" json:"fields"`
+}
+
+// examineGraphQL verifies a GraphQL response, the paths of the fields are
+// relative to its data object and the errors are reported by their messages.
+func examineGraphQL(m *MeasureGraphQL, body []byte, soft bool, skew time.Duration) map[string]error {
+	errs := make(map[string]error, 0)
+	var envelope map[string]interface{}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		errs["GraphQL"] = fmt.Errorf("Response body is not a JSON document, error: %s", err.Error())
+		return errs
+	}
+	_, hasData := envelope["data"]
+	_, hasErrors := envelope["errors"]
+	if !hasData && !hasErrors {
+		errs["GraphQL"] = fmt.Errorf("Response body has neither [data] nor [errors]")
+		return errs
+	}
+	messages := getGraphQLErrors(envelope["errors"])
+	if m.NoErrors != nil {
+		if *m.NoErrors && len(messages) > 0 {
+			errs["GraphQL/NoErrors"] = fmt.Errorf("Response has errors: %s", strings.Join(messages, "; "))
+		}
+		if !*m.NoErrors && len(messages) == 0 {
+			errs["GraphQL/NoErrors"] = fmt.Errorf("Response has no errors")
+		}
+	}
+	if m.ErrorIncludes != nil && !strings.Contains(strings.Join(messages, "\n"), *m.ErrorIncludes) {
+		errs["GraphQL/ErrorIncludes"] = fmt.Errorf("Error messages %v do not include: [%s]", messages, *m.ErrorIncludes)
+	}
+	data, _ := envelope["data"].(map[string]interface{})
+	if data == nil {
+		data = make(map[string]interface{})
+	}
+	fields := m.Fields
+	if m.DataPath != nil {
+		fields = append([]MeasureBodyField{ { Path: m.DataPath, IsEqualTo: m.IsEqualTo, Exists: m.Exists } }, fields...)
+	}
+	flattened, _ := utils.Flatten("", data)
+	for _, field := range fields {
+		if field.Path == nil {
+			continue
+		}
+		key := "GraphQL/Fields/" + *field.Path
+		var value interface{}
+		var found bool
+		if strings.HasPrefix(*field.Path, "$") {
+			var err error
+			value, found, err = utils.EvaluateJsonPath(*field.Path, data)
+			if err != nil {
+				errs[key] = err
+				continue
+			}
+		} else {
+			value, found = flattened[*field.Path]
+		}
+		if err := examineBodyField(field, value, found, soft, skew); err != nil {
+			errs[key] = err
+		}
+	}
+	return errs
+}
+
+func getGraphQLErrors(value interface{}) []string {
+	items, _ := value.([]interface{})
+	messages := make([]string, 0, len(items))
+	for _, item := range items {
+		if obj, ok := item.(map[string]interface{}); ok {
+			if message, ok := obj["message"].(string); ok {
+				messages = append(messages, message)
+				continue
+			}
+		}
+		messages = append(messages, fmt.Sprintf("%v", item))
+	}
+	return messages
+}
@@ -0,0 +1,36 @@
+package engine
+
+import(
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExamineGraphQL(t *testing.T) {
+	ref := func(s string) *string { return &s }
+	yes, no := true, false
+	data := `{"data":{"viewer":{"login":"octocat","repositories":{"totalCount":8}}}}`
+	failed := `{"data":null,"errors":[{"message":"Field 'logn' doesn't exist on type 'User'"}]}`
+	TESTCASES := []struct {
+		graphql MeasureGraphQL
+		body string
+		errors []string
+	}{
+		{ graphql: MeasureGraphQL{ NoErrors: &yes, DataPath: ref("viewer.login"), IsEqualTo: "octocat" }, body: data, errors: []string{} },
+		{ graphql: MeasureGraphQL{ DataPath: ref("viewer.login"), IsEqualTo: "hubot" }, body: data, errors: []string{ "GraphQL/Fields/viewer.login" } },
+		{ graphql: MeasureGraphQL{ DataPath: ref("$.viewer.repositories.totalCount"), IsEqualTo: 8 }, body: data, errors: []string{} },
+		{ graphql: MeasureGraphQL{ DataPath: ref("viewer.email"), Exists: &no }, body: data, errors: []string{} },
+		{ graphql: MeasureGraphQL{ NoErrors: &yes, DataPath: ref("viewer.login"), Exists: &yes }, body: failed, errors: []string{ "GraphQL/NoErrors", "GraphQL/Fields/viewer.login" } },
+		{ graphql: MeasureGraphQL{ NoErrors: &no, ErrorIncludes: ref("doesn't exist") }, body: failed, errors: []string{} },
+		{ graphql: MeasureGraphQL{ NoErrors: &no, ErrorIncludes: ref("timeout") }, body: data, errors: []string{ "GraphQL/NoErrors", "GraphQL/ErrorIncludes" } },
+		{ graphql: MeasureGraphQL{ NoErrors: &yes }, body: `{"items":[]}`, errors: []string{ "GraphQL" } },
+		{ graphql: MeasureGraphQL{ NoErrors: &yes }, body: `<html></html>`, errors: []string{ "GraphQL" } },
+	}
+	for _, tc := range TESTCASES {
+		errs := examineGraphQL(&tc.graphql, []byte(tc.body), false, 0)
+		keys := make([]string, 0)
+		for key := range errs {
+			keys = append(keys, key)
+		}
+		assert.ElementsMatch(t, tc.errors, keys, tc.body)
+	}
+}
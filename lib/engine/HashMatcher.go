@@ -0,0 +1,41 @@
+package engine
+
+import(
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"strings"
+)
+
+func hasBodyHashes(eb *MeasureBody) bool {
+	return eb.HasSha256 != nil || eb.HasMd5 != nil || eb.HasCrc32 != nil
+}
+
+// examineBodyHashes compares the digests (hex encoded) of the raw body with
+// the expected ones, crc32 uses the IEEE polynomial.
+func examineBodyHashes(eb *MeasureBody, body []byte) map[string]error {
+	errs := make(map[string]error, 0)
+	checks := []struct {
+		key string
+		expected *string
+		digest hash.Hash
+	}{
+		{ key: "Body/HasSha256", expected: eb.HasSha256, digest: sha256.New() },
+		{ key: "Body/HasMd5", expected: eb.HasMd5, digest: md5.New() },
+		{ key: "Body/HasCrc32", expected: eb.HasCrc32, digest: crc32.NewIEEE() },
+	}
+	for _, check := range checks {
+		if check.expected == nil {
+			continue
+		}
+		check.digest.Write(body)
+		received := hex.EncodeToString(check.digest.Sum(nil))
+		if expected := strings.ToLower(strings.TrimSpace(*check.expected)); received != expected {
+			errs[check.key] = fmt.Errorf("Body digest [%s] is mismatched with expected: [%s]", received, expected)
+		}
+	}
+	return errs
+}
@@ -0,0 +1,29 @@
+package engine
+
+import(
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExamineBodyHashes(t *testing.T) {
+	ref := func(s string) *string { return &s }
+	TESTCASES := []struct {
+		body MeasureBody
+		errors []string
+	}{
+		{ body: MeasureBody{ HasSha256: ref("2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824") }, errors: []string{} },
+		{ body: MeasureBody{ HasSha256: ref("2CF24DBA5FB0A30E26E83B2AC5B9E29E1B161E5C1FA7425E73043362938B9824") }, errors: []string{} },
+		{ body: MeasureBody{ HasMd5: ref("5d41402abc4b2a76b9719d911017c592") }, errors: []string{} },
+		{ body: MeasureBody{ HasCrc32: ref("3610a686") }, errors: []string{} },
+		{ body: MeasureBody{ HasMd5: ref("00000000000000000000000000000000") }, errors: []string{ "Body/HasMd5" } },
+		{ body: MeasureBody{ HasCrc32: ref("3610a687") }, errors: []string{ "Body/HasCrc32" } },
+	}
+	for i, c := range TESTCASES {
+		errs := examineBodyHashes(&c.body, []byte("hello"))
+		keys := make([]string, 0)
+		for key := range errs {
+			keys = append(keys, key)
+		}
+		assert.Equal(t, c.errors, keys, "testcase #%d", i)
+	}
+}
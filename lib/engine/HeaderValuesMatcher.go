@@ -0,0 +1,64 @@
+package engine
+
+import(
+	"fmt"
+)
+
+func hasHeaderValuesMatchers(item MeasureHeader) bool {
+	return item.HasValues != nil || item.ContainsValue != nil
+}
+
+// examineHeaderValues checks all the values of a repeated header (Vary,
+// Set-Cookie), each one being compared as a single header value.
+func examineHeaderValues(item MeasureHeader, values []string) error {
+	if item.HasValues != nil {
+		if item.Ordered != nil && *item.Ordered {
+			if !hasOrderedHeaderValues(values, item) {
+				return fmt.Errorf("Returned values: %q are mismatched with expected: %q", values, item.HasValues)
+			}
+		} else if !hasHeaderValues(values, item) {
+			return fmt.Errorf("Returned values: %q are mismatched with expected (in any order): %q", values, item.HasValues)
+		}
+	}
+	if item.ContainsValue != nil {
+		for _, value := range values {
+			if compareHeader(value, *item.ContainsValue, item) {
+				return nil
+			}
+		}
+		return fmt.Errorf("Returned values: %q do not contain: [%s]", values, *item.ContainsValue)
+	}
+	return nil
+}
+
+func hasOrderedHeaderValues(values []string, item MeasureHeader) bool {
+	if len(values) != len(item.HasValues) {
+		return false
+	}
+	for i := range values {
+		if !compareHeader(values[i], item.HasValues[i], item) {
+			return false
+		}
+	}
+	return true
+}
+
+func hasHeaderValues(values []string, item MeasureHeader) bool {
+	if len(values) != len(item.HasValues) {
+		return false
+	}
+	used := make([]bool, len(values))
+	for _, expected := range item.HasValues {
+		matched := false
+		for i, value := range values {
+			if !used[i] && compareHeader(value, expected, item) {
+				used[i], matched = true, true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,31 @@
+package engine
+
+import(
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExamineHeaderValues(t *testing.T) {
+	ref := func(s string) *string { return &s }
+	yes := true
+	vary := []string{"Accept-Encoding", "Origin"}
+	TESTCASES := []struct {
+		item MeasureHeader
+		values []string
+		failed bool
+	}{
+		{ item: MeasureHeader{ HasValues: []string{"Origin", "Accept-Encoding"} }, values: vary, failed: false },
+		{ item: MeasureHeader{ HasValues: []string{"Origin", "Accept-Encoding"}, Ordered: &yes }, values: vary, failed: true },
+		{ item: MeasureHeader{ HasValues: []string{"Accept-Encoding", "Origin"}, Ordered: &yes }, values: vary, failed: false },
+		{ item: MeasureHeader{ HasValues: []string{"Origin"} }, values: vary, failed: true },
+		{ item: MeasureHeader{ HasValues: []string{"Origin", "Origin"} }, values: vary, failed: true },
+		{ item: MeasureHeader{ HasValues: []string{"origin", "accept-encoding"}, IgnoreCase: &yes }, values: vary, failed: false },
+		{ item: MeasureHeader{ ContainsValue: ref("Origin") }, values: vary, failed: false },
+		{ item: MeasureHeader{ ContainsValue: ref("Cookie") }, values: vary, failed: true },
+		{ item: MeasureHeader{ ContainsValue: ref("sid=1") }, values: nil, failed: true },
+	}
+	for i, tc := range TESTCASES {
+		err := examineHeaderValues(tc.item, tc.values)
+		assert.Equal(t, tc.failed, err != nil, "testcase #%d", i)
+	}
+}
@@ -0,0 +1,62 @@
+package engine
+
+import(
+	"fmt"
+	"github.com/opwire/opwire-testa/lib/sieve"
+)
+
+// ExamineInSuite examines a test case between the before-each and after-each
+// hooks of its test suite. The test case is not sent when a before-each hook
+// fails, the after-each hooks are always examined and their failures are
+// reported as the failures of the test case.
+func (e *SpecHandler) ExamineInSuite(testsuite *TestSuite, testcase *TestCase) (*ExaminationResult, error) {
+	cache := testsuite.GetResultCache()
+	session := testsuite.GetSession()
+	if len(testsuite.BeforeEach) == 0 && len(testsuite.AfterEach) == 0 || testcase.Pending != nil && *testcase.Pending {
+		return e.Examine(testcase, cache, session)
+	}
+	var result *ExaminationResult
+	var err error
+	hookErrors, warnings, hookErr := e.examineHooks("BeforeEach", testsuite.BeforeEach, cache, session)
+	if len(hookErrors) > 0 || hookErr != nil {
+		result = &ExaminationResult{ Status: "error", Errors: hookErrors, Warnings: warnings }
+		err = hookErr
+	} else {
+		result, err = e.Examine(testcase, cache, session)
+		result.Warnings = append(warnings, result.Warnings...)
+	}
+	hookErrors, warnings, _ = e.examineHooks("AfterEach", testsuite.AfterEach, cache, session)
+	if len(hookErrors) > 0 {
+		if result.Errors == nil {
+			result.Errors = make(map[string]error, 0)
+		}
+		for key, hookErr := range hookErrors {
+			result.Errors[key] = hookErr
+		}
+		result.Status = "error"
+	}
+	result.Warnings = append(result.Warnings, warnings...)
+	return result, err
+}
+
+// examineHooks examines the hooks in order and stops at the first failure,
+// the keys of the errors are prefixed with the section and the hook title.
+func (e *SpecHandler) examineHooks(section string, hooks []*TestCase, cache *sieve.RestCache, session *Session) (map[string]error, []Warning, error) {
+	errors := make(map[string]error, 0)
+	warnings := make([]Warning, 0)
+	for _, hook := range hooks {
+		result, err := e.Examine(hook, cache, session)
+		prefix := fmt.Sprintf("%s[%s]/", section, hook.Title)
+		for key, hookErr := range result.Errors {
+			errors[prefix + key] = hookErr
+		}
+		for _, warning := range result.Warnings {
+			warning.Message = fmt.Sprintf("%s[%s]: %s", section, hook.Title, warning.Message)
+			warnings = append(warnings, warning)
+		}
+		if err != nil || len(result.Errors) > 0 {
+			return errors, warnings, err
+		}
+	}
+	return errors, warnings, nil
+}
@@ -0,0 +1,59 @@
+package engine
+
+import(
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/opwire/opwire-testa/lib/client"
+)
+
+func TestSpecHandler_ExamineInSuite(t *testing.T) {
+	paths := make([]string, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		code, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/"))
+		w.WriteHeader(code)
+	}))
+	defer server.Close()
+
+	e, err := NewSpecHandler(nil)
+	assert.Nil(t, err)
+
+	hook := func(title string, code string) *TestCase {
+		return &TestCase{
+			Title: title,
+			Request: &client.HttpRequest{ Method: http.MethodGet, Url: server.URL + "/" + code },
+			Expectation: &Expectation{ StatusCode: &MeasureStatusCode{ IsOneOf: []int{ 200, 201 } } },
+		}
+	}
+	testcase := hook("Read", "200")
+
+	t.Run("Hooks are examined around the testcase", func(t *testing.T) {
+		paths = paths[:0]
+		suite := &TestSuite{ BeforeEach: []*TestCase{ hook("Create", "201") }, AfterEach: []*TestCase{ hook("Delete", "200") } }
+		result, err := e.ExamineInSuite(suite, testcase)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(result.Errors))
+		assert.Equal(t, []string{ "/201", "/200", "/200" }, paths)
+	})
+
+	t.Run("Testcase is not sent when a before-each hook fails", func(t *testing.T) {
+		paths = paths[:0]
+		suite := &TestSuite{ BeforeEach: []*TestCase{ hook("Create", "500"), hook("Other", "200") }, AfterEach: []*TestCase{ hook("Delete", "200") } }
+		result, err := e.ExamineInSuite(suite, testcase)
+		assert.Nil(t, err)
+		assert.Contains(t, result.Errors, "BeforeEach[Create]/StatusCode")
+		assert.Equal(t, []string{ "/500", "/200" }, paths)
+	})
+
+	t.Run("Failures of after-each hooks fail the testcase", func(t *testing.T) {
+		suite := &TestSuite{ AfterEach: []*TestCase{ hook("Delete", "404") } }
+		result, err := e.ExamineInSuite(suite, testcase)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(result.Errors))
+		assert.Contains(t, result.Errors, "AfterEach[Delete]/StatusCode")
+	})
+}
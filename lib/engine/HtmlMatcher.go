@@ -0,0 +1,76 @@
+package engine
+
+import(
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"github.com/PuerkitoBio/goquery"
+	"github.com/andybalholm/cascadia"
+	"github.com/opwire/opwire-testa/lib/utils"
+)
+
+// examineHtml verifies an HTML response body, the fields are selected with
+// CSS selectors and their (trimmed) text is checked with the same matchers
+// as the JSON fields.
+func examineHtml(eb *MeasureBody, body []byte, soft bool, skew time.Duration) map[string]error {
+	format := utils.BODY_FORMAT_HTML
+	errs := make(map[string]error, 0)
+	if len(body) == 0 {
+		errs["Body/ReceivedObject"] = fmt.Errorf("[%s] Response body is empty", format)
+		return errs
+	}
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		errs["Body/ReceivedObject"] = fmt.Errorf("[%s] Invalid response content: %s", format, err)
+		return errs
+	}
+	if eb.IsEqualTo != nil {
+		errs["Body/IsEqualTo"] = fmt.Errorf("[%s] The [is-equal-to] attribute is unsupported, please use [fields] instead", format)
+	}
+	if eb.Includes != nil {
+		errs["Body/Includes"] = fmt.Errorf("[%s] The [includes] attribute is unsupported, please use [fields] instead", format)
+	}
+	if eb.MatchWith != nil {
+		if reg, err := regexp.Compile(*eb.MatchWith); err == nil {
+			if !reg.Match(body) {
+				errs["Body/MatchWith"] = fmt.Errorf("[%s] Response body is mismatched with the pattern.\nReceived: %s\nPattern: %s", format, string(body), *eb.MatchWith)
+			}
+		} else {
+			errs["Body/Expectation"] = fmt.Errorf("[%s] Invalid regular expression[%s], error: %s", format, *eb.MatchWith, err.Error())
+		}
+	}
+	for _, eField := range eb.Fields {
+		selector := eField.Select
+		if selector == nil {
+			selector = eField.Path
+		}
+		if selector == nil {
+			continue
+		}
+		fieldKey := "Body/Fields/" + *selector
+		rValue, found, err := evaluateSelector(*selector, doc)
+		if err != nil {
+			errs[fieldKey] = err
+			continue
+		}
+		if err := examineBodyField(eField, rValue, found, soft, skew); err != nil {
+			errs[fieldKey] = err
+		}
+	}
+	return errs
+}
+
+// evaluateSelector returns the text of the first selected element.
+func evaluateSelector(selector string, doc *goquery.Document) (interface{}, bool, error) {
+	matcher, err := cascadia.Compile(selector)
+	if err != nil {
+		return nil, false, fmt.Errorf("Invalid CSS selector[%s], error: %s", selector, err.Error())
+	}
+	selection := doc.FindMatcher(matcher)
+	if selection.Length() == 0 {
+		return nil, false, nil
+	}
+	return strings.TrimSpace(selection.First().Text()), true, nil
+}
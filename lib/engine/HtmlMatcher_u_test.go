@@ -0,0 +1,62 @@
+package engine
+
+import(
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/opwire/opwire-testa/lib/utils"
+)
+
+func TestExamineHtml(t *testing.T) {
+	truthy, falsy := true, false
+	body := []byte(`<!DOCTYPE html>
+<html>
+	<head><title> Dashboard - Testa </title></head>
+	<body>
+		<h1 class="greeting">Welcome back, octocat</h1>
+		<ul id="projects"><li>testa</li><li>agent</li></ul>
+	</body>
+</html>`)
+	TESTCASES := []struct {
+		body MeasureBody
+		failed []string
+	}{
+		{
+			body: MeasureBody{
+				Fields: []MeasureBodyField{
+					{ Select: utils.RefOfString("title"), TextIncludes: utils.RefOfString("Dashboard") },
+					{ Select: utils.RefOfString("h1.greeting"), MatchWith: utils.RefOfString(`octocat$`) },
+					{ Select: utils.RefOfString("#projects li:nth-child(2)"), IsEqualTo: "agent" },
+					{ Path: utils.RefOfString("title"), IsEqualTo: "Dashboard - Testa" },
+					{ Select: utils.RefOfString(".error"), Exists: &falsy },
+				},
+				MatchWith: utils.RefOfString(`<ul id="projects">`),
+			},
+		},
+		{
+			body: MeasureBody{
+				Fields: []MeasureBodyField{
+					{ Select: utils.RefOfString("title"), TextIncludes: utils.RefOfString("Login") },
+					{ Select: utils.RefOfString(".error"), Exists: &truthy },
+					{ Select: utils.RefOfString("li["), Exists: &truthy },
+				},
+			},
+			failed: []string{ "Body/Fields/title", "Body/Fields/.error", "Body/Fields/li[" },
+		},
+		{
+			body: MeasureBody{
+				IsEqualTo: utils.RefOfString(`<html></html>`),
+			},
+			failed: []string{ "Body/IsEqualTo" },
+		},
+	}
+	for i, c := range TESTCASES {
+		errs := examineHtml(&c.body, body, false, 0)
+		keys := make([]string, 0)
+		for key := range errs {
+			keys = append(keys, key)
+		}
+		assert.ElementsMatch(t, c.failed, keys, "testcase #%d", i)
+	}
+	errs := examineHtml(&MeasureBody{}, []byte{}, false, 0)
+	assert.Contains(t, errs, "Body/ReceivedObject")
+}
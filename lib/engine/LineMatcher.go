@@ -0,0 +1,60 @@
+package engine
+
+import(
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+func hasLineMatchers(eb *MeasureBody) bool {
+	return eb.HasLineCount != nil || eb.LineMatches != nil || eb.EveryLineMatches != nil
+}
+
+// examineLines verifies the lines of the body (plain text, NDJSON), the line
+// breaks may be "\n" or "\r\n" and the break terminating the last line does
+// not start another one.
+func examineLines(eb *MeasureBody, body []byte, soft bool) map[string]error {
+	errs := make(map[string]error, 0)
+	lines := splitBodyLines(string(body))
+	if eb.HasLineCount != nil {
+		if err := examineNumber(len(lines), eb.HasLineCount.toOperators()); err != nil {
+			errs["Body/HasLineCount"] = fmt.Errorf("Number of lines: %s", err.Error())
+		}
+	}
+	if _lm := eb.LineMatches; _lm != nil {
+		if reg, err := regexp.Compile(_lm.Pattern); err != nil {
+			errs["Body/LineMatches"] = fmt.Errorf("Invalid regular expression[%s], error: %s", _lm.Pattern, err.Error())
+		} else if _lm.Number < 1 || _lm.Number > len(lines) {
+			errs["Body/LineMatches"] = fmt.Errorf("Line #%d not found, the body has %d lines", _lm.Number, len(lines))
+		} else if line := lines[_lm.Number - 1]; !reg.MatchString(line) {
+			errs["Body/LineMatches"] = fmt.Errorf("Line #%d: [%s] is mismatched with pattern: [%s]", _lm.Number, line, _lm.Pattern)
+		}
+	}
+	if eb.EveryLineMatches != nil {
+		if reg, err := regexp.Compile(*eb.EveryLineMatches); err != nil {
+			errs["Body/EveryLineMatches"] = fmt.Errorf("Invalid regular expression[%s], error: %s", *eb.EveryLineMatches, err.Error())
+		} else {
+			for i, line := range lines {
+				if reg.MatchString(line) {
+					continue
+				}
+				addFailure(errs, "Body/EveryLineMatches", fmt.Errorf("Line #%d: [%s] is mismatched with pattern: [%s]", i + 1, line, *eb.EveryLineMatches), soft)
+				if !soft {
+					break
+				}
+			}
+		}
+	}
+	return errs
+}
+
+func splitBodyLines(text string) []string {
+	if len(text) == 0 {
+		return []string{}
+	}
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return lines
+}
@@ -0,0 +1,48 @@
+package engine
+
+import(
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/opwire/opwire-testa/lib/utils"
+)
+
+func TestExamineLines(t *testing.T) {
+	three, two := int64(3), int64(2)
+	body := []byte("{\"id\":1}\r\n{\"id\":2}\r\n{\"id\":3}\r\n")
+	TESTCASES := []struct {
+		body MeasureBody
+		failed []string
+	}{
+		{
+			body: MeasureBody{
+				HasLineCount: &MeasureSize{ IsEqualTo: &three },
+				LineMatches: &MeasureLine{ Number: 2, Pattern: `^\{"id":2\}$` },
+				EveryLineMatches: utils.RefOfString(`^\{"id":\d+\}$`),
+			},
+		},
+		{
+			body: MeasureBody{
+				HasLineCount: &MeasureSize{ IsLTE: &two },
+				LineMatches: &MeasureLine{ Number: 4, Pattern: `.` },
+				EveryLineMatches: utils.RefOfString(`"id":[12]\}`),
+			},
+			failed: []string{ "Body/HasLineCount", "Body/LineMatches", "Body/EveryLineMatches" },
+		},
+		{
+			body: MeasureBody{
+				LineMatches: &MeasureLine{ Number: 1, Pattern: `(` },
+			},
+			failed: []string{ "Body/LineMatches" },
+		},
+	}
+	for i, c := range TESTCASES {
+		errs := examineLines(&c.body, body, false)
+		keys := make([]string, 0)
+		for key := range errs {
+			keys = append(keys, key)
+		}
+		assert.ElementsMatch(t, c.failed, keys, "testcase #%d", i)
+	}
+	assert.Equal(t, []string{}, splitBodyLines(""))
+	assert.Equal(t, []string{ "a", "", "b" }, splitBodyLines("a\n\nb"))
+}
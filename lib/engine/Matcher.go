@@ -0,0 +1,135 @@
+package engine
+
+import(
+	"fmt"
+	"plugin"
+	"sort"
+	"sync"
+	"github.com/opwire/opwire-testa/lib/client"
+)
+
+// Matcher is a custom check of the responses, referenced from the scripts
+// by its registered name (custom: {name: my-matcher, args: {...}}).
+type Matcher interface {
+	Match(res *client.HttpResponse, args map[string]interface{}) error
+}
+
+// MatcherFunc adapts a function to the Matcher interface.
+type MatcherFunc func(res *client.HttpResponse, args map[string]interface{}) error
+
+func (f MatcherFunc) Match(res *client.HttpResponse, args map[string]interface{}) error {
+	return f(res, args)
+}
+
+type MeasureCustom struct {
+	Name *string `yaml:"name" json:"name"`
+	Args map[string]interface{} `yaml:"args,omitempty" json:"args"`
+}
+
+var matcherRegistry = struct {
+	sync.RWMutex
+	matchers map[string]Matcher
+	plugins map[string]bool
+}{
+	matchers: make(map[string]Matcher),
+	plugins: make(map[string]bool),
+}
+
+// RegisterMatcher makes a custom matcher available to the scripts, the
+// plugins call it from their init() function.
+func RegisterMatcher(name string, matcher Matcher) error {
+	if len(name) == 0 || matcher == nil {
+		return fmt.Errorf("Matcher must have a name and an implementation")
+	}
+	matcherRegistry.Lock()
+	defer matcherRegistry.Unlock()
+	if _, ok := matcherRegistry.matchers[name]; ok {
+		return fmt.Errorf("Matcher [%s] has already been registered", name)
+	}
+	matcherRegistry.matchers[name] = matcher
+	return nil
+}
+
+func GetMatcher(name string) (Matcher, bool) {
+	matcherRegistry.RLock()
+	defer matcherRegistry.RUnlock()
+	matcher, ok := matcherRegistry.matchers[name]
+	return matcher, ok
+}
+
+func GetMatcherNames() []string {
+	matcherRegistry.RLock()
+	defer matcherRegistry.RUnlock()
+	names := make([]string, 0, len(matcherRegistry.matchers))
+	for name := range matcherRegistry.matchers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadMatcherPlugins opens the Go plugins (built with -buildmode=plugin)
+// which register their matchers when they are initialized.
+func LoadMatcherPlugins(paths []string) error {
+	for _, path := range paths {
+		matcherRegistry.RLock()
+		loaded := matcherRegistry.plugins[path]
+		matcherRegistry.RUnlock()
+		if loaded {
+			continue
+		}
+		if _, err := plugin.Open(path); err != nil {
+			return fmt.Errorf("Loading matcher plugin [%s] failed: %s", path, err.Error())
+		}
+		matcherRegistry.Lock()
+		matcherRegistry.plugins[path] = true
+		matcherRegistry.Unlock()
+	}
+	return nil
+}
+
+func examineCustom(m *MeasureCustom, res *client.HttpResponse) (err error) {
+	if m.Name == nil {
+		return fmt.Errorf("Custom matcher has no name")
+	}
+	matcher, ok := GetMatcher(*m.Name)
+	if !ok {
+		return fmt.Errorf("Matcher [%s] is not registered, available matchers: %v", *m.Name, GetMatcherNames())
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("Matcher [%s] panicked: %v", *m.Name, r)
+		}
+	}()
+	return matcher.Match(res, standardizeArgs(m.Args))
+}
+
+// standardizeArgs converts the nested maps decoded from YAML to the maps of
+// strings, as decoded from JSON.
+func standardizeArgs(args map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(args))
+	for key, value := range args {
+		result[key] = standardizeArg(value)
+	}
+	return result
+}
+
+func standardizeArg(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[fmt.Sprintf("%v", key)] = standardizeArg(item)
+		}
+		return result
+	case map[string]interface{}:
+		return standardizeArgs(v)
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = standardizeArg(item)
+		}
+		return result
+	}
+	return value
+}
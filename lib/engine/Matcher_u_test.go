@@ -0,0 +1,49 @@
+package engine
+
+import(
+	"fmt"
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/opwire/opwire-testa/lib/client"
+)
+
+func TestRegisterMatcher(t *testing.T) {
+	err := RegisterMatcher("test-has-header", MatcherFunc(func(res *client.HttpResponse, args map[string]interface{}) error {
+		name := fmt.Sprintf("%v", args["name"])
+		if len(res.Header.Get(name)) == 0 {
+			return fmt.Errorf("Header [%s] is missing", name)
+		}
+		return nil
+	}))
+	assert.Nil(t, err)
+	assert.NotNil(t, RegisterMatcher("test-has-header", MatcherFunc(nil)))
+	assert.NotNil(t, RegisterMatcher("", MatcherFunc(nil)))
+	assert.Contains(t, GetMatcherNames(), "test-has-header")
+
+	res := &client.HttpResponse{ Header: map[string][]string{ "X-Trace-Id": { "abc" } } }
+	name, unknown := "test-has-header", "test-unknown"
+	assert.Nil(t, examineCustom(&MeasureCustom{ Name: &name, Args: map[string]interface{}{ "name": "X-Trace-Id" } }, res))
+	assert.NotNil(t, examineCustom(&MeasureCustom{ Name: &name, Args: map[string]interface{}{ "name": "X-Request-Id" } }, res))
+	assert.NotNil(t, examineCustom(&MeasureCustom{ Name: &unknown }, res))
+
+	t.Run("Panicking matcher", func(t *testing.T) {
+		panicking := "test-panicking"
+		RegisterMatcher(panicking, MatcherFunc(func(res *client.HttpResponse, args map[string]interface{}) error {
+			panic("boom")
+		}))
+		assert.NotNil(t, examineCustom(&MeasureCustom{ Name: &panicking }, res))
+	})
+
+	t.Run("Missing plugin", func(t *testing.T) {
+		assert.NotNil(t, LoadMatcherPlugins([]string{ "/nonexistent/matcher.so" }))
+	})
+}
+
+func TestStandardizeArgs(t *testing.T) {
+	args := standardizeArgs(map[string]interface{}{
+		"limits": map[interface{}]interface{}{ "max": 10, 1: []interface{}{ map[interface{}]interface{}{ "a": true } } },
+	})
+	assert.Equal(t, map[string]interface{}{
+		"limits": map[string]interface{}{ "max": 10, "1": []interface{}{ map[string]interface{}{ "a": true } } },
+	}, args)
+}
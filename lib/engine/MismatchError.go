@@ -0,0 +1,16 @@
+package engine
+
+// MismatchError carries the diff (-expected +received) of a failed body
+// matcher, so that the reporter is able to highlight it.
+type MismatchError struct {
+	Summary string
+	Diff string
+}
+
+func (e *MismatchError) Error() string {
+	return e.Summary + ":\n" + e.Diff
+}
+
+func newBodyMismatch(format string, diff string) *MismatchError {
+	return &MismatchError{ Summary: "[" + format + "] Body mismatch (-expected +received)", Diff: diff }
+}
@@ -0,0 +1,51 @@
+package engine
+
+import(
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// examineNegatedHeader checks that a header value does not contain the
+// forbidden content, an absent header satisfies all the negated matchers.
+func examineNegatedHeader(item MeasureHeader, value string) error {
+	unexpected := item.IsNotEqualTo
+	if unexpected == nil && item.Is != nil && item.Is.NotEqualTo != nil {
+		text := fmt.Sprintf("%v", item.Is.NotEqualTo)
+		unexpected = &text
+	}
+	if unexpected != nil && len(value) > 0 && compareHeader(value, *unexpected, item) {
+		return fmt.Errorf("Returned value: [%s] must not be equal to: [%s]", value, *unexpected)
+	}
+	if item.NotIncludes != nil && len(*item.NotIncludes) > 0 && strings.Contains(value, *item.NotIncludes) {
+		return fmt.Errorf("Returned value: [%s] must not include: [%s]", value, *item.NotIncludes)
+	}
+	if item.NotMatches != nil {
+		reg, err := regexp.Compile(*item.NotMatches)
+		if err != nil {
+			return fmt.Errorf("Invalid regular expression[%s], error: %s", *item.NotMatches, err.Error())
+		}
+		if len(value) > 0 && reg.MatchString(value) {
+			return fmt.Errorf("Returned value: [%s] must not match pattern: [%s]", value, *item.NotMatches)
+		}
+	}
+	return nil
+}
+
+// examineNegatedBody checks that the raw body does not contain the forbidden
+// content (error markers, stack traces), whatever its format.
+func examineNegatedBody(eb *MeasureBody, body []byte) map[string]error {
+	errs := make(map[string]error, 0)
+	if eb.NotIncludes != nil && len(*eb.NotIncludes) > 0 && strings.Contains(string(body), *eb.NotIncludes) {
+		errs["Body/NotIncludes"] = fmt.Errorf("Response body must not include: [%s]", *eb.NotIncludes)
+	}
+	if eb.NotMatches != nil {
+		reg, err := regexp.Compile(*eb.NotMatches)
+		if err != nil {
+			errs["Body/NotMatches"] = fmt.Errorf("Invalid regular expression[%s], error: %s", *eb.NotMatches, err.Error())
+		} else if found := reg.Find(body); found != nil {
+			errs["Body/NotMatches"] = fmt.Errorf("Response body must not match pattern: [%s], found: [%s]", *eb.NotMatches, found)
+		}
+	}
+	return errs
+}
@@ -0,0 +1,53 @@
+package engine
+
+import(
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExamineNegatedHeader(t *testing.T) {
+	ref := func(s string) *string { return &s }
+	truthy := true
+	TESTCASES := []struct {
+		item MeasureHeader
+		value string
+		ok bool
+	}{
+		{ item: MeasureHeader{ IsNotEqualTo: ref("nginx") }, value: "opwire", ok: true },
+		{ item: MeasureHeader{ IsNotEqualTo: ref("nginx") }, value: "nginx", ok: false },
+		{ item: MeasureHeader{ IsNotEqualTo: ref("NGINX"), IgnoreCase: &truthy }, value: "nginx", ok: false },
+		{ item: MeasureHeader{ IsNotEqualTo: ref("nginx") }, value: "", ok: true },
+		{ item: MeasureHeader{ Is: &ComparisonOperators{ NotEqualTo: 0 } }, value: "0", ok: false },
+		{ item: MeasureHeader{ NotIncludes: ref("PHP") }, value: "PHP/7.2", ok: false },
+		{ item: MeasureHeader{ NotIncludes: ref("PHP") }, value: "Go", ok: true },
+		{ item: MeasureHeader{ NotMatches: ref(`\d+\.\d+`) }, value: "nginx/1.14", ok: false },
+		{ item: MeasureHeader{ NotMatches: ref(`\d+\.\d+`) }, value: "nginx", ok: true },
+		{ item: MeasureHeader{ NotMatches: ref(`(`) }, value: "nginx", ok: false },
+	}
+	for i, c := range TESTCASES {
+		err := examineNegatedHeader(c.item, c.value)
+		assert.Equal(t, c.ok, err == nil, "testcase #%d: %v", i, err)
+	}
+}
+
+func TestExamineNegatedBody(t *testing.T) {
+	ref := func(s string) *string { return &s }
+	body := []byte(`{"error":"internal","trace":"at main.go:42"}`)
+	TESTCASES := []struct {
+		body MeasureBody
+		errors []string
+	}{
+		{ body: MeasureBody{ NotIncludes: ref("Exception") }, errors: []string{} },
+		{ body: MeasureBody{ NotIncludes: ref("main.go") }, errors: []string{ "Body/NotIncludes" } },
+		{ body: MeasureBody{ NotMatches: ref(`\w+\.go:\d+`) }, errors: []string{ "Body/NotMatches" } },
+		{ body: MeasureBody{ NotMatches: ref(`\w+\.java:\d+`) }, errors: []string{} },
+	}
+	for i, c := range TESTCASES {
+		errs := examineNegatedBody(&c.body, body)
+		keys := make([]string, 0)
+		for key := range errs {
+			keys = append(keys, key)
+		}
+		assert.Equal(t, c.errors, keys, "testcase #%d", i)
+	}
+}
@@ -0,0 +1,66 @@
+package engine
+
+import(
+	"bytes"
+	"fmt"
+	"net/http"
+	"github.com/opwire/opwire-testa/lib/client"
+	"github.com/opwire/opwire-testa/lib/utils"
+)
+
+const MAX_RANGE_PARTS int64 = 1000
+
+// examineRanges downloads the resource again in chunks of byte ranges and
+// verifies that each part is a correct 206 response and that the parts put
+// together are equal to the full body.
+func (e *SpecHandler) examineRanges(chunkSize int64, req *client.HttpRequest, res *client.HttpResponse, interceptors []client.Interceptor) map[string]error {
+	errs := make(map[string]error, 0)
+	if res.BodyTruncated {
+		errs["Ranges"] = fmt.Errorf("The full body is truncated, the ranges could not be compared")
+		return errs
+	}
+	total := int64(len(res.Body))
+	if total == 0 {
+		errs["Ranges"] = fmt.Errorf("The full body is empty, there is no range to be requested")
+		return errs
+	}
+	if parts := (total + chunkSize - 1) / chunkSize; parts > MAX_RANGE_PARTS {
+		errs["Ranges"] = fmt.Errorf("The chunk size %d results in %d parts, the maximum is %d", chunkSize, parts, MAX_RANGE_PARTS)
+		return errs
+	}
+	assembled := make([]byte, 0, total)
+	for start := int64(0); start < total; start += chunkSize {
+		end := start + chunkSize - 1
+		if end >= total {
+			end = total - 1
+		}
+		key := fmt.Sprintf("Ranges/%d-%d", start, end)
+		partial, err := e.invoker.Do(req.WithHeader("Range", fmt.Sprintf("bytes=%d-%d", start, end)), interceptors...)
+		if err != nil {
+			errs[key] = utils.LabelifyError("Range request failed", err)
+			return errs
+		}
+		if err := examineRangePart(partial, start, end, total, res.Body[start:end+1]); err != nil {
+			errs[key] = err
+		}
+		assembled = append(assembled, partial.Body...)
+	}
+	if len(errs) == 0 && !bytes.Equal(assembled, res.Body) {
+		errs["Ranges/Concatenation"] = fmt.Errorf("The concatenated ranges (%d bytes) are mismatched with the full body (%d bytes)", len(assembled), total)
+	}
+	return errs
+}
+
+func examineRangePart(partial *client.HttpResponse, start int64, end int64, total int64, expected []byte) error {
+	if partial.StatusCode != http.StatusPartialContent {
+		return fmt.Errorf("Response StatusCode [%d] is not equal to expected value [%d]", partial.StatusCode, http.StatusPartialContent)
+	}
+	contentRange := fmt.Sprintf("bytes %d-%d/%d", start, end, total)
+	if received := partial.Header.Get("Content-Range"); received != contentRange {
+		return fmt.Errorf("Content-Range [%s] is mismatched with expected: [%s]", received, contentRange)
+	}
+	if !bytes.Equal(partial.Body, expected) {
+		return fmt.Errorf("Range content mismatch expected: %q / received: %q", expected, partial.Body)
+	}
+	return nil
+}
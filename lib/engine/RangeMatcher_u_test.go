@@ -0,0 +1,47 @@
+package engine
+
+import(
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"github.com/stretchr/testify/assert"
+	"github.com/opwire/opwire-testa/lib/client"
+)
+
+func TestSpecHandler_examineRanges(t *testing.T) {
+	content := []byte("The quick brown fox jumps over the lazy dog")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ignored" {
+			w.Write(content)
+			return
+		}
+		http.ServeContent(w, r, "fox.txt", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	e, err := NewSpecHandler(nil)
+	assert.Nil(t, err)
+
+	t.Run("Ranges are served correctly", func(t *testing.T) {
+		req := &client.HttpRequest{ Method: http.MethodGet, Url: server.URL + "/fox" }
+		res := &client.HttpResponse{ StatusCode: http.StatusOK, Body: content }
+		errs := e.examineRanges(10, req, res, nil)
+		assert.Equal(t, 0, len(errs))
+	})
+
+	t.Run("Range header is ignored", func(t *testing.T) {
+		req := &client.HttpRequest{ Method: http.MethodGet, Url: server.URL + "/ignored" }
+		res := &client.HttpResponse{ StatusCode: http.StatusOK, Body: content }
+		errs := e.examineRanges(20, req, res, nil)
+		assert.Equal(t, 3, len(errs))
+		assert.NotNil(t, errs["Ranges/0-19"])
+	})
+
+	t.Run("Full body is empty", func(t *testing.T) {
+		req := &client.HttpRequest{ Method: http.MethodGet, Url: server.URL + "/fox" }
+		errs := e.examineRanges(10, req, &client.HttpResponse{}, nil)
+		assert.NotNil(t, errs["Ranges"])
+	})
+}
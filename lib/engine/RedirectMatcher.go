@@ -0,0 +1,49 @@
+package engine
+
+import(
+	"fmt"
+	"regexp"
+	"github.com/opwire/opwire-testa/lib/client"
+)
+
+type MeasureRedirects struct {
+	Total *MeasureTotal `yaml:"total,omitempty" json:"total"`
+	Hops []MeasureRedirectHop `yaml:"hops,omitempty" json:"hops"`
+}
+
+type MeasureRedirectHop struct {
+	StatusCode *int `yaml:"status-code,omitempty" json:"status-code"`
+	LocationMatches *string `yaml:"location-matches,omitempty" json:"location-matches"`
+}
+
+// examineRedirects verifies the chain of the intermediate responses which
+// have been followed, the hops are checked from the first one.
+func examineRedirects(m *MeasureRedirects, chain []client.RedirectHop) map[string]error {
+	errs := make(map[string]error, 0)
+	if m.Total != nil && m.Total.Is != nil {
+		if err := examineNumber(len(chain), m.Total.Is); err != nil {
+			errs["Redirects/Total"] = fmt.Errorf("Total of redirects: %s", err.Error())
+		}
+	}
+	for i, hop := range m.Hops {
+		key := fmt.Sprintf("Redirects/Hops[%d]", i)
+		if i >= len(chain) {
+			errs[key] = fmt.Errorf("Redirect #%d has not been followed, total of redirects: %d", i + 1, len(chain))
+			continue
+		}
+		received := chain[i]
+		if hop.StatusCode != nil && received.StatusCode != *hop.StatusCode {
+			errs[key] = fmt.Errorf("Redirect [%s] status code [%d] is mismatched with expected: [%d]", received.Url, received.StatusCode, *hop.StatusCode)
+			continue
+		}
+		if hop.LocationMatches != nil {
+			reg, err := regexp.Compile(*hop.LocationMatches)
+			if err != nil {
+				errs[key] = fmt.Errorf("Invalid regular expression[%s], error: %s", *hop.LocationMatches, err.Error())
+			} else if !reg.MatchString(received.Location) {
+				errs[key] = fmt.Errorf("Redirect [%s] location [%s] is mismatched with pattern: [%s]", received.Url, received.Location, *hop.LocationMatches)
+			}
+		}
+	}
+	return errs
+}
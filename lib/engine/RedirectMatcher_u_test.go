@@ -0,0 +1,57 @@
+package engine
+
+import(
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/opwire/opwire-testa/lib/client"
+)
+
+func TestExamineRedirects(t *testing.T) {
+	ref := func(s string) *string { return &s }
+	num := func(n int) *int { return &n }
+	total := func(n int) *MeasureTotal { return &MeasureTotal{ Is: &ComparisonOperators{ EqualTo: n } } }
+	chain := []client.RedirectHop{
+		{ Url: "http://example.test/old", StatusCode: 301, Location: "/login?next=/home" },
+		{ Url: "http://example.test/login?next=/home", StatusCode: 302, Location: "http://example.test/home" },
+	}
+	TESTCASES := []struct {
+		redirects MeasureRedirects
+		errors []string
+	}{
+		{
+			redirects: MeasureRedirects{
+				Total: total(2),
+				Hops: []MeasureRedirectHop{
+					{ StatusCode: num(301), LocationMatches: ref(`^/login\?next=`) },
+					{ StatusCode: num(302), LocationMatches: ref(`/home$`) },
+				},
+			},
+			errors: []string{},
+		},
+		{
+			redirects: MeasureRedirects{
+				Total: total(1),
+				Hops: []MeasureRedirectHop{
+					{ StatusCode: num(308) },
+					{ LocationMatches: ref(`^/`) },
+					{ StatusCode: num(200) },
+				},
+			},
+			errors: []string{ "Redirects/Total", "Redirects/Hops[0]", "Redirects/Hops[1]", "Redirects/Hops[2]" },
+		},
+		{
+			redirects: MeasureRedirects{ Hops: []MeasureRedirectHop{ { LocationMatches: ref(`(`) } } },
+			errors: []string{ "Redirects/Hops[0]" },
+		},
+	}
+	for i, tc := range TESTCASES {
+		errs := examineRedirects(&tc.redirects, chain)
+		keys := make([]string, 0)
+		for key := range errs {
+			keys = append(keys, key)
+		}
+		assert.ElementsMatch(t, tc.errors, keys, "testcase #%d", i)
+	}
+	errs := examineRedirects(&MeasureRedirects{ Total: total(0) }, nil)
+	assert.Equal(t, 0, len(errs))
+}
@@ -0,0 +1,118 @@
+package engine
+
+import(
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+type SectionRepeat struct {
+	Count int `yaml:"count" json:"count"`
+	Delay string `yaml:"delay,omitempty" json:"delay,omitempty"`
+	Parallel bool `yaml:"parallel,omitempty" json:"parallel,omitempty"`
+}
+
+// RepeatStats aggregates the outcomes of the iterations of a repeated test case.
+type RepeatStats struct {
+	Count int
+	Passed int
+	Failed int
+	Min time.Duration
+	Mean time.Duration
+	P95 time.Duration
+	Max time.Duration
+}
+
+func (s *RepeatStats) String() string {
+	return fmt.Sprintf("%d runs: %d passed, %d failed; min %s, mean %s, p95 %s, max %s",
+		s.Count, s.Passed, s.Failed, s.Min, s.Mean, s.P95, s.Max)
+}
+
+// ExamineRepeated examines the test case (in its test suite) as many times as
+// its repeat section asks, serially (waiting for the delay between the
+// iterations) or in parallel (their starts being spaced by the delay). The
+// test case fails when any iteration fails, the errors of the first failed
+// iteration are reported.
+func (e *SpecHandler) ExamineRepeated(testsuite *TestSuite, testcase *TestCase) (*ExaminationResult, error) {
+	repeat := testcase.Repeat
+	if repeat == nil || repeat.Count <= 1 || testcase.Pending != nil && *testcase.Pending {
+		return e.ExamineInSuite(testsuite, testcase)
+	}
+	delay := parseRetryDuration(repeat.Delay, 0)
+	results := make([]*ExaminationResult, repeat.Count)
+	errs := make([]error, repeat.Count)
+	startTime := time.Now()
+	if repeat.Parallel {
+		// the cache and the session are created before the iterations share them
+		testsuite.GetResultCache()
+		testsuite.GetSession()
+		var wg sync.WaitGroup
+		for i := range results {
+			if i > 0 && delay > 0 {
+				time.Sleep(delay)
+			}
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				results[i], errs[i] = e.ExamineInSuite(testsuite, testcase)
+			}(i)
+		}
+		wg.Wait()
+	} else {
+		for i := range results {
+			if i > 0 && delay > 0 {
+				time.Sleep(delay)
+			}
+			results[i], errs[i] = e.ExamineInSuite(testsuite, testcase)
+		}
+	}
+	return aggregateRepeated(results, errs, time.Since(startTime))
+}
+
+func aggregateRepeated(results []*ExaminationResult, errs []error, elapsed time.Duration) (*ExaminationResult, error) {
+	stats := &RepeatStats{ Count: len(results) }
+	durations := make([]time.Duration, 0, len(results))
+	var total time.Duration
+	aggregated := &ExaminationResult{ Duration: elapsed, Repeat: stats }
+	var firstErr error
+	failed := -1
+	seen := make(map[string]bool, 0)
+	for i, result := range results {
+		durations = append(durations, result.Duration)
+		total += result.Duration
+		aggregated.Retries += result.Retries
+		aggregated.RetryWait += result.RetryWait
+		for _, warning := range result.Warnings {
+			if !seen[warning.String()] {
+				seen[warning.String()] = true
+				aggregated.Warnings = append(aggregated.Warnings, warning)
+			}
+		}
+		if errs[i] != nil || len(result.Errors) > 0 {
+			stats.Failed++
+			if failed < 0 {
+				failed = i
+				firstErr = errs[i]
+			}
+		} else {
+			stats.Passed++
+		}
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	stats.Min, stats.Max = durations[0], durations[len(durations) - 1]
+	stats.Mean = total / time.Duration(len(durations))
+	stats.P95 = durations[(len(durations) * 95 + 99) / 100 - 1]
+	reported := results[len(results) - 1]
+	if failed >= 0 {
+		reported = results[failed]
+		aggregated.Errors = make(map[string]error, 0)
+		aggregated.Errors["Repeat"] = fmt.Errorf("%d of %d iterations failed, the first one is iteration #%d", stats.Failed, stats.Count, failed + 1)
+		for key, err := range reported.Errors {
+			aggregated.Errors[fmt.Sprintf("Repeat[%d]/%s", failed + 1, key)] = err
+		}
+	}
+	aggregated.Status = reported.Status
+	aggregated.Request, aggregated.Response = reported.Request, reported.Response
+	return aggregated, firstErr
+}
@@ -0,0 +1,68 @@
+package engine
+
+import(
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+	"github.com/stretchr/testify/assert"
+	"github.com/opwire/opwire-testa/lib/client"
+)
+
+func TestSpecHandler_ExamineRepeated(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// every third request fails
+		if atomic.AddInt32(&hits, 1) % 3 == 0 {
+			w.WriteHeader(500)
+			return
+		}
+		w.WriteHeader(200)
+	}))
+	defer server.Close()
+
+	e, err := NewSpecHandler(nil)
+	assert.Nil(t, err)
+
+	repeated := func(repeat *SectionRepeat) *TestCase {
+		return &TestCase{
+			Title: "Flaky",
+			Request: &client.HttpRequest{ Method: http.MethodGet, Url: server.URL + "/flaky" },
+			Expectation: &Expectation{ StatusCode: &MeasureStatusCode{ IsOneOf: []int{ 200 } } },
+			Repeat: repeat,
+		}
+	}
+
+	t.Run("Serial iterations are aggregated", func(t *testing.T) {
+		atomic.StoreInt32(&hits, 0)
+		start := time.Now()
+		result, err := e.ExamineRepeated(&TestSuite{}, repeated(&SectionRepeat{ Count: 6, Delay: "10ms" }))
+		assert.Nil(t, err)
+		assert.True(t, time.Since(start) >= 50 * time.Millisecond)
+		assert.Equal(t, int32(6), atomic.LoadInt32(&hits))
+		assert.Equal(t, 6, result.Repeat.Count)
+		assert.Equal(t, 4, result.Repeat.Passed)
+		assert.Equal(t, 2, result.Repeat.Failed)
+		assert.True(t, result.Repeat.Min <= result.Repeat.Mean && result.Repeat.Mean <= result.Repeat.Max)
+		assert.EqualError(t, result.Errors["Repeat"], "2 of 6 iterations failed, the first one is iteration #3")
+		assert.Contains(t, result.Errors, "Repeat[3]/StatusCode")
+	})
+
+	t.Run("Parallel iterations", func(t *testing.T) {
+		atomic.StoreInt32(&hits, 0)
+		result, err := e.ExamineRepeated(&TestSuite{}, repeated(&SectionRepeat{ Count: 9, Parallel: true }))
+		assert.Nil(t, err)
+		assert.Equal(t, int32(9), atomic.LoadInt32(&hits))
+		assert.Equal(t, 6, result.Repeat.Passed)
+		assert.Equal(t, 3, result.Repeat.Failed)
+	})
+
+	t.Run("Testcase without repeat section", func(t *testing.T) {
+		atomic.StoreInt32(&hits, 0)
+		result, err := e.ExamineRepeated(&TestSuite{}, repeated(nil))
+		assert.Nil(t, err)
+		assert.Nil(t, result.Repeat)
+		assert.Equal(t, 0, len(result.Errors))
+	})
+}
@@ -0,0 +1,75 @@
+package engine
+
+import(
+	"fmt"
+	"sort"
+	"strings"
+	"github.com/opwire/opwire-testa/lib/client"
+)
+
+// RequestScriptEngine is implemented by the script engines which can alter
+// the requests before they are sent. The script receives a request table with
+// the method, url, headers (lower-cased names) and body, and modifies it in
+// place.
+type RequestScriptEngine interface {
+	Mutate(source string, request map[string]interface{}) (map[string]interface{}, error)
+}
+
+func applyRequestScript(m *MeasureScript, req *client.HttpRequest) error {
+	if m.Engine == nil || m.Source == nil {
+		return fmt.Errorf("Script must have an engine and a source")
+	}
+	engine, err := getScriptEngine(*m.Engine)
+	if err != nil {
+		return err
+	}
+	mutator, ok := engine.(RequestScriptEngine)
+	if !ok {
+		return fmt.Errorf("Script engine [%s] cannot alter the requests", *m.Engine)
+	}
+	vars := buildScriptContext(req, &client.HttpResponse{}, 0)["request"].(map[string]interface{})
+	url := vars["url"]
+	mutated, err := mutator.Mutate(*m.Source, vars)
+	if err != nil {
+		return fmt.Errorf("Script has been interrupted, error: %s", err.Error())
+	}
+	if method, ok := mutated["method"].(string); ok {
+		req.Method = method
+	}
+	if target, ok := mutated["url"].(string); ok && target != url {
+		req.Url, req.PDP, req.Path = target, "", ""
+	}
+	if body, ok := mutated["body"].(string); ok {
+		req.Body = body
+	}
+	if headers, ok := mutated["headers"].(map[string]string); ok {
+		req.Headers = mergeRequestHeaders(req.Headers, headers)
+	}
+	return nil
+}
+
+// mergeRequestHeaders keeps the names and the sensitive flags of the headers
+// which are still present, and appends the new ones in order of names.
+func mergeRequestHeaders(original []client.HttpHeader, headers map[string]string) []client.HttpHeader {
+	result := make([]client.HttpHeader, 0, len(headers))
+	found := make(map[string]bool, len(original))
+	for _, header := range original {
+		name := strings.ToLower(header.Name)
+		if value, ok := headers[name]; ok && !found[name] {
+			header.Value = value
+			result = append(result, header)
+			found[name] = true
+		}
+	}
+	names := make([]string, 0)
+	for name := range headers {
+		if !found[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		result = append(result, client.HttpHeader{ Name: name, Value: headers[name] })
+	}
+	return result
+}
@@ -0,0 +1,75 @@
+package engine
+
+import(
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/opwire/opwire-testa/lib/client"
+)
+
+type fakeRequestScriptEngine struct {
+	mutate func(request map[string]interface{})
+}
+
+func (f *fakeRequestScriptEngine) Run(source string, vars map[string]interface{}) ([]string, error) {
+	return nil, nil
+}
+
+func (f *fakeRequestScriptEngine) Mutate(source string, request map[string]interface{}) (map[string]interface{}, error) {
+	f.mutate(request)
+	return request, nil
+}
+
+func TestApplyRequestScript(t *testing.T) {
+	ref := func(s string) *string { return &s }
+	scriptEngines["fake"] = &fakeRequestScriptEngine{
+		mutate: func(request map[string]interface{}) {
+			headers := request["headers"].(map[string]string)
+			delete(headers, "x-removed")
+			headers["authorization"] = "Bearer changed"
+			headers["x-signature"] = "abc"
+			request["method"] = "PUT"
+			request["body"] = `{"signed":true}`
+		},
+	}
+	defer delete(scriptEngines, "fake")
+	req := &client.HttpRequest{
+		Method: "POST",
+		PDP: "http://example.test",
+		Path: "/items",
+		Headers: []client.HttpHeader{
+			{ Name: "Authorization", Value: "Bearer abc", Sensitive: true },
+			{ Name: "X-Removed", Value: "1" },
+		},
+	}
+	err := applyRequestScript(&MeasureScript{ Engine: ref("fake"), Source: ref("--") }, req)
+	assert.Nil(t, err)
+	assert.Equal(t, "PUT", req.Method)
+	assert.Equal(t, "http://example.test", req.PDP)
+	assert.Equal(t, "/items", req.Path)
+	assert.Equal(t, `{"signed":true}`, req.Body)
+	assert.Equal(t, []client.HttpHeader{
+		{ Name: "Authorization", Value: "Bearer changed", Sensitive: true },
+		{ Name: "x-signature", Value: "abc" },
+	}, req.Headers)
+
+	assert.NotNil(t, applyRequestScript(&MeasureScript{ Engine: ref("tcl"), Source: ref("--") }, req))
+}
+
+func TestApplyRequestScript_Lua(t *testing.T) {
+	if _, ok := scriptEngines["lua"]; !ok {
+		return
+	}
+	ref := func(s string) *string { return &s }
+	req := &client.HttpRequest{ Method: "GET", Url: "http://example.test/items" }
+	source := `
+		request.url = request.url .. "?page=2"
+		request.headers["x-trace"] = "t-" .. string.lower(request.method)
+	`
+	err := applyRequestScript(&MeasureScript{ Engine: ref("lua"), Source: ref(source) }, req)
+	assert.Nil(t, err)
+	assert.Equal(t, "http://example.test/items?page=2", req.Url)
+	assert.Equal(t, []client.HttpHeader{ { Name: "x-trace", Value: "t-get" } }, req.Headers)
+
+	err = applyRequestScript(&MeasureScript{ Engine: ref("lua"), Source: ref(`error("denied")`) }, req)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,68 @@
+package engine
+
+import(
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"github.com/opwire/opwire-testa/lib/client"
+)
+
+const DEFAULT_RETRY_BACKOFF time.Duration = time.Second
+const DEFAULT_RETRY_MAX_WAIT time.Duration = 30 * time.Second
+
+// invoke sends the request, it is retried on 429 and 503 responses as long
+// as attempts remain, waiting for the delay given by the Retry-After header
+// (or the fixed backoff), capped by max-wait.
+func (e *SpecHandler) invoke(retry *SectionRetry, req *client.HttpRequest, interceptors []client.Interceptor, result *ExaminationResult) (*client.HttpResponse, error) {
+	res, err := e.invoker.Do(req, interceptors...)
+	if retry == nil {
+		return res, err
+	}
+	backoff := parseRetryDuration(retry.Backoff, DEFAULT_RETRY_BACKOFF)
+	maxWait := parseRetryDuration(retry.MaxWait, DEFAULT_RETRY_MAX_WAIT)
+	for attempt := 1; attempt < retry.Attempts && err == nil && isRetryable(res.StatusCode); attempt++ {
+		wait := getRetryDelay(res.Header.Get("Retry-After"), backoff, time.Now())
+		if wait > maxWait {
+			wait = maxWait
+		}
+		time.Sleep(wait)
+		result.Retries += 1
+		result.RetryWait += wait
+		res, err = e.invoker.Do(req.WithMethod(req.Method), interceptors...)
+	}
+	return res, err
+}
+
+func isRetryable(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode == http.StatusServiceUnavailable
+}
+
+// getRetryDelay reads the Retry-After value, either a number of seconds or
+// an HTTP date, the fallback is used when it is missing or invalid.
+func getRetryDelay(retryAfter string, fallback time.Duration, now time.Time) time.Duration {
+	retryAfter = strings.TrimSpace(retryAfter)
+	if len(retryAfter) == 0 {
+		return fallback
+	}
+	if seconds, err := strconv.ParseInt(retryAfter, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if moment, err := http.ParseTime(retryAfter); err == nil {
+		if wait := moment.Sub(now); wait > 0 {
+			return wait
+		}
+		return 0
+	}
+	return fallback
+}
+
+func parseRetryDuration(value string, defaultValue time.Duration) time.Duration {
+	if duration, err := time.ParseDuration(value); err == nil && duration >= 0 {
+		return duration
+	}
+	return defaultValue
+}
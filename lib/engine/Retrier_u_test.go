@@ -0,0 +1,27 @@
+package engine
+
+import(
+	"net/http"
+	"testing"
+	"time"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetRetryDelay(t *testing.T) {
+	now := time.Date(2019, 6, 1, 10, 0, 0, 0, time.UTC)
+	TESTCASES := []struct {
+		retryAfter string
+		expected time.Duration
+	}{
+		{ retryAfter: "", expected: time.Second },
+		{ retryAfter: "3", expected: 3 * time.Second },
+		{ retryAfter: " 0 ", expected: 0 },
+		{ retryAfter: "-5", expected: 0 },
+		{ retryAfter: now.Add(90 * time.Second).Format(http.TimeFormat), expected: 90 * time.Second },
+		{ retryAfter: now.Add(-time.Minute).Format(http.TimeFormat), expected: 0 },
+		{ retryAfter: "soon", expected: time.Second },
+	}
+	for i, c := range TESTCASES {
+		assert.Equal(t, c.expected, getRetryDelay(c.retryAfter, time.Second, now), "testcase #%d", i)
+	}
+}
@@ -0,0 +1,102 @@
+package engine
+
+import(
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"github.com/opwire/opwire-testa/lib/schema"
+	"github.com/opwire/opwire-testa/lib/storage"
+)
+
+// examineSchema validates the received object against a JSON Schema given
+// inline or as a file path (relative to the testsuite file), each violation
+// is reported as a distinct error.
+func (e *SpecHandler) examineSchema(hasSchema string, home string, body []byte, received interface{}) map[string]error {
+	errs := make(map[string]error, 0)
+	source, err := loadSchemaSource(hasSchema, home)
+	if err != nil {
+		errs["Body/HasSchema"] = err
+		return errs
+	}
+	ok, report := e.verdicts.Evaluate("Body/HasSchema", source, body, func() (bool, string) {
+		validator, err := schema.NewValidator(&schema.ValidatorOptions{ Schema: source })
+		if err != nil {
+			return false, "\t" + err.Error()
+		}
+		result, err := validator.Validate(normalizeObject(received))
+		if err != nil {
+			return false, "\t" + err.Error()
+		}
+		lines := make([]string, 0)
+		for _, violation := range result.Errors() {
+			lines = append(lines, violation.Field() + "\t" + violation.Description())
+		}
+		return result.Valid(), strings.Join(lines, "\n")
+	})
+	if ok {
+		return errs
+	}
+	for _, line := range strings.Split(report, "\n") {
+		parts := strings.SplitN(line, "\t", 2)
+		if len(parts) < 2 {
+			continue
+		}
+		key := "Body/HasSchema"
+		if len(parts[0]) > 0 {
+			key = key + "/" + parts[0]
+		}
+		if prev, found := errs[key]; found {
+			errs[key] = fmt.Errorf("%s; %s", prev.Error(), parts[1])
+		} else {
+			errs[key] = errors.New(parts[1])
+		}
+	}
+	return errs
+}
+
+func loadSchemaSource(hasSchema string, home string) (string, error) {
+	if strings.HasPrefix(strings.TrimSpace(hasSchema), "{") {
+		return hasSchema, nil
+	}
+	path := hasSchema
+	if !filepath.IsAbs(path) && len(home) > 0 {
+		path = filepath.Join(home, path)
+	}
+	file, err := storage.GetFs().Open(path)
+	if err != nil {
+		return "", fmt.Errorf("Loading schema [%s] failed: %s", hasSchema, err.Error())
+	}
+	defer file.Close()
+	content, err := ioutil.ReadAll(file)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+// normalizeObject converts the maps decoded from YAML into JSON compatible ones.
+func normalizeObject(node interface{}) interface{} {
+	switch obj := node.(type) {
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(obj))
+		for key, val := range obj {
+			result[fmt.Sprintf("%v", key)] = normalizeObject(val)
+		}
+		return result
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(obj))
+		for key, val := range obj {
+			result[key] = normalizeObject(val)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(obj))
+		for i, val := range obj {
+			result[i] = normalizeObject(val)
+		}
+		return result
+	}
+	return node
+}
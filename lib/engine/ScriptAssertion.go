@@ -0,0 +1,81 @@
+package engine
+
+import(
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"github.com/opwire/opwire-testa/lib/client"
+)
+
+type MeasureScript struct {
+	Engine *string `yaml:"engine,omitempty" json:"engine"`
+	Source *string `yaml:"source,omitempty" json:"source"`
+}
+
+// ScriptEngine runs an assertion script, which calls assert(ok, message)
+// and fail(message), and returns the messages of the failed assertions.
+type ScriptEngine interface {
+	Run(source string, vars map[string]interface{}) ([]string, error)
+}
+
+// scriptEngines are registered by the builds tagged with the name given in
+// scriptEngineTags, keeping the interpreters out of the default binary.
+var scriptEngines = make(map[string]ScriptEngine)
+
+var scriptEngineTags = map[string]string{
+	"js": "goja",
+	"lua": "lua",
+}
+
+func getScriptEngine(name string) (ScriptEngine, error) {
+	engine, ok := scriptEngines[name]
+	if !ok {
+		if tag, found := scriptEngineTags[name]; found {
+			return nil, fmt.Errorf("Script engine [%s] is not supported by this build, rebuild with the [%s] tag", name, tag)
+		}
+		names := make([]string, 0, len(scriptEngineTags))
+		for name := range scriptEngineTags {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("Script engine [%s] is unknown, available engines: %v", name, names)
+	}
+	return engine, nil
+}
+
+func examineScript(m *MeasureScript, req *client.HttpRequest, res *client.HttpResponse, duration time.Duration) error {
+	if m.Engine == nil || m.Source == nil {
+		return fmt.Errorf("Script must have an engine and a source")
+	}
+	engine, err := getScriptEngine(*m.Engine)
+	if err != nil {
+		return err
+	}
+	failures, err := engine.Run(*m.Source, buildScriptContext(req, res, duration))
+	if err != nil {
+		return fmt.Errorf("Script has been interrupted, error: %s", err.Error())
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("Script assertions failed: %s", strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// buildScriptContext exposes the request and the response (as described in
+// buildAssertionContext) to the scripts.
+func buildScriptContext(req *client.HttpRequest, res *client.HttpResponse, duration time.Duration) map[string]interface{} {
+	headers := make(map[string]string, len(req.Headers))
+	for _, header := range req.Headers {
+		headers[strings.ToLower(header.Name)] = header.Value
+	}
+	return map[string]interface{}{
+		"request": map[string]interface{}{
+			"method": req.Method,
+			"url": client.BuildUrl(req),
+			"headers": headers,
+			"body": req.Body,
+		},
+		"response": buildAssertionContext(res, duration),
+	}
+}
@@ -0,0 +1,95 @@
+package engine
+
+import(
+	"net/http"
+	"testing"
+	"time"
+	"github.com/stretchr/testify/assert"
+	"github.com/opwire/opwire-testa/lib/client"
+)
+
+func TestBuildScriptContext(t *testing.T) {
+	req := &client.HttpRequest{
+		Method: "POST",
+		Url: "http://example.test/items",
+		Headers: []client.HttpHeader{ { Name: "Content-Type", Value: "application/json" } },
+		Body: `{"name":"testa"}`,
+	}
+	res := &client.HttpResponse{ StatusCode: 201, Body: []byte(`{"id":1}`) }
+	vars := buildScriptContext(req, res, time.Millisecond)
+	assert.Equal(t, map[string]interface{}{
+		"method": "POST",
+		"url": "http://example.test/items",
+		"headers": map[string]string{ "content-type": "application/json" },
+		"body": `{"name":"testa"}`,
+	}, vars["request"])
+	assert.Equal(t, int64(201), vars["response"].(map[string]interface{})["status"])
+}
+
+func TestExamineScript(t *testing.T) {
+	ref := func(s string) *string { return &s }
+	req := &client.HttpRequest{ Method: "GET", Url: "http://example.test/items" }
+	res := &client.HttpResponse{
+		StatusCode: 200,
+		Header: http.Header{ "Content-Type": []string{"application/json"} },
+		Body: []byte(`{"items":[{"price":2,"qty":3},{"price":4,"qty":1}],"total":10}`),
+	}
+	assert.NotNil(t, examineScript(&MeasureScript{ Engine: ref("js") }, req, res, 0))
+	assert.NotNil(t, examineScript(&MeasureScript{ Engine: ref("tcl"), Source: ref("1") }, req, res, 0))
+	if _, ok := scriptEngines["js"]; !ok {
+		assert.NotNil(t, examineScript(&MeasureScript{ Engine: ref("js"), Source: ref("assert(true)") }, req, res, 0))
+		return
+	}
+	TESTCASES := []struct {
+		source string
+		failed bool
+	}{
+		{ source: `assert(response.status == 200 && request.method == "GET")`, failed: false },
+		{ source: `
+			var sum = 0;
+			response.body.items.forEach(function(item) { sum += item.price * item.qty; });
+			assert(sum == response.body.total, "total mismatched: " + sum);
+		`, failed: false },
+		{ source: `assert(response.headers["content-type"] == "text/html", "not html")`, failed: true },
+		{ source: `fail("always")`, failed: true },
+		{ source: `throw new Error("broken")`, failed: true },
+		{ source: `assert(`, failed: true },
+	}
+	for _, tc := range TESTCASES {
+		err := examineScript(&MeasureScript{ Engine: ref("js"), Source: ref(tc.source) }, req, res, 0)
+		assert.Equal(t, tc.failed, err != nil, tc.source)
+	}
+}
+
+func TestExamineScript_Lua(t *testing.T) {
+	ref := func(s string) *string { return &s }
+	req := &client.HttpRequest{ Method: "GET", Url: "http://example.test/items" }
+	res := &client.HttpResponse{
+		StatusCode: 200,
+		Header: http.Header{ "Content-Type": []string{"application/json"} },
+		Body: []byte(`{"items":[{"price":2,"qty":3},{"price":4,"qty":1}],"total":10}`),
+	}
+	if _, ok := scriptEngines["lua"]; !ok {
+		assert.NotNil(t, examineScript(&MeasureScript{ Engine: ref("lua"), Source: ref("assert(true)") }, req, res, 0))
+		return
+	}
+	TESTCASES := []struct {
+		source string
+		failed bool
+	}{
+		{ source: `assert(response.status == 200 and request.method == "GET")`, failed: false },
+		{ source: `
+			local sum = 0
+			for _, item in ipairs(response.body.items) do sum = sum + item.price * item.qty end
+			assert(sum == response.body.total, "total mismatched: " .. sum)
+		`, failed: false },
+		{ source: `assert(response.headers["content-type"] == "text/html", "not html")`, failed: true },
+		{ source: `fail("always")`, failed: true },
+		{ source: `error("broken")`, failed: true },
+		{ source: `assert(`, failed: true },
+	}
+	for _, tc := range TESTCASES {
+		err := examineScript(&MeasureScript{ Engine: ref("lua"), Source: ref(tc.source) }, req, res, 0)
+		assert.Equal(t, tc.failed, err != nil, tc.source)
+	}
+}
@@ -0,0 +1,51 @@
+// +build goja
+
+package engine
+
+import(
+	"fmt"
+	"time"
+	"github.com/dop251/goja"
+)
+
+const JS_SCRIPT_TIMEOUT time.Duration = 5 * time.Second
+
+func init() {
+	scriptEngines["js"] = &jsScriptEngine{}
+}
+
+type jsScriptEngine struct {}
+
+func (j *jsScriptEngine) Run(source string, vars map[string]interface{}) ([]string, error) {
+	vm := goja.New()
+	failures := make([]string, 0)
+	fail := func(message goja.Value, fallback string) {
+		if !goja.IsUndefined(message) {
+			fallback = message.String()
+		}
+		failures = append(failures, fallback)
+	}
+	for name, value := range vars {
+		if err := vm.Set(name, value); err != nil {
+			return nil, err
+		}
+	}
+	vm.Set("assert", func(call goja.FunctionCall) goja.Value {
+		if !call.Argument(0).ToBoolean() {
+			fail(call.Argument(1), "assertion is false")
+		}
+		return goja.Undefined()
+	})
+	vm.Set("fail", func(call goja.FunctionCall) goja.Value {
+		fail(call.Argument(0), "failed")
+		return goja.Undefined()
+	})
+	timer := time.AfterFunc(JS_SCRIPT_TIMEOUT, func() {
+		vm.Interrupt(fmt.Sprintf("timeout after %s", JS_SCRIPT_TIMEOUT))
+	})
+	defer timer.Stop()
+	if _, err := vm.RunString(source); err != nil {
+		return failures, err
+	}
+	return failures, nil
+}
@@ -0,0 +1,104 @@
+// +build lua
+
+package engine
+
+import(
+	"context"
+	"time"
+	"github.com/yuin/gopher-lua"
+)
+
+const LUA_SCRIPT_TIMEOUT time.Duration = 5 * time.Second
+
+func init() {
+	scriptEngines["lua"] = &luaScriptEngine{}
+}
+
+type luaScriptEngine struct {}
+
+func (l *luaScriptEngine) Run(source string, vars map[string]interface{}) ([]string, error) {
+	L := lua.NewState()
+	defer L.Close()
+	failures := make([]string, 0)
+	L.SetGlobal("assert", L.NewFunction(func(L *lua.LState) int {
+		if !L.ToBool(1) {
+			failures = append(failures, L.OptString(2, "assertion is false"))
+		}
+		return 0
+	}))
+	L.SetGlobal("fail", L.NewFunction(func(L *lua.LState) int {
+		failures = append(failures, L.OptString(1, "failed"))
+		return 0
+	}))
+	for name, value := range vars {
+		L.SetGlobal(name, toLuaValue(L, value))
+	}
+	return failures, l.exec(L, source)
+}
+
+func (l *luaScriptEngine) Mutate(source string, request map[string]interface{}) (map[string]interface{}, error) {
+	L := lua.NewState()
+	defer L.Close()
+	table := toLuaValue(L, request)
+	L.SetGlobal("request", table)
+	if err := l.exec(L, source); err != nil {
+		return nil, err
+	}
+	mutated := make(map[string]interface{})
+	for _, key := range []string{"method", "url", "body"} {
+		if value, ok := L.GetField(table, key).(lua.LString); ok {
+			mutated[key] = string(value)
+		}
+	}
+	if fields, ok := L.GetField(table, "headers").(*lua.LTable); ok {
+		headers := make(map[string]string)
+		fields.ForEach(func(name lua.LValue, value lua.LValue) {
+			if value != lua.LNil {
+				headers[name.String()] = value.String()
+			}
+		})
+		mutated["headers"] = headers
+	}
+	return mutated, nil
+}
+
+func (l *luaScriptEngine) exec(L *lua.LState, source string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), LUA_SCRIPT_TIMEOUT)
+	defer cancel()
+	L.SetContext(ctx)
+	return L.DoString(source)
+}
+
+func toLuaValue(L *lua.LState, value interface{}) lua.LValue {
+	switch v := value.(type) {
+	case bool:
+		return lua.LBool(v)
+	case string:
+		return lua.LString(v)
+	case float64:
+		return lua.LNumber(v)
+	case int64:
+		return lua.LNumber(v)
+	case int:
+		return lua.LNumber(v)
+	case map[string]string:
+		table := L.NewTable()
+		for key, item := range v {
+			table.RawSetString(key, lua.LString(item))
+		}
+		return table
+	case map[string]interface{}:
+		table := L.NewTable()
+		for key, item := range v {
+			table.RawSetString(key, toLuaValue(L, item))
+		}
+		return table
+	case []interface{}:
+		table := L.NewTable()
+		for _, item := range v {
+			table.Append(toLuaValue(L, item))
+		}
+		return table
+	}
+	return lua.LNil
+}
@@ -0,0 +1,58 @@
+// +build lua
+
+package engine
+
+import(
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLuaScriptEngine_Run(t *testing.T) {
+	engine := &luaScriptEngine{}
+	vars := map[string]interface{}{
+		"response": map[string]interface{}{
+			"status": 200,
+			"headers": map[string]string{ "content-type": "application/json" },
+			"body": map[string]interface{}{
+				"items": []interface{}{ float64(2), float64(3) },
+				"active": true,
+			},
+		},
+	}
+
+	failures, err := engine.Run(`
+		assert(response.status == 200)
+		assert(response.headers["content-type"] == "application/json")
+		assert(#response.body.items == 2 and response.body.active)
+	`, vars)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{}, failures)
+
+	failures, err = engine.Run(`
+		assert(response.status == 201, "status mismatched")
+		assert(response.body.missing ~= nil)
+		fail("always")
+	`, vars)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{ "status mismatched", "assertion is false", "always" }, failures)
+
+	_, err = engine.Run(`error("broken")`, vars)
+	assert.NotNil(t, err)
+}
+
+func TestLuaScriptEngine_Mutate(t *testing.T) {
+	engine := &luaScriptEngine{}
+	mutated, err := engine.Mutate(`
+		request.url = request.url .. "?page=2"
+		request.headers["X-Trace"] = "abc"
+		request.headers["X-Removed"] = nil
+	`, map[string]interface{}{
+		"method": "GET",
+		"url": "http://example.test/items",
+		"headers": map[string]string{ "X-Removed": "1", "Accept": "application/json" },
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "GET", mutated["method"])
+	assert.Equal(t, "http://example.test/items?page=2", mutated["url"])
+	assert.Equal(t, map[string]string{ "Accept": "application/json", "X-Trace": "abc" }, mutated["headers"])
+}
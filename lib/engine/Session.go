@@ -0,0 +1,91 @@
+package engine
+
+import(
+	"net/http"
+	"net/http/cookiejar"
+	"strings"
+	"sync"
+	"github.com/opwire/opwire-testa/lib/client"
+)
+
+type SessionConfig struct {
+	Cookies *bool `yaml:"cookies,omitempty" json:"cookies"`
+	Headers []client.HttpHeader `yaml:"headers,omitempty" json:"headers"`
+}
+
+// Session keeps the cookies and the default headers shared by the chained
+// requests of a testsuite, so that login-then-act flows need no manual plumbing.
+type Session struct {
+	jar http.CookieJar
+	headers []client.HttpHeader
+	mutex sync.RWMutex
+}
+
+func NewSession(config *SessionConfig) (s *Session, err error) {
+	s = &Session{}
+	if config == nil {
+		return s, nil
+	}
+	if config.Cookies == nil || *config.Cookies {
+		s.jar, err = cookiejar.New(nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, header := range config.Headers {
+		s.SetHeader(header.Name, header.Value)
+	}
+	return s, nil
+}
+
+func (s *Session) SetHeader(name string, value string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for i := range s.headers {
+		if strings.EqualFold(s.headers[i].Name, name) {
+			s.headers[i].Value = value
+			return
+		}
+	}
+	s.headers = append(s.headers, client.HttpHeader{ Name: name, Value: value })
+}
+
+func (s *Session) GetHeaders() []client.HttpHeader {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return append([]client.HttpHeader{}, s.headers...)
+}
+
+func (s *Session) PreProcess(req *client.HttpRequest) error {
+	lowReq, err := req.GetRawRequest()
+	if err != nil {
+		return err
+	}
+	// the headers of the request take precedence over the session ones
+	for _, header := range s.GetHeaders() {
+		if len(header.Name) > 0 && len(lowReq.Header.Get(header.Name)) == 0 {
+			lowReq.Header.Set(header.Name, header.Value)
+		}
+	}
+	if s.jar != nil {
+		for _, cookie := range s.jar.Cookies(lowReq.URL) {
+			lowReq.AddCookie(cookie)
+		}
+	}
+	return nil
+}
+
+func (s *Session) PostProcess(req *client.HttpRequest, res *client.HttpResponse) error {
+	if s.jar == nil || res == nil {
+		return nil
+	}
+	lowReq, err := req.GetRawRequest()
+	if err != nil {
+		return err
+	}
+	cookies := res.Cookies()
+	if len(cookies) > 0 {
+		s.jar.SetCookies(lowReq.URL, cookies)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package engine
+
+import(
+	"net/http"
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/opwire/opwire-testa/lib/client"
+)
+
+func TestSession(t *testing.T) {
+	session, err := NewSession(&SessionConfig{
+		Headers: []client.HttpHeader{
+			{ Name: "Accept", Value: "application/json" },
+		},
+	})
+	assert.Nil(t, err)
+
+	login := &client.HttpRequest{ Method: "POST", Url: "http://example.test/login" }
+	session.PostProcess(login, &client.HttpResponse{
+		Header: http.Header{ "Set-Cookie": []string{"sid=abc; Path=/"} },
+	})
+	session.SetHeader("authorization", "Bearer token")
+
+	t.Run("Session headers and cookies are attached", func(t *testing.T) {
+		req := &client.HttpRequest{ Method: "GET", Url: "http://example.test/profile" }
+		assert.Nil(t, session.PreProcess(req))
+		lowReq, _ := req.GetRawRequest()
+		assert.Equal(t, "application/json", lowReq.Header.Get("Accept"))
+		assert.Equal(t, "Bearer token", lowReq.Header.Get("Authorization"))
+		cookie, err := lowReq.Cookie("sid")
+		assert.Nil(t, err)
+		assert.Equal(t, "abc", cookie.Value)
+	})
+
+	t.Run("Request headers take precedence", func(t *testing.T) {
+		req := &client.HttpRequest{
+			Method: "GET",
+			Url: "http://example.test/profile",
+			Headers: []client.HttpHeader{
+				{ Name: "Accept", Value: "text/plain" },
+			},
+		}
+		assert.Nil(t, session.PreProcess(req))
+		lowReq, _ := req.GetRawRequest()
+		assert.Equal(t, "text/plain", lowReq.Header.Get("Accept"))
+	})
+
+	t.Run("Cookies are disabled", func(t *testing.T) {
+		disabled := false
+		session, _ := NewSession(&SessionConfig{ Cookies: &disabled })
+		session.PostProcess(login, &client.HttpResponse{
+			Header: http.Header{ "Set-Cookie": []string{"sid=abc; Path=/"} },
+		})
+		req := &client.HttpRequest{ Method: "GET", Url: "http://example.test/profile" }
+		assert.Nil(t, session.PreProcess(req))
+		lowReq, _ := req.GetRawRequest()
+		assert.Equal(t, "", lowReq.Header.Get("Cookie"))
+	})
+}
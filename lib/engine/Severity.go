@@ -0,0 +1,115 @@
+package engine
+
+import(
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const SEVERITY_ERROR string = "error"
+const SEVERITY_WARNING string = "warning"
+
+func isWarningSeverity(severity *string) bool {
+	return severity != nil && *severity == SEVERITY_WARNING
+}
+
+// getWarningKeys collects the keys of the failures of the matchers marked
+// with the warning severity, a key ending with a slash stands for all of the
+// keys it prefixes.
+func (e *Expectation) getWarningKeys() map[string]bool {
+	keys := make(map[string]bool, 0)
+	if e == nil {
+		return keys
+	}
+	if e.StatusCode != nil && isWarningSeverity(e.StatusCode.Severity) {
+		keys["StatusCode"] = true
+	}
+	if e.StatusText != nil && isWarningSeverity(e.StatusText.Severity) {
+		keys["StatusText"] = true
+	}
+	if e.Version != nil && isWarningSeverity(e.Version.Severity) {
+		keys["Version"] = true
+	}
+	if e.ContentLength != nil && isWarningSeverity(e.ContentLength.Severity) {
+		keys["ContentLength"] = true
+	}
+	if e.Network != nil && isWarningSeverity(e.Network.Severity) {
+		keys["Network/"] = true
+	}
+	for _, cookie := range e.Cookies {
+		if cookie.Name != nil && isWarningSeverity(cookie.Severity) {
+			keys[fmt.Sprintf("Cookie[%s]", *cookie.Name)] = true
+		}
+	}
+	for kind, headers := range map[string]*MeasureHeaders{ "Header": e.Headers, "Trailer": e.Trailers } {
+		if headers == nil {
+			continue
+		}
+		for _, item := range headers.Items {
+			if item.Name != nil && isWarningSeverity(item.Severity) {
+				keys[fmt.Sprintf("%s[%s]", kind, *item.Name)] = true
+			}
+		}
+	}
+	if e.Body != nil {
+		if isWarningSeverity(e.Body.Severity) {
+			keys["Body/"] = true
+		}
+		for _, field := range e.Body.Fields {
+			if !isWarningSeverity(field.Severity) {
+				continue
+			}
+			if field.Path != nil {
+				keys["Body/Fields/" + *field.Path] = true
+			}
+			if field.Select != nil {
+				keys["Body/Fields/" + *field.Select] = true
+			}
+		}
+		if e.Body.Csv != nil {
+			for _, column := range e.Body.Csv.Columns {
+				if column.Path != nil && isWarningSeverity(column.Severity) {
+					keys["Body/Csv/Columns/" + *column.Path] = true
+				}
+			}
+		}
+	}
+	return keys
+}
+
+// downgradeFailures reports the failures of the warning-severity matchers as
+// warnings, they do not fail the testcase.
+func downgradeFailures(errors map[string]error, keys map[string]bool, result *ExaminationResult) {
+	downgraded := make([]string, 0)
+	for key := range errors {
+		if isWarningKey(key, keys) {
+			downgraded = append(downgraded, key)
+		}
+	}
+	sort.Strings(downgraded)
+	for _, key := range downgraded {
+		result.Warnings = append(result.Warnings, Warning{
+			Category: WARNING_EXPECTATION,
+			Message: fmt.Sprintf("%s: %s", key, errors[key].Error()),
+		})
+		delete(errors, key)
+	}
+}
+
+// isWarningKey reports whether the failure of the key is downgraded, the
+// fields and the columns of the body are ruled by their own severity rather
+// than by the one of the body.
+func isWarningKey(key string, keys map[string]bool) bool {
+	if keys[key] {
+		return true
+	}
+	if strings.HasPrefix(key, "Body/Fields/") || strings.HasPrefix(key, "Body/Csv/Columns/") {
+		return false
+	}
+	for prefix := range keys {
+		if strings.HasSuffix(prefix, "/") && strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package engine
+
+import(
+	"fmt"
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/opwire/opwire-testa/lib/utils"
+)
+
+func TestDowngradeFailures(t *testing.T) {
+	warning, severe := SEVERITY_WARNING, SEVERITY_ERROR
+	expect := &Expectation{
+		StatusCode: &MeasureStatusCode{ Severity: &severe },
+		Headers: &MeasureHeaders{
+			Items: []MeasureHeader{
+				{ Name: utils.RefOfString("Cache-Control"), Severity: &warning },
+				{ Name: utils.RefOfString("Content-Type") },
+			},
+		},
+		Body: &MeasureBody{
+			Fields: []MeasureBodyField{
+				{ Path: utils.RefOfString("vat"), Severity: &warning },
+				{ Select: utils.RefOfString("h1.title"), Severity: &warning },
+			},
+		},
+	}
+	keys := expect.getWarningKeys()
+	assert.Equal(t, map[string]bool{ "Header[Cache-Control]": true, "Body/Fields/vat": true, "Body/Fields/h1.title": true }, keys)
+
+	errs := map[string]error{
+		"StatusCode": fmt.Errorf("Response StatusCode [500] is not equal to expected value [200]"),
+		"Header[Cache-Control]": fmt.Errorf("Header must be present"),
+		"Body/Fields/vat": fmt.Errorf("Field mismatch expected: 0.2 / received: 0.19"),
+	}
+	result := &ExaminationResult{}
+	downgradeFailures(errs, keys, result)
+	assert.Equal(t, 1, len(errs))
+	assert.Contains(t, errs, "StatusCode")
+	assert.Equal(t, []Warning{
+		{ Category: WARNING_EXPECTATION, Message: "Body/Fields/vat: Field mismatch expected: 0.2 / received: 0.19" },
+		{ Category: WARNING_EXPECTATION, Message: "Header[Cache-Control]: Header must be present" },
+	}, result.Warnings)
+
+	assert.Equal(t, 0, len((*Expectation)(nil).getWarningKeys()))
+}
+
+func TestDowngradeFailures_Prefixes(t *testing.T) {
+	warning := SEVERITY_WARNING
+	length := int64(42)
+	expect := &Expectation{
+		Cookies: []MeasureCookie{
+			{ Name: utils.RefOfString("session"), Severity: &warning },
+			{ Name: utils.RefOfString("csrf") },
+		},
+		ContentLength: &MeasureContentLength{ IsEqualTo: &length, Severity: &warning },
+		Network: &MeasureNetwork{ Severity: &warning },
+		Body: &MeasureBody{
+			Severity: &warning,
+			Fields: []MeasureBodyField{
+				{ Path: utils.RefOfString("id") },
+			},
+		},
+	}
+	errs := map[string]error{
+		"Cookie[session]": fmt.Errorf("Cookie must be present"),
+		"Cookie[csrf]": fmt.Errorf("Cookie must be present"),
+		"ContentLength": fmt.Errorf("Content-Length mismatch expected: 42 / received: 40"),
+		"Network/TLSVersion": fmt.Errorf("TLS version [TLS 1.2] is mismatched with expected: [TLS 1.3]"),
+		"Body/HasSchema": fmt.Errorf("Body does not match the schema"),
+		"Body/Fields/id": fmt.Errorf("Field must be present"),
+	}
+	result := &ExaminationResult{}
+	downgradeFailures(errs, expect.getWarningKeys(), result)
+	assert.Equal(t, map[string]error{
+		"Cookie[csrf]": fmt.Errorf("Cookie must be present"),
+		"Body/Fields/id": fmt.Errorf("Field must be present"),
+	}, errs)
+	assert.Equal(t, 4, len(result.Warnings))
+}
@@ -0,0 +1,25 @@
+package engine
+
+import(
+	"github.com/opwire/opwire-testa/lib/utils"
+)
+
+// mergeFailure combines a failure with the previous one of the same matcher,
+// both are listed in the soft-assertion mode, otherwise the latter wins.
+func mergeFailure(previous error, failure error, soft bool) error {
+	if previous == nil || !soft {
+		return failure
+	}
+	return utils.BuildMultilineError([]string{ previous.Error(), failure.Error() })
+}
+
+func addFailure(errors map[string]error, key string, failure error, soft bool) {
+	errors[key] = mergeFailure(errors[key], failure, soft)
+}
+
+func (e *Expectation) isSoft(fallback bool) bool {
+	if e != nil && e.SoftAssertions != nil {
+		return *e.SoftAssertions
+	}
+	return fallback
+}
@@ -0,0 +1,37 @@
+package engine
+
+import(
+	"fmt"
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddFailure(t *testing.T) {
+	errs := make(map[string]error, 0)
+	addFailure(errs, "StatusCode", fmt.Errorf("first"), false)
+	addFailure(errs, "StatusCode", fmt.Errorf("second"), false)
+	assert.Equal(t, "second", errs["StatusCode"].Error())
+
+	errs = make(map[string]error, 0)
+	addFailure(errs, "StatusCode", fmt.Errorf("first"), true)
+	addFailure(errs, "StatusCode", fmt.Errorf("second"), true)
+	assert.Equal(t, "first\nsecond", errs["StatusCode"].Error())
+}
+
+func TestExamineBodyField_Soft(t *testing.T) {
+	pattern := "^eu-"
+	vtype := "number"
+	field := MeasureBodyField{ IsType: &vtype, MatchWith: &pattern }
+	assert.Equal(t, "Field mismatch pattern: ^eu- / received: us-1", examineBodyField(field, "us-1", true, false, 0).Error())
+	assert.Equal(t, "Field type mismatch expected: number / received: string\nField mismatch pattern: ^eu- / received: us-1", examineBodyField(field, "us-1", true, true, 0).Error())
+}
+
+func TestExpectation_isSoft(t *testing.T) {
+	enabled := true
+	disabled := false
+	assert.False(t, (*Expectation)(nil).isSoft(false))
+	assert.True(t, (*Expectation)(nil).isSoft(true))
+	assert.True(t, (&Expectation{ SoftAssertions: &enabled }).isSoft(false))
+	assert.False(t, (&Expectation{ SoftAssertions: &disabled }).isSoft(true))
+	assert.True(t, (&Expectation{}).isSoft(true))
+}
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
+	"strings"
 	"time"
 	"gopkg.in/yaml.v2"
 	"github.com/opwire/opwire-testa/lib/client"
@@ -15,6 +17,8 @@ type SpecBuilder struct {
 	Version string
 }
 
+const SNAPSHOT_TAG string = "snapshot"
+
 func NewSpecBuilder() (*SpecBuilder, error) {
 	ref := new(SpecBuilder)
 	ref.ExcludedHeaders = []string {
@@ -30,9 +34,9 @@ func (g *SpecBuilder) GenerateTestCase(w io.Writer, req *client.HttpRequest, res
 	s.Title = "<Generated testcase>"
 	s.Version = utils.RefOfString(g.Version)
 	s.Request = req
-	s.Expectation = g.generateExpectation(res)
+	s.Expectation = g.generateExpectation(req, res)
 	s.CreatedTime = utils.RefOfString(time.Now().Format(time.RFC3339))
-	s.Tags = []string {"snapshot"}
+	s.Tags = []string {SNAPSHOT_TAG}
 	username, err := utils.FindUsername()
 	if err == nil {
 		if tag, err := utils.StandardizeTagLabel(username); err == nil {
@@ -53,7 +57,7 @@ func (g *SpecBuilder) GenerateTestCase(w io.Writer, req *client.HttpRequest, res
 	return nil
 }
 
-func (g *SpecBuilder) generateExpectation(res *client.HttpResponse) *Expectation {
+func (g *SpecBuilder) generateExpectation(req *client.HttpRequest, res *client.HttpResponse) *Expectation {
 	if res == nil {
 		return nil
 	}
@@ -95,10 +99,33 @@ func (g *SpecBuilder) generateExpectation(res *client.HttpResponse) *Expectation
 				}
 				e.Headers.Items = append(e.Headers.Items, one)
 				count = count + 1
+			} else if len(vals) > 1 {
+				name := key
+				e.Headers.Items = append(e.Headers.Items, MeasureHeader{
+					Name: &name,
+					HasValues: append([]string{}, vals...),
+				})
+				count = count + 1
 			}
 		}
 	}
 
+	// allowed methods of an OPTIONS request
+	if req != nil && strings.ToUpper(req.Method) == http.MethodOptions {
+		if allowed := client.ParseAllowedMethods(res.Header); len(allowed) > 0 {
+			e.AllowMethods = &MeasureAllowMethods{ Includes: allowed }
+		}
+	}
+
+	// a response has no body by definition (HEAD, 204, 304)
+	method := ""
+	if req != nil {
+		method = req.Method
+	}
+	if client.HasNoBody(method, res.StatusCode) {
+		return e
+	}
+
 	// body
 	e.Body = &MeasureBody{}
 
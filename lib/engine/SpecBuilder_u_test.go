@@ -0,0 +1,38 @@
+package engine
+
+import(
+	"net/http"
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/opwire/opwire-testa/lib/client"
+)
+
+func TestSpecBuilder_generateExpectation(t *testing.T) {
+	g, err := NewSpecBuilder()
+	assert.Nil(t, err)
+
+	t.Run("HEAD response has no body expectation", func(t *testing.T) {
+		req := &client.HttpRequest{ Method: "HEAD" }
+		res := &client.HttpResponse{ StatusCode: 200, Header: http.Header{} }
+		e := g.generateExpectation(req, res)
+		assert.Nil(t, e.Body)
+		assert.Nil(t, e.AllowMethods)
+	})
+
+	t.Run("OPTIONS response expects the allowed methods", func(t *testing.T) {
+		req := &client.HttpRequest{ Method: "OPTIONS" }
+		res := &client.HttpResponse{ StatusCode: 204, Header: http.Header{ "Allow": []string{"GET, post"} } }
+		e := g.generateExpectation(req, res)
+		assert.Nil(t, e.Body)
+		assert.Equal(t, &MeasureAllowMethods{ Includes: []string{"GET", "POST"} }, e.AllowMethods)
+	})
+
+	t.Run("Repeated header expects the list of values", func(t *testing.T) {
+		req := &client.HttpRequest{ Method: "HEAD" }
+		res := &client.HttpResponse{ StatusCode: 200, Header: http.Header{ "Vary": []string{"Accept", "Origin"} } }
+		e := g.generateExpectation(req, res)
+		assert.Equal(t, 1, len(e.Headers.Items))
+		assert.Equal(t, []string{"Accept", "Origin"}, e.Headers.Items[0].HasValues)
+		assert.Nil(t, e.Headers.Items[0].Is)
+	})
+}
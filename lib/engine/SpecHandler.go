@@ -2,7 +2,13 @@ package engine
 
 import(
 	"fmt"
+	"net"
+	"net/http"
+	"reflect"
 	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 	"github.com/opwire/opwire-testa/lib/client"
 	"github.com/opwire/opwire-testa/lib/comparison"
@@ -11,22 +17,96 @@ import(
 )
 
 type SpecHandlerOptions interface {
+	GetRateLimit() float64
+	GetRequestDelay() time.Duration
+	GetMaxResponseSize() int64
+	GetClockSkew() time.Duration
+	GetHttp3() bool
+	GetSoftAssertions() bool
+}
+
+// TransportProvider may be implemented by the SpecHandlerOptions of programs
+// embedding the engine to route the requests through a custom RoundTripper.
+type TransportProvider interface {
+	GetTransport() http.RoundTripper
+}
+
+// RateLimiterProvider may be implemented by the SpecHandlerOptions to share a
+// rate limiter between the spec handlers which send requests concurrently.
+type RateLimiterProvider interface {
+	GetRateLimiter() *client.RateLimiter
+}
+
+// EnvironmentProvider may be implemented by the SpecHandlerOptions to send the
+// requests to the PDP of an environment, with its default headers.
+type EnvironmentProvider interface {
+	GetPDP() string
+	GetDefaultHeaders() []client.HttpHeader
 }
 
 type SpecHandler struct {
 	invoker client.HttpInvoker
+	maxResponseSize int64
+	clockSkew time.Duration
+	softAssertions bool
+	verdicts *VerdictCache
+	targetGuard TargetGuard
 }
 
+// TargetGuard is called with the request (its expressions evaluated) before
+// it is sent, the test case fails without sending the request on error.
+type TargetGuard func(testcase *TestCase, req *client.HttpRequest) error
+
 func NewSpecHandler(opts SpecHandlerOptions) (e *SpecHandler, err error) {
-	e = &SpecHandler{}
-	e.invoker, err = client.NewHttpInvoker(nil)
+	e = &SpecHandler{ verdicts: NewVerdictCache() }
+	invokerOptions := &client.HttpInvokerOptions{}
+	if opts != nil {
+		invokerOptions.RateLimit = opts.GetRateLimit()
+		invokerOptions.RequestDelay = opts.GetRequestDelay()
+		invokerOptions.MaxResponseSize = opts.GetMaxResponseSize()
+		e.maxResponseSize = invokerOptions.MaxResponseSize
+		e.clockSkew = opts.GetClockSkew()
+		e.softAssertions = opts.GetSoftAssertions()
+		invokerOptions.Http3 = opts.GetHttp3()
+		if provider, ok := opts.(TransportProvider); ok {
+			invokerOptions.Transport = provider.GetTransport()
+		}
+		if provider, ok := opts.(RateLimiterProvider); ok {
+			invokerOptions.RateLimiter = provider.GetRateLimiter()
+		}
+		if provider, ok := opts.(EnvironmentProvider); ok {
+			invokerOptions.PDP = provider.GetPDP()
+			invokerOptions.DefaultHeaders = provider.GetDefaultHeaders()
+		}
+	}
+	e.invoker, err = client.NewHttpInvoker(invokerOptions)
 	if err != nil {
 		return nil, err
 	}
 	return e, nil
 }
 
-func (e *SpecHandler) Examine(testcase *TestCase, cache *sieve.RestCache) (*ExaminationResult, error) {
+func (e *SpecHandler) SetTargetGuard(guard TargetGuard) {
+	e.targetGuard = guard
+}
+
+// Resolve evaluates the expressions of the request of the test case and
+// completes it as Examine() does, without sending it. The request is returned
+// as written, with the error, when its expressions cannot be evaluated yet
+// (e.g. the variables captured by the other test cases).
+func (e *SpecHandler) Resolve(testcase *TestCase, cache *sieve.RestCache) (*client.HttpRequest, error) {
+	req, err := cache.Apply(testcase.Request)
+	if err != nil {
+		req = testcase.Request.WithMethod(testcase.Request.Method)
+		req.Headers = append([]client.HttpHeader{}, req.Headers...)
+	}
+	if preparer, ok := e.invoker.(client.RequestPreparer); ok {
+		preparer.Prepare(req)
+	}
+	return req, err
+}
+
+func (e *SpecHandler) Examine(testcase *TestCase, cache *sieve.RestCache, session *Session) (*ExaminationResult, error) {
 	if testcase == nil {
 		panic(fmt.Errorf("TestCase must not be nil"))
 	}
@@ -42,14 +122,47 @@ func (e *SpecHandler) Examine(testcase *TestCase, cache *sieve.RestCache) (*Exam
 	// start time
 	startTime := time.Now()
 
-	// transform expression
+	// transform expression, a test case referring to a value which is not
+	// available (a failed capture, a missing response) is not sent
 	req, err := cache.Apply(testcase.Request)
+	result.Request = req
 	if err != nil {
-		panic(err)
+		result.Status = "error"
+		result.Errors = map[string]error{ "Request/Expressions": err }
+		result.Duration = time.Since(startTime)
+		return result, nil
+	}
+
+	if testcase.PreRequest != nil {
+		if err := applyRequestScript(testcase.PreRequest, req); err != nil {
+			result.Status = "error"
+			result.Errors = map[string]error{ "PreRequest": err }
+			result.Duration = time.Since(startTime)
+			return result, nil
+		}
+	}
+
+	if e.targetGuard != nil {
+		if err := e.targetGuard(testcase, req); err != nil {
+			result.Status = "refused"
+			result.Errors = map[string]error{ "Request/Target": err }
+			return result, nil
+		}
+	}
+
+	if len(req.GetSensitiveValues()) > 0 && strings.HasPrefix(strings.ToLower(client.BuildUrl(req)), "http://") {
+		result.Warnings = append(result.Warnings, Warning{
+			Category: WARNING_SECURITY,
+			Message: "Credentials are sent over an unencrypted connection",
+		})
 	}
 
 	// make the testing request
-	res, err := e.invoker.Do(req)
+	interceptors := make([]client.Interceptor, 0)
+	if session != nil {
+		interceptors = append(interceptors, session)
+	}
+	res, err := e.invoke(testcase.Retry, req, interceptors, result)
 	if err != nil {
 		result.Duration = time.Since(startTime)
 		result.Status = "error"
@@ -62,157 +175,671 @@ func (e *SpecHandler) Examine(testcase *TestCase, cache *sieve.RestCache) (*Exam
 
 	// matching with expectation
 	errors := make(map[string]error, 0)
-	expect := testcase.Expectation
+	expect, unresolved := resolveExpectation(testcase.Expectation, cache)
+	soft := expect.isSoft(e.softAssertions)
+	if res.BodyTruncated {
+		if res.ContentLength > 0 {
+			addFailure(errors, "Body/Size", fmt.Errorf("Response body size (%d bytes) exceeds the limit (%d bytes)", res.BodySize, e.maxResponseSize), soft)
+		} else {
+			addFailure(errors, "Body/Size", fmt.Errorf("Response body exceeds the limit (%d bytes)", e.maxResponseSize), soft)
+		}
+	}
+	if len(unresolved) > 0 {
+		addFailure(errors, "Expectation/Variables", fmt.Errorf("Unresolved expressions: %s", strings.Join(unresolved, "; ")), soft)
+	}
+	// the codings claimed by the server are verified whatever the expectation
+	content := res.Body
+	if len(res.GetContentEncodings()) > 0 && len(res.Body) > 0 && !res.BodyTruncated {
+		decompressed, err := res.DecodeContent()
+		if _, unsupported := err.(*client.UnsupportedCodingError); unsupported {
+			result.Warnings = append(result.Warnings, Warning{
+				Category: WARNING_TOLERATED,
+				Message: fmt.Sprintf("%s, the body is matched compressed", err.Error()),
+			})
+		} else if err != nil {
+			addFailure(errors, "ContentEncoding", err, soft)
+		}
+		content = decompressed
+	}
 	if expect != nil {
-		_sc := expect.StatusCode
-		if _sc != nil && _sc.Is != nil {
-			if _sc.Is.EqualTo != nil {
-				if eq, _ := comparison.IsEqualTo(res.StatusCode, _sc.Is.EqualTo); !eq {
-					errors["StatusCode"] = fmt.Errorf("Response StatusCode [%d] is not equal to expected value [%v]", res.StatusCode, _sc.Is.EqualTo)
+		x := &examination{ testcase: testcase, req: req, res: res, content: content, cache: cache, result: result, interceptors: interceptors, startTime: startTime, soft: soft }
+		for key, err := range e.examineExpectation(expect, x) {
+			addFailure(errors, key, err, soft)
+		}
+	}
+	// cache HttpResponse
+	if testcase.Capture != nil && len(testcase.Capture.StoreID) > 0 {
+		_, err := cache.Store(testcase.Capture.StoreID, res)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	// capture the session headers
+	if testcase.Capture != nil && len(testcase.Capture.SessionHeaders) > 0 {
+		for _, header := range testcase.Capture.SessionHeaders {
+			value, errs := cache.EvaluateWithExplanation(header.Value)
+			if len(errs) > 0 {
+				addFailure(errors, "Capture/SessionHeaders/" + header.Name, utils.BuildMultilineError(errs), soft)
+				continue
+			}
+			if session == nil {
+				addFailure(errors, "Capture/SessionHeaders/" + header.Name, fmt.Errorf("TestSuite has no session"), soft)
+				continue
+			}
+			session.SetHeader(header.Name, value)
+		}
+	}
+
+	// normalize the captured identifiers
+	if testcase.Capture != nil && len(testcase.Capture.StoreID) > 0 {
+		for _, rule := range testcase.Capture.Normalize {
+			if err := cache.NormalizeField(testcase.Capture.StoreID, rule.Field, rule.Strategy); err != nil {
+				addFailure(errors, "Capture/Normalize/" + rule.Field, err, soft)
+			}
+		}
+	}
+
+	// capture the variables of the run
+	if testcase.Capture != nil {
+		for _, variable := range testcase.Capture.Variables {
+			value, err := captureVariable(variable, res, content)
+			if err != nil {
+				addFailure(errors, "Capture/Variables/" + variable.Name, err, soft)
+				continue
+			}
+			cache.StoreVariable(variable.Name, value)
+		}
+	}
+
+	downgradeFailures(errors, expect.getWarningKeys(), result)
+
+	result.Errors = errors
+
+	if len(errors) == 0 {
+		result.Status = "ok"
+	} else {
+		result.Status = "error"
+	}
+
+	result.Duration = time.Since(startTime)
+	return result, nil
+}
+
+// examination holds the exchange which the expectations are matched against.
+type examination struct {
+	testcase *TestCase
+	req *client.HttpRequest
+	res *client.HttpResponse
+	content []byte
+	cache *sieve.RestCache
+	result *ExaminationResult
+	interceptors []client.Interceptor
+	startTime time.Time
+	soft bool
+}
+
+func (e *SpecHandler) examineExpectation(expect *Expectation, x *examination) map[string]error {
+	errors := make(map[string]error, 0)
+	testcase, req, res, content, cache, result := x.testcase, x.req, x.res, x.content, x.cache, x.result
+	interceptors, startTime, soft := x.interceptors, x.startTime, x.soft
+	_sc := expect.StatusCode
+	if _sc != nil {
+		if err := examineStatusCode(res.StatusCode, _sc); err != nil {
+			addFailure(errors, "StatusCode", err, soft)
+		}
+	}
+	if _sc != nil && _sc.Is != nil {
+		if _sc.Is.EqualTo != nil {
+			if eq, _ := comparison.IsEqualTo(res.StatusCode, _sc.Is.EqualTo); !eq {
+				addFailure(errors, "StatusCode", fmt.Errorf("Response StatusCode [%d] is not equal to expected value [%v]", res.StatusCode, _sc.Is.EqualTo), soft)
+			}
+		}
+		if _sc.Is.MemberOf != nil {
+			if !comparison.BelongsTo(res.StatusCode, _sc.Is.MemberOf) {
+				addFailure(errors, "StatusCode", fmt.Errorf("Response StatusCode [%d] must belong to inclusive list %v", res.StatusCode, _sc.Is.MemberOf), soft)
+			}
+		}
+		if _sc.Is.NotMemberOf != nil {
+			if comparison.BelongsTo(res.StatusCode, _sc.Is.NotMemberOf) {
+				addFailure(errors, "StatusCode", fmt.Errorf("Response StatusCode [%d] must not belong to exclusive list %v", res.StatusCode, _sc.Is.NotMemberOf), soft)
+			}
+		}
+	}
+	if err, failed := errors["StatusCode"]; failed && _sc != nil {
+		errors["StatusCode"] = withMessage(_sc.Message, err, failureContext{ Name: "StatusCode", Expected: _sc.getExpected(), Actual: res.StatusCode })
+	}
+	if expect.StatusText != nil {
+		if err := examineStatusText(expect.StatusText, res); err != nil {
+			addFailure(errors, "StatusText", err, soft)
+		}
+	}
+	if expect.Headers != nil {
+		for key, err := range examineHeaders(expect.Headers, res.Header, "Header", cache, result, soft, e.clockSkew) {
+			addFailure(errors, key, err, soft)
+		}
+	}
+	if expect.Trailers != nil {
+		for key, err := range examineHeaders(expect.Trailers, res.Trailer, "Trailer", cache, result, soft, e.clockSkew) {
+			addFailure(errors, key, err, soft)
+		}
+	}
+	if len(expect.Cookies) > 0 {
+		for key, err := range examineCookies(expect.Cookies, res.Cookies()) {
+			addFailure(errors, key, err, soft)
+		}
+	}
+	if _mv := expect.getVersion(); _mv != nil {
+		if err := examineVersion(_mv, res.Version); err != nil {
+			addFailure(errors, "Version", err, soft)
+		}
+	}
+	_nw := expect.Network
+	if _nw != nil {
+		network := res.Network
+		if _nw.RemoteIP != nil && !matchIP(network.RemoteIP, *_nw.RemoteIP) {
+			addFailure(errors, "Network/RemoteIP", fmt.Errorf("Remote IP [%s] is mismatched with expected: [%s]", network.RemoteIP, *_nw.RemoteIP), soft)
+		}
+		if _nw.TLSVersion != nil && network.TLSVersion != *_nw.TLSVersion {
+			addFailure(errors, "Network/TLSVersion", fmt.Errorf("TLS version [%s] is mismatched with expected: [%s]", network.TLSVersion, *_nw.TLSVersion), soft)
+		}
+		if _nw.CipherSuite != nil && network.CipherSuite != *_nw.CipherSuite {
+			addFailure(errors, "Network/CipherSuite", fmt.Errorf("Cipher suite [%s] is mismatched with expected: [%s]", network.CipherSuite, *_nw.CipherSuite), soft)
+		}
+		if _nw.ALPN != nil && network.ALPN != *_nw.ALPN {
+			addFailure(errors, "Network/ALPN", fmt.Errorf("Negotiated protocol [%s] is mismatched with expected: [%s]", network.ALPN, *_nw.ALPN), soft)
+		}
+	}
+	if expect.Certificate != nil {
+		for key, err := range examineCertificate(expect.Certificate, res.Network.Certificates, time.Now(), e.clockSkew) {
+			addFailure(errors, key, err, soft)
+		}
+	}
+	if expect.ContentEncoding != nil {
+		for key, err := range examineContentEncoding(expect.ContentEncoding, res) {
+			addFailure(errors, key, err, soft)
+		}
+	}
+	if expect.Charset != nil {
+		for key, err := range examineCharset(expect.Charset, res) {
+			addFailure(errors, key, err, soft)
+		}
+	}
+	if expect.Redirects != nil {
+		for key, err := range examineRedirects(expect.Redirects, res.Redirects) {
+			addFailure(errors, key, err, soft)
+		}
+	}
+	if expect.GraphQL != nil {
+		for key, err := range examineGraphQL(expect.GraphQL, res.Body, soft, e.clockSkew) {
+			addFailure(errors, key, err, soft)
+		}
+	}
+	if _cm := expect.Custom; _cm != nil && _cm.Name != nil {
+		if err := examineCustom(_cm, res); err != nil {
+			addFailure(errors, fmt.Sprintf("Custom[%s]", *_cm.Name), err, soft)
+		}
+	}
+	if len(expect.Asserts) > 0 {
+		for key, err := range examineAsserts(expect.Asserts, res, time.Since(startTime)) {
+			addFailure(errors, key, err, soft)
+		}
+	}
+	if expect.Script != nil {
+		if err := examineScript(expect.Script, req, res, time.Since(startTime)); err != nil {
+			addFailure(errors, "Script", err, soft)
+		}
+	}
+	_gc := expect.GotContinue
+	if _gc != nil {
+		if *_gc && !res.GotContinue {
+			addFailure(errors, "GotContinue", fmt.Errorf("Server has not issued the interim [100 Continue] response"), soft)
+		}
+		if !*_gc && res.GotContinue {
+			addFailure(errors, "GotContinue", fmt.Errorf("Server has issued an unexpected interim [100 Continue] response"), soft)
+		}
+	}
+	_dt := expect.Date
+	if _dt != nil && _dt.FreshWithin != nil {
+		if within, err := time.ParseDuration(*_dt.FreshWithin); err == nil {
+			if err := examineFreshness(res.Header.Get("Date"), within, e.clockSkew); err != nil {
+				addFailure(errors, "Date/FreshWithin", err, soft)
+			}
+		} else {
+			addFailure(errors, "Date/Expectation", fmt.Errorf("Invalid duration [%s], error: %s", *_dt.FreshWithin, err.Error()), soft)
+		}
+	}
+	_am := expect.AllowMethods
+	if _am != nil {
+		allowed := client.ParseAllowedMethods(res.Header)
+		for _, method := range _am.Includes {
+			if !utils.Contains(allowed, strings.ToUpper(method)) {
+				addFailure(errors, "AllowMethods/" + method, fmt.Errorf("Method [%s] is not allowed, allowed methods: %v", method, allowed), soft)
+			}
+		}
+		for _, method := range _am.Excludes {
+			if utils.Contains(allowed, strings.ToUpper(method)) {
+				addFailure(errors, "AllowMethods/" + method, fmt.Errorf("Method [%s] must not be allowed, allowed methods: %v", method, allowed), soft)
+			}
+		}
+	}
+	_bs := expect.BodySize
+	if _bs != nil && _bs.Is != nil {
+		if err := examineNumber(res.BodySize, _bs.Is); err != nil {
+			addFailure(errors, "BodySize", fmt.Errorf("Response body size: %s", err.Error()), soft)
+		}
+	}
+	if expect.ContentLength != nil && expect.ContentLength.IsEqualTo != nil {
+		if err := examineContentLength(res, *expect.ContentLength.IsEqualTo); err != nil {
+			addFailure(errors, "ContentLength", err, soft)
+		}
+	}
+	if expect.PairedGet != nil && *expect.PairedGet {
+		if err := e.examinePairedGet(req, res, interceptors); err != nil {
+			addFailure(errors, "PairedGet", err, soft)
+		}
+	}
+	if expect.Ranges != nil && expect.Ranges.ChunkSize != nil {
+		for key, err := range e.examineRanges(*expect.Ranges.ChunkSize, req, res, interceptors) {
+			addFailure(errors, key, err, soft)
+		}
+	}
+	if expect.Body != nil && expect.Body.IsEmpty != nil {
+		if *expect.Body.IsEmpty && res.BodySize > 0 {
+			addFailure(errors, "Body/IsEmpty", fmt.Errorf("Response body must be empty, received %d bytes", res.BodySize), soft)
+		}
+		if !*expect.Body.IsEmpty && res.BodySize == 0 {
+			addFailure(errors, "Body/IsEmpty", fmt.Errorf("Response body must not be empty"), soft)
+		}
+	}
+	if expect.Body != nil && expect.Body.HasSize != nil {
+		withBody := strings.ToUpper(req.Method) != http.MethodHead && res.StatusCode != http.StatusNotModified
+		for key, err := range examineBodySize(res, expect.Body.HasSize, withBody) {
+			addFailure(errors, key, err, soft)
+		}
+	}
+	_eb := expect.Body
+	if res.BodyTruncated {
+		// the truncated content is irrelevant to the body matchers
+		_eb = nil
+	}
+	if res.StatusCode == http.StatusNotModified {
+		// a 304 response carries no body to be matched
+		_eb = nil
+	}
+	if _eb != nil && strings.ToUpper(req.Method) == http.MethodHead {
+		if hasBodyMatchers(_eb) {
+			addFailure(errors, "Body/Expectation", fmt.Errorf("Body matchers are not applicable to the response of a HEAD request"), soft)
+		}
+		_eb = nil
+	}
+	body := content
+	if _eb != nil {
+		decoded, err := decodeBody(res.Header, content)
+		if err != nil {
+			result.Warnings = append(result.Warnings, Warning{
+				Category: WARNING_TOLERATED,
+				Message: fmt.Sprintf("%s, the body is matched undecoded", err.Error()),
+			})
+		}
+		body = decoded
+	}
+	if _eb != nil {
+		for key, err := range examineNegatedBody(_eb, body) {
+			addFailure(errors, key, err, soft)
+		}
+	}
+	if _eb != nil && (_eb.StartsWith != nil || _eb.EndsWith != nil) {
+		if err := examineAffixes(string(body), _eb.StartsWith, _eb.EndsWith, false); err != nil {
+			addFailure(errors, "Body/Affixes", fmt.Errorf("Response body: %s", err.Error()), soft)
+		}
+	}
+	if _eb != nil && hasLineMatchers(_eb) {
+		for key, err := range examineLines(_eb, body, soft) {
+			addFailure(errors, key, err, soft)
+		}
+	}
+	if _eb != nil && hasBodyHashes(_eb) {
+		for key, err := range examineBodyHashes(_eb, res.Body) {
+			addFailure(errors, key, err, soft)
+		}
+	}
+	if _eb != nil && _eb.HasFormat != nil {
+		var format string = *_eb.HasFormat
+		if format == utils.BODY_FORMAT_FLAT {
+			var hold bool
+			if _eb.IsEqualTo != nil && _eb.isCanonical() {
+				hold = true
+				for key, err := range examineCanonicalBody(_eb, format, body) {
+					addFailure(errors, key, err, soft)
 				}
 			}
-			if _sc.Is.MemberOf != nil {
-				if !comparison.BelongsTo(res.StatusCode, _sc.Is.MemberOf) {
-					errors["StatusCode"] = fmt.Errorf("Response StatusCode [%d] must belong to inclusive list %v", res.StatusCode, _sc.Is.MemberOf)
+			if _eb.IsEqualTo != nil && !_eb.isCanonical() {
+				hold = true
+				if different, diff := comparison.LineDiff(*_eb.IsEqualTo, string(body)); different {
+					addFailure(errors, "Body/IsEqualTo", newBodyMismatch(format, diff), soft)
 				}
 			}
-			if _sc.Is.NotMemberOf != nil {
-				if comparison.BelongsTo(res.StatusCode, _sc.Is.NotMemberOf) {
-					errors["StatusCode"] = fmt.Errorf("Response StatusCode [%d] must not belong to exclusive list %v", res.StatusCode, _sc.Is.MemberOf)
+			if _eb.MatchWith != nil {
+				hold = true
+				_rb := string(body)
+				if reg, err := regexp.Compile(*_eb.MatchWith); err == nil {
+					if !reg.MatchString(_rb) {
+						addFailure(errors, "Body/MatchWith", fmt.Errorf("[%s] Response body is mismatched with the pattern.\nReceived: %s\nPattern: %s", format, _rb, *_eb.MatchWith), soft)
+					}
+				} else {
+					addFailure(errors, "Body/Expectation", fmt.Errorf("[%s] Invalid regular expression[%s], error: %s", format, *_eb.MatchWith, err.Error()), soft)
 				}
 			}
+			if !hold {
+				addFailure(errors, "Body/Expectation", fmt.Errorf("[%s] One of [%s] attributes must be provided", format, "is-equal-to, match-with"), soft)
+			}
+		}
+		if format == utils.BODY_FORMAT_XML {
+			for key, err := range examineXml(_eb, body, soft, e.clockSkew) {
+				addFailure(errors, key, err, soft)
+			}
+		}
+		if format == utils.BODY_FORMAT_CSV {
+			for key, err := range examineCsv(_eb, body, soft, e.clockSkew) {
+				addFailure(errors, key, err, soft)
+			}
+		}
+		if format == utils.BODY_FORMAT_HTML {
+			for key, err := range examineHtml(_eb, body, soft, e.clockSkew) {
+				addFailure(errors, key, err, soft)
+			}
 		}
-		_hs := expect.Headers
-		if _hs != nil {
-			if _hs.Total != nil && _hs.Total.Is != nil {
-				headerTotal := len(res.Header)
-				totalIs := _hs.Total.Is
-				if totalIs.EqualTo != nil {
-					if eq, _ := comparison.IsEqualTo(headerTotal, totalIs.EqualTo); !eq {
-						errors["Header/Total"] = fmt.Errorf("Total of headers (%d) mismatchs with expected number (%v)", headerTotal, totalIs.EqualTo)
+		multiDoc := format == utils.BODY_FORMAT_YAML && isMultiDocumentYaml(_eb, body)
+		if multiDoc {
+			for key, err := range examineYamlDocuments(_eb, body, soft, e.clockSkew) {
+				addFailure(errors, key, err, soft)
+			}
+		}
+		if !multiDoc && (format == utils.BODY_FORMAT_JSON || format == utils.BODY_FORMAT_YAML) {
+			var receivedObj, expectedObj map[string]interface{}
+			next := true
+			if (body == nil) {
+				addFailure(errors, "Body/ReceivedObject", fmt.Errorf("[%s] Response body is empty", format), soft)
+				next = false
+			} else if err := utils.Unmarshal(format, body, &receivedObj); err != nil {
+				addFailure(errors, "Body/ReceivedObject", fmt.Errorf("[%s] Invalid response content: %s", format, err), soft)
+				next = false
+			}
+			// the ignored fields are stripped from a copy of the received object
+			comparedObj := receivedObj
+			ignoredKey := ""
+			if next && len(_eb.IgnoreFields) > 0 && (_eb.IsEqualTo != nil || _eb.Includes != nil) {
+				ignoredKey = "/" + strings.Join(_eb.IgnoreFields, ",")
+				comparedObj = nil
+				utils.Unmarshal(format, body, &comparedObj)
+				if err := removeIgnoredFields(_eb.IgnoreFields, comparedObj); err != nil {
+					addFailure(errors, "Body/IgnoreFields", fmt.Errorf("[%s] %s", format, err.Error()), soft)
+					next = soft
+				}
+			}
+			if next && _eb.IsEqualTo != nil && _eb.isCanonical() {
+				if format != utils.BODY_FORMAT_JSON {
+					addFailure(errors, "Body/Expectation", fmt.Errorf("[%s] The [canonicalize] attribute is only applicable to JSON content", format), soft)
+				} else {
+					for key, err := range examineCanonicalBody(_eb, format, body) {
+						addFailure(errors, key, err, soft)
 					}
 				}
 			}
-			if _hs.Items != nil {
-				for _, item := range _hs.Items {
-					headerVal := res.Header.Get(*item.Name)
-					if item.Is != nil && item.Is.EqualTo != nil {
-						eq, _ := comparison.IsEqualTo(headerVal, item.Is.EqualTo)
-						if !eq {
-							errors[fmt.Sprintf("Header[%s]", *item.Name)] = fmt.Errorf("Returned value: [%s] is mismatched with expected: [%s]", headerVal, item.Is.EqualTo)
-						}
+			if next && _eb.IsEqualTo != nil && !_eb.isCanonical() {
+				valid := true
+				if err := utils.Unmarshal(format, []byte(*_eb.IsEqualTo), &expectedObj); err != nil {
+					addFailure(errors, "Body/ExpectedObject", fmt.Errorf("[%s] Invalid expected content: %s", format, err), soft)
+					next, valid = soft, false
+				}
+				if valid {
+					removeIgnoredFields(_eb.IgnoreFields, expectedObj)
+					ok, diff := e.verdicts.Evaluate("Body/IsEqualTo/" + format + ignoredKey, *_eb.IsEqualTo, body, func() (bool, string) {
+						different, diff := comparison.DeepDiff(expectedObj, comparedObj)
+						return !different, diff
+					})
+					if !ok {
+						addFailure(errors, "Body/IsEqualTo", newBodyMismatch(format, diff), soft)
 					}
 				}
 			}
-		}
-		_eb := expect.Body
-		if _eb != nil && _eb.HasFormat != nil {
-			var format string = *_eb.HasFormat
-			if format == utils.BODY_FORMAT_FLAT {
-				var hold bool
-				if _eb.IsEqualTo != nil {
-					hold = true
-					_rb := string(res.Body)
-					if _rb != *_eb.IsEqualTo {
-						errors["Body/IsEqualTo"] = fmt.Errorf("[%s] Response body is mismatched with expected content.\nReceived: %s\nExpected: %s", format, _rb, *_eb.IsEqualTo)
+			if next && _eb.Includes != nil {
+				valid := true
+				if err := utils.Unmarshal(format, []byte(*_eb.Includes), &expectedObj); err != nil {
+					addFailure(errors, "Body/ExpectedObject", fmt.Errorf("[%s] Invalid expected content: %s", format, err), soft)
+					next, valid = soft, false
+				}
+				if valid {
+					removeIgnoredFields(_eb.IgnoreFields, expectedObj)
+					ok, diff := e.verdicts.Evaluate("Body/Includes/" + format + ignoredKey, *_eb.Includes, body, func() (bool, string) {
+						return comparison.IsPartOf(expectedObj, comparedObj)
+					})
+					if !ok {
+						addFailure(errors, "Body/Includes", newBodyMismatch(format, diff), soft)
 					}
 				}
-				if _eb.MatchWith != nil {
-					hold = true
-					_rb := string(res.Body)
-					if reg, err := regexp.Compile(*_eb.MatchWith); err == nil {
-						if !reg.MatchString(_rb) {
-							errors["Body/MatchWith"] = fmt.Errorf("[%s] Response body is mismatched with the pattern.\nReceived: %s\nPattern: %s", format, _rb, *_eb.MatchWith)
+			}
+			if next && _eb.HasSchema != nil {
+				for key, err := range e.examineSchema(*_eb.HasSchema, testcase.home, body, receivedObj) {
+					addFailure(errors, key, err, soft)
+				}
+			}
+			if next && len(_eb.Fields) > 0 {
+				eFields := _eb.Fields
+				rFields, _ := utils.Flatten("", receivedObj)
+				for _, eField := range eFields {
+					if eField.Path == nil {
+						continue
+					}
+					fieldKey := "Body/Fields/" + *eField.Path
+					var rValue interface{}
+					var found bool
+					if strings.HasPrefix(*eField.Path, "$") {
+						var err error
+						rValue, found, err = utils.EvaluateJsonPath(*eField.Path, receivedObj)
+						if err != nil {
+							addFailure(errors, fieldKey, err, soft)
+							continue
 						}
 					} else {
-						errors["Body/Expectation"] = fmt.Errorf("[%s] Invalid regular expression[%s], error: %s", format, *_eb.MatchWith, err.Error())
+						rValue, found = rFields[*eField.Path]
+					}
+					if err := examineBodyField(eField, rValue, found, soft, e.clockSkew); err != nil {
+						addFailure(errors, fieldKey, err, soft)
 					}
 				}
-				if !hold {
-					errors["Body/Expectation"] = fmt.Errorf("[%s] One of [%s] attributes must be provided", format, "is-equal-to, matches")
+			}
+		}
+	} else {
+		if _eb != nil && _eb.HasFormat == nil && (_eb.IsEqualTo != nil || _eb.Includes != nil) {
+			addFailure(errors, "Body/Expectation", fmt.Errorf("Unknown body format, please provides [has-format] value"), soft)
+		}
+	}
+	if hasGroups(expect) {
+		for key, err := range e.examineGroups(expect, x) {
+			addFailure(errors, key, err, soft)
+		}
+	}
+	return errors
+}
+
+// examineHeaders verifies the headers (or the trailers) of a response, the
+// kind prefixes the keys of the errors, the failures of an item are listed
+// together in the soft-assertion mode.
+func examineHeaders(_hs *MeasureHeaders, header http.Header, kind string, cache *sieve.RestCache, result *ExaminationResult, soft bool, skew time.Duration) map[string]error {
+	errors := make(map[string]error, 0)
+	if _hs.Total != nil && _hs.Total.Is != nil {
+		headerTotal := len(header)
+		totalIs := _hs.Total.Is
+		if totalIs.EqualTo != nil {
+			if eq, _ := comparison.IsEqualTo(headerTotal, totalIs.EqualTo); !eq {
+				addFailure(errors, kind + "/Total", fmt.Errorf("Total of headers (%d) mismatchs with expected number (%v)", headerTotal, totalIs.EqualTo), soft)
+			}
+		}
+	}
+	for _, name := range _hs.Forbidden {
+		if _, present := header[http.CanonicalHeaderKey(name)]; present {
+			addFailure(errors, fmt.Sprintf("%s[%s]", kind, name), fmt.Errorf("Forbidden header is returned with value: [%s]", header.Get(name)), soft)
+		}
+	}
+	if _hs.Items != nil {
+		for _, item := range _hs.Items {
+			headerVal := header.Get(*item.Name)
+			if item.IsAbsent != nil {
+				_, present := header[http.CanonicalHeaderKey(*item.Name)]
+				if *item.IsAbsent && present {
+					addFailure(errors, fmt.Sprintf("%s[%s]", kind, *item.Name), fmt.Errorf("Header must be absent, returned value: [%s]", headerVal), soft)
+				}
+				if !*item.IsAbsent && !present {
+					addFailure(errors, fmt.Sprintf("%s[%s]", kind, *item.Name), fmt.Errorf("Header must be present"), soft)
 				}
 			}
-			if format == utils.BODY_FORMAT_JSON || format == utils.BODY_FORMAT_YAML {
-				var receivedObj, expectedObj map[string]interface{}
-				next := true
-				if (res.Body == nil) {
-					errors["Body/ReceivedObject"] = fmt.Errorf("[%s] Response body is empty", format)
-					next = false
-				} else if err := utils.Unmarshal(format, res.Body, &receivedObj); err != nil {
-					errors["Body/ReceivedObject"] = fmt.Errorf("[%s] Invalid response content: %s", format, err)
-					next = false
+			if item.Is != nil && item.Is.EqualTo != nil {
+				expected := fmt.Sprintf("%v", item.Is.EqualTo)
+				eq := compareHeader(headerVal, expected, item)
+				if eq && headerVal != expected {
+					result.Warnings = append(result.Warnings, Warning{
+						Category: WARNING_TOLERATED,
+						Message: fmt.Sprintf("%s[%s] value [%s] is tolerated as [%s]", kind, *item.Name, headerVal, expected),
+					})
 				}
-				if next && _eb.IsEqualTo != nil {
-					if err := utils.Unmarshal(format, []byte(*_eb.IsEqualTo), &expectedObj); err != nil {
-						errors["Body/ExpectedObject"] = fmt.Errorf("[%s] Invalid expected content: %s", format, err)
-						next = false
-					}
-					if next {
-						ok, diff := comparison.DeepDiff(expectedObj, receivedObj)
-						if !ok {
-							errors["Body/IsEqualTo"] = fmt.Errorf("[%s] Body mismatch (-expected +received):\n%s", format, diff)
-						}
-					}
+				if !eq {
+					addFailure(errors, fmt.Sprintf("%s[%s]", kind, *item.Name), fmt.Errorf("Returned value: [%s] is mismatched with expected: [%s]", headerVal, item.Is.EqualTo), soft)
 				}
-				if next && _eb.Includes != nil {
-					if err := utils.Unmarshal(format, []byte(*_eb.Includes), &expectedObj); err != nil {
-						errors["Body/ExpectedObject"] = fmt.Errorf("[%s] Invalid expected content: %s", format, err)
-						next = false
-					}
-					if next {
-						ok, diff := comparison.IsPartOf(expectedObj, receivedObj)
-						if !ok {
-							errors["Body/Includes"] = fmt.Errorf("[%s] Body mismatch (-expected +received):\n%s", format, diff)
-						}
-					}
+			}
+			if item.Matches != nil {
+				pattern := *item.Matches
+				if item.IgnoreCase != nil && *item.IgnoreCase {
+					pattern = "(?i)" + pattern
 				}
-				if next && len(_eb.Fields) > 0 {
-					eFields := _eb.Fields
-					rFields, _ := utils.Flatten("", receivedObj)
-					for _, eField := range eFields {
-						if eField.Is != nil && eField.Is.EqualTo != nil {
-							eValue := eField.Is.EqualTo
-							if rValue, ok := rFields[*eField.Path]; ok {
-								if eq, _ := comparison.IsEqualTo(rValue, eValue); !eq {
-									errors["Body/Fields/" + *eField.Path] = fmt.Errorf("Field mismatch expected: %v / received: %v", eValue, rValue)
-								}
-							} else {
-								errors["Body/Fields/" + *eField.Path] = fmt.Errorf("Field not found, expected: %v", eValue)
-							}
-						}
+				if item.Trim != nil && *item.Trim {
+					headerVal = strings.TrimSpace(headerVal)
+				}
+				groups, err := matchHeader(headerVal, pattern)
+				if err != nil {
+					addFailure(errors, fmt.Sprintf("%s[%s]", kind, *item.Name), err, soft)
+				}
+				for name, value := range groups {
+					if sieve.IsReservedVariable(name) {
+						addFailure(errors, fmt.Sprintf("%s[%s]", kind, *item.Name), fmt.Errorf("Group name [%s] is reserved", name), soft)
+						continue
 					}
+					cache.StoreVariable(name, value)
 				}
 			}
-		} else {
-			if _eb.HasFormat == nil && (_eb.IsEqualTo != nil || _eb.Includes != nil) {
-				errors["Body/Expectation"] = fmt.Errorf("Unknown body format, please provides [has-format] value")
+			if len(item.IsOneOf) > 0 {
+				_, present := header[http.CanonicalHeaderKey(*item.Name)]
+				if !present {
+					addFailure(errors, fmt.Sprintf("%s[%s]", kind, *item.Name), fmt.Errorf("Header must be present, expected one of: %v", item.IsOneOf), soft)
+				} else if !isOneOfHeaders(headerVal, item) {
+					addFailure(errors, fmt.Sprintf("%s[%s]", kind, *item.Name), fmt.Errorf("Returned value: [%s] is not one of: %v", headerVal, item.IsOneOf), soft)
+				}
+			}
+			if item.StartsWith != nil || item.EndsWith != nil {
+				if _, present := header[http.CanonicalHeaderKey(*item.Name)]; !present {
+					addFailure(errors, fmt.Sprintf("%s[%s]", kind, *item.Name), fmt.Errorf("Header must be present"), soft)
+				} else if err := examineAffixes(headerVal, item.StartsWith, item.EndsWith, item.IgnoreCase != nil && *item.IgnoreCase); err != nil {
+					addFailure(errors, fmt.Sprintf("%s[%s]", kind, *item.Name), err, soft)
+				}
+			}
+			if err := examineNegatedHeader(item, headerVal); err != nil {
+				addFailure(errors, fmt.Sprintf("%s[%s]", kind, *item.Name), err, soft)
+			}
+			if hasHeaderValuesMatchers(item) {
+				if err := examineHeaderValues(item, header[http.CanonicalHeaderKey(*item.Name)]); err != nil {
+					addFailure(errors, fmt.Sprintf("%s[%s]", kind, *item.Name), err, soft)
+				}
+			}
+			if hasTimestampMatchers(item.MeasureTimestamp) {
+				if _, present := header[http.CanonicalHeaderKey(*item.Name)]; !present {
+					addFailure(errors, fmt.Sprintf("%s[%s]", kind, *item.Name), fmt.Errorf("Header must be present"), soft)
+				} else if err := examineTimestamp(item.MeasureTimestamp, strings.TrimSpace(headerVal), time.Now(), skew); err != nil {
+					addFailure(errors, fmt.Sprintf("%s[%s]", kind, *item.Name), err, soft)
+				}
+			}
+			if err, failed := errors[fmt.Sprintf("%s[%s]", kind, *item.Name)]; failed {
+				errors[fmt.Sprintf("%s[%s]", kind, *item.Name)] = withMessage(item.Message, err, failureContext{ Name: *item.Name, Expected: item.getExpected(), Actual: headerVal })
 			}
 		}
 	}
-	result.Errors = errors
+	return errors
+}
 
-	if len(errors) == 0 {
-		result.Status = "ok"
-	} else {
-		result.Status = "error"
+// examineFreshness checks that a HTTP date is not farther from the local clock
+// than the allowed duration, widened by the clock-skew allowance.
+func examineFreshness(value string, within time.Duration, skew time.Duration) error {
+	if len(value) == 0 {
+		return fmt.Errorf("Response has no date")
 	}
+	moment, err := http.ParseTime(value)
+	if err != nil {
+		return fmt.Errorf("Invalid date [%s], error: %s", value, err.Error())
+	}
+	distance := time.Since(moment)
+	if distance < 0 {
+		distance = -distance
+	}
+	if distance > within + skew {
+		return fmt.Errorf("Date [%s] is %s away from the local clock, expected within %s (clock skew: %s)", value, distance.Round(time.Millisecond), within, skew)
+	}
+	return nil
+}
 
-	// cache HttpResponse
-	if testcase.Capture != nil && len(testcase.Capture.StoreID) > 0 {
-		_, err := cache.Store(testcase.Capture.StoreID, res)
-		if err != nil {
-			panic(err)
-		}
+// matchIP compares an address with an IP or with a CIDR block.
+func matchIP(address string, expected string) bool {
+	if _, block, err := net.ParseCIDR(expected); err == nil {
+		ip := net.ParseIP(address)
+		return ip != nil && block.Contains(ip)
 	}
+	if ip := net.ParseIP(expected); ip != nil {
+		return ip.Equal(net.ParseIP(address))
+	}
+	return address == expected
+}
 
-	result.Duration = time.Since(startTime)
-	return result, nil
+func examineNumber(value interface{}, is *ComparisonOperators) error {
+	if is.EqualTo != nil {
+		if r, err := comparison.CompareNumbers(value, is.EqualTo); err != nil || r != 0 {
+			return fmt.Errorf("[%v] is not equal to expected value [%v]", value, is.EqualTo)
+		}
+	}
+	if is.NotEqualTo != nil {
+		if r, err := comparison.CompareNumbers(value, is.NotEqualTo); err != nil || r == 0 {
+			return fmt.Errorf("[%v] must not be equal to [%v]", value, is.NotEqualTo)
+		}
+	}
+	if is.LT != nil {
+		if r, err := comparison.CompareNumbers(value, is.LT); err != nil || r >= 0 {
+			return fmt.Errorf("[%v] must be less than [%v]", value, is.LT)
+		}
+	}
+	if is.LTE != nil {
+		if r, err := comparison.CompareNumbers(value, is.LTE); err != nil || r > 0 {
+			return fmt.Errorf("[%v] must be less than or equal to [%v]", value, is.LTE)
+		}
+	}
+	if is.GT != nil {
+		if r, err := comparison.CompareNumbers(value, is.GT); err != nil || r <= 0 {
+			return fmt.Errorf("[%v] must be greater than [%v]", value, is.GT)
+		}
+	}
+	if is.GTE != nil {
+		if r, err := comparison.CompareNumbers(value, is.GTE); err != nil || r < 0 {
+			return fmt.Errorf("[%v] must be greater than or equal to [%v]", value, is.GTE)
+		}
+	}
+	return nil
 }
 
 type TestSuite struct {
 	TestCases []*TestCase `yaml:"testcases" json:"testcases"`
 	Pending *bool `yaml:"pending,omitempty" json:"pending"`
+	Session *SessionConfig `yaml:"session,omitempty" json:"session"`
+	BeforeEach []*TestCase `yaml:"before-each,omitempty" json:"before-each"`
+	AfterEach []*TestCase `yaml:"after-each,omitempty" json:"after-each"`
 	resultCache *sieve.RestCache
+	session *Session
 }
 
 func (r *TestSuite) GetResultCache() (*sieve.RestCache) {
@@ -222,6 +849,23 @@ func (r *TestSuite) GetResultCache() (*sieve.RestCache) {
 	return r.resultCache
 }
 
+// SetHome records the directory of the testsuite file, which the relative
+// paths of the testcases are resolved against.
+func (r *TestSuite) SetHome(home string) {
+	for _, testcase := range append(append(append([]*TestCase{}, r.TestCases...), r.BeforeEach...), r.AfterEach...) {
+		if testcase != nil {
+			testcase.home = home
+		}
+	}
+}
+
+func (r *TestSuite) GetSession() (*Session) {
+	if r.session == nil && r.Session != nil {
+		r.session, _ = NewSession(r.Session)
+	}
+	return r.session
+}
+
 type TestCase struct {
 	Title string `yaml:"title" json:"title"`
 	Version *string `yaml:"version,omitempty" json:"version"`
@@ -229,27 +873,170 @@ type TestCase struct {
 	Capture *SectionCapture `yaml:"capture" json:"capture"`
 	Expectation *Expectation `yaml:"expectation" json:"expectation"`
 	Pending *bool `yaml:"pending,omitempty" json:"pending"`
+	Destructive *bool `yaml:"destructive,omitempty" json:"destructive"`
 	Tags []string `yaml:"tags,omitempty" json:"tags"`
 	CreatedTime *string `yaml:"created-time,omitempty" json:"created-time"`
+	Barrier *string `yaml:"barrier,omitempty" json:"barrier"`
+	DependsOn []string `yaml:"depends-on,omitempty" json:"depends-on"`
+	DataFile *string `yaml:"data-file,omitempty" json:"data-file"`
+	SkipIf *string `yaml:"skip-if,omitempty" json:"skip-if"`
+	Retry *SectionRetry `yaml:"retry,omitempty" json:"retry"`
+	RetryFailed *bool `yaml:"retry-failed,omitempty" json:"retry-failed"`
+	Repeat *SectionRepeat `yaml:"repeat,omitempty" json:"repeat"`
+	PreRequest *MeasureScript `yaml:"pre-request,omitempty" json:"pre-request"`
+	home string
+	prerequisites []*TestCase
+	rows []map[string]string
+	origin *TestCase
+	skipCondition *Condition
+}
+
+func (t *TestCase) IsDestructive() bool {
+	return t.Destructive != nil && *t.Destructive
+}
+
+// IsRetryable reports whether a failed test case may be examined again, the
+// test cases sending a non-idempotent request must opt in by retry-failed.
+func (t *TestCase) IsRetryable() bool {
+	if t.RetryFailed != nil {
+		return *t.RetryFailed
+	}
+	return t.Request != nil && t.Request.IsIdempotent()
+}
+
+func (t *TestCase) GetBarrier() string {
+	if t.Barrier == nil {
+		return ""
+	}
+	return *t.Barrier
+}
+
+type SectionRetry struct {
+	Attempts int `yaml:"attempts,omitempty" json:"attempts,omitempty"`
+	Backoff string `yaml:"backoff,omitempty" json:"backoff,omitempty"`
+	MaxWait string `yaml:"max-wait,omitempty" json:"max-wait,omitempty"`
 }
 
 type SectionCapture struct {
 	StoreID string `yaml:"store-id,omitempty" json:"store-id"`
+	SessionHeaders []client.HttpHeader `yaml:"session-headers,omitempty" json:"session-headers"`
+	Normalize []NormalizeRule `yaml:"normalize,omitempty" json:"normalize"`
+	Variables []CaptureVariable `yaml:"variables,omitempty" json:"variables"`
+}
+
+type NormalizeRule struct {
+	Field string `yaml:"field" json:"field"`
+	Strategy string `yaml:"strategy" json:"strategy"`
+}
+
+// CaptureVariable extracts a value of the response (by one of JsonPath, Header
+// or Regex) into the variables of the run, available as ${{vars.<name>}}.
+type CaptureVariable struct {
+	Name string `yaml:"name" json:"name"`
+	JsonPath *string `yaml:"json-path,omitempty" json:"json-path"`
+	Header *string `yaml:"header,omitempty" json:"header"`
+	Regex *string `yaml:"regex,omitempty" json:"regex"`
 }
 
 type Expectation struct {
 	StatusCode *MeasureStatusCode `yaml:"status-code,omitempty" json:"status-code"`
+	StatusText *MeasureStatusText `yaml:"status-text,omitempty" json:"status-text"`
 	Headers *MeasureHeaders `yaml:"headers,omitempty" json:"headers"`
+	Trailers *MeasureHeaders `yaml:"trailers,omitempty" json:"trailers"`
+	Cookies []MeasureCookie `yaml:"cookies,omitempty" json:"cookies"`
 	Body *MeasureBody `yaml:"body,omitempty" json:"body"`
+	GotContinue *bool `yaml:"got-continue,omitempty" json:"got-continue"`
+	ContentLength *MeasureContentLength `yaml:"content-length,omitempty" json:"content-length"`
+	PairedGet *bool `yaml:"paired-get,omitempty" json:"paired-get"`
+	Ranges *MeasureRanges `yaml:"ranges,omitempty" json:"ranges"`
+	BodySize *MeasureTotal `yaml:"body-size,omitempty" json:"body-size"`
+	AllowMethods *MeasureAllowMethods `yaml:"allow-methods,omitempty" json:"allow-methods"`
+	Date *MeasureDate `yaml:"date,omitempty" json:"date"`
+	// Protocol is deprecated, it is an alias of version.is-equal-to
+	Protocol *string `yaml:"protocol,omitempty" json:"protocol"`
+	Version *MeasureVersion `yaml:"version,omitempty" json:"version"`
+	Network *MeasureNetwork `yaml:"network,omitempty" json:"network"`
+	Certificate *MeasureCertificate `yaml:"certificate,omitempty" json:"certificate"`
+	Custom *MeasureCustom `yaml:"custom,omitempty" json:"custom"`
+	Asserts []string `yaml:"asserts,omitempty" json:"asserts"`
+	Script *MeasureScript `yaml:"script,omitempty" json:"script"`
+	GraphQL *MeasureGraphQL `yaml:"graphql,omitempty" json:"graphql"`
+	Redirects *MeasureRedirects `yaml:"redirects,omitempty" json:"redirects"`
+	Charset *MeasureCharset `yaml:"charset,omitempty" json:"charset"`
+	ContentEncoding *MeasureContentEncoding `yaml:"content-encoding,omitempty" json:"content-encoding"`
+	SoftAssertions *bool `yaml:"soft-assertions,omitempty" json:"soft-assertions"`
+	AllOf []*Expectation `yaml:"all-of,omitempty" json:"all-of"`
+	AnyOf []*Expectation `yaml:"any-of,omitempty" json:"any-of"`
+	NoneOf []*Expectation `yaml:"none-of,omitempty" json:"none-of"`
+}
+
+type MeasureVersion struct {
+	IsEqualTo *string `yaml:"is-equal-to,omitempty" json:"is-equal-to"`
+	IsOneOf []string `yaml:"is-one-of,omitempty" json:"is-one-of"`
+	IsNotEqualTo *string `yaml:"is-not-equal-to,omitempty" json:"is-not-equal-to"`
+	Message *string `yaml:"message,omitempty" json:"message"`
+	Severity *string `yaml:"severity,omitempty" json:"severity"`
+}
+
+type MeasureNetwork struct {
+	RemoteIP *string `yaml:"remote-ip,omitempty" json:"remote-ip"`
+	TLSVersion *string `yaml:"tls-version,omitempty" json:"tls-version"`
+	CipherSuite *string `yaml:"cipher-suite,omitempty" json:"cipher-suite"`
+	ALPN *string `yaml:"alpn,omitempty" json:"alpn"`
+	Severity *string `yaml:"severity,omitempty" json:"severity"`
+}
+
+// MeasureContentLength is written either as the expected length, or as an
+// object giving the severity.
+type MeasureContentLength struct {
+	IsEqualTo *int64 `yaml:"is-equal-to,omitempty" json:"is-equal-to"`
+	Severity *string `yaml:"severity,omitempty" json:"severity"`
+}
+
+func (m *MeasureContentLength) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var length int64
+	if err := unmarshal(&length); err == nil {
+		m.IsEqualTo = &length
+		return nil
+	}
+	type plain MeasureContentLength
+	return unmarshal((*plain)(m))
+}
+
+type MeasureRanges struct {
+	ChunkSize *int64 `yaml:"chunk-size,omitempty" json:"chunk-size"`
+}
+
+type MeasureDate struct {
+	FreshWithin *string `yaml:"fresh-within,omitempty" json:"fresh-within"`
+}
+
+type MeasureAllowMethods struct {
+	Includes []string `yaml:"includes,omitempty" json:"includes"`
+	Excludes []string `yaml:"excludes,omitempty" json:"excludes"`
 }
 
 type MeasureStatusCode struct {
 	Is *ComparisonOperators `yaml:"is,omitempty" json:"is"`
+	BelongsTo []int `yaml:"belongs-to,omitempty" json:"belongs-to"`
+	IsOneOf []int `yaml:"is-one-of,omitempty" json:"is-one-of"`
+	IsNotEqualTo *int `yaml:"is-not-equal-to,omitempty" json:"is-not-equal-to"`
+	Message *string `yaml:"message,omitempty" json:"message"`
+	Severity *string `yaml:"severity,omitempty" json:"severity"`
+}
+
+type MeasureStatusText struct {
+	IsEqualTo *string `yaml:"is-equal-to,omitempty" json:"is-equal-to"`
+	Matches *string `yaml:"matches,omitempty" json:"matches"`
+	IsStandard *bool `yaml:"is-standard,omitempty" json:"is-standard"`
+	Message *string `yaml:"message,omitempty" json:"message"`
+	Severity *string `yaml:"severity,omitempty" json:"severity"`
 }
 
 type MeasureHeaders struct {
 	Total *MeasureTotal `yaml:"total,omitempty" json:"total"`
 	Items []MeasureHeader `yaml:"items,omitempty" json:"items"`
+	Forbidden []string `yaml:"forbidden,omitempty" json:"forbidden"`
 }
 
 type MeasureTotal struct {
@@ -259,19 +1046,100 @@ type MeasureTotal struct {
 type MeasureHeader struct {
 	Name *string `yaml:"name" json:"name"`
 	Is *ComparisonOperators `yaml:"is,omitempty" json:"is"`
+	Matches *string `yaml:"matches,omitempty" json:"matches"`
+	IsAbsent *bool `yaml:"is-absent,omitempty" json:"is-absent"`
+	IgnoreCase *bool `yaml:"ignore-case,omitempty" json:"ignore-case"`
+	Trim *bool `yaml:"trim,omitempty" json:"trim"`
+	TokenList *bool `yaml:"token-list,omitempty" json:"token-list"`
+	IsNotEqualTo *string `yaml:"is-not-equal-to,omitempty" json:"is-not-equal-to"`
+	IsOneOf []string `yaml:"is-one-of,omitempty" json:"is-one-of"`
+	NotIncludes *string `yaml:"not-includes,omitempty" json:"not-includes"`
+	NotMatches *string `yaml:"not-matches,omitempty" json:"not-matches"`
+	StartsWith *string `yaml:"starts-with,omitempty" json:"starts-with"`
+	EndsWith *string `yaml:"ends-with,omitempty" json:"ends-with"`
+	HasValues []string `yaml:"has-values,omitempty" json:"has-values"`
+	ContainsValue *string `yaml:"contains-value,omitempty" json:"contains-value"`
+	Ordered *bool `yaml:"ordered,omitempty" json:"ordered"`
+	Message *string `yaml:"message,omitempty" json:"message"`
+	Severity *string `yaml:"severity,omitempty" json:"severity"`
+	MeasureTimestamp `yaml:",inline"`
 }
 
 type MeasureBody struct {
 	HasFormat *string `yaml:"has-format,omitempty" json:"has-format"`
 	Includes *string `yaml:"includes,omitempty" json:"includes"`
+	NotIncludes *string `yaml:"not-includes,omitempty" json:"not-includes"`
+	NotMatches *string `yaml:"not-matches,omitempty" json:"not-matches"`
+	StartsWith *string `yaml:"starts-with,omitempty" json:"starts-with"`
+	EndsWith *string `yaml:"ends-with,omitempty" json:"ends-with"`
 	IsEqualTo *string `yaml:"is-equal-to,omitempty" json:"is-equal-to"`
+	IgnoreFields []string `yaml:"ignore-fields,omitempty" json:"ignore-fields"`
+	Canonicalize *bool `yaml:"canonicalize,omitempty" json:"canonicalize"`
+	IsEmpty *bool `yaml:"is-empty,omitempty" json:"is-empty"`
+	HasSize *MeasureSize `yaml:"has-size,omitempty" json:"has-size"`
+	HasSha256 *string `yaml:"has-sha256,omitempty" json:"has-sha256"`
+	HasMd5 *string `yaml:"has-md5,omitempty" json:"has-md5"`
+	HasCrc32 *string `yaml:"has-crc32,omitempty" json:"has-crc32"`
 	MatchWith *string `yaml:"match-with,omitempty" json:"match-with"`
+	HasSchema *string `yaml:"has-schema,omitempty" json:"has-schema"`
 	Fields []MeasureBodyField `yaml:"fields,omitempty" json:"fields"`
+	Csv *MeasureCsv `yaml:"csv,omitempty" json:"csv"`
+	HasLineCount *MeasureSize `yaml:"has-line-count,omitempty" json:"has-line-count"`
+	LineMatches *MeasureLine `yaml:"line-matches,omitempty" json:"line-matches"`
+	EveryLineMatches *string `yaml:"every-line-matches,omitempty" json:"every-line-matches"`
+	// Severity applies to the matchers of the whole body, the fields and the
+	// columns have their own
+	Severity *string `yaml:"severity,omitempty" json:"severity"`
+}
+
+type MeasureLine struct {
+	Number int `yaml:"number" json:"number"`
+	Pattern string `yaml:"pattern" json:"pattern"`
+}
+
+type MeasureCsv struct {
+	Delimiter *string `yaml:"delimiter,omitempty" json:"delimiter"`
+	HasHeader *bool `yaml:"has-header,omitempty" json:"has-header"`
+	RowCount *MeasureSize `yaml:"row-count,omitempty" json:"row-count"`
+	Columns []MeasureBodyField `yaml:"columns,omitempty" json:"columns"`
+}
+
+type MeasureSize struct {
+	IsEqualTo *int64 `yaml:"is-equal-to,omitempty" json:"is-equal-to"`
+	IsLT *int64 `yaml:"is-lt,omitempty" json:"is-lt"`
+	IsLTE *int64 `yaml:"is-lte,omitempty" json:"is-lte"`
+	IsGT *int64 `yaml:"is-gt,omitempty" json:"is-gt"`
+	IsGTE *int64 `yaml:"is-gte,omitempty" json:"is-gte"`
 }
 
 type MeasureBodyField struct {
 	Path *string `yaml:"path,omitempty" json:"path"`
+	Select *string `yaml:"select,omitempty" json:"select"`
 	Is *ComparisonOperators `yaml:"is,omitempty" json:"is"`
+	IsEqualTo interface{} `yaml:"is-equal-to,omitempty" json:"is-equal-to"`
+	IsNotEqualTo interface{} `yaml:"is-not-equal-to,omitempty" json:"is-not-equal-to"`
+	IsOneOf []interface{} `yaml:"is-one-of,omitempty" json:"is-one-of"`
+	MatchWith *string `yaml:"match-with,omitempty" json:"match-with"`
+	NotMatches *string `yaml:"not-matches,omitempty" json:"not-matches"`
+	TextIncludes *string `yaml:"text-includes,omitempty" json:"text-includes"`
+	StartsWith *string `yaml:"starts-with,omitempty" json:"starts-with"`
+	EndsWith *string `yaml:"ends-with,omitempty" json:"ends-with"`
+	Exists *bool `yaml:"exists,omitempty" json:"exists"`
+	IsType *string `yaml:"is-type,omitempty" json:"is-type"`
+	HasLength *int `yaml:"has-length,omitempty" json:"has-length"`
+	HasMinLength *int `yaml:"has-min-length,omitempty" json:"has-min-length"`
+	ContainsElement interface{} `yaml:"contains-element,omitempty" json:"contains-element"`
+	EveryElementMatches interface{} `yaml:"every-element-matches,omitempty" json:"every-element-matches"`
+	IsCloseTo *MeasureCloseTo `yaml:"is-close-to,omitempty" json:"is-close-to"`
+	Normalize *string `yaml:"normalize,omitempty" json:"normalize"`
+	Message *string `yaml:"message,omitempty" json:"message"`
+	Severity *string `yaml:"severity,omitempty" json:"severity"`
+	MeasureTimestamp `yaml:",inline"`
+}
+
+type MeasureCloseTo struct {
+	Value float64 `yaml:"value" json:"value"`
+	Delta float64 `yaml:"delta" json:"delta"`
 }
 
 type ComparisonOperators struct {
@@ -288,6 +1156,335 @@ type ComparisonOperators struct {
 type ExaminationResult struct {
 	Duration time.Duration
 	Errors map[string]error
+	Request *client.HttpRequest
 	Response *client.HttpResponse
 	Status string
+	Retries int
+	RetryWait time.Duration
+	Repeat *RepeatStats
+	Warnings []Warning
+}
+
+const WARNING_DEPRECATION string = "deprecation"
+const WARNING_SECURITY string = "security"
+const WARNING_SLOW string = "slow"
+const WARNING_TOLERATED string = "tolerated"
+const WARNING_EXPECTATION string = "expectation"
+
+// Warning reports a finding which does not fail the testcase.
+type Warning struct {
+	Category string
+	Message string
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("[%s] %s", w.Category, w.Message)
+}
+
+func examineStatusCode(statusCode int, sc *MeasureStatusCode) error {
+	if len(sc.BelongsTo) == 2 && (statusCode < sc.BelongsTo[0] || statusCode > sc.BelongsTo[1]) {
+		return fmt.Errorf("Response StatusCode [%d] must belong to the range [%d, %d]", statusCode, sc.BelongsTo[0], sc.BelongsTo[1])
+	}
+	if len(sc.IsOneOf) > 0 && !utils.ContainsInt(sc.IsOneOf, statusCode) {
+		return fmt.Errorf("Response StatusCode [%d] must be one of %v", statusCode, sc.IsOneOf)
+	}
+	// is-not-equal-to and is.not-equal-to are the same matcher
+	unexpected := make([]interface{}, 0)
+	if sc.IsNotEqualTo != nil {
+		unexpected = append(unexpected, *sc.IsNotEqualTo)
+	}
+	if sc.Is != nil && sc.Is.NotEqualTo != nil {
+		unexpected = append(unexpected, sc.Is.NotEqualTo)
+	}
+	for _, value := range unexpected {
+		if eq, _ := comparison.IsEqualTo(statusCode, value); eq {
+			return fmt.Errorf("Response StatusCode [%d] must not be equal to [%v]", statusCode, value)
+		}
+	}
+	return nil
+}
+
+// compareHeader compares a header value with the expected one, optionally
+// ignoring the case, the surrounding spaces or the order of the tokens of a
+// comma-separated list (e.g. Vary, Cache-Control).
+func compareHeader(received string, expected string, item MeasureHeader) bool {
+	if item.IgnoreCase != nil && *item.IgnoreCase {
+		received, expected = strings.ToLower(received), strings.ToLower(expected)
+	}
+	if item.TokenList != nil && *item.TokenList {
+		rTokens, eTokens := splitTokens(received), splitTokens(expected)
+		if len(rTokens) != len(eTokens) {
+			return false
+		}
+		for i := range rTokens {
+			if rTokens[i] != eTokens[i] {
+				return false
+			}
+		}
+		return true
+	}
+	if item.Trim != nil && *item.Trim {
+		received, expected = strings.TrimSpace(received), strings.TrimSpace(expected)
+	}
+	return received == expected
+}
+
+func isOneOfHeaders(received string, item MeasureHeader) bool {
+	for _, expected := range item.IsOneOf {
+		if compareHeader(received, expected, item) {
+			return true
+		}
+	}
+	return false
+}
+
+func splitTokens(value string) []string {
+	tokens := make([]string, 0)
+	for _, token := range strings.Split(value, ",") {
+		if token = strings.TrimSpace(token); len(token) > 0 {
+			tokens = append(tokens, token)
+		}
+	}
+	sort.Strings(tokens)
+	return tokens
+}
+
+// matchHeader verifies a header value with a regular expression, the named
+// groups are returned to be stored as run variables.
+func matchHeader(value string, pattern string) (map[string]string, error) {
+	reg, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid regular expression[%s], error: %s", pattern, err.Error())
+	}
+	match := reg.FindStringSubmatch(value)
+	if match == nil {
+		return nil, fmt.Errorf("Returned value: [%s] is mismatched with pattern: [%s]", value, pattern)
+	}
+	groups := make(map[string]string, 0)
+	for i, name := range reg.SubexpNames() {
+		if i > 0 && len(name) > 0 {
+			groups[name] = match[i]
+		}
+	}
+	return groups, nil
+}
+
+// examinePairedGet sends the HEAD request again with the GET method and
+// compares the declared Content-Length with the size of the GET body.
+func (e *SpecHandler) examinePairedGet(req *client.HttpRequest, res *client.HttpResponse, interceptors []client.Interceptor) error {
+	if strings.ToUpper(req.Method) != http.MethodHead {
+		return fmt.Errorf("[paired-get] is only applicable to HEAD requests, received: %s", req.Method)
+	}
+	declared := res.Header.Get("Content-Length")
+	if len(declared) == 0 {
+		return fmt.Errorf("HEAD response has no Content-Length header")
+	}
+	getRes, err := e.invoker.Do(req.WithMethod(http.MethodGet), interceptors...)
+	if err != nil {
+		return utils.LabelifyError("Paired GET request failed", err)
+	}
+	if length, err := strconv.ParseInt(declared, 10, 64); err != nil || length != getRes.BodySize {
+		return fmt.Errorf("HEAD Content-Length [%s] is mismatched with the GET body size [%d]", declared, getRes.BodySize)
+	}
+	return nil
+}
+
+func hasBodyMatchers(eb *MeasureBody) bool {
+	return eb.HasFormat != nil || eb.IsEqualTo != nil || eb.Includes != nil || eb.MatchWith != nil ||
+		eb.HasSchema != nil || len(eb.Fields) > 0 || hasBodyHashes(eb) ||
+		eb.NotIncludes != nil || eb.NotMatches != nil || hasLineMatchers(eb) || eb.StartsWith != nil || eb.EndsWith != nil
+}
+
+func examineContentLength(res *client.HttpResponse, expected int64) error {
+	if declared := res.Header.Get("Content-Length"); len(declared) > 0 {
+		if length, err := strconv.ParseInt(declared, 10, 64); err != nil || length != expected {
+			return fmt.Errorf("Content-Length mismatch expected: %d / received: %s", expected, declared)
+		}
+	}
+	if res.BodySize != expected {
+		return fmt.Errorf("Response body size mismatch expected: %d / received: %d", expected, res.BodySize)
+	}
+	return nil
+}
+
+// examineBodySize compares the number of bytes read with the bounds, and with
+// the Content-Length of the response when it carries the body.
+func examineBodySize(res *client.HttpResponse, m *MeasureSize, withBody bool) map[string]error {
+	errs := make(map[string]error, 0)
+	if err := examineNumber(res.BodySize, m.toOperators()); err != nil {
+		errs["Body/HasSize"] = fmt.Errorf("Response body size: %s", err.Error())
+	}
+	if withBody && res.ContentLength >= 0 && res.ContentLength != res.BodySize {
+		errs["Body/HasSize/ContentLength"] = fmt.Errorf("Content-Length [%d] is inconsistent with the %d bytes read", res.ContentLength, res.BodySize)
+	}
+	return errs
+}
+
+func (m *MeasureSize) toOperators() *ComparisonOperators {
+	is := &ComparisonOperators{}
+	if m.IsEqualTo != nil {
+		is.EqualTo = *m.IsEqualTo
+	}
+	if m.IsLT != nil {
+		is.LT = *m.IsLT
+	}
+	if m.IsLTE != nil {
+		is.LTE = *m.IsLTE
+	}
+	if m.IsGT != nil {
+		is.GT = *m.IsGT
+	}
+	if m.IsGTE != nil {
+		is.GTE = *m.IsGTE
+	}
+	return is
+}
+
+// getValueType returns the JSON type name of a decoded value.
+func getValueType(value interface{}) string {
+	if value == nil {
+		return "null"
+	}
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func examineBodyField(eField MeasureBodyField, rValue interface{}, found bool, soft bool, skew time.Duration) error {
+	name := ""
+	if eField.Path != nil {
+		name = *eField.Path
+	} else if eField.Select != nil {
+		name = *eField.Select
+	}
+	failure := examineBodyFieldValue(eField, rValue, found, soft, skew)
+	return withMessage(eField.Message, failure, failureContext{ Name: name, Expected: eField.getExpected(), Actual: rValue })
+}
+
+func examineBodyFieldValue(eField MeasureBodyField, rValue interface{}, found bool, soft bool, skew time.Duration) error {
+	var failure error
+	if eField.Exists != nil {
+		if *eField.Exists && !found {
+			failure = mergeFailure(failure, fmt.Errorf("Field not found"), soft)
+		}
+		if !*eField.Exists && found {
+			failure = mergeFailure(failure, fmt.Errorf("Field must not exist, received: %v", rValue), soft)
+		}
+	}
+	if eField.IsType != nil {
+		if !found {
+			failure = mergeFailure(failure, fmt.Errorf("Field not found, expected type: %s", *eField.IsType), soft)
+		} else if rType := getValueType(rValue); rType != *eField.IsType {
+			failure = mergeFailure(failure, fmt.Errorf("Field type mismatch expected: %s / received: %s", *eField.IsType, rType), soft)
+		}
+	}
+	if hasArrayMatchers(eField) {
+		if err := examineArrayField(eField, rValue, found); err != nil {
+			failure = mergeFailure(failure, err, soft)
+		}
+	}
+	eValue := eField.IsEqualTo
+	if eField.Is != nil && eField.Is.EqualTo != nil {
+		eValue = eField.Is.EqualTo
+	}
+	if eValue != nil && found && eField.Normalize != nil {
+		var err error
+		if rValue, err = sieve.Normalize(*eField.Normalize, rValue); err != nil {
+			return fmt.Errorf("Normalizing received value failed: %s", err.Error())
+		}
+		if eValue, err = sieve.Normalize(*eField.Normalize, eValue); err != nil {
+			return fmt.Errorf("Normalizing expected value failed: %s", err.Error())
+		}
+	}
+	if eValue != nil {
+		if found {
+			if eq, _ := comparison.IsEqualTo(rValue, eValue); !eq {
+				failure = mergeFailure(failure, fmt.Errorf("Field mismatch expected: %v / received: %v", eValue, rValue), soft)
+			}
+		} else {
+			failure = mergeFailure(failure, fmt.Errorf("Field not found, expected: %v", eValue), soft)
+		}
+	}
+	if _ct := eField.IsCloseTo; _ct != nil {
+		if !found {
+			failure = mergeFailure(failure, fmt.Errorf("Field not found, expected: %v (±%v)", _ct.Value, _ct.Delta), soft)
+		} else if ok, err := comparison.IsCloseTo(rValue, _ct.Value, _ct.Delta); err != nil {
+			failure = mergeFailure(failure, fmt.Errorf("Field type mismatch expected: number / received: %v", rValue), soft)
+		} else if !ok {
+			failure = mergeFailure(failure, fmt.Errorf("Field mismatch expected: %v (±%v) / received: %v", _ct.Value, _ct.Delta, rValue), soft)
+		}
+	}
+	if hasTimestampMatchers(eField.MeasureTimestamp) {
+		if !found {
+			failure = mergeFailure(failure, fmt.Errorf("Field not found, expected a timestamp"), soft)
+		} else if err := examineTimestamp(eField.MeasureTimestamp, fmt.Sprintf("%v", rValue), time.Now(), skew); err != nil {
+			failure = mergeFailure(failure, fmt.Errorf("Field mismatch: %s", err.Error()), soft)
+		}
+	}
+	if len(eField.IsOneOf) > 0 {
+		if !found {
+			failure = mergeFailure(failure, fmt.Errorf("Field not found, expected one of: %v", eField.IsOneOf), soft)
+		} else if !comparison.BelongsTo(rValue, eField.IsOneOf) {
+			failure = mergeFailure(failure, fmt.Errorf("Field mismatch expected one of: %v / received: %v", eField.IsOneOf, rValue), soft)
+		}
+	}
+	if found && eField.IsNotEqualTo != nil {
+		if eq, _ := comparison.IsEqualTo(rValue, eField.IsNotEqualTo); eq {
+			failure = mergeFailure(failure, fmt.Errorf("Field must not be equal to: %v", eField.IsNotEqualTo), soft)
+		}
+	}
+	if eField.NotMatches != nil {
+		reg, err := regexp.Compile(*eField.NotMatches)
+		if err != nil {
+			failure = mergeFailure(failure, fmt.Errorf("Invalid regular expression[%s], error: %s", *eField.NotMatches, err.Error()), soft)
+		} else if rText := fmt.Sprintf("%v", rValue); found && reg.MatchString(rText) {
+			failure = mergeFailure(failure, fmt.Errorf("Field must not match pattern: %s / received: %s", *eField.NotMatches, rText), soft)
+		}
+	}
+	if eField.MatchWith != nil {
+		reg, err := regexp.Compile(*eField.MatchWith)
+		if err != nil {
+			failure = mergeFailure(failure, fmt.Errorf("Invalid regular expression[%s], error: %s", *eField.MatchWith, err.Error()), soft)
+		} else if !found {
+			failure = mergeFailure(failure, fmt.Errorf("Field not found, pattern: %s", *eField.MatchWith), soft)
+		} else if rText := fmt.Sprintf("%v", rValue); !reg.MatchString(rText) {
+			failure = mergeFailure(failure, fmt.Errorf("Field mismatch pattern: %s / received: %s", *eField.MatchWith, rText), soft)
+		}
+	}
+	if eField.StartsWith != nil || eField.EndsWith != nil {
+		if !found {
+			failure = mergeFailure(failure, fmt.Errorf("Field not found"), soft)
+		} else if err := examineAffixes(fmt.Sprintf("%v", rValue), eField.StartsWith, eField.EndsWith, false); err != nil {
+			failure = mergeFailure(failure, fmt.Errorf("Field mismatch: %s", err.Error()), soft)
+		}
+	}
+	if eField.TextIncludes != nil {
+		if !found {
+			failure = mergeFailure(failure, fmt.Errorf("Field not found, expected to include: %s", *eField.TextIncludes), soft)
+		} else if rText := fmt.Sprintf("%v", rValue); !strings.Contains(rText, *eField.TextIncludes) {
+			failure = mergeFailure(failure, fmt.Errorf("Field must include: %s / received: %s", *eField.TextIncludes, rText), soft)
+		}
+	}
+	return failure
+}
+
+func removeIgnoredFields(paths []string, obj map[string]interface{}) error {
+	for _, path := range paths {
+		if _, err := utils.RemoveJsonPath(path, obj); err != nil {
+			return err
+		}
+	}
+	return nil
 }
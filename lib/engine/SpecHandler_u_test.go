@@ -0,0 +1,422 @@
+package engine
+
+import(
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"github.com/stretchr/testify/assert"
+	"github.com/opwire/opwire-testa/lib/client"
+	"github.com/opwire/opwire-testa/lib/sieve"
+)
+
+func TestExamineFreshness(t *testing.T) {
+	now := time.Now().UTC()
+	TESTCASES := []struct {
+		value string
+		within time.Duration
+		skew time.Duration
+		ok bool
+	}{
+		{ value: now.Format(http.TimeFormat), within: 5 * time.Second, ok: true },
+		{ value: now.Add(-time.Minute).Format(http.TimeFormat), within: 5 * time.Second, ok: false },
+		{ value: now.Add(-time.Minute).Format(http.TimeFormat), within: 5 * time.Second, skew: 2 * time.Minute, ok: true },
+		{ value: now.Add(time.Minute).Format(http.TimeFormat), within: 5 * time.Second, ok: false },
+		{ value: now.Add(time.Minute).Format(http.TimeFormat), within: 5 * time.Second, skew: 2 * time.Minute, ok: true },
+		{ value: "", within: time.Hour, ok: false },
+		{ value: "yesterday", within: time.Hour, ok: false },
+	}
+	for i, c := range TESTCASES {
+		err := examineFreshness(c.value, c.within, c.skew)
+		assert.Equal(t, c.ok, err == nil, "testcase #%d", i)
+	}
+}
+
+func TestMatchIP(t *testing.T) {
+	TESTCASES := []struct {
+		address string
+		expected string
+		ok bool
+	}{
+		{ address: "10.0.2.15", expected: "10.0.2.15", ok: true },
+		{ address: "10.0.2.15", expected: "10.0.2.0/24", ok: true },
+		{ address: "10.0.3.15", expected: "10.0.2.0/24", ok: false },
+		{ address: "::1", expected: "0:0:0:0:0:0:0:1", ok: true },
+		{ address: "", expected: "10.0.2.0/24", ok: false },
+	}
+	for i, c := range TESTCASES {
+		assert.Equal(t, c.ok, matchIP(c.address, c.expected), "testcase #%d", i)
+	}
+}
+
+func TestSpecHandler_examineSchema(t *testing.T) {
+	e := &SpecHandler{ verdicts: NewVerdictCache() }
+	schema := `{"type": "object", "required": ["id"], "properties": {"id": {"type": "integer"}, "name": {"type": "string"}}}`
+
+	t.Run("Valid object", func(t *testing.T) {
+		received := map[string]interface{}{ "id": 1, "name": "opwire" }
+		errs := e.examineSchema(schema, "", []byte(`{"id":1,"name":"opwire"}`), received)
+		assert.Equal(t, 0, len(errs))
+	})
+
+	t.Run("Each violation is reported", func(t *testing.T) {
+		received := map[string]interface{}{ "name": 2 }
+		errs := e.examineSchema(schema, "", []byte(`{"name":2}`), received)
+		assert.Equal(t, 2, len(errs))
+		assert.NotNil(t, errs["Body/HasSchema/(root)"])
+		assert.NotNil(t, errs["Body/HasSchema/name"])
+	})
+
+	t.Run("Nested YAML maps are normalized", func(t *testing.T) {
+		received := map[string]interface{}{ "id": 1, "meta": map[interface{}]interface{}{ "tag": "x" } }
+		errs := e.examineSchema(schema, "", []byte("id: 1\nmeta:\n  tag: x\n"), received)
+		assert.Equal(t, 0, len(errs))
+	})
+
+	t.Run("Missing schema file", func(t *testing.T) {
+		errs := e.examineSchema("not-found.json", "/nonexistent", []byte(`{}`), map[string]interface{}{})
+		assert.NotNil(t, errs["Body/HasSchema"])
+	})
+}
+
+func TestExamineContentLength(t *testing.T) {
+	TESTCASES := []struct {
+		declared string
+		size int64
+		expected int64
+		ok bool
+	}{
+		{ size: 0, expected: 0, ok: true },
+		{ declared: "0", size: 0, expected: 0, ok: true },
+		{ declared: "12", size: 12, expected: 12, ok: true },
+		{ declared: "12", size: 12, expected: 0, ok: false },
+		{ declared: "0", size: 4, expected: 0, ok: false },
+		{ size: 4, expected: 0, ok: false },
+	}
+	for i, c := range TESTCASES {
+		res := &client.HttpResponse{ Header: http.Header{}, BodySize: c.size }
+		if len(c.declared) > 0 {
+			res.Header.Set("Content-Length", c.declared)
+		}
+		err := examineContentLength(res, c.expected)
+		assert.Equal(t, c.ok, err == nil, "testcase #%d", i)
+	}
+}
+
+func TestExamineBodySize(t *testing.T) {
+	lt, gte := int64(10), int64(4)
+	TESTCASES := []struct {
+		size MeasureSize
+		contentLength int64
+		bodySize int64
+		withBody bool
+		errors []string
+	}{
+		{ size: MeasureSize{ IsLT: &lt, IsGTE: &gte }, contentLength: 4, bodySize: 4, withBody: true, errors: []string{} },
+		{ size: MeasureSize{ IsLT: &lt }, contentLength: -1, bodySize: 12, withBody: true, errors: []string{ "Body/HasSize" } },
+		{ size: MeasureSize{ IsGTE: &gte }, contentLength: 2, bodySize: 2, withBody: true, errors: []string{ "Body/HasSize" } },
+		{ size: MeasureSize{ IsEqualTo: &gte }, contentLength: 8, bodySize: 4, withBody: true, errors: []string{ "Body/HasSize/ContentLength" } },
+		{ size: MeasureSize{}, contentLength: 8, bodySize: 0, withBody: false, errors: []string{} },
+	}
+	for i, c := range TESTCASES {
+		res := &client.HttpResponse{ ContentLength: c.contentLength, BodySize: c.bodySize }
+		errs := examineBodySize(res, &c.size, c.withBody)
+		keys := make([]string, 0)
+		for key := range errs {
+			keys = append(keys, key)
+		}
+		assert.Equal(t, c.errors, keys, "testcase #%d", i)
+	}
+}
+
+func TestMatchHeader(t *testing.T) {
+	TESTCASES := []struct {
+		value string
+		pattern string
+		groups map[string]string
+		ok bool
+	}{
+		{ value: "/orders/42", pattern: `^/orders/\d+$`, groups: map[string]string{}, ok: true },
+		{ value: "/orders/42", pattern: `^/orders/(?P<orderId>\d+)$`, groups: map[string]string{ "orderId": "42" }, ok: true },
+		{ value: "/users/42", pattern: `^/orders/(?P<orderId>\d+)$`, ok: false },
+		{ value: "/orders/42", pattern: `^/orders/(\d+`, ok: false },
+	}
+	for i, c := range TESTCASES {
+		groups, err := matchHeader(c.value, c.pattern)
+		assert.Equal(t, c.ok, err == nil, "testcase #%d", i)
+		assert.Equal(t, c.groups, groups, "testcase #%d", i)
+	}
+}
+
+func TestCompareHeader(t *testing.T) {
+	truthy := true
+	TESTCASES := []struct {
+		received string
+		expected string
+		item MeasureHeader
+		ok bool
+	}{
+		{ received: "no-cache", expected: "no-cache", ok: true },
+		{ received: "No-Cache", expected: "no-cache", ok: false },
+		{ received: "No-Cache", expected: "no-cache", item: MeasureHeader{ IgnoreCase: &truthy }, ok: true },
+		{ received: " no-cache ", expected: "no-cache", ok: false },
+		{ received: " no-cache ", expected: "no-cache", item: MeasureHeader{ Trim: &truthy }, ok: true },
+		{ received: "Accept-Encoding, Origin", expected: "Origin,Accept-Encoding", ok: false },
+		{ received: "Accept-Encoding, Origin", expected: "Origin,Accept-Encoding", item: MeasureHeader{ TokenList: &truthy }, ok: true },
+		{ received: "accept-encoding, origin", expected: "Origin, Accept-Encoding", item: MeasureHeader{ TokenList: &truthy }, ok: false },
+		{ received: "accept-encoding, origin", expected: "Origin, Accept-Encoding", item: MeasureHeader{ TokenList: &truthy, IgnoreCase: &truthy }, ok: true },
+		{ received: "Origin", expected: "Origin, Accept-Encoding", item: MeasureHeader{ TokenList: &truthy }, ok: false },
+	}
+	for i, c := range TESTCASES {
+		assert.Equal(t, c.ok, compareHeader(c.received, c.expected, c.item), "testcase #%d", i)
+	}
+}
+
+func TestIsOneOfHeaders(t *testing.T) {
+	truthy := true
+	TESTCASES := []struct {
+		received string
+		item MeasureHeader
+		ok bool
+	}{
+		{ received: "backend-1", item: MeasureHeader{ IsOneOf: []string{ "backend-1", "backend-2" } }, ok: true },
+		{ received: "backend-3", item: MeasureHeader{ IsOneOf: []string{ "backend-1", "backend-2" } }, ok: false },
+		{ received: "Backend-2", item: MeasureHeader{ IsOneOf: []string{ "backend-1", "backend-2" }, IgnoreCase: &truthy }, ok: true },
+	}
+	for i, c := range TESTCASES {
+		assert.Equal(t, c.ok, isOneOfHeaders(c.received, c.item), "testcase #%d", i)
+	}
+}
+
+func TestExamineHeaders_Trailers(t *testing.T) {
+	ref := func(s string) *string { return &s }
+	truthy := true
+	cache, err := sieve.NewRestCache()
+	assert.Nil(t, err)
+	trailers := http.Header{ "Grpc-Status": []string{"0"}, "X-Checksum": []string{"abc123"} }
+	expected := &MeasureHeaders{
+		Items: []MeasureHeader{
+			{ Name: ref("grpc-status"), Is: &ComparisonOperators{ EqualTo: "0" } },
+			{ Name: ref("X-Checksum"), Matches: ref(`^[0-9a-f]+$`) },
+			{ Name: ref("Grpc-Message"), IsAbsent: &truthy },
+			{ Name: ref("X-Missing"), IsAbsent: new(bool) },
+		},
+		Forbidden: []string{ "X-Checksum" },
+	}
+	errs := examineHeaders(expected, trailers, "Trailer", cache, &ExaminationResult{}, false, 0)
+	keys := make([]string, 0)
+	for key := range errs {
+		keys = append(keys, key)
+	}
+	assert.ElementsMatch(t, []string{ "Trailer[X-Missing]", "Trailer[X-Checksum]" }, keys)
+}
+
+func TestExamineBodyField_IsOneOf(t *testing.T) {
+	field := MeasureBodyField{ IsOneOf: []interface{}{ "eu-1", "eu-2", 3 } }
+	assert.Nil(t, examineBodyField(field, "eu-2", true, false, 0))
+	assert.Nil(t, examineBodyField(field, 3.0, true, false, 0))
+	assert.NotNil(t, examineBodyField(field, "us-1", true, false, 0))
+	assert.NotNil(t, examineBodyField(field, nil, false, false, 0))
+}
+
+func TestExamineStatusCode(t *testing.T) {
+	forbidden := 500
+	TESTCASES := []struct {
+		statusCode int
+		sc MeasureStatusCode
+		ok bool
+	}{
+		{ statusCode: 204, sc: MeasureStatusCode{ BelongsTo: []int{ 200, 299 } }, ok: true },
+		{ statusCode: 301, sc: MeasureStatusCode{ BelongsTo: []int{ 200, 299 } }, ok: false },
+		{ statusCode: 201, sc: MeasureStatusCode{ IsOneOf: []int{ 200, 201 } }, ok: true },
+		{ statusCode: 202, sc: MeasureStatusCode{ IsOneOf: []int{ 200, 201 } }, ok: false },
+		{ statusCode: 404, sc: MeasureStatusCode{ IsNotEqualTo: &forbidden }, ok: true },
+		{ statusCode: 500, sc: MeasureStatusCode{ IsNotEqualTo: &forbidden }, ok: false },
+		{ statusCode: 500, sc: MeasureStatusCode{ BelongsTo: []int{ 200, 599 }, IsNotEqualTo: &forbidden }, ok: false },
+		{ statusCode: 500, sc: MeasureStatusCode{ Is: &ComparisonOperators{ NotEqualTo: 500 } }, ok: false },
+		{ statusCode: 404, sc: MeasureStatusCode{ Is: &ComparisonOperators{ NotEqualTo: 500 } }, ok: true },
+		{ statusCode: 500, sc: MeasureStatusCode{ Is: &ComparisonOperators{ NotEqualTo: 500 }, IsNotEqualTo: &forbidden }, ok: false },
+	}
+	for i, c := range TESTCASES {
+		err := examineStatusCode(c.statusCode, &c.sc)
+		assert.Equal(t, c.ok, err == nil, "testcase #%d", i)
+	}
+}
+
+func TestGetValueType(t *testing.T) {
+	TESTCASES := []struct {
+		value interface{}
+		expected string
+	}{
+		{ value: nil, expected: "null" },
+		{ value: "42", expected: "string" },
+		{ value: 42, expected: "number" },
+		{ value: 9.99, expected: "number" },
+		{ value: true, expected: "boolean" },
+		{ value: []interface{}{ 1, 2 }, expected: "array" },
+		{ value: map[string]interface{}{ "id": 1 }, expected: "object" },
+		{ value: map[interface{}]interface{}{ "id": 1 }, expected: "object" },
+	}
+	for i, c := range TESTCASES {
+		assert.Equal(t, c.expected, getValueType(c.value), "testcase #%d", i)
+	}
+}
+
+type resolveOptions struct {}
+func (o *resolveOptions) GetRateLimit() float64 { return 0 }
+func (o *resolveOptions) GetRequestDelay() time.Duration { return 0 }
+func (o *resolveOptions) GetMaxResponseSize() int64 { return 0 }
+func (o *resolveOptions) GetClockSkew() time.Duration { return 0 }
+func (o *resolveOptions) GetHttp3() bool { return false }
+func (o *resolveOptions) GetSoftAssertions() bool { return false }
+func (o *resolveOptions) GetPDP() string { return "https://staging.example.com" }
+func (o *resolveOptions) GetDefaultHeaders() []client.HttpHeader {
+	return []client.HttpHeader{ { Name: "X-Env", Value: "staging" } }
+}
+
+func TestSpecHandler_Resolve(t *testing.T) {
+	handler, err := NewSpecHandler(&resolveOptions{})
+	assert.Nil(t, err)
+	variables := sieve.NewVariableStore()
+	variables.Set("id", "42")
+	cache, _ := sieve.NewRestCache()
+	cache.SetVariableStore(variables)
+
+	t.Run("the expressions are evaluated and the defaults of the environment are set", func(t *testing.T) {
+		testcase := &TestCase{ Request: &client.HttpRequest{ Method: "GET", Path: "/users/${{vars.id}}" } }
+		req, err := handler.Resolve(testcase, cache)
+		assert.Nil(t, err)
+		assert.Equal(t, "https://staging.example.com/users/42", client.BuildUrl(req))
+		assert.Equal(t, []client.HttpHeader{ { Name: "X-Env", Value: "staging" } }, req.Headers)
+		assert.Equal(t, 0, len(testcase.Request.Headers))
+	})
+
+	t.Run("the request is returned as written when a variable is not captured yet", func(t *testing.T) {
+		testcase := &TestCase{ Request: &client.HttpRequest{ Url: "http://localhost:17779/orders/${{vars.order}}" } }
+		req, err := handler.Resolve(testcase, cache)
+		assert.NotNil(t, err)
+		assert.Equal(t, "http://localhost:17779/orders/${{vars.order}}", client.BuildUrl(req))
+		assert.Equal(t, 0, len(testcase.Request.Headers))
+	})
+}
+
+func TestExamineHeaders_NamedGroups(t *testing.T) {
+	ref := func(s string) *string { return &s }
+	cache, err := sieve.NewRestCache()
+	assert.Nil(t, err)
+	cache.SetVariable(sieve.RUN_WORKDIR, "/tmp/run")
+	header := http.Header{ "Location": []string{"/orders/42"}, "X-Path": []string{"/var/tmp"} }
+	expected := &MeasureHeaders{
+		Items: []MeasureHeader{
+			{ Name: ref("Location"), Matches: ref(`^/orders/(?P<orderId>\d+)$`) },
+			{ Name: ref("X-Path"), Matches: ref(`^(?P<workdir>.+)$`) },
+		},
+	}
+	errs := examineHeaders(expected, header, "Header", cache, &ExaminationResult{}, false, 0)
+	assert.Equal(t, 1, len(errs))
+	assert.NotNil(t, errs["Header[X-Path]"])
+
+	orderId, err := cache.Query("${{vars.orderId}}")
+	assert.Nil(t, err)
+	assert.Equal(t, "42", orderId)
+	workdir, err := cache.Query("${{run.workdir}}")
+	assert.Nil(t, err)
+	assert.Equal(t, "/tmp/run", workdir)
+	_, err = cache.Query("${{vars.workdir}}")
+	assert.NotNil(t, err)
+}
+
+func TestSpecHandler_Examine_GotContinue(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/reject" {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+		ioutil.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	e, err := NewSpecHandler(nil)
+	assert.Nil(t, err)
+	yes, no := true, false
+
+	TESTCASES := []struct {
+		path string
+		expectContinue *bool
+		gotContinue *bool
+		failed bool
+	}{
+		{ path: "/accept", expectContinue: &yes, gotContinue: &yes, failed: false },
+		{ path: "/reject", expectContinue: &yes, gotContinue: &no, failed: false },
+		{ path: "/reject", expectContinue: &yes, gotContinue: &yes, failed: true },
+		{ path: "/accept", expectContinue: &yes, gotContinue: &no, failed: true },
+		{ path: "/accept", expectContinue: nil, gotContinue: &yes, failed: true },
+	}
+	for i, tc := range TESTCASES {
+		cache, err := sieve.NewRestCache()
+		assert.Nil(t, err)
+		testcase := &TestCase{
+			Title: "Upload",
+			Request: &client.HttpRequest{ Method: http.MethodPost, Url: server.URL + tc.path, Body: "payload", ExpectContinue: tc.expectContinue },
+			Expectation: &Expectation{ GotContinue: tc.gotContinue },
+		}
+		result, err := e.Examine(testcase, cache, nil)
+		assert.Nil(t, err, "case #%d", i)
+		if tc.failed {
+			assert.Equal(t, 1, len(result.Errors), "case #%d", i)
+			assert.Contains(t, result.Errors, "GotContinue", "case #%d", i)
+		} else {
+			assert.Equal(t, 0, len(result.Errors), "case #%d", i)
+		}
+	}
+}
+
+func TestSpecHandler_Examine_ConditionalOn(t *testing.T) {
+	conditions := make([]string, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conditions = append(conditions, r.Header.Get("If-None-Match"))
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"version": 1}`))
+	}))
+	defer server.Close()
+
+	e, err := NewSpecHandler(nil)
+	assert.Nil(t, err)
+	cache, err := sieve.NewRestCache()
+	assert.Nil(t, err)
+	format, body := "json", `{"version": 1}`
+
+	result, err := e.Examine(&TestCase{
+		Title: "Read",
+		Request: &client.HttpRequest{ Method: http.MethodGet, Url: server.URL + "/doc" },
+		Expectation: &Expectation{ StatusCode: &MeasureStatusCode{ IsOneOf: []int{ 200 } }, Body: &MeasureBody{ HasFormat: &format, IsEqualTo: &body } },
+		Capture: &SectionCapture{ StoreID: "doc" },
+	}, cache, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(result.Errors))
+
+	// the body matchers are skipped on the 304 response
+	result, err = e.Examine(&TestCase{
+		Title: "Revalidate",
+		Request: &client.HttpRequest{ Method: http.MethodGet, Url: server.URL + "/doc", ConditionalOn: "doc" },
+		Expectation: &Expectation{ StatusCode: &MeasureStatusCode{ IsOneOf: []int{ 304 } }, Body: &MeasureBody{ HasFormat: &format, IsEqualTo: &body } },
+	}, cache, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(result.Errors))
+	assert.Equal(t, []string{ "", `"v1"` }, conditions)
+
+	// a testcase referring to a missing response is not sent
+	result, err = e.Examine(&TestCase{
+		Title: "Unknown",
+		Request: &client.HttpRequest{ Method: http.MethodGet, Url: server.URL + "/doc", ConditionalOn: "unknown" },
+	}, cache, nil)
+	assert.Equal(t, "error", result.Status)
+	assert.Equal(t, 2, len(conditions))
+}
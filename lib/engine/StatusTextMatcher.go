@@ -0,0 +1,54 @@
+package engine
+
+import(
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"github.com/opwire/opwire-testa/lib/client"
+)
+
+// examineStatusText verifies the reason phrase of the status line, whatever
+// the status code. HTTP/2 and HTTP/3 have no reason phrase, the standard
+// text of the code is received instead.
+func examineStatusText(st *MeasureStatusText, res *client.HttpResponse) error {
+	phrase := getReasonPhrase(res)
+	var failure error
+	if st.IsEqualTo != nil && phrase != *st.IsEqualTo {
+		failure = fmt.Errorf("Reason phrase [%s] is mismatched with expected: [%s]", phrase, *st.IsEqualTo)
+	}
+	if failure == nil && st.Matches != nil {
+		reg, err := regexp.Compile(*st.Matches)
+		if err != nil {
+			failure = fmt.Errorf("Invalid regular expression[%s], error: %s", *st.Matches, err.Error())
+		} else if !reg.MatchString(phrase) {
+			failure = fmt.Errorf("Reason phrase [%s] is mismatched with pattern: [%s]", phrase, *st.Matches)
+		}
+	}
+	if failure == nil && st.IsStandard != nil {
+		standard := http.StatusText(res.StatusCode)
+		if *st.IsStandard && !strings.EqualFold(phrase, standard) {
+			failure = fmt.Errorf("Reason phrase [%s] differs from the standard one of [%d]: [%s]", phrase, res.StatusCode, standard)
+		}
+		if !*st.IsStandard && strings.EqualFold(phrase, standard) {
+			failure = fmt.Errorf("Reason phrase [%s] must differ from the standard one of [%d]", phrase, res.StatusCode)
+		}
+	}
+	return withMessage(st.Message, failure, failureContext{ Name: "StatusText", Expected: st.getExpected(), Actual: phrase })
+}
+
+// getReasonPhrase strips the status code from the status line (e.g. "201 Created").
+func getReasonPhrase(res *client.HttpResponse) string {
+	return strings.TrimSpace(strings.TrimPrefix(res.Status, strconv.Itoa(res.StatusCode)))
+}
+
+func (st MeasureStatusText) getExpected() interface{} {
+	switch {
+	case st.IsEqualTo != nil:
+		return *st.IsEqualTo
+	case st.Matches != nil:
+		return *st.Matches
+	}
+	return nil
+}
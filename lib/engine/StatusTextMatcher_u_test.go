@@ -0,0 +1,32 @@
+package engine
+
+import(
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/opwire/opwire-testa/lib/client"
+	"github.com/opwire/opwire-testa/lib/utils"
+)
+
+func TestExamineStatusText(t *testing.T) {
+	truthy, falsy := true, false
+	TESTCASES := []struct {
+		status string
+		code int
+		measure MeasureStatusText
+		ok bool
+	}{
+		{ status: "201 Created", code: 201, measure: MeasureStatusText{ Matches: utils.RefOfString("Created") }, ok: true },
+		{ status: "201 Created", code: 201, measure: MeasureStatusText{ IsEqualTo: utils.RefOfString("Created"), IsStandard: &truthy }, ok: true },
+		{ status: "200 Okay", code: 200, measure: MeasureStatusText{ IsStandard: &truthy }, ok: false },
+		{ status: "200 Okay", code: 200, measure: MeasureStatusText{ IsStandard: &falsy }, ok: true },
+		{ status: "422 Unprocessable Entity", code: 422, measure: MeasureStatusText{ IsEqualTo: utils.RefOfString("Unprocessable") }, ok: false },
+		{ status: "500", code: 500, measure: MeasureStatusText{ Matches: utils.RefOfString("^$") }, ok: true },
+		{ status: "500", code: 500, measure: MeasureStatusText{ Matches: utils.RefOfString("(") }, ok: false },
+	}
+	for i, c := range TESTCASES {
+		err := examineStatusText(&c.measure, &client.HttpResponse{ Status: c.status, StatusCode: c.code })
+		assert.Equal(t, c.ok, err == nil, "testcase #%d", i)
+	}
+	err := examineStatusText(&MeasureStatusText{ IsEqualTo: utils.RefOfString("OK"), Message: utils.RefOfString("proxy rewrote {{.Actual}}") }, &client.HttpResponse{ Status: "200 Fine", StatusCode: 200 })
+	assert.Contains(t, err.Error(), "proxy rewrote Fine")
+}
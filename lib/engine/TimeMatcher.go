@@ -0,0 +1,68 @@
+package engine
+
+import(
+	"fmt"
+	"time"
+	"github.com/opwire/opwire-testa/lib/comparison"
+)
+
+type MeasureTimestamp struct {
+	IsRFC3339 *bool `yaml:"is-rfc3339,omitempty" json:"is-rfc3339"`
+	MatchesLayout *string `yaml:"matches-layout,omitempty" json:"matches-layout"`
+	IsBefore *string `yaml:"is-before,omitempty" json:"is-before"`
+	IsAfter *string `yaml:"is-after,omitempty" json:"is-after"`
+}
+
+func hasTimestampMatchers(m MeasureTimestamp) bool {
+	return m.IsRFC3339 != nil || m.MatchesLayout != nil || m.IsBefore != nil || m.IsAfter != nil
+}
+
+// examineTimestamp verifies the format of a time value and compares it with
+// the bounds, a bound is a timestamp or relative to the local clock (now+1h).
+// The bounds are widened by the clock-skew allowance.
+func examineTimestamp(m MeasureTimestamp, value string, now time.Time, skew time.Duration) error {
+	if m.IsRFC3339 != nil {
+		valid := comparison.IsRFC3339(value)
+		if *m.IsRFC3339 && !valid {
+			return fmt.Errorf("[%s] is not a RFC3339 timestamp", value)
+		}
+		if !*m.IsRFC3339 && valid {
+			return fmt.Errorf("[%s] must not be a RFC3339 timestamp", value)
+		}
+	}
+	var layout string
+	if m.MatchesLayout != nil {
+		layout = *m.MatchesLayout
+		if _, err := comparison.ParseTimestamp(value, layout); err != nil {
+			return fmt.Errorf("[%s] does not match the layout [%s]", value, layout)
+		}
+	}
+	if m.IsBefore == nil && m.IsAfter == nil {
+		return nil
+	}
+	moment, err := comparison.ParseTimestamp(value, layout)
+	if err != nil {
+		return err
+	}
+	if m.IsBefore != nil {
+		bound, err := comparison.ResolveTimeBound(*m.IsBefore, now)
+		if err != nil {
+			return err
+		}
+		bound = bound.Add(skew)
+		if !moment.Before(bound) {
+			return fmt.Errorf("[%s] must be before [%s] (%s)", value, *m.IsBefore, bound.Format(time.RFC3339))
+		}
+	}
+	if m.IsAfter != nil {
+		bound, err := comparison.ResolveTimeBound(*m.IsAfter, now)
+		if err != nil {
+			return err
+		}
+		bound = bound.Add(-skew)
+		if !moment.After(bound) {
+			return fmt.Errorf("[%s] must be after [%s] (%s)", value, *m.IsAfter, bound.Format(time.RFC3339))
+		}
+	}
+	return nil
+}
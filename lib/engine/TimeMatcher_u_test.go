@@ -0,0 +1,60 @@
+package engine
+
+import(
+	"testing"
+	"time"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExamineTimestamp(t *testing.T) {
+	truthy, falsy := true, false
+	now := time.Date(2019, 5, 20, 10, 30, 0, 0, time.UTC)
+	ref := func(s string) *string { return &s }
+	TESTCASES := []struct {
+		measure MeasureTimestamp
+		value string
+		ok bool
+	}{
+		{ measure: MeasureTimestamp{ IsRFC3339: &truthy }, value: "2019-05-20T10:00:00Z", ok: true },
+		{ measure: MeasureTimestamp{ IsRFC3339: &truthy }, value: "2019-05-20 10:00:00", ok: false },
+		{ measure: MeasureTimestamp{ IsRFC3339: &falsy }, value: "2019-05-20", ok: true },
+		{ measure: MeasureTimestamp{ MatchesLayout: ref("2006-01-02") }, value: "2019-05-20", ok: true },
+		{ measure: MeasureTimestamp{ MatchesLayout: ref("2006-01-02") }, value: "2019-05-20T10:00:00Z", ok: false },
+		{ measure: MeasureTimestamp{ IsBefore: ref("now+1h") }, value: "2019-05-20T11:00:00+07:00", ok: true },
+		{ measure: MeasureTimestamp{ IsBefore: ref("now+1h") }, value: "2019-05-20T11:30:00Z", ok: false },
+		{ measure: MeasureTimestamp{ IsAfter: ref("now-1h") }, value: "Mon, 20 May 2019 10:00:00 GMT", ok: true },
+		{ measure: MeasureTimestamp{ IsAfter: ref("now") }, value: "2019-05-20T10:00:00Z", ok: false },
+		{
+			measure: MeasureTimestamp{ MatchesLayout: ref("2006-01-02"), IsAfter: ref("2019-05-01T00:00:00Z"), IsBefore: ref("now") },
+			value: "2019-05-20", ok: true,
+		},
+		{ measure: MeasureTimestamp{ IsBefore: ref("now") }, value: "yesterday", ok: false },
+		{ measure: MeasureTimestamp{ IsBefore: ref("soon") }, value: "2019-05-20T10:00:00Z", ok: false },
+	}
+	for i, c := range TESTCASES {
+		err := examineTimestamp(c.measure, c.value, now, 0)
+		assert.Equal(t, c.ok, err == nil, "testcase #%d: %v", i, err)
+	}
+}
+
+func TestExamineTimestamp_ClockSkew(t *testing.T) {
+	now := time.Date(2019, 5, 20, 10, 30, 0, 0, time.UTC)
+	ref := func(s string) *string { return &s }
+	TESTCASES := []struct {
+		measure MeasureTimestamp
+		value string
+		skew time.Duration
+		ok bool
+	}{
+		{ measure: MeasureTimestamp{ IsBefore: ref("now") }, value: "2019-05-20T10:30:01Z", skew: 0, ok: false },
+		{ measure: MeasureTimestamp{ IsBefore: ref("now") }, value: "2019-05-20T10:30:01Z", skew: 2 * time.Second, ok: true },
+		{ measure: MeasureTimestamp{ IsBefore: ref("now") }, value: "2019-05-20T10:30:03Z", skew: 2 * time.Second, ok: false },
+		{ measure: MeasureTimestamp{ IsAfter: ref("now") }, value: "2019-05-20T10:29:59Z", skew: 0, ok: false },
+		{ measure: MeasureTimestamp{ IsAfter: ref("now") }, value: "2019-05-20T10:29:59Z", skew: 2 * time.Second, ok: true },
+		{ measure: MeasureTimestamp{ IsAfter: ref("now") }, value: "2019-05-20T10:29:57Z", skew: 2 * time.Second, ok: false },
+	}
+	for i, c := range TESTCASES {
+		err := examineTimestamp(c.measure, c.value, now, c.skew)
+		assert.Equal(t, c.ok, err == nil, "testcase #%d: %v", i, err)
+	}
+}
@@ -0,0 +1,59 @@
+package engine
+
+import(
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"github.com/opwire/opwire-testa/lib/client"
+	"github.com/opwire/opwire-testa/lib/utils"
+)
+
+// captureVariable extracts the value of a variable from the response, the
+// values which are not strings are captured as JSON.
+func captureVariable(variable CaptureVariable, res *client.HttpResponse, content []byte) (string, error) {
+	switch {
+	case variable.JsonPath != nil:
+		var doc interface{}
+		if err := utils.Unmarshal(utils.BODY_FORMAT_JSON, content, &doc); err != nil {
+			return "", fmt.Errorf("Response body is not a JSON document: %s", err.Error())
+		}
+		value, found, err := utils.EvaluateJsonPath(*variable.JsonPath, doc)
+		if err != nil {
+			return "", err
+		}
+		if !found {
+			return "", fmt.Errorf("JSONPath [%s] not found", *variable.JsonPath)
+		}
+		if text, ok := value.(string); ok {
+			return text, nil
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return "", err
+		}
+		return string(encoded), nil
+	case variable.Header != nil:
+		values, found := res.Header[http.CanonicalHeaderKey(*variable.Header)]
+		if !found || len(values) == 0 {
+			return "", fmt.Errorf("Header [%s] not found", *variable.Header)
+		}
+		return strings.Join(values, ", "), nil
+	case variable.Regex != nil:
+		reg, err := regexp.Compile(*variable.Regex)
+		if err != nil {
+			return "", fmt.Errorf("Invalid regular expression [%s], error: %s", *variable.Regex, err.Error())
+		}
+		match := reg.FindSubmatch(content)
+		if match == nil {
+			return "", fmt.Errorf("Response body is mismatched with the pattern [%s]", *variable.Regex)
+		}
+		// the first group is captured when the pattern has one
+		if len(match) > 1 {
+			return string(match[1]), nil
+		}
+		return string(match[0]), nil
+	}
+	return "", fmt.Errorf("One of json-path, header or regex must be given")
+}
@@ -0,0 +1,37 @@
+package engine
+
+import(
+	"net/http"
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/opwire/opwire-testa/lib/client"
+	"github.com/opwire/opwire-testa/lib/utils"
+)
+
+func TestCaptureVariable(t *testing.T) {
+	content := []byte(`{"data": {"id": 42, "name": "alice", "roles": ["admin"]}}`)
+	res := &client.HttpResponse{ Header: http.Header{ "Etag": []string{ `"v1"` } }, Body: content }
+	TESTCASES := []struct {
+		variable CaptureVariable
+		value string
+		failed bool
+	}{
+		{ variable: CaptureVariable{ Name: "name", JsonPath: utils.RefOfString("$.data.name") }, value: "alice" },
+		{ variable: CaptureVariable{ Name: "id", JsonPath: utils.RefOfString("$.data.id") }, value: "42" },
+		{ variable: CaptureVariable{ Name: "roles", JsonPath: utils.RefOfString("$.data.roles") }, value: `["admin"]` },
+		{ variable: CaptureVariable{ Name: "missing", JsonPath: utils.RefOfString("$.data.email") }, failed: true },
+		{ variable: CaptureVariable{ Name: "etag", Header: utils.RefOfString("ETag") }, value: `"v1"` },
+		{ variable: CaptureVariable{ Name: "missing", Header: utils.RefOfString("Location") }, failed: true },
+		{ variable: CaptureVariable{ Name: "id", Regex: utils.RefOfString(`"id":\s*(\d+)`) }, value: "42" },
+		{ variable: CaptureVariable{ Name: "whole", Regex: utils.RefOfString(`ali\w+`) }, value: "alice" },
+		{ variable: CaptureVariable{ Name: "missing", Regex: utils.RefOfString(`"email"`) }, failed: true },
+		{ variable: CaptureVariable{ Name: "nothing" }, failed: true },
+	}
+	for i, c := range TESTCASES {
+		value, err := captureVariable(c.variable, res, content)
+		assert.Equal(t, c.failed, err != nil, "testcase #%d", i)
+		assert.Equal(t, c.value, value, "testcase #%d", i)
+	}
+	_, err := captureVariable(CaptureVariable{ Name: "id", JsonPath: utils.RefOfString("$.id") }, res, []byte("<html/>"))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,54 @@
+package engine
+
+import(
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+)
+
+// VerdictCache keeps the verdicts of the expensive matchers during a run,
+// so that identical responses are not compared again.
+type VerdictCache struct {
+	verdicts map[string]*verdict
+	mutex sync.RWMutex
+}
+
+type verdict struct {
+	ok bool
+	diff string
+}
+
+func NewVerdictCache() *VerdictCache {
+	return &VerdictCache{ verdicts: make(map[string]*verdict, 0) }
+}
+
+func (c *VerdictCache) Evaluate(matcher string, expected string, received []byte, compare func() (bool, string)) (bool, string) {
+	key := verdictKey(matcher, expected, received)
+	c.mutex.RLock()
+	v, found := c.verdicts[key]
+	c.mutex.RUnlock()
+	if found {
+		return v.ok, v.diff
+	}
+	ok, diff := compare()
+	c.mutex.Lock()
+	c.verdicts[key] = &verdict{ ok: ok, diff: diff }
+	c.mutex.Unlock()
+	return ok, diff
+}
+
+func (c *VerdictCache) Size() int {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return len(c.verdicts)
+}
+
+func verdictKey(matcher string, expected string, received []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(matcher))
+	hash.Write([]byte{0})
+	hash.Write([]byte(expected))
+	hash.Write([]byte{0})
+	hash.Write(received)
+	return hex.EncodeToString(hash.Sum(nil))
+}
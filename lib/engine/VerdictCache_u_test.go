@@ -0,0 +1,29 @@
+package engine
+
+import(
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerdictCache_Evaluate(t *testing.T) {
+	cache := NewVerdictCache()
+	calls := 0
+	compare := func() (bool, string) {
+		calls++
+		return false, "diff"
+	}
+
+	ok, diff := cache.Evaluate("Body/IsEqualTo/json", `{"a": 1}`, []byte(`{"a": 2}`), compare)
+	assert.False(t, ok)
+	assert.Equal(t, "diff", diff)
+
+	ok, diff = cache.Evaluate("Body/IsEqualTo/json", `{"a": 1}`, []byte(`{"a": 2}`), compare)
+	assert.False(t, ok)
+	assert.Equal(t, "diff", diff)
+	assert.Equal(t, 1, calls)
+
+	cache.Evaluate("Body/Includes/json", `{"a": 1}`, []byte(`{"a": 2}`), compare)
+	cache.Evaluate("Body/IsEqualTo/json", `{"a": 1}`, []byte(`{"a": 3}`), compare)
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, 3, cache.Size())
+}
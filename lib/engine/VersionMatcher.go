@@ -0,0 +1,59 @@
+package engine
+
+import(
+	"fmt"
+	"strings"
+)
+
+// examineVersion verifies the protocol version of the response (e.g.
+// "HTTP/2.0"), the minor version may be omitted ("HTTP/2").
+func examineVersion(mv *MeasureVersion, version string) error {
+	received := normalizeVersion(version)
+	var failure error
+	if mv.IsEqualTo != nil && received != normalizeVersion(*mv.IsEqualTo) {
+		failure = fmt.Errorf("Response protocol version [%s] is mismatched with expected: [%s]", version, *mv.IsEqualTo)
+	}
+	if failure == nil && len(mv.IsOneOf) > 0 {
+		matched := false
+		for _, expected := range mv.IsOneOf {
+			if received == normalizeVersion(expected) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			failure = fmt.Errorf("Response protocol version [%s] is not one of: %v", version, mv.IsOneOf)
+		}
+	}
+	if failure == nil && mv.IsNotEqualTo != nil && received == normalizeVersion(*mv.IsNotEqualTo) {
+		failure = fmt.Errorf("Response protocol version [%s] must not be equal to [%s]", version, *mv.IsNotEqualTo)
+	}
+	return withMessage(mv.Message, failure, failureContext{ Name: "Version", Expected: mv.getExpected(), Actual: version })
+}
+
+// getVersion returns the matcher of the protocol version, the deprecated
+// protocol field is turned into version.is-equal-to.
+func (e *Expectation) getVersion() *MeasureVersion {
+	if e.Version == nil && e.Protocol != nil {
+		return &MeasureVersion{ IsEqualTo: e.Protocol }
+	}
+	return e.Version
+}
+
+func normalizeVersion(version string) string {
+	version = strings.ToUpper(strings.TrimSpace(version))
+	if slash := strings.Index(version, "/"); slash >= 0 && !strings.Contains(version[slash:], ".") {
+		version = version + ".0"
+	}
+	return version
+}
+
+func (mv MeasureVersion) getExpected() interface{} {
+	switch {
+	case mv.IsEqualTo != nil:
+		return *mv.IsEqualTo
+	case len(mv.IsOneOf) > 0:
+		return mv.IsOneOf
+	}
+	return nil
+}
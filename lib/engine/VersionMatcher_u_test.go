@@ -0,0 +1,34 @@
+package engine
+
+import(
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/opwire/opwire-testa/lib/utils"
+)
+
+func TestExamineVersion(t *testing.T) {
+	TESTCASES := []struct {
+		version string
+		measure MeasureVersion
+		ok bool
+	}{
+		{ version: "HTTP/2.0", measure: MeasureVersion{ IsEqualTo: utils.RefOfString("HTTP/2.0") }, ok: true },
+		{ version: "HTTP/2.0", measure: MeasureVersion{ IsEqualTo: utils.RefOfString("http/2") }, ok: true },
+		{ version: "HTTP/1.1", measure: MeasureVersion{ IsEqualTo: utils.RefOfString("HTTP/2.0") }, ok: false },
+		{ version: "HTTP/1.1", measure: MeasureVersion{ IsOneOf: []string{ "HTTP/2", "HTTP/3" } }, ok: false },
+		{ version: "HTTP/3.0", measure: MeasureVersion{ IsOneOf: []string{ "HTTP/2", "HTTP/3" } }, ok: true },
+		{ version: "HTTP/1.0", measure: MeasureVersion{ IsNotEqualTo: utils.RefOfString("HTTP/1.0") }, ok: false },
+	}
+	for i, c := range TESTCASES {
+		err := examineVersion(&c.measure, c.version)
+		assert.Equal(t, c.ok, err == nil, "testcase #%d", i)
+	}
+}
+
+func TestExpectation_GetVersion(t *testing.T) {
+	protocol := &Expectation{ Protocol: utils.RefOfString("HTTP/2.0") }
+	assert.Equal(t, &MeasureVersion{ IsEqualTo: utils.RefOfString("HTTP/2.0") }, protocol.getVersion())
+	version := &MeasureVersion{ IsOneOf: []string{ "HTTP/2", "HTTP/3" } }
+	assert.Equal(t, version, (&Expectation{ Protocol: utils.RefOfString("HTTP/2.0"), Version: version }).getVersion())
+	assert.Nil(t, (&Expectation{}).getVersion())
+}
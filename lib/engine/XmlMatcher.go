@@ -0,0 +1,101 @@
+package engine
+
+import(
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"github.com/antchfx/xmlquery"
+	"github.com/antchfx/xpath"
+	"github.com/opwire/opwire-testa/lib/comparison"
+	"github.com/opwire/opwire-testa/lib/utils"
+)
+
+// examineXml verifies an XML response body, the fields are selected with
+// XPath expressions and checked with the same matchers as the JSON fields.
+func examineXml(eb *MeasureBody, body []byte, soft bool, skew time.Duration) map[string]error {
+	format := utils.BODY_FORMAT_XML
+	errs := make(map[string]error, 0)
+	if len(body) == 0 {
+		errs["Body/ReceivedObject"] = fmt.Errorf("[%s] Response body is empty", format)
+		return errs
+	}
+	received, err := xmlquery.Parse(bytes.NewReader(body))
+	if err != nil {
+		errs["Body/ReceivedObject"] = fmt.Errorf("[%s] Invalid response content: %s", format, err)
+		return errs
+	}
+	if eb.IsEqualTo != nil {
+		expected, err := xmlquery.Parse(strings.NewReader(*eb.IsEqualTo))
+		if err != nil {
+			errs["Body/ExpectedObject"] = fmt.Errorf("[%s] Invalid expected content: %s", format, err)
+		} else if different, diff := comparison.LineDiff(canonicalXml(expected), canonicalXml(received)); different {
+			errs["Body/IsEqualTo"] = newBodyMismatch(format, diff)
+		}
+	}
+	if eb.Includes != nil {
+		errs["Body/Includes"] = fmt.Errorf("[%s] The [includes] attribute is unsupported, please use [fields] instead", format)
+	}
+	if eb.MatchWith != nil {
+		if reg, err := regexp.Compile(*eb.MatchWith); err == nil {
+			if !reg.Match(body) {
+				errs["Body/MatchWith"] = fmt.Errorf("[%s] Response body is mismatched with the pattern.\nReceived: %s\nPattern: %s", format, string(body), *eb.MatchWith)
+			}
+		} else {
+			errs["Body/Expectation"] = fmt.Errorf("[%s] Invalid regular expression[%s], error: %s", format, *eb.MatchWith, err.Error())
+		}
+	}
+	for _, eField := range eb.Fields {
+		if eField.Path == nil {
+			continue
+		}
+		fieldKey := "Body/Fields/" + *eField.Path
+		rValue, found, err := evaluateXPath(*eField.Path, received)
+		if err != nil {
+			errs[fieldKey] = err
+			continue
+		}
+		if err := examineBodyField(eField, rValue, found, soft, skew); err != nil {
+			errs[fieldKey] = err
+		}
+	}
+	return errs
+}
+
+// evaluateXPath returns the text of the first selected node, or the value of
+// an expression resulting in a number, a string or a boolean.
+func evaluateXPath(path string, doc *xmlquery.Node) (interface{}, bool, error) {
+	expr, err := xpath.Compile(path)
+	if err != nil {
+		return nil, false, fmt.Errorf("Invalid XPath expression[%s], error: %s", path, err.Error())
+	}
+	switch result := expr.Evaluate(xmlquery.CreateXPathNavigator(doc)).(type) {
+	case *xpath.NodeIterator:
+		if result.MoveNext() {
+			return result.Current().Value(), true, nil
+		}
+		return nil, false, nil
+	default:
+		return result, true, nil
+	}
+}
+
+// canonicalXml renders a document without the whitespace-only text nodes,
+// so that the indentation does not affect the comparison.
+func canonicalXml(doc *xmlquery.Node) string {
+	stripBlankText(doc)
+	return doc.OutputXML(false)
+}
+
+func stripBlankText(node *xmlquery.Node) {
+	for child := node.FirstChild; child != nil; {
+		next := child.NextSibling
+		if child.Type == xmlquery.TextNode && len(strings.TrimSpace(child.Data)) == 0 {
+			xmlquery.RemoveFromTree(child)
+		} else {
+			stripBlankText(child)
+		}
+		child = next
+	}
+}
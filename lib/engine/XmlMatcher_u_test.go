@@ -0,0 +1,65 @@
+package engine
+
+import(
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/opwire/opwire-testa/lib/utils"
+)
+
+func TestExamineXml(t *testing.T) {
+	truthy, falsy := true, false
+	body := []byte(`<?xml version="1.0"?>
+<order id="42">
+	<item sku="a1">Pen</item>
+	<item sku="b2">Book</item>
+	<price>9.99</price>
+</order>`)
+	TESTCASES := []struct {
+		body MeasureBody
+		failed []string
+	}{
+		{
+			body: MeasureBody{
+				Fields: []MeasureBodyField{
+					{ Path: utils.RefOfString("/order/@id"), IsEqualTo: 42 },
+					{ Path: utils.RefOfString("/order/item[@sku='b2']"), IsEqualTo: "Book" },
+					{ Path: utils.RefOfString("count(//item)"), IsEqualTo: 2 },
+					{ Path: utils.RefOfString("//price"), MatchWith: utils.RefOfString(`^\d+\.\d{2}$`) },
+					{ Path: utils.RefOfString("//discount"), Exists: &falsy },
+				},
+			},
+		},
+		{
+			body: MeasureBody{
+				Fields: []MeasureBodyField{
+					{ Path: utils.RefOfString("/order/item[1]"), IsEqualTo: "Book" },
+					{ Path: utils.RefOfString("//discount"), Exists: &truthy },
+					{ Path: utils.RefOfString("//item["), Exists: &truthy },
+				},
+			},
+			failed: []string{ "Body/Fields//order/item[1]", "Body/Fields///discount", "Body/Fields///item[" },
+		},
+		{
+			body: MeasureBody{
+				IsEqualTo: utils.RefOfString(`<order id="42"><item sku="a1">Pen</item><item sku="b2">Book</item><price>9.99</price></order>`),
+				MatchWith: utils.RefOfString(`<price>`),
+			},
+		},
+		{
+			body: MeasureBody{
+				IsEqualTo: utils.RefOfString(`<order id="42"><item sku="a1">Pen</item></order>`),
+			},
+			failed: []string{ "Body/IsEqualTo" },
+		},
+	}
+	for i, c := range TESTCASES {
+		errs := examineXml(&c.body, body, false, 0)
+		keys := make([]string, 0)
+		for key := range errs {
+			keys = append(keys, key)
+		}
+		assert.ElementsMatch(t, c.failed, keys, "testcase #%d", i)
+	}
+	errs := examineXml(&MeasureBody{}, []byte(`<order>`), false, 0)
+	assert.Contains(t, errs, "Body/ReceivedObject")
+}
@@ -0,0 +1,126 @@
+package engine
+
+import(
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"github.com/opwire/opwire-testa/lib/comparison"
+	"github.com/opwire/opwire-testa/lib/utils"
+)
+
+// isMultiDocumentYaml reports whether the received body or one of the
+// expected contents is a YAML stream of several documents.
+func isMultiDocumentYaml(eb *MeasureBody, body []byte) bool {
+	sources := [][]byte{ body }
+	if eb.IsEqualTo != nil {
+		sources = append(sources, []byte(*eb.IsEqualTo))
+	}
+	if eb.Includes != nil {
+		sources = append(sources, []byte(*eb.Includes))
+	}
+	for _, source := range sources {
+		if docs, err := utils.UnmarshalYamlDocuments(source); err == nil && len(docs) > 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// examineYamlDocuments verifies a multi-document YAML body, the documents are
+// compared structurally in order and the paths of the fields are prefixed
+// with the index of the document (e.g. "1.metadata.name").
+func examineYamlDocuments(eb *MeasureBody, body []byte, soft bool, skew time.Duration) map[string]error {
+	format := utils.BODY_FORMAT_YAML
+	errs := make(map[string]error, 0)
+	received, err := utils.UnmarshalYamlDocuments(body)
+	if err != nil {
+		errs["Body/ReceivedObject"] = fmt.Errorf("[%s] Invalid response content: %s", format, err)
+		return errs
+	}
+	if len(received) == 0 {
+		errs["Body/ReceivedObject"] = fmt.Errorf("[%s] Response body is empty", format)
+		return errs
+	}
+	compared := received
+	if len(eb.IgnoreFields) > 0 {
+		compared, _ = utils.UnmarshalYamlDocuments(body)
+		if err := removeIgnoredDocumentFields(eb.IgnoreFields, compared); err != nil {
+			addFailure(errs, "Body/IgnoreFields", fmt.Errorf("[%s] %s", format, err.Error()), soft)
+		}
+	}
+	if eb.IsEqualTo != nil {
+		if expected, err := utils.UnmarshalYamlDocuments([]byte(*eb.IsEqualTo)); err != nil {
+			addFailure(errs, "Body/ExpectedObject", fmt.Errorf("[%s] Invalid expected content: %s", format, err), soft)
+		} else {
+			removeIgnoredDocumentFields(eb.IgnoreFields, expected)
+			if different, diff := comparison.DeepDiff(expected, compared); different {
+				addFailure(errs, "Body/IsEqualTo", newBodyMismatch(format, diff), soft)
+			}
+		}
+	}
+	if eb.Includes != nil {
+		if expected, err := utils.UnmarshalYamlDocuments([]byte(*eb.Includes)); err != nil {
+			addFailure(errs, "Body/ExpectedObject", fmt.Errorf("[%s] Invalid expected content: %s", format, err), soft)
+		} else {
+			removeIgnoredDocumentFields(eb.IgnoreFields, expected)
+			if len(expected) > len(compared) {
+				addFailure(errs, "Body/Includes", fmt.Errorf("[%s] Expected %d documents, received %d", format, len(expected), len(compared)), soft)
+			} else {
+				diffs := make([]string, 0)
+				for i := range expected {
+					if ok, diff := comparison.IsPartOf(expected[i], compared[i]); !ok {
+						diffs = append(diffs, fmt.Sprintf("document #%d:\n%s", i, diff))
+					}
+				}
+				if len(diffs) > 0 {
+					addFailure(errs, "Body/Includes", newBodyMismatch(format, strings.Join(diffs, "\n")), soft)
+				}
+			}
+		}
+	}
+	if len(eb.Fields) > 0 {
+		rFields := make(map[string]interface{}, 0)
+		for i, doc := range received {
+			if obj, ok := doc.(map[string]interface{}); ok {
+				flatten, _ := utils.Flatten(strconv.Itoa(i), obj)
+				for key, value := range flatten {
+					rFields[key] = value
+				}
+			}
+		}
+		for _, eField := range eb.Fields {
+			if eField.Path == nil {
+				continue
+			}
+			fieldKey := "Body/Fields/" + *eField.Path
+			var rValue interface{}
+			var found bool
+			if strings.HasPrefix(*eField.Path, "$") {
+				var err error
+				rValue, found, err = utils.EvaluateJsonPath(*eField.Path, received)
+				if err != nil {
+					addFailure(errs, fieldKey, err, soft)
+					continue
+				}
+			} else {
+				rValue, found = rFields[*eField.Path]
+			}
+			if err := examineBodyField(eField, rValue, found, soft, skew); err != nil {
+				addFailure(errs, fieldKey, err, soft)
+			}
+		}
+	}
+	return errs
+}
+
+func removeIgnoredDocumentFields(paths []string, docs []interface{}) error {
+	for _, doc := range docs {
+		if obj, ok := doc.(map[string]interface{}); ok {
+			if err := removeIgnoredFields(paths, obj); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
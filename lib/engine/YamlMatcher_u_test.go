@@ -0,0 +1,73 @@
+package engine
+
+import(
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/opwire/opwire-testa/lib/utils"
+)
+
+func TestExamineYamlDocuments(t *testing.T) {
+	body := []byte(`kind: Service
+metadata:
+  name: api
+  labels:
+    app: web
+---
+kind: Deployment
+metadata:
+  name: api
+spec:
+  replicas: 3
+`)
+	TESTCASES := []struct {
+		body MeasureBody
+		failed []string
+	}{
+		{
+			body: MeasureBody{
+				Includes: utils.RefOfString("metadata:\n  labels: {app: web}\n---\nspec: {replicas: 3}\n"),
+				Fields: []MeasureBodyField{
+					{ Path: utils.RefOfString("0.metadata.labels.app"), IsEqualTo: "web" },
+					{ Path: utils.RefOfString("1.spec.replicas"), IsEqualTo: 3 },
+					{ Path: utils.RefOfString("$[1].kind"), IsEqualTo: "Deployment" },
+				},
+			},
+		},
+		{
+			body: MeasureBody{
+				IsEqualTo: utils.RefOfString("kind: Deployment\nmetadata: {name: api}\nspec: {replicas: 3}\n---\nkind: Service\nmetadata: {name: api, labels: {app: web}}\n"),
+			},
+			failed: []string{ "Body/IsEqualTo" },
+		},
+		{
+			body: MeasureBody{
+				IsEqualTo: utils.RefOfString("kind: Service\nmetadata: {name: api, labels: {app: web}}\n---\nkind: Deployment\nmetadata: {name: other}\nspec: {replicas: 3}\n"),
+				IgnoreFields: []string{ "$.metadata.name" },
+			},
+		},
+		{
+			body: MeasureBody{
+				Includes: utils.RefOfString("kind: Service\n---\nspec: {replicas: 2}\n"),
+			},
+			failed: []string{ "Body/Includes" },
+		},
+		{
+			body: MeasureBody{
+				Includes: utils.RefOfString("kind: Service\n---\nkind: Deployment\n---\nkind: Ingress\n"),
+			},
+			failed: []string{ "Body/Includes" },
+		},
+	}
+	for i, c := range TESTCASES {
+		assert.True(t, isMultiDocumentYaml(&c.body, body), "testcase #%d", i)
+		errs := examineYamlDocuments(&c.body, body, false, 0)
+		keys := make([]string, 0)
+		for key := range errs {
+			keys = append(keys, key)
+		}
+		assert.ElementsMatch(t, c.failed, keys, "testcase #%d", i)
+	}
+	assert.False(t, isMultiDocumentYaml(&MeasureBody{ Includes: utils.RefOfString("kind: Service") }, []byte("kind: Service\n")))
+	errs := examineYamlDocuments(&MeasureBody{}, []byte("a: [\n---\nb: 1\n"), false, 0)
+	assert.Contains(t, errs, "Body/ReceivedObject")
+}
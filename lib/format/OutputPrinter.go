@@ -35,6 +35,11 @@ func (w *OutputPrinter) SetWriter(writer io.Writer) {
 	w.writer = writer
 }
 
+// Fork creates a printer with the same options, writing to another writer.
+func (w *OutputPrinter) Fork(writer io.Writer) *OutputPrinter {
+	return &OutputPrinter{ options: w.options, writer: writer }
+}
+
 func (w *OutputPrinter) Printf(format string, args ...interface{}) (n int, err error) {
 	return fmt.Fprintf(w.GetWriter(), format, args...)
 }
@@ -113,6 +118,11 @@ func (w *OutputPrinter) Failure(title string) string {
 	return fmt.Sprintf("[%s] %s", pen("x"), title)
 }
 
+func (w *OutputPrinter) Flaky(title string) string {
+	pen := w.GetPen(FlakyPen)
+	return fmt.Sprintf("[%s] %s", pen("~"), title)
+}
+
 func (w *OutputPrinter) InfoMsg(msg string) string {
 	pen := w.GetPen(SuccessPen)
 	return pen(msg)
@@ -128,6 +138,33 @@ func (w *OutputPrinter) Cracked(title string) string {
 	return fmt.Sprintf("[%s] %s", pen("~"), title)
 }
 
+func (w *OutputPrinter) Unreachable(title string) string {
+	pen := w.GetPen(UnreachablePen)
+	return fmt.Sprintf("[%s] %s", pen("!"), title)
+}
+
+func (w *OutputPrinter) Warning(msg string) string {
+	pen := w.GetPen(SkippedPen)
+	return fmt.Sprintf("!!! %s", pen(msg))
+}
+
+// Diff highlights the removed (-) and the added (+) lines of a diff.
+func (w *OutputPrinter) Diff(block string) string {
+	removedPen := w.GetPen(RemovedLinePen)
+	addedPen := w.GetPen(AddedLinePen)
+	lines := strings.Split(block, "\n")
+	lines = utils.Map(lines, func(line string, number int) string {
+		if strings.HasPrefix(line, "-") {
+			return removedPen(line)
+		}
+		if strings.HasPrefix(line, "+") {
+			return addedPen(line)
+		}
+		return line
+	})
+	return strings.Join(lines, "\n")
+}
+
 func (w *OutputPrinter) SectionTitle(title string) string {
 	return fmt.Sprintf("--- %s", title)
 }
@@ -184,6 +221,14 @@ func (w *OutputPrinter) GetPen(name PenType) Renderer {
 				pen = color.Style{color.FgRed, color.OpBold}.Render
 			case CrackedPen:
 				pen = color.Style{color.FgRed, color.OpBold}.Render
+			case UnreachablePen:
+				pen = color.Style{color.FgMagenta, color.OpBold}.Render
+			case FlakyPen:
+				pen = color.Style{color.FgLightYellow, color.OpBold}.Render
+			case RemovedLinePen:
+				pen = color.Style{color.FgRed}.Render
+			case AddedLinePen:
+				pen = color.Style{color.FgGreen}.Render
 			}
 			Pens[name] = pen
 		}
@@ -225,6 +270,10 @@ const (
 	SuccessPen
 	FailurePen
 	CrackedPen
+	UnreachablePen
+	RemovedLinePen
+	AddedLinePen
+	FlakyPen
 )
 
 var Pens map[PenType]Renderer
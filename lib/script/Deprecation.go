@@ -0,0 +1,41 @@
+package script
+
+import (
+	"fmt"
+	"strings"
+	"github.com/opwire/opwire-testa/lib/utils"
+)
+
+type Deprecation struct {
+	TestCaseIndex int
+	Field string
+	Replacement string
+}
+
+func (d Deprecation) String() string {
+	return fmt.Sprintf("Field [%s] is deprecated, use [%s] instead", d.Field, d.Replacement)
+}
+
+func DetectDeprecations(content []byte) []Deprecation {
+	return detectDeprecations(content, fieldRenamings)
+}
+
+func detectDeprecations(content []byte, renamings []FieldRenaming) []Deprecation {
+	testcases, _, err := utils.LocateTestCases(content)
+	if err != nil || testcases == nil {
+		return nil
+	}
+	deprecations := make([]Deprecation, 0)
+	for i, testcase := range testcases.Content {
+		for _, renaming := range renamings {
+			if key, _ := findField(findNode(testcase, renaming.Path), renaming.From); key != nil {
+				deprecations = append(deprecations, Deprecation{
+					TestCaseIndex: i,
+					Field: strings.Join(append(append([]string{}, renaming.Path...), renaming.From), "."),
+					Replacement: strings.Join(append(append([]string{}, renaming.Path...), renaming.To), "."),
+				})
+			}
+		}
+	}
+	return deprecations
+}
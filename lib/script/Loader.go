@@ -2,8 +2,9 @@ package script
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
-	"regexp"
+	"path/filepath"
 	"strings"
 	"gopkg.in/yaml.v2"
 	"github.com/opwire/opwire-testa/lib/engine"
@@ -12,6 +13,13 @@ import (
 	"github.com/opwire/opwire-testa/lib/utils"
 )
 
+const SCRIPT_FILE_EXT string = ".yml"
+
+// IsScriptFile reports whether a file (by its name) is a test suite file.
+func IsScriptFile(name string) bool {
+	return strings.HasSuffix(name, SCRIPT_FILE_EXT)
+}
+
 type LoaderOptions interface {
 	GetTestDirs() []string
 }
@@ -42,7 +50,7 @@ func (l *Loader) LoadFrom(sourceDirs []string) (map[string]*Descriptor) {
 			sourceDirs = l.source.GetTestDirs()
 		}
 	}
-	locators, _ := l.ReadDirs(sourceDirs, ".yml")
+	locators, _ := l.ReadDirs(sourceDirs, SCRIPT_FILE_EXT)
 	descriptors := l.LoadFiles(locators)
 	return descriptors
 }
@@ -75,8 +83,10 @@ func (l *Loader) LoadFile(locator *Locator) (*Descriptor) {
 		}
 	}
 
-	parser := yaml.NewDecoder(file)
-	err2 := parser.Decode(testsuite)
+	content, err2 := ioutil.ReadAll(file)
+	if err2 == nil {
+		err2 = yaml.Unmarshal(content, testsuite)
+	}
 	if err2 != nil {
 		return &Descriptor{
 			Locator: locator,
@@ -84,6 +94,9 @@ func (l *Loader) LoadFile(locator *Locator) (*Descriptor) {
 		}
 	}
 
+	// detect deprecated fields
+	deprecations := DetectDeprecations(content)
+
 	// validate Test Suite by schema
 	result, err3 := l.validator.Validate(testsuite)
 	if err3 != nil {
@@ -106,9 +119,39 @@ func (l *Loader) LoadFile(locator *Locator) (*Descriptor) {
 		}
 	}
 
+	// resolve the dependencies between the test cases
+	if _, err4 := testsuite.Schedule(); err4 != nil {
+		return &Descriptor{
+			Locator: locator,
+			TestSuite: testsuite,
+			Error: err4,
+		}
+	}
+
+	// parse the skip-if conditions of the test cases
+	if err6 := testsuite.ParseConditions(); err6 != nil {
+		return &Descriptor{
+			Locator: locator,
+			TestSuite: testsuite,
+			Error: err6,
+		}
+	}
+
+	testsuite.SetHome(filepath.Dir(locator.AbsolutePath))
+
+	// load the rows of the data-driven test cases
+	if err5 := testsuite.LoadDataFiles(); err5 != nil {
+		return &Descriptor{
+			Locator: locator,
+			TestSuite: testsuite,
+			Error: err5,
+		}
+	}
+
 	return &Descriptor{
 		Locator: locator,
 		TestSuite: testsuite,
+		Deprecations: deprecations,
 	}
 }
 
@@ -128,8 +171,7 @@ func (l *Loader) ReadDir(sourceDir string, ext string) ([]*Locator, error) {
 	fs := storage.GetFs()
 	err := fs.Walk(sourceDir, func(path string, f os.FileInfo, err error) error {
 		if err == nil && !f.IsDir() {
-			r, err := regexp.MatchString(ext, f.Name())
-			if err == nil && r {
+			if strings.HasSuffix(f.Name(), ext) {
 				locator := &Locator{}
 				locator.AbsolutePath = path
 				locator.RelativePath, _ = utils.DetectRelativePath(path)
@@ -153,6 +195,7 @@ type Locator struct {
 type Descriptor struct {
 	Locator *Locator
 	TestSuite *engine.TestSuite
+	Deprecations []Deprecation
 	Error error
 }
 
@@ -174,6 +217,65 @@ const scriptSchema string = `{
 					"type": "boolean"
 				}
 			]
+		},
+		"before-each": {
+			"oneOf": [
+				{
+					"type": "null"
+				},
+				{
+					"type": "array",
+					"items": {
+						"$ref": "#/definitions/TestCase"
+					}
+				}
+			]
+		},
+		"after-each": {
+			"oneOf": [
+				{
+					"type": "null"
+				},
+				{
+					"type": "array",
+					"items": {
+						"$ref": "#/definitions/TestCase"
+					}
+				}
+			]
+		},
+		"session": {
+			"oneOf": [
+				{
+					"type": "null"
+				},
+				{
+					"type": "object",
+					"properties": {
+						"cookies": {
+							"oneOf": [
+								{
+									"type": "null"
+								},
+								{
+									"type": "boolean"
+								}
+							]
+						},
+						"headers": {
+							"oneOf": [
+								{
+									"type": "null"
+								},
+								{
+									"$ref": "#/definitions/HeaderList"
+								}
+							]
+						}
+					},
+					"additionalProperties": false
+				}
+			]
 		}
 	},
 	"definitions": {
@@ -211,7 +313,7 @@ const scriptSchema string = `{
 							"type": "null"
 						},
 						{
-							"$ref": "#/definitions/Expectation"
+							"$ref": "#/definitions/Capture"
 						}
 					]
 				},
@@ -235,6 +337,26 @@ const scriptSchema string = `{
 						}
 					]
 				},
+				"destructive": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "boolean"
+						}
+					]
+				},
+				"retry-failed": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "boolean"
+						}
+					]
+				},
 				"tags": {
 					"oneOf": [
 						{
@@ -249,6 +371,125 @@ const scriptSchema string = `{
 						}
 					]
 				},
+				"barrier": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "string",
+							"minLength": 1
+						}
+					]
+				},
+				"skip-if": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "string",
+							"minLength": 1
+						}
+					]
+				},
+				"data-file": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "string",
+							"minLength": 1
+						}
+					]
+				},
+				"depends-on": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "array",
+							"items": {
+								"type": "string",
+								"minLength": 1
+							}
+						}
+					]
+				},
+				"pre-request": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "object",
+							"properties": {
+								"engine": {
+									"type": "string",
+									"enum": ["lua"]
+								},
+								"source": {
+									"type": "string",
+									"minLength": 1
+								}
+							},
+							"required": ["engine", "source"],
+							"additionalProperties": false
+						}
+					]
+				},
+				"retry": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "object",
+							"properties": {
+								"attempts": {
+									"type": "integer",
+									"minimum": 1
+								},
+								"backoff": {
+									"type": "string",
+									"minLength": 1
+								},
+								"max-wait": {
+									"type": "string",
+									"minLength": 1
+								}
+							},
+							"additionalProperties": false
+						}
+					]
+				},
+				"repeat": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "object",
+							"properties": {
+								"count": {
+									"type": "integer",
+									"minimum": 1
+								},
+								"delay": {
+									"type": "string",
+									"minLength": 1
+								},
+								"parallel": {
+									"type": "boolean"
+								}
+							},
+							"required": ["count"],
+							"additionalProperties": false
+						}
+					]
+				},
 				"created-time": {
 					"oneOf": [
 						{
@@ -268,7 +509,7 @@ const scriptSchema string = `{
 			"properties": {
 				"method": {
 					"type": "string",
-					"enum": [ "", "GET", "PUT", "POST", "PATCH", "DELETE" ]
+					"enum": [ "", "GET", "HEAD", "OPTIONS", "PUT", "POST", "PATCH", "DELETE" ]
 				},
 				"url": {
 					"type": "string"
@@ -280,18 +521,14 @@ const scriptSchema string = `{
 					"type": "string"
 				},
 				"headers": {
-					"type": "array",
-					"items": {
-						"type": "object",
-						"properties": {
-							"name": {
-								"type": "string"
-							},
-							"value": {
-								"type": "string"
-							}
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"$ref": "#/definitions/HeaderList"
 						}
-					}
+					]
 				},
 				"body": {
 					"type": "string"
@@ -306,14 +543,24 @@ const scriptSchema string = `{
 							"pattern": "^` + utils.TIMEOUT_PATTERN + `$"
 						}
 					]
-				}
-			},
-			"additionalProperties": false
-		},
-		"Expectation": {
-			"type": "object",
-			"properties": {
-				"status-code": {
+				},
+				"expect-continue": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "boolean"
+						}
+					]
+				},
+				"idempotency-key": {
+					"type": "string"
+				},
+				"conditional-on": {
+					"type": "string"
+				},
+				"auth": {
 					"oneOf": [
 						{
 							"type": "null"
@@ -321,22 +568,26 @@ const scriptSchema string = `{
 						{
 							"type": "object",
 							"properties": {
-								"is": {
-									"oneOf": [
-										{
-											"type": "null"
-										},
-										{
-											"$ref": "#/definitions/ComparisonOperators"
-										}
-									]
+								"type": {
+									"type": "string",
+									"enum": [ "ntlm", "negotiate" ]
+								},
+								"username": {
+									"type": "string"
+								},
+								"password": {
+									"type": "string"
+								},
+								"service-principal": {
+									"type": "string"
 								}
 							},
+							"required": [ "type" ],
 							"additionalProperties": false
 						}
 					]
 				},
-				"headers": {
+				"sensitive": {
 					"oneOf": [
 						{
 							"type": "null"
@@ -344,30 +595,20 @@ const scriptSchema string = `{
 						{
 							"type": "object",
 							"properties": {
-								"total": {
+								"query": {
 									"oneOf": [
 										{
 											"type": "null"
 										},
 										{
-											"type": "object",
-											"properties": {
-												"is": {
-													"oneOf": [
-														{
-															"type": "null"
-														},
-														{
-															"$ref": "#/definitions/IntegerComparators"
-														}
-													]
-												}
-											},
-											"additionalProperties": false
+											"type": "array",
+											"items": {
+												"type": "string"
+											}
 										}
 									]
 								},
-								"items": {
+								"body": {
 									"oneOf": [
 										{
 											"type": "null"
@@ -375,32 +616,174 @@ const scriptSchema string = `{
 										{
 											"type": "array",
 											"items": {
-												"type": "object",
-												"properties": {
-													"name": {
-														"type": "string"
-													},
-													"is": {
-														"oneOf": [
-															{
-																"type": "null"
-															},
-															{
-																"$ref": "#/definitions/ComparisonOperators"
-															}
-														]
-													}
-												},
-												"additionalProperties": false
+												"type": "string"
 											}
 										}
 									]
 								}
-							}
+							},
+							"additionalProperties": false
 						}
 					]
+				}
+			},
+			"additionalProperties": false
+		},
+		"HeaderList": {
+			"type": "array",
+			"items": {
+				"type": "object",
+				"properties": {
+					"name": {
+						"type": "string"
+					},
+					"value": {
+						"type": "string"
+					},
+					"sensitive": {
+						"type": "boolean"
+					}
+				}
+			}
+		},
+		"Capture": {
+			"type": "object",
+			"properties": {
+				"store-id": {
+					"type": "string"
 				},
-				"body": {
+				"session-headers": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"$ref": "#/definitions/HeaderList"
+						}
+					]
+				},
+				"normalize": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "array",
+							"items": {
+								"type": "object",
+								"properties": {
+									"field": {
+										"type": "string",
+										"minLength": 1
+									},
+									"strategy": {
+										"type": "string",
+										"minLength": 1
+									}
+								},
+								"additionalProperties": false
+							}
+						}
+					]
+				},
+				"variables": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "array",
+							"items": {
+								"type": "object",
+								"properties": {
+									"name": {
+										"type": "string",
+										"pattern": "^[A-Za-z0-9_\\-]+$"
+									},
+									"json-path": {
+										"oneOf": [
+											{
+												"type": "null"
+											},
+											{
+												"type": "string",
+												"minLength": 1
+											}
+										]
+									},
+									"header": {
+										"oneOf": [
+											{
+												"type": "null"
+											},
+											{
+												"type": "string",
+												"minLength": 1
+											}
+										]
+									},
+									"regex": {
+										"oneOf": [
+											{
+												"type": "null"
+											},
+											{
+												"type": "string",
+												"minLength": 1
+											}
+										]
+									}
+								},
+								"additionalProperties": false
+							}
+						}
+					]
+				}
+			}
+		},
+		"Expectation": {
+			"type": "object",
+			"properties": {
+				"all-of": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "array",
+							"items": {
+								"$ref": "#/definitions/Expectation"
+							}
+						}
+					]
+				},
+				"any-of": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "array",
+							"items": {
+								"$ref": "#/definitions/Expectation"
+							}
+						}
+					]
+				},
+				"none-of": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "array",
+							"items": {
+								"$ref": "#/definitions/Expectation"
+							}
+						}
+					]
+				},
+				"status-code": {
 					"oneOf": [
 						{
 							"type": "null"
@@ -408,80 +791,234 @@ const scriptSchema string = `{
 						{
 							"type": "object",
 							"properties": {
-								"has-format": {
+								"is": {
 									"oneOf": [
 										{
 											"type": "null"
 										},
 										{
-											"type": "string",
-											"enum": ["` + utils.BODY_FORMAT_JSON + `", "` + utils.BODY_FORMAT_YAML + `", "` + utils.BODY_FORMAT_FLAT + `"]
+											"$ref": "#/definitions/ComparisonOperators"
 										}
 									]
 								},
-								"includes": {
+								"belongs-to": {
 									"oneOf": [
 										{
 											"type": "null"
 										},
 										{
-											"type": "string"
+											"type": "array",
+											"items": {
+												"type": "integer"
+											},
+											"minItems": 2,
+											"maxItems": 2
 										}
 									]
 								},
-								"is-equal-to": {
+								"is-one-of": {
 									"oneOf": [
 										{
 											"type": "null"
 										},
 										{
-											"type": "string"
+											"type": "array",
+											"items": {
+												"type": "integer"
+											},
+											"minItems": 1
 										}
 									]
 								},
-								"match-with": {
+								"message": {
 									"oneOf": [
 										{
 											"type": "null"
 										},
 										{
-											"type": "string"
+											"type": "string",
+											"minLength": 1
 										}
 									]
 								},
-								"fields": {
+								"severity": {
 									"oneOf": [
 										{
 											"type": "null"
 										},
 										{
-											"type": "array",
-											"items": {
-												"oneOf": [
-													{
-														"type": "null"
-													},
-													{
-														"type": "object",
-														"properties": {
-															"path": {
-																"type": "string"
+											"type": "string",
+											"enum": [ "error", "warning" ]
+										}
+									]
+								},
+								"is-not-equal-to": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "integer"
+										}
+									]
+								}
+							},
+							"additionalProperties": false
+						}
+					]
+				},
+				"headers": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"$ref": "#/definitions/Headers"
+						}
+					]
+				},
+				"trailers": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"$ref": "#/definitions/Headers"
+						}
+					]
+				},
+				"cookies": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "array",
+							"items": {
+								"type": "object",
+								"properties": {
+									"name": {
+										"type": "string",
+										"minLength": 1
+									},
+									"is-absent": {
+										"oneOf": [
+											{
+												"type": "null"
+											},
+											{
+												"type": "boolean"
+											}
+										]
+									},
+									"is": {
+										"oneOf": [
+											{
+												"type": "null"
+											},
+											{
+												"$ref": "#/definitions/ComparisonOperators"
+											}
+										]
+									},
+									"matches": {
+										"oneOf": [
+											{
+												"type": "null"
+											},
+											{
+												"type": "string",
+												"minLength": 1
+											}
+										]
+									},
+									"secure": {
+										"oneOf": [
+											{
+												"type": "null"
+											},
+											{
+												"type": "boolean"
+											}
+										]
+									},
+									"http-only": {
+										"oneOf": [
+											{
+												"type": "null"
+											},
+											{
+												"type": "boolean"
+											}
+										]
+									},
+									"same-site": {
+										"oneOf": [
+											{
+												"type": "null"
+											},
+											{
+												"type": "string",
+												"enum": ["Strict", "Lax", "None", "strict", "lax", "none"]
+											}
+										]
+									},
+									"max-age": {
+										"oneOf": [
+											{
+												"type": "null"
+											},
+											{
+												"type": "object",
+												"properties": {
+													"is": {
+														"oneOf": [
+															{
+																"type": "null"
 															},
-															"is": {
-																"oneOf": [
-																	{
-																		"type": "null"
-																	},
-																	{
-																		"$ref": "#/definitions/ComparisonOperators"
-																	}
-																]
+															{
+																"$ref": "#/definitions/IntegerComparators"
 															}
-														},
-														"additionalProperties": false
+														]
 													}
-												]
+												},
+												"additionalProperties": false
+											}
+										]
+									},
+									"severity": {
+										"oneOf": [
+											{
+												"type": "null"
+											},
+											{
+												"type": "string",
+												"enum": [ "error", "warning" ]
 											}
+										]
+									}
+								},
+								"required": ["name"],
+								"additionalProperties": false
+							}
+						}
+					]
+				},
+				"body-size": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "object",
+							"properties": {
+								"is": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"$ref": "#/definitions/IntegerComparators"
 										}
 									]
 								}
@@ -489,6 +1026,1577 @@ const scriptSchema string = `{
 							"additionalProperties": false
 						}
 					]
+				},
+				"allow-methods": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "object",
+							"properties": {
+								"includes": {
+									"$ref": "#/definitions/MethodList"
+								},
+								"excludes": {
+									"$ref": "#/definitions/MethodList"
+								}
+							},
+							"additionalProperties": false
+						}
+					]
+				},
+				"got-continue": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "boolean"
+						}
+					]
+				},
+				"ranges": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "object",
+							"properties": {
+								"chunk-size": {
+									"type": "integer",
+									"minimum": 1
+								}
+							},
+							"additionalProperties": false
+						}
+					]
+				},
+				"paired-get": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "boolean"
+						}
+					]
+				},
+				"content-length": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "object",
+							"properties": {
+								"is-equal-to": {
+									"type": "integer",
+									"minimum": 0
+								},
+								"severity": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "string",
+											"enum": [ "error", "warning" ]
+										}
+									]
+								}
+							},
+							"required": [ "is-equal-to" ],
+							"additionalProperties": false
+						}
+					]
+				},
+				"network": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "object",
+							"properties": {
+								"remote-ip": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "string"
+										}
+									]
+								},
+								"tls-version": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "string"
+										}
+									]
+								},
+								"cipher-suite": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "string"
+										}
+									]
+								},
+								"alpn": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "string"
+										}
+									]
+								},
+								"severity": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "string",
+											"enum": [ "error", "warning" ]
+										}
+									]
+								}
+							},
+							"additionalProperties": false
+						}
+					]
+				},
+				"asserts": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "array",
+							"items": {
+								"type": "string",
+								"minLength": 1
+							}
+						}
+					]
+				},
+				"script": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "object",
+							"properties": {
+								"engine": {
+									"type": "string",
+									"enum": ["js", "lua"]
+								},
+								"source": {
+									"type": "string",
+									"minLength": 1
+								}
+							},
+							"required": ["engine", "source"],
+							"additionalProperties": false
+						}
+					]
+				},
+				"soft-assertions": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "boolean"
+						}
+					]
+				},
+				"charset": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "object",
+							"properties": {
+								"is-equal-to": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "string",
+											"minLength": 1
+										}
+									]
+								},
+								"matches-content": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "boolean"
+										}
+									]
+								}
+							},
+							"additionalProperties": false
+						}
+					]
+				},
+				"redirects": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "object",
+							"properties": {
+								"total": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "object",
+											"properties": {
+												"is": {
+													"oneOf": [
+														{
+															"type": "null"
+														},
+														{
+															"$ref": "#/definitions/IntegerComparators"
+														}
+													]
+												}
+											},
+											"additionalProperties": false
+										}
+									]
+								},
+								"hops": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "array",
+											"items": {
+												"type": "object",
+												"properties": {
+													"status-code": {
+														"oneOf": [
+															{
+																"type": "null"
+															},
+															{
+																"type": "integer",
+																"minimum": 300,
+																"maximum": 399
+															}
+														]
+													},
+													"location-matches": {
+														"oneOf": [
+															{
+																"type": "null"
+															},
+															{
+																"type": "string"
+															}
+														]
+													}
+												},
+												"additionalProperties": false
+											}
+										}
+									]
+								}
+							},
+							"additionalProperties": false
+						}
+					]
+				},
+				"graphql": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "object",
+							"properties": {
+								"no-errors": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "boolean"
+										}
+									]
+								},
+								"error-includes": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "string"
+										}
+									]
+								},
+								"data-path": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "string",
+											"minLength": 1
+										}
+									]
+								},
+								"is-equal-to": {
+									"type": ["null", "boolean", "number", "string"]
+								},
+								"exists": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "boolean"
+										}
+									]
+								},
+								"fields": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "array",
+											"items": {
+												"type": "object",
+												"required": ["path"]
+											}
+										}
+									]
+								}
+							},
+							"additionalProperties": false
+						}
+					]
+				},
+				"custom": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "object",
+							"properties": {
+								"name": {
+									"type": "string",
+									"minLength": 1
+								},
+								"args": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "object"
+										}
+									]
+								}
+							},
+							"required": ["name"],
+							"additionalProperties": false
+						}
+					]
+				},
+				"certificate": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "object",
+							"properties": {
+								"issuer-cn": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "string"
+										}
+									]
+								},
+								"covers-hosts": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "array",
+											"items": {
+												"type": "string",
+												"minLength": 1
+											}
+										}
+									]
+								},
+								"days-until-expiry": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "object",
+											"properties": {
+												"is": {
+													"oneOf": [
+														{
+															"type": "null"
+														},
+														{
+															"$ref": "#/definitions/IntegerComparators"
+														}
+													]
+												}
+											},
+											"additionalProperties": false
+										}
+									]
+								}
+							},
+							"additionalProperties": false
+						}
+					]
+				},
+				"protocol": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "string"
+						}
+					]
+				},
+				"version": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "object",
+							"properties": {
+								"is-equal-to": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "string",
+											"minLength": 1
+										}
+									]
+								},
+								"is-one-of": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "array",
+											"items": {
+												"type": "string",
+												"minLength": 1
+											}
+										}
+									]
+								},
+								"is-not-equal-to": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "string",
+											"minLength": 1
+										}
+									]
+								},
+								"message": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "string",
+											"minLength": 1
+										}
+									]
+								},
+								"severity": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "string",
+											"enum": [ "error", "warning" ]
+										}
+									]
+								}
+							},
+							"additionalProperties": false
+						}
+					]
+				},
+				"content-encoding": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "object",
+							"properties": {
+								"is-equal-to": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "string"
+										}
+									]
+								},
+								"decoded-size": {
+									"$ref": "#/definitions/SizeComparators"
+								}
+							},
+							"additionalProperties": false
+						}
+					]
+				},
+				"status-text": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "object",
+							"properties": {
+								"is-equal-to": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "string"
+										}
+									]
+								},
+								"matches": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "string",
+											"minLength": 1
+										}
+									]
+								},
+								"is-standard": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "boolean"
+										}
+									]
+								},
+								"message": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "string",
+											"minLength": 1
+										}
+									]
+								},
+								"severity": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "string",
+											"enum": [ "error", "warning" ]
+										}
+									]
+								}
+							},
+							"additionalProperties": false
+						}
+					]
+				},
+				"date": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "object",
+							"properties": {
+								"fresh-within": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "string"
+										}
+									]
+								}
+							}
+						}
+					]
+				},
+				"body": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "object",
+							"properties": {
+								"has-format": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "string",
+											"enum": ["` + utils.BODY_FORMAT_JSON + `", "` + utils.BODY_FORMAT_YAML + `", "` + utils.BODY_FORMAT_FLAT + `", "` + utils.BODY_FORMAT_XML + `", "` + utils.BODY_FORMAT_HTML + `", "` + utils.BODY_FORMAT_CSV + `"]
+										}
+									]
+								},
+								"has-schema": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "string",
+											"minLength": 1
+										}
+									]
+								},
+								"includes": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "string"
+										}
+									]
+								},
+								"ignore-fields": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "array",
+											"items": {
+												"type": "string",
+												"minLength": 1
+											}
+										}
+									]
+								},
+								"is-empty": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "boolean"
+										}
+									]
+								},
+								"canonicalize": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "boolean"
+										}
+									]
+								},
+								"has-sha256": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "string",
+											"pattern": "^[0-9a-fA-F]{64}$"
+										}
+									]
+								},
+								"has-md5": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "string",
+											"pattern": "^[0-9a-fA-F]{32}$"
+										}
+									]
+								},
+								"has-crc32": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "string",
+											"pattern": "^[0-9a-fA-F]{8}$"
+										}
+									]
+								},
+								"not-includes": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "string",
+											"minLength": 1
+										}
+									]
+								},
+								"not-matches": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "string",
+											"minLength": 1
+										}
+									]
+								},
+								"starts-with": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "string",
+											"minLength": 1
+										}
+									]
+								},
+								"ends-with": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "string",
+											"minLength": 1
+										}
+									]
+								},
+								"has-size": {
+									"$ref": "#/definitions/SizeComparators"
+								},
+								"is-equal-to": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "string"
+										}
+									]
+								},
+								"match-with": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "string"
+										}
+									]
+								},
+								"fields": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "array",
+											"items": {
+												"$ref": "#/definitions/BodyField"
+											}
+										}
+									]
+								},
+								"has-line-count": {
+									"$ref": "#/definitions/SizeComparators"
+								},
+								"line-matches": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "object",
+											"properties": {
+												"number": {
+													"type": "integer",
+													"minimum": 1
+												},
+												"pattern": {
+													"type": "string",
+													"minLength": 1
+												}
+											},
+											"required": [ "number", "pattern" ],
+											"additionalProperties": false
+										}
+									]
+								},
+								"severity": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "string",
+											"enum": [ "error", "warning" ]
+										}
+									]
+								},
+								"every-line-matches": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "string",
+											"minLength": 1
+										}
+									]
+								},
+								"csv": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"type": "object",
+											"properties": {
+												"delimiter": {
+													"oneOf": [
+														{
+															"type": "null"
+														},
+														{
+															"type": "string",
+															"minLength": 1,
+															"maxLength": 2
+														}
+													]
+												},
+												"has-header": {
+													"oneOf": [
+														{
+															"type": "null"
+														},
+														{
+															"type": "boolean"
+														}
+													]
+												},
+												"row-count": {
+													"$ref": "#/definitions/SizeComparators"
+												},
+												"columns": {
+													"oneOf": [
+														{
+															"type": "null"
+														},
+														{
+															"type": "array",
+															"items": {
+																"$ref": "#/definitions/BodyField"
+															}
+														}
+													]
+												}
+											},
+											"additionalProperties": false
+										}
+									]
+								}
+							},
+							"additionalProperties": false
+						}
+					]
+				}
+			}
+		},
+		"BodyField": {
+			"oneOf": [
+				{
+					"type": "null"
+				},
+				{
+					"type": "object",
+					"properties": {
+						"path": {
+							"oneOf": [
+								{
+									"type": "null"
+								},
+								{
+									"type": "string"
+								}
+							]
+						},
+						"select": {
+							"oneOf": [
+								{
+									"type": "null"
+								},
+								{
+									"type": "string",
+									"minLength": 1
+								}
+							]
+						},
+						"is": {
+							"oneOf": [
+								{
+									"type": "null"
+								},
+								{
+									"$ref": "#/definitions/ComparisonOperators"
+								}
+							]
+						},
+						"is-equal-to": {
+							"type": ["null", "boolean", "number", "string"]
+						},
+						"text-includes": {
+							"oneOf": [
+								{
+									"type": "null"
+								},
+								{
+									"type": "string"
+								}
+							]
+						},
+						"match-with": {
+							"oneOf": [
+								{
+									"type": "null"
+								},
+								{
+									"type": "string"
+								}
+							]
+						},
+						"exists": {
+							"oneOf": [
+								{
+									"type": "null"
+								},
+								{
+									"type": "boolean"
+								}
+							]
+						},
+						"has-length": {
+							"oneOf": [
+								{
+									"type": "null"
+								},
+								{
+									"type": "integer",
+									"minimum": 0
+								}
+							]
+						},
+						"has-min-length": {
+							"oneOf": [
+								{
+									"type": "null"
+								},
+								{
+									"type": "integer",
+									"minimum": 0
+								}
+							]
+						},
+						"is-not-equal-to": {},
+						"is-one-of": {
+							"oneOf": [
+								{
+									"type": "null"
+								},
+								{
+									"type": "array",
+									"minItems": 1
+								}
+							]
+						},
+						"not-matches": {
+							"oneOf": [
+								{
+									"type": "null"
+								},
+								{
+									"type": "string",
+									"minLength": 1
+								}
+							]
+						},
+						"starts-with": {
+							"oneOf": [
+								{
+									"type": "null"
+								},
+								{
+									"type": "string",
+									"minLength": 1
+								}
+							]
+						},
+						"ends-with": {
+							"oneOf": [
+								{
+									"type": "null"
+								},
+								{
+									"type": "string",
+									"minLength": 1
+								}
+							]
+						},
+						"contains-element": {},
+						"is-rfc3339": {
+							"oneOf": [
+								{
+									"type": "null"
+								},
+								{
+									"type": "boolean"
+								}
+							]
+						},
+						"matches-layout": {
+							"oneOf": [
+								{
+									"type": "null"
+								},
+								{
+									"type": "string",
+									"minLength": 1
+								}
+							]
+						},
+						"is-before": {
+							"oneOf": [
+								{
+									"type": "null"
+								},
+								{
+									"type": "string",
+									"minLength": 1
+								}
+							]
+						},
+						"is-after": {
+							"oneOf": [
+								{
+									"type": "null"
+								},
+								{
+									"type": "string",
+									"minLength": 1
+								}
+							]
+						},
+						"every-element-matches": {},
+						"is-close-to": {
+							"oneOf": [
+								{
+									"type": "null"
+								},
+								{
+									"type": "object",
+									"properties": {
+										"value": {
+											"type": "number"
+										},
+										"delta": {
+											"type": "number",
+											"minimum": 0
+										}
+									},
+									"required": ["value", "delta"],
+									"additionalProperties": false
+								}
+							]
+						},
+						"is-type": {
+							"oneOf": [
+								{
+									"type": "null"
+								},
+								{
+									"type": "string",
+									"enum": ["string", "number", "boolean", "array", "object", "null"]
+								}
+							]
+						},
+						"message": {
+							"oneOf": [
+								{
+									"type": "null"
+								},
+								{
+									"type": "string",
+									"minLength": 1
+								}
+							]
+						},
+						"severity": {
+							"oneOf": [
+								{
+									"type": "null"
+								},
+								{
+									"type": "string",
+									"enum": [ "error", "warning" ]
+								}
+							]
+						},
+						"normalize": {
+							"oneOf": [
+								{
+									"type": "null"
+								},
+								{
+									"type": "string"
+								}
+							]
+						}
+					},
+					"additionalProperties": false
+				}
+			]
+		},
+		"SizeComparators": {
+			"oneOf": [
+				{
+					"type": "null"
+				},
+				{
+					"type": "object",
+					"properties": {
+						"is-equal-to": {
+							"oneOf": [
+								{
+									"type": "null"
+								},
+								{
+									"type": "integer",
+									"minimum": 0
+								}
+							]
+						},
+						"is-lt": {
+							"oneOf": [
+								{
+									"type": "null"
+								},
+								{
+									"type": "integer",
+									"minimum": 0
+								}
+							]
+						},
+						"is-lte": {
+							"oneOf": [
+								{
+									"type": "null"
+								},
+								{
+									"type": "integer",
+									"minimum": 0
+								}
+							]
+						},
+						"is-gt": {
+							"oneOf": [
+								{
+									"type": "null"
+								},
+								{
+									"type": "integer",
+									"minimum": 0
+								}
+							]
+						},
+						"is-gte": {
+							"oneOf": [
+								{
+									"type": "null"
+								},
+								{
+									"type": "integer",
+									"minimum": 0
+								}
+							]
+						}
+					},
+					"additionalProperties": false
+				}
+			]
+		},
+		"MethodList": {
+			"oneOf": [
+				{
+					"type": "null"
+				},
+				{
+					"type": "array",
+					"items": {
+						"type": "string",
+						"enum": [ "GET", "HEAD", "OPTIONS", "PUT", "POST", "PATCH", "DELETE", "CONNECT", "TRACE" ]
+					}
+				}
+			]
+		},
+		"Headers": {
+			"type": "object",
+			"properties": {
+				"total": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "object",
+							"properties": {
+								"is": {
+									"oneOf": [
+										{
+											"type": "null"
+										},
+										{
+											"$ref": "#/definitions/IntegerComparators"
+										}
+									]
+								}
+							},
+							"additionalProperties": false
+						}
+					]
+				},
+				"items": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "array",
+							"items": {
+								"type": "object",
+								"properties": {
+									"name": {
+										"type": "string"
+									},
+									"is": {
+										"oneOf": [
+											{
+												"type": "null"
+											},
+											{
+												"$ref": "#/definitions/ComparisonOperators"
+											}
+										]
+									},
+									"matches": {
+										"oneOf": [
+											{
+												"type": "null"
+											},
+											{
+												"type": "string",
+												"minLength": 1
+											}
+										]
+									},
+									"is-absent": {
+										"oneOf": [
+											{
+												"type": "null"
+											},
+											{
+												"type": "boolean"
+											}
+										]
+									},
+									"ignore-case": {
+										"oneOf": [
+											{
+												"type": "null"
+											},
+											{
+												"type": "boolean"
+											}
+										]
+									},
+									"trim": {
+										"oneOf": [
+											{
+												"type": "null"
+											},
+											{
+												"type": "boolean"
+											}
+										]
+									},
+									"has-values": {
+										"oneOf": [
+											{
+												"type": "null"
+											},
+											{
+												"type": "array",
+												"items": {
+													"type": "string"
+												}
+											}
+										]
+									},
+									"contains-value": {
+										"oneOf": [
+											{
+												"type": "null"
+											},
+											{
+												"type": "string"
+											}
+										]
+									},
+									"message": {
+										"oneOf": [
+											{
+												"type": "null"
+											},
+											{
+												"type": "string",
+												"minLength": 1
+											}
+										]
+									},
+									"severity": {
+										"oneOf": [
+											{
+												"type": "null"
+											},
+											{
+												"type": "string",
+												"enum": [ "error", "warning" ]
+											}
+										]
+									},
+									"ordered": {
+										"oneOf": [
+											{
+												"type": "null"
+											},
+											{
+												"type": "boolean"
+											}
+										]
+									},
+									"token-list": {
+										"oneOf": [
+											{
+												"type": "null"
+											},
+											{
+												"type": "boolean"
+											}
+										]
+									},
+									"is-not-equal-to": {
+										"oneOf": [
+											{
+												"type": "null"
+											},
+											{
+												"type": "string",
+												"minLength": 1
+											}
+										]
+									},
+									"is-one-of": {
+										"oneOf": [
+											{
+												"type": "null"
+											},
+											{
+												"type": "array",
+												"items": {
+													"type": "string"
+												},
+												"minItems": 1
+											}
+										]
+									},
+									"not-includes": {
+										"oneOf": [
+											{
+												"type": "null"
+											},
+											{
+												"type": "string",
+												"minLength": 1
+											}
+										]
+									},
+									"not-matches": {
+										"oneOf": [
+											{
+												"type": "null"
+											},
+											{
+												"type": "string",
+												"minLength": 1
+											}
+										]
+									},
+									"starts-with": {
+										"oneOf": [
+											{
+												"type": "null"
+											},
+											{
+												"type": "string",
+												"minLength": 1
+											}
+										]
+									},
+									"ends-with": {
+										"oneOf": [
+											{
+												"type": "null"
+											},
+											{
+												"type": "string",
+												"minLength": 1
+											}
+										]
+									},
+									"is-rfc3339": {
+										"oneOf": [
+											{
+												"type": "null"
+											},
+											{
+												"type": "boolean"
+											}
+										]
+									},
+									"matches-layout": {
+										"oneOf": [
+											{
+												"type": "null"
+											},
+											{
+												"type": "string",
+												"minLength": 1
+											}
+										]
+									},
+									"is-before": {
+										"oneOf": [
+											{
+												"type": "null"
+											},
+											{
+												"type": "string",
+												"minLength": 1
+											}
+										]
+									},
+									"is-after": {
+										"oneOf": [
+											{
+												"type": "null"
+											},
+											{
+												"type": "string",
+												"minLength": 1
+											}
+										]
+									}
+								},
+								"additionalProperties": false
+							}
+						}
+					]
+				},
+				"forbidden": {
+					"oneOf": [
+						{
+							"type": "null"
+						},
+						{
+							"type": "array",
+							"items": {
+								"type": "string",
+								"minLength": 1
+							}
+						}
+					]
 				}
 			}
 		},
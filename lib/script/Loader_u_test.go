@@ -0,0 +1,61 @@
+package script
+
+import(
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsScriptFile(t *testing.T) {
+	assert.True(t, IsScriptFile("users.yml"))
+	assert.True(t, IsScriptFile("users.v2.yml"))
+	assert.False(t, IsScriptFile("ayml.txt"))
+	assert.False(t, IsScriptFile("users.yml.bak"))
+	assert.False(t, IsScriptFile("users.yaml"))
+}
+
+func TestLoader_LoadFile_Severity(t *testing.T) {
+	dir, err := ioutil.TempDir("", "opwire-testa-loader-")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "severity.yml")
+	assert.Nil(t, ioutil.WriteFile(path, []byte(`testcases:
+- title: Exact length
+  request:
+    url: http://localhost:17779/items
+  expectation:
+    content-length: 42
+- title: Advised matchers
+  request:
+    url: http://localhost:17779/items
+  expectation:
+    content-length:
+      is-equal-to: 42
+      severity: warning
+    cookies:
+    - name: session
+      secure: true
+      severity: warning
+    network:
+      alpn: h2
+      severity: warning
+    body:
+      has-format: json
+      severity: warning
+`), 0644))
+
+	l, err := NewLoader(nil)
+	assert.Nil(t, err)
+	descriptor := l.LoadFile(&Locator{ AbsolutePath: path, Home: dir })
+	assert.Nil(t, descriptor.Error)
+	testcases := descriptor.TestSuite.TestCases
+	assert.Equal(t, int64(42), *testcases[0].Expectation.ContentLength.IsEqualTo)
+	assert.Nil(t, testcases[0].Expectation.ContentLength.Severity)
+	assert.Equal(t, int64(42), *testcases[1].Expectation.ContentLength.IsEqualTo)
+	assert.Equal(t, "warning", *testcases[1].Expectation.ContentLength.Severity)
+	assert.Equal(t, "warning", *testcases[1].Expectation.Cookies[0].Severity)
+	assert.Equal(t, "warning", *testcases[1].Expectation.Network.Severity)
+	assert.Equal(t, "warning", *testcases[1].Expectation.Body.Severity)
+}
@@ -0,0 +1,230 @@
+package script
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"gopkg.in/yaml.v3"
+	"github.com/opwire/opwire-testa/lib/utils"
+)
+
+type MigratorOptions interface {
+	GetVersion() string
+}
+
+// Migrator renames the deprecated fields of the testcases in the text of a
+// script file, only the renamed keys and the version are touched so that
+// comments and layout are preserved.
+type Migrator struct {
+	version string
+	renamings []FieldRenaming
+}
+
+func NewMigrator(opts MigratorOptions) (ref *Migrator, err error) {
+	ref = &Migrator{}
+	if opts != nil {
+		ref.version = opts.GetVersion()
+	}
+	ref.renamings = fieldRenamings
+	return ref, err
+}
+
+type FieldRenaming struct {
+	Path []string
+	From string
+	To string
+	// Wrap is the field of the new mapping which receives the scalar value of
+	// the former field (protocol: X -> version: { is-equal-to: X })
+	Wrap string
+}
+
+func (f FieldRenaming) String() string {
+	prefix := strings.Join(f.Path, ".")
+	if len(prefix) > 0 {
+		prefix = prefix + "."
+	}
+	if len(f.Wrap) > 0 {
+		return fmt.Sprintf("%s%s -> %s%s.%s", prefix, f.From, prefix, f.To, f.Wrap)
+	}
+	return fmt.Sprintf("%s%s -> %s%s", prefix, f.From, prefix, f.To)
+}
+
+// fieldRenamings lists the fields renamed by the script schema, the schema
+// keeps accepting the former name (reported as deprecated) until it is
+// removed.
+var fieldRenamings = []FieldRenaming{
+	{
+		Path: []string{"expectation"},
+		From: "protocol",
+		To: "version",
+		Wrap: "is-equal-to",
+	},
+}
+
+type textEdit struct {
+	line int
+	column int
+	length int
+	text string
+}
+
+type lineInsert struct {
+	line int
+	lines []string
+}
+
+func (m *Migrator) Migrate(content []byte) ([]byte, []string, error) {
+	changes := make([]string, 0)
+	testcases, endLine, err := utils.LocateTestCases(content)
+	if err != nil {
+		return content, nil, err
+	}
+	if testcases == nil {
+		return content, changes, nil
+	}
+
+	lines := strings.Split(string(content), "\n")
+
+	edits := make([]textEdit, 0)
+	inserts := make([]lineInsert, 0)
+	for i, testcase := range testcases.Content {
+		if testcase.Kind != yaml.MappingNode {
+			continue
+		}
+		changed := false
+		for _, renaming := range m.renamings {
+			node := findNode(testcase, renaming.Path)
+			if key, value := findField(node, renaming.From); key != nil {
+				if other, _ := findField(node, renaming.To); other != nil {
+					continue
+				}
+				edits = append(edits, replaceScalar(key, renaming.To))
+				if len(renaming.Wrap) > 0 {
+					wrapping, err := wrapScalar(lines, value, renaming.Wrap)
+					if err != nil {
+						return content, nil, fmt.Errorf("Testcase [%d]: %s", i, err.Error())
+					}
+					edits = append(edits, wrapping...)
+				}
+				changes = append(changes, fmt.Sprintf("testcases[%d]: %s", i, renaming.String()))
+				changed = true
+			}
+		}
+		if !changed || len(m.version) == 0 {
+			continue
+		}
+		if _, value := findField(testcase, "version"); value != nil {
+			if value.Kind != yaml.ScalarNode {
+				return content, nil, fmt.Errorf("Testcase [%d]: the version must be a string", i)
+			}
+			edits = append(edits, replaceScalar(value, m.version))
+		} else if testcase.Style & yaml.FlowStyle == 0 {
+			end := utils.FindTestCaseEnd(lines, testcases, i, endLine)
+			indent := strings.Repeat(" ", testcase.Column - 1)
+			inserts = append(inserts, lineInsert{ line: end, lines: []string{ indent + "version: " + m.version } })
+		}
+	}
+	if len(changes) == 0 {
+		return content, changes, nil
+	}
+
+	// replace the keys from the end of the lines, the positions of the other
+	// keys of a line stay valid
+	sort.Slice(edits, func(i, j int) bool {
+		if edits[i].line != edits[j].line {
+			return edits[i].line > edits[j].line
+		}
+		return edits[i].column > edits[j].column
+	})
+	for _, edit := range edits {
+		text := lines[edit.line]
+		if edit.column + edit.length > len(text) {
+			return content, nil, fmt.Errorf("Unable to locate the field at line %d", edit.line + 1)
+		}
+		lines[edit.line] = text[:edit.column] + edit.text + text[edit.column + edit.length:]
+	}
+	for i := len(inserts) - 1; i >= 0; i-- {
+		insert := inserts[i]
+		tail := append(append([]string{}, insert.lines...), lines[insert.line:]...)
+		lines = append(lines[:insert.line], tail...)
+	}
+	return []byte(strings.Join(lines, "\n")), changes, nil
+}
+
+// replaceScalar replaces the text of a single line scalar, keeping its quotes.
+func replaceScalar(node *yaml.Node, value string) textEdit {
+	edit := textEdit{ line: node.Line - 1, column: node.Column - 1, length: len(node.Value), text: value }
+	if node.Style & (yaml.DoubleQuotedStyle | yaml.SingleQuotedStyle) != 0 {
+		edit.column += 1
+	}
+	return edit
+}
+
+// wrapScalar turns a single line scalar into a flow mapping of one field,
+// the text of the scalar (its quotes included) is kept.
+func wrapScalar(lines []string, node *yaml.Node, field string) ([]textEdit, error) {
+	if node.Kind != yaml.ScalarNode || node.Style & (yaml.LiteralStyle | yaml.FoldedStyle) != 0 {
+		return nil, fmt.Errorf("Unable to migrate the value at line %d, a single line value is expected", node.Line)
+	}
+	line, column := node.Line - 1, node.Column - 1
+	text := lines[line]
+	end := -1
+	switch {
+	case node.Style & yaml.DoubleQuotedStyle != 0:
+		for i := column + 1; i < len(text); i++ {
+			if text[i] == '\\' {
+				i++
+			} else if text[i] == '"' {
+				end = i + 1
+				break
+			}
+		}
+	case node.Style & yaml.SingleQuotedStyle != 0:
+		for i := column + 1; i < len(text); i++ {
+			if text[i] == '\'' {
+				if i + 1 < len(text) && text[i + 1] == '\'' {
+					i++
+					continue
+				}
+				end = i + 1
+				break
+			}
+		}
+	default:
+		if column + len(node.Value) <= len(text) && text[column:column + len(node.Value)] == node.Value {
+			end = column + len(node.Value)
+		}
+	}
+	if end < 0 {
+		return nil, fmt.Errorf("Unable to locate the value at line %d", node.Line)
+	}
+	return []textEdit{
+		{ line: line, column: end, length: 0, text: " }" },
+		{ line: line, column: column, length: 0, text: "{ " + field + ": " },
+	}, nil
+}
+
+// findNode returns the mapping at the path of keys under the node, or nil.
+func findNode(node *yaml.Node, path []string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	if len(path) == 0 {
+		return node
+	}
+	_, value := findField(node, path[0])
+	return findNode(value, path[1:])
+}
+
+// findField returns the key and the value of a field of a mapping.
+func findField(node *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i + 1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i], node.Content[i + 1]
+		}
+	}
+	return nil, nil
+}
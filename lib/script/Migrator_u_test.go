@@ -0,0 +1,122 @@
+package script
+
+import(
+	"testing"
+	"gopkg.in/yaml.v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/opwire/opwire-testa/lib/engine"
+)
+
+type fakeMigratorOptions struct {}
+
+func (o *fakeMigratorOptions) GetVersion() string {
+	return "1.0.0"
+}
+
+// the tests of the text edits use their own rule
+var testRenamings = []FieldRenaming{
+	{
+		Path: []string{"expectation", "body"},
+		From: "legacy-pattern",
+		To: "match-with",
+	},
+}
+
+func TestMigrator_Migrate(t *testing.T) {
+	m, err := NewMigrator(&fakeMigratorOptions{})
+	assert.Nil(t, err)
+	m.renamings = testRenamings
+
+	t.Run("Rename deprecated fields", func(t *testing.T) {
+		output, changes, err := m.Migrate([]byte("testcases:\n- title: hello\n  expectation:\n    body:\n      has-format: text\n      legacy-pattern: .*\n"))
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"testcases[0]: expectation.body.legacy-pattern -> expectation.body.match-with"}, changes)
+		assert.Equal(t, "testcases:\n- title: hello\n  expectation:\n    body:\n      has-format: text\n      match-with: .*\n  version: 1.0.0\n", string(output))
+	})
+
+	t.Run("Preserve comments and layout", func(t *testing.T) {
+		source := "# users API\ntestcases:\n\n  # the list\n  - title: list\n    version: \"0.9.0\"   # old\n    expectation:\n        body: { \"legacy-pattern\": '^\\[', has-format: json }\n\n  - title: get\n    expectation:\n        body:\n            legacy-pattern: ok   # keep\n\n# end\n"
+		output, changes, err := m.Migrate([]byte(source))
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(changes))
+		assert.Equal(t, "# users API\ntestcases:\n\n  # the list\n  - title: list\n    version: \"1.0.0\"   # old\n    expectation:\n        body: { \"match-with\": '^\\[', has-format: json }\n\n  - title: get\n    expectation:\n        body:\n            match-with: ok   # keep\n    version: 1.0.0\n\n# end\n", string(output))
+	})
+
+	t.Run("Keep the new field", func(t *testing.T) {
+		source := "testcases:\n- title: hello\n  expectation:\n    body:\n      legacy-pattern: a\n      match-with: b\n"
+		output, changes, err := m.Migrate([]byte(source))
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(changes))
+		assert.Equal(t, source, string(output))
+	})
+
+	t.Run("Up to date", func(t *testing.T) {
+		source := "testcases:\n- title: hello\n  expectation:\n    body:\n      match-with: .*\n"
+		output, changes, err := m.Migrate([]byte(source))
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(changes))
+		assert.Equal(t, source, string(output))
+	})
+}
+
+func TestMigrator_Migrate_Protocol(t *testing.T) {
+	m, err := NewMigrator(&fakeMigratorOptions{})
+	assert.Nil(t, err)
+
+	source := "testcases:\n" +
+		"- title: plain\n" +
+		"  expectation:\n" +
+		"    protocol: HTTP/2.0   # negotiated\n" +
+		"- title: quoted\n" +
+		"  expectation: { protocol: 'HTTP/1.1', status-code: { is: { equal-to: 200 } } }\n" +
+		"- title: double quoted\n" +
+		"  expectation:\n" +
+		"    protocol: \"HTTP/3.0\"\n"
+	output, changes, err := m.Migrate([]byte(source))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"testcases[0]: expectation.protocol -> expectation.version.is-equal-to",
+		"testcases[1]: expectation.protocol -> expectation.version.is-equal-to",
+		"testcases[2]: expectation.protocol -> expectation.version.is-equal-to",
+	}, changes)
+	assert.Equal(t, "testcases:\n" +
+		"- title: plain\n" +
+		"  expectation:\n" +
+		"    version: { is-equal-to: HTTP/2.0 }   # negotiated\n" +
+		"  version: 1.0.0\n" +
+		"- title: quoted\n" +
+		"  expectation: { version: { is-equal-to: 'HTTP/1.1' }, status-code: { is: { equal-to: 200 } } }\n" +
+		"  version: 1.0.0\n" +
+		"- title: double quoted\n" +
+		"  expectation:\n" +
+		"    version: { is-equal-to: \"HTTP/3.0\" }\n" +
+		"  version: 1.0.0\n", string(output))
+
+	// the migrated script holds the same assertions, and is up to date
+	suite := struct {
+		TestCases []engine.TestCase `yaml:"testcases"`
+	}{}
+	assert.Nil(t, yaml.Unmarshal(output, &suite))
+	expected := []string{ "HTTP/2.0", "HTTP/1.1", "HTTP/3.0" }
+	for i, testcase := range suite.TestCases {
+		assert.Nil(t, testcase.Expectation.Protocol)
+		assert.Equal(t, expected[i], *testcase.Expectation.Version.IsEqualTo)
+	}
+	_, changes, err = m.Migrate(output)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(changes))
+	assert.Equal(t, 0, len(DetectDeprecations(output)))
+	assert.Equal(t, 3, len(DetectDeprecations([]byte(source))))
+}
+
+func TestDetectDeprecations(t *testing.T) {
+	deprecations := detectDeprecations([]byte("testcases:\n- title: first\n- title: second\n  expectation:\n    body:\n      legacy-pattern: .*\n"), testRenamings)
+	assert.Equal(t, []Deprecation{
+		{
+			TestCaseIndex: 1,
+			Field: "expectation.body.legacy-pattern",
+			Replacement: "expectation.body.match-with",
+		},
+	}, deprecations)
+	assert.Equal(t, 0, len(DetectDeprecations([]byte("testcases:\n- title: first\n"))))
+}
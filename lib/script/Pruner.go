@@ -0,0 +1,59 @@
+package script
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"github.com/opwire/opwire-testa/lib/utils"
+)
+
+// RemoveTestCases deletes the testcases (by their index) from the text of a
+// script file, the other lines are kept unchanged.
+func RemoveTestCases(content []byte, indexes []int) ([]byte, error) {
+	testcases, endLine, err := utils.LocateTestCases(content)
+	if err != nil {
+		return nil, err
+	}
+	if testcases == nil {
+		return nil, fmt.Errorf("The testcases must be a block sequence")
+	}
+	lines := strings.Split(string(content), "\n")
+
+	selected := make(map[int]bool)
+	for _, index := range indexes {
+		if index >= 0 && index < len(testcases.Content) {
+			selected[index] = true
+		}
+	}
+	sorted := make([]int, 0, len(selected))
+	for index := range selected {
+		sorted = append(sorted, index)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(sorted)))
+
+	first := testcases.Content[0].Line - 1
+	for _, index := range sorted {
+		start := testcases.Content[index].Line - 1
+		end := utils.FindTestCaseEnd(lines, testcases, index, endLine)
+		limit := endLine - 1
+		if index + 1 < len(testcases.Content) {
+			limit = testcases.Content[index + 1].Line - 1
+		}
+		for end < limit && end + 1 < len(lines) && len(strings.TrimSpace(lines[end])) == 0 {
+			end++
+		}
+		lines = append(lines[:start], lines[end:]...)
+	}
+
+	// an empty list keeps the script valid
+	if len(sorted) == len(testcases.Content) {
+		for k := first - 1; k >= 0; k-- {
+			if pos := strings.Index(lines[k], "testcases:"); pos >= 0 {
+				pos += len("testcases:")
+				lines[k] = lines[k][:pos] + " []" + lines[k][pos:]
+				break
+			}
+		}
+	}
+	return []byte(strings.Join(lines, "\n")), nil
+}
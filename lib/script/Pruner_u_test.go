@@ -0,0 +1,63 @@
+package script
+
+import(
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemoveTestCases(t *testing.T) {
+	content := "---\n" +
+		"testcases:\n" +
+		"# first\n" +
+		"- title: a\n" +
+		"  request:\n" +
+		"    url: /a\n" +
+		"\n" +
+		"# second\n" +
+		"- title: b\n" +
+		"  request:\n" +
+		"    url: /b\n" +
+		"- title: c\n" +
+		"  tags: [x]\n"
+
+	TESTCASES := []struct {
+		indexes []int
+		expected string
+	}{
+		{
+			indexes: []int{ 1 },
+			expected: "---\n" +
+				"testcases:\n" +
+				"# first\n" +
+				"- title: a\n" +
+				"  request:\n" +
+				"    url: /a\n" +
+				"\n" +
+				"# second\n" +
+				"- title: c\n" +
+				"  tags: [x]\n",
+		},
+		{
+			indexes: []int{ 2, 0, 9 },
+			expected: "---\n" +
+				"testcases:\n" +
+				"# first\n" +
+				"# second\n" +
+				"- title: b\n" +
+				"  request:\n" +
+				"    url: /b\n",
+		},
+		{
+			indexes: []int{ 0, 1, 2 },
+			expected: "---\n" +
+				"testcases: []\n" +
+				"# first\n" +
+				"# second\n",
+		},
+	}
+	for i, c := range TESTCASES {
+		output, err := RemoveTestCases([]byte(content), c.indexes)
+		assert.Nil(t, err, "testcase #%d", i)
+		assert.Equal(t, c.expected, string(output), "testcase #%d", i)
+	}
+}
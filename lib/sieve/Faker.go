@@ -0,0 +1,118 @@
+package sieve
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+	"github.com/opwire/opwire-testa/lib/utils"
+)
+
+// the fake data is generated by its own source, so that a seed given with
+// SetFakerSeed reproduces the same payloads whatever the other functions do.
+var faker = rand.New(rand.NewSource(time.Now().UnixNano()))
+var fakerMutex sync.Mutex
+
+// SetFakerSeed makes the fake values of the next requests reproducible.
+func SetFakerSeed(seed int64) {
+	fakerMutex.Lock()
+	defer fakerMutex.Unlock()
+	faker.Seed(seed)
+}
+
+var fakeFirstNames = []string{
+	"James", "Mary", "John", "Patricia", "Robert", "Jennifer", "Michael", "Linda",
+	"William", "Elizabeth", "David", "Barbara", "Richard", "Susan", "Joseph", "Jessica",
+	"Thomas", "Sarah", "Charles", "Karen", "Daniel", "Nancy", "Matthew", "Lisa",
+	"Anthony", "Betty", "Mark", "Margaret", "Paul", "Sandra", "Steven", "Ashley",
+}
+
+var fakeLastNames = []string{
+	"Smith", "Johnson", "Williams", "Brown", "Jones", "Garcia", "Miller", "Davis",
+	"Rodriguez", "Martinez", "Hernandez", "Lopez", "Gonzalez", "Wilson", "Anderson", "Thomas",
+	"Taylor", "Moore", "Jackson", "Martin", "Lee", "Perez", "Thompson", "White",
+	"Harris", "Sanchez", "Clark", "Ramirez", "Lewis", "Robinson", "Walker", "Young",
+}
+
+var fakeCompanySuffixes = []string{ "Inc", "LLC", "Group", "Ltd", "Partners", "Holdings" }
+
+var fakeCities = []string{
+	"Springfield", "Riverside", "Franklin", "Greenville", "Fairview", "Madison",
+	"Georgetown", "Salem", "Clinton", "Arlington", "Ashland", "Dover",
+}
+
+var fakeEmailDomains = []string{ "example.com", "example.org", "example.net" }
+
+// fakePhoneFormats are the patterns of the phone numbers by country, each '#'
+// is replaced by a random digit.
+var fakePhoneFormats = map[string]string{
+	"US": "+1 (###) ###-####",
+	"GB": "+44 7### ######",
+	"FR": "+33 6 ## ## ## ##",
+	"DE": "+49 15# #######",
+	"VN": "+84 9# ### ####",
+}
+
+func init() {
+	functions["fakeFirstName"] = fakeFunction("fakeFirstName", func() string {
+		return pickFake(fakeFirstNames)
+	})
+	functions["fakeLastName"] = fakeFunction("fakeLastName", func() string {
+		return pickFake(fakeLastNames)
+	})
+	functions["fakeName"] = fakeFunction("fakeName", func() string {
+		return pickFake(fakeFirstNames) + " " + pickFake(fakeLastNames)
+	})
+	functions["fakeEmail"] = fakeFunction("fakeEmail", func() string {
+		return fmt.Sprintf("%s.%s%d@%s", strings.ToLower(pickFake(fakeFirstNames)),
+			strings.ToLower(pickFake(fakeLastNames)), fakeInt(100), pickFake(fakeEmailDomains))
+	})
+	functions["fakeCompany"] = fakeFunction("fakeCompany", func() string {
+		return pickFake(fakeLastNames) + " " + pickFake(fakeCompanySuffixes)
+	})
+	functions["fakeCity"] = fakeFunction("fakeCity", func() string {
+		return pickFake(fakeCities)
+	})
+	functions["fakePhone"] = func(args []string) (string, error) {
+		if err := expectArgs("fakePhone", args, 0, 1); err != nil {
+			return utils.BLANK, err
+		}
+		country := "US"
+		if len(args) > 0 {
+			country = strings.ToUpper(args[0])
+		}
+		format, found := fakePhoneFormats[country]
+		if !found {
+			return utils.BLANK, fmt.Errorf("Function[fakePhone] does not support the country [%s]", args[0])
+		}
+		var phone strings.Builder
+		for _, r := range format {
+			if r == '#' {
+				phone.WriteByte(byte('0' + fakeInt(10)))
+			} else {
+				phone.WriteRune(r)
+			}
+		}
+		return phone.String(), nil
+	}
+}
+
+func fakeFunction(name string, generate func() string) Function {
+	return func(args []string) (string, error) {
+		if err := expectArgs(name, args, 0, 0); err != nil {
+			return utils.BLANK, err
+		}
+		return generate(), nil
+	}
+}
+
+func pickFake(values []string) string {
+	return values[fakeInt(len(values))]
+}
+
+func fakeInt(n int) int {
+	fakerMutex.Lock()
+	defer fakerMutex.Unlock()
+	return faker.Intn(n)
+}
@@ -0,0 +1,27 @@
+package sieve
+
+import(
+	"regexp"
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestCache_FakerFunctions(t *testing.T) {
+	cache, _ := NewRestCache()
+
+	assert.Regexp(t, regexp.MustCompile(`^[A-Z][a-z]+ [A-Z][a-z]+$`), cache.Evaluate("${{fakeName}}"))
+	assert.Regexp(t, regexp.MustCompile(`^[a-z]+\.[a-z]+[0-9]+@example\.(com|org|net)$`), cache.Evaluate("${{fakeEmail}}"))
+	assert.Regexp(t, regexp.MustCompile(`^\+1 \([0-9]{3}\) [0-9]{3}-[0-9]{4}$`), cache.Evaluate("${{fakePhone}}"))
+	assert.Regexp(t, regexp.MustCompile(`^\+33 6( [0-9]{2}){4}$`), cache.Evaluate(`${{fakePhone "fr"}}`))
+
+	_, errs := cache.EvaluateWithExplanation(`${{fakePhone "XX"}}`)
+	assert.Equal(t, []string{ "Function[fakePhone] does not support the country [XX]" }, errs)
+
+	t.Run("Reproducible with a seed", func(t *testing.T) {
+		text := `{"name": "${{fakeName}}", "email": "${{fakeEmail}}", "phone": "${{fakePhone "US"}}"}`
+		SetFakerSeed(42)
+		first := cache.Evaluate(text)
+		SetFakerSeed(42)
+		assert.Equal(t, first, cache.Evaluate(text))
+	})
+}
@@ -0,0 +1,161 @@
+package sieve
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"github.com/opwire/opwire-testa/lib/utils"
+)
+
+// Function generates a value from its arguments, e.g. ${{randInt 1 100}}.
+type Function func(args []string) (string, error)
+
+var functions = map[string]Function{
+	"uuid": func(args []string) (string, error) {
+		if err := expectArgs("uuid", args, 0, 0); err != nil {
+			return utils.BLANK, err
+		}
+		return utils.GenerateUUID()
+	},
+	"now": func(args []string) (string, error) {
+		if err := expectArgs("now", args, 0, 1); err != nil {
+			return utils.BLANK, err
+		}
+		layout := time.RFC3339
+		if len(args) > 0 {
+			layout = args[0]
+		}
+		return time.Now().Format(layout), nil
+	},
+	"randInt": func(args []string) (string, error) {
+		if err := expectArgs("randInt", args, 2, 2); err != nil {
+			return utils.BLANK, err
+		}
+		min, err1 := strconv.Atoi(args[0])
+		max, err2 := strconv.Atoi(args[1])
+		if err1 != nil || err2 != nil || max < min {
+			return utils.BLANK, fmt.Errorf("Function[randInt] expects two integers (min <= max), got [%s, %s]", args[0], args[1])
+		}
+		return strconv.Itoa(min + randomInt(max - min + 1)), nil
+	},
+	"randAlpha": func(args []string) (string, error) {
+		if err := expectArgs("randAlpha", args, 1, 1); err != nil {
+			return utils.BLANK, err
+		}
+		length, err := strconv.Atoi(args[0])
+		if err != nil || length < 0 {
+			return utils.BLANK, fmt.Errorf("Function[randAlpha] expects a positive length, got [%s]", args[0])
+		}
+		const letters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+		b := make([]byte, length)
+		for i := range b {
+			b[i] = letters[randomInt(len(letters))]
+		}
+		return string(b), nil
+	},
+	"b64enc": func(args []string) (string, error) {
+		return base64.StdEncoding.EncodeToString([]byte(strings.Join(args, ""))), nil
+	},
+}
+
+var random = rand.New(rand.NewSource(time.Now().UnixNano()))
+var randomMutex sync.Mutex
+
+func randomInt(n int) int {
+	randomMutex.Lock()
+	defer randomMutex.Unlock()
+	return random.Intn(n)
+}
+
+func expectArgs(name string, args []string, min int, max int) error {
+	if len(args) < min || len(args) > max {
+		if min == max {
+			return fmt.Errorf("Function[%s] expects %d argument(s), got %d", name, min, len(args))
+		}
+		return fmt.Errorf("Function[%s] expects %d to %d argument(s), got %d", name, min, max, len(args))
+	}
+	return nil
+}
+
+// callFunction evaluates an expression invoking a function. The arguments are
+// quoted strings or words, a word referring to a value of the cache (e.g.
+// vars.user) is replaced by the value.
+func (s *RestCache) callFunction(query string) (string, bool, error) {
+	body := strings.TrimSpace(query)
+	if !strings.HasPrefix(body, "${{") || !strings.HasSuffix(body, "}}") {
+		return utils.BLANK, false, nil
+	}
+	tokens, err := tokenizeArguments(body[3:len(body)-2])
+	if err != nil {
+		return utils.BLANK, true, fmt.Errorf("Query[%s] is invalid: %s", query, err.Error())
+	}
+	if len(tokens) == 0 || tokens[0].quoted {
+		return utils.BLANK, false, nil
+	}
+	function, found := functions[tokens[0].text]
+	if !found {
+		return utils.BLANK, false, nil
+	}
+	args := make([]string, 0, len(tokens) - 1)
+	for _, token := range tokens[1:] {
+		if !token.quoted {
+			if q, _ := Parse("${{" + token.text + "}}"); q != nil {
+				value, err := s.Query("${{" + token.text + "}}")
+				if err != nil {
+					return utils.BLANK, true, err
+				}
+				args = append(args, value)
+				continue
+			}
+		}
+		args = append(args, token.text)
+	}
+	result, err := function(args)
+	return result, true, err
+}
+
+type argumentToken struct {
+	text string
+	quoted bool
+}
+
+func tokenizeArguments(source string) ([]argumentToken, error) {
+	tokens := make([]argumentToken, 0)
+	runes := []rune(source)
+	for i := 0; i < len(runes); {
+		switch {
+		case unicode.IsSpace(runes[i]):
+			i++
+		case runes[i] == '"':
+			j := i + 1
+			for j < len(runes) && runes[j] != '"' {
+				if runes[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(runes) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			text, err := strconv.Unquote(string(runes[i:j+1]))
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, argumentToken{ text: text, quoted: true })
+			i = j + 1
+		default:
+			j := i
+			for j < len(runes) && !unicode.IsSpace(runes[j]) {
+				j++
+			}
+			tokens = append(tokens, argumentToken{ text: string(runes[i:j]) })
+			i = j
+		}
+	}
+	return tokens, nil
+}
@@ -0,0 +1,52 @@
+package sieve
+
+import(
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestCache_Functions(t *testing.T) {
+	cache, _ := NewRestCache()
+	cache.StoreVariable("user", "alice")
+
+	t.Run("uuid", func(t *testing.T) {
+		first, second := cache.Evaluate("${{uuid}}"), cache.Evaluate("${{ uuid }}")
+		assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), first)
+		assert.NotEqual(t, first, second)
+	})
+
+	t.Run("now", func(t *testing.T) {
+		assert.Equal(t, time.Now().Format("2006-01-02"), cache.Evaluate(`${{now "2006-01-02"}}`))
+		_, err := time.Parse(time.RFC3339, cache.Evaluate("${{now}}"))
+		assert.Nil(t, err)
+	})
+
+	t.Run("randInt", func(t *testing.T) {
+		for i := 0; i < 50; i++ {
+			value, err := strconv.Atoi(cache.Evaluate("${{randInt 1 3}}"))
+			assert.Nil(t, err)
+			assert.True(t, value >= 1 && value <= 3)
+		}
+		_, errs := cache.EvaluateWithExplanation("${{randInt 5 1}}")
+		assert.Equal(t, []string{ "Function[randInt] expects two integers (min <= max), got [5, 1]" }, errs)
+	})
+
+	t.Run("randAlpha", func(t *testing.T) {
+		assert.Regexp(t, regexp.MustCompile(`^[A-Za-z]{8}$`), cache.Evaluate("${{randAlpha 8}}"))
+		_, errs := cache.EvaluateWithExplanation("${{randAlpha}}")
+		assert.Equal(t, []string{ "Function[randAlpha] expects 1 argument(s), got 0" }, errs)
+	})
+
+	t.Run("b64enc", func(t *testing.T) {
+		assert.Equal(t, "Basic YWxpY2U6c2VjcmV0", cache.Evaluate(`Basic ${{b64enc vars.user ":secret"}}`))
+		_, errs := cache.EvaluateWithExplanation(`${{b64enc vars.missing}}`)
+		assert.Equal(t, []string{ "Vars[missing] not found" }, errs)
+	})
+
+	t.Run("Unknown function", func(t *testing.T) {
+		assert.Equal(t, "${{lowercase X}}", cache.Evaluate("${{lowercase X}}"))
+	})
+}
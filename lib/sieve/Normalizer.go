@@ -0,0 +1,78 @@
+package sieve
+
+import(
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Normalizer converts an identifier into its canonical representation,
+// so that the values returned by different endpoints can be compared.
+type Normalizer func(value interface{}) (interface{}, error)
+
+const NORMALIZER_UUID_LOWERCASE string = "uuid-lowercase"
+const NORMALIZER_STRING string = "string"
+const NORMALIZER_INTEGER string = "integer"
+
+var normalizers = map[string]Normalizer{
+	NORMALIZER_UUID_LOWERCASE: normalizeUuidLowercase,
+	NORMALIZER_STRING: normalizeString,
+	NORMALIZER_INTEGER: normalizeInteger,
+}
+
+var normalizersMutex sync.RWMutex
+
+// RegisterNormalizer adds (or replaces) a named strategy.
+func RegisterNormalizer(name string, normalizer Normalizer) {
+	normalizersMutex.Lock()
+	defer normalizersMutex.Unlock()
+	normalizers[name] = normalizer
+}
+
+func Normalize(strategy string, value interface{}) (interface{}, error) {
+	normalizersMutex.RLock()
+	normalizer, found := normalizers[strategy]
+	normalizersMutex.RUnlock()
+	if !found {
+		return value, fmt.Errorf("Normalizer [%s] not found", strategy)
+	}
+	return normalizer(value)
+}
+
+func normalizeUuidLowercase(value interface{}) (interface{}, error) {
+	text, ok := value.(string)
+	if !ok {
+		return value, fmt.Errorf("Value [%v] is not a string", value)
+	}
+	return strings.ToLower(strings.Trim(strings.TrimSpace(text), "{}")), nil
+}
+
+func normalizeString(value interface{}) (interface{}, error) {
+	if number, ok := value.(float64); ok && number == math.Trunc(number) {
+		return strconv.FormatFloat(number, 'f', -1, 64), nil
+	}
+	return fmt.Sprintf("%v", value), nil
+}
+
+func normalizeInteger(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	case float64:
+		if v != math.Trunc(v) {
+			return value, fmt.Errorf("Value [%v] is not an integer", value)
+		}
+		return int64(v), nil
+	case string:
+		number, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		if err != nil {
+			return value, fmt.Errorf("Value [%v] is not an integer", value)
+		}
+		return number, nil
+	}
+	return value, fmt.Errorf("Value [%v] is not an integer", value)
+}
@@ -0,0 +1,43 @@
+package sieve
+
+import(
+	"strings"
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	TESTCASES := []struct {
+		strategy string
+		value interface{}
+		expected interface{}
+		failed bool
+	}{
+		{ strategy: NORMALIZER_UUID_LOWERCASE, value: "{6BA7B810-9DAD-11D1-80B4-00C04FD430C8}", expected: "6ba7b810-9dad-11d1-80b4-00c04fd430c8" },
+		{ strategy: NORMALIZER_UUID_LOWERCASE, value: 12, failed: true },
+		{ strategy: NORMALIZER_STRING, value: float64(42), expected: "42" },
+		{ strategy: NORMALIZER_STRING, value: "42", expected: "42" },
+		{ strategy: NORMALIZER_INTEGER, value: "42", expected: int64(42) },
+		{ strategy: NORMALIZER_INTEGER, value: float64(42), expected: int64(42) },
+		{ strategy: NORMALIZER_INTEGER, value: float64(4.2), failed: true },
+		{ strategy: "unknown", value: "42", failed: true },
+	}
+	for i, c := range TESTCASES {
+		result, err := Normalize(c.strategy, c.value)
+		if c.failed {
+			assert.NotNil(t, err, "testcase #%d", i)
+			continue
+		}
+		assert.Nil(t, err, "testcase #%d", i)
+		assert.Equal(t, c.expected, result, "testcase #%d", i)
+	}
+}
+
+func TestRegisterNormalizer(t *testing.T) {
+	RegisterNormalizer("upper", func(value interface{}) (interface{}, error) {
+		return strings.ToUpper(value.(string)), nil
+	})
+	result, err := Normalize("upper", "abc")
+	assert.Nil(t, err)
+	assert.Equal(t, "ABC", result)
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"regexp"
 	"strings"
+	"sync"
 	"github.com/opwire/opwire-testa/lib/client"
 	"github.com/opwire/opwire-testa/lib/utils"
 )
@@ -12,11 +13,58 @@ import (
 func NewRestCache() (*RestCache, error) {
 	s := &RestCache{}
 	s.restResult = make(map[string]*RestResult, 0)
+	s.store = NewVariableStore()
 	return s, nil
 }
 
 type RestCache struct {
 	restResult map[string]*RestResult
+	variables map[string]string
+	store *VariableStore
+	mutex sync.RWMutex
+}
+
+// SetVariableStore replaces the store of the captured variables, a run shares
+// one store between the caches of its test suites.
+func (s *RestCache) SetVariableStore(store *VariableStore) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.store = store
+}
+
+// StoreVariable records a captured value in the store of the cache.
+func (s *RestCache) StoreVariable(name string, value string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.store == nil {
+		s.store = NewVariableStore()
+	}
+	s.store.Set(name, value)
+}
+
+func (s *RestCache) SetVariable(name string, value string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.variables == nil {
+		s.variables = make(map[string]string, 0)
+	}
+	s.variables[name] = value
+}
+
+// RUN_WORKDIR is the run variable of the workspace path (${{run.workdir}}).
+const RUN_WORKDIR string = "workdir"
+
+// RESERVED_VARIABLES are the names of the variables set by the runner, the
+// test cases can not store values with these names.
+var RESERVED_VARIABLES = []string{ RUN_WORKDIR }
+
+func IsReservedVariable(name string) bool {
+	for _, reserved := range RESERVED_VARIABLES {
+		if name == reserved {
+			return true
+		}
+	}
+	return false
 }
 
 func (s *RestCache) Evaluate(text string) string {
@@ -55,9 +103,38 @@ func (s *RestCache) Query(query string) (string, error) {
 		return utils.BLANK, err
 	}
 	if q == nil {
+		if result, found, err := s.callFunction(query); found {
+			return result, err
+		}
 		return utils.BLANK, fmt.Errorf("Query[%s] not found", query)
 	}
 
+	if q.Attr == RUN_VARIABLE {
+		s.mutex.RLock()
+		defer s.mutex.RUnlock()
+		if val, found := s.variables[q.TestID]; found {
+			return val, nil
+		}
+		if len(q.Default) > 0 {
+			return q.Default, nil
+		}
+		return utils.BLANK, fmt.Errorf("Run[%s] not found", q.TestID)
+	}
+
+	if q.Attr == STORED_VARIABLE {
+		s.mutex.RLock()
+		defer s.mutex.RUnlock()
+		if s.store != nil {
+			if val, found := s.store.Get(q.TestID); found {
+				return val, nil
+			}
+		}
+		if len(q.Default) > 0 {
+			return q.Default, nil
+		}
+		return utils.BLANK, fmt.Errorf("Vars[%s] not found", q.TestID)
+	}
+
 	if len(q.TestID) == 0 {
 		return utils.BLANK, fmt.Errorf("TestID must not be empty")
 	}
@@ -119,11 +196,25 @@ func (s *RestCache) Query(query string) (string, error) {
 			return utils.BLANK, fmt.Errorf("Resp[%s].BodyField[%s] not found", q.TestID, q.ItemKey)
 		}
 		return fmt.Sprintf("%v", val), nil
+
+	case RESP_IDEMPOTENCY_KEY:
+		if len(rr.IdempotencyKey) == 0 {
+			if len(q.Default) > 0 {
+				return q.Default, nil
+			}
+			return utils.BLANK, fmt.Errorf("Resp[%s].IdempotencyKey is empty", q.TestID)
+		}
+		return rr.IdempotencyKey, nil
+
+	case RESP_BODY_SIZE:
+		return fmt.Sprintf("%d", rr.BodySize), nil
 	}
 	return utils.BLANK, nil
 }
 
 func (s *RestCache) Get(testId string) (*RestResult, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
 	if rr, ok := s.restResult[testId]; ok {
 		return rr, nil
 	} else {
@@ -174,6 +265,7 @@ func (s *RestCache) Apply(req *client.HttpRequest) (r *client.HttpRequest, err e
 			newH := client.HttpHeader{
 				Name: h.Name,
 				Value: h.Value,
+				Sensitive: h.Sensitive,
 			}
 			if len(newH.Value) > 0 {
 				var err2 []string
@@ -199,7 +291,40 @@ func (s *RestCache) Apply(req *client.HttpRequest) (r *client.HttpRequest, err e
 		}
 	}
 
+	if len(req.IdempotencyKey) > 0 {
+		r.IdempotencyKey, err1 = s.EvaluateWithExplanation(req.IdempotencyKey)
+		if err1 != nil {
+			errs = append(errs, "Evaluate(req.IdempotencyKey) failed")
+			errs = utils.AppendLinesWithIndent(errs, err1, 2)
+		}
+	}
+
+	if len(req.ConditionalOn) > 0 {
+		r.ConditionalOn = req.ConditionalOn
+		r.Headers, err1 = s.appendConditionalHeaders(r.Headers, req.ConditionalOn)
+		if err1 != nil {
+			errs = append(errs, "Evaluate(req.ConditionalOn) failed")
+			errs = utils.AppendLinesWithIndent(errs, err1, 2)
+		}
+	}
+
+	if req.Auth != nil {
+		r.Auth = &client.HttpAuth{ Type: req.Auth.Type, ServicePrincipal: req.Auth.ServicePrincipal }
+		r.Auth.Username, err1 = s.EvaluateWithExplanation(req.Auth.Username)
+		if err1 != nil {
+			errs = append(errs, "Evaluate(req.Auth.Username) failed")
+			errs = utils.AppendLinesWithIndent(errs, err1, 2)
+		}
+		r.Auth.Password, err1 = s.EvaluateWithExplanation(req.Auth.Password)
+		if err1 != nil {
+			errs = append(errs, "Evaluate(req.Auth.Password) failed")
+			errs = utils.AppendLinesWithIndent(errs, err1, 2)
+		}
+	}
+
 	r.Timeout = req.Timeout
+	r.ExpectContinue = req.ExpectContinue
+	r.Sensitive = req.Sensitive
 
 	if errs != nil && len(errs) > 0 {
 		return r, utils.BuildMultilineError(errs)
@@ -208,16 +333,60 @@ func (s *RestCache) Apply(req *client.HttpRequest) (r *client.HttpRequest, err e
 	return r, nil
 }
 
-func (s *RestCache) Store(testId string, res *client.HttpResponse) (*RestResult, error) {
-	if s.restResult == nil {
-		s.restResult = make(map[string]*RestResult, 0)
+// appendConditionalHeaders turns the validators (ETag, Last-Modified) of a
+// captured response into the If-None-Match/If-Modified-Since request headers.
+func (s *RestCache) appendConditionalHeaders(headers []client.HttpHeader, testId string) ([]client.HttpHeader, []string) {
+	rr, err := s.Get(testId)
+	if err != nil {
+		return headers, []string{err.Error()}
+	}
+	validators := []struct {
+		source string
+		target string
+	}{
+		{ source: "ETag", target: "If-None-Match" },
+		{ source: "Last-Modified", target: "If-Modified-Since" },
+	}
+	found := false
+	for _, v := range validators {
+		value := rr.Header.Get(v.source)
+		if len(value) == 0 {
+			continue
+		}
+		found = true
+		if hasHeader(headers, v.target) {
+			continue
+		}
+		headers = append(headers, client.HttpHeader{ Name: v.target, Value: value })
 	}
+	if !found {
+		return headers, []string{fmt.Sprintf("Resp[%s] has neither ETag nor Last-Modified header", testId)}
+	}
+	return headers, nil
+}
 
+func hasHeader(headers []client.HttpHeader, name string) bool {
+	for _, h := range headers {
+		if strings.EqualFold(h.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *RestCache) Store(testId string, res *client.HttpResponse) (*RestResult, error) {
 	newRR, err := NewRestResult(res)
 	if err != nil {
 		return nil, err
 	}
 
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.restResult == nil {
+		s.restResult = make(map[string]*RestResult, 0)
+	}
+
 	oldRR, ok := s.restResult[testId]
 	s.restResult[testId] = newRR
 
@@ -227,6 +396,26 @@ func (s *RestCache) Store(testId string, res *client.HttpResponse) (*RestResult,
 	return nil, nil
 }
 
+// NormalizeField rewrites a captured body field with the given strategy.
+func (s *RestCache) NormalizeField(testId string, field string, strategy string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	rr, ok := s.restResult[testId]
+	if !ok {
+		return fmt.Errorf("RestResult[%s] not found", testId)
+	}
+	val, found := rr.BodyField[field]
+	if !found {
+		return fmt.Errorf("Resp[%s].BodyField[%s] not found", testId, field)
+	}
+	normalized, err := Normalize(strategy, val)
+	if err != nil {
+		return err
+	}
+	rr.BodyField[field] = normalized
+	return nil
+}
+
 func NewRestResult(lowRes *client.HttpResponse) (*RestResult, error) {
 	if lowRes == nil {
 		panic(fmt.Errorf("HttpResponse must not be nil"))
@@ -239,6 +428,8 @@ func NewRestResult(lowRes *client.HttpResponse) (*RestResult, error) {
 	res.Header = lowRes.Header
 	res.ContentLength = lowRes.ContentLength
 	res.Body = lowRes.Body
+	res.BodySize = lowRes.BodySize
+	res.IdempotencyKey = lowRes.IdempotencyKey
 
 	// BodyField
 	obj := make(map[string]interface{}, 0)
@@ -274,7 +465,9 @@ type RestResult struct {
 	Header http.Header
 	ContentLength int64
 	Body []byte
+	BodySize int64
 	BodyField map[string]interface{}
+	IdempotencyKey string
 }
 
 type DataType int
@@ -286,6 +479,10 @@ const (
 	RESP_HEADER
 	RESP_BODY
 	RESP_BODY_FIELD
+	RESP_IDEMPOTENCY_KEY
+	RESP_BODY_SIZE
+	RUN_VARIABLE
+	STORED_VARIABLE
 )
 
 type Query struct {
@@ -302,6 +499,10 @@ var STEP_RES_STATUS_CODE_REGEXP = regexp.MustCompile(fmt.Sprintf(STEP_PATTERN_BO
 var STEP_RES_HEADER_REGEXP = regexp.MustCompile(fmt.Sprintf(STEP_PATTERN_BOUND, `\s*case\[([^\]]*)\]\.Header\[([^\]]*)\]\s*(\:\-([^\}]*))?\s*`))
 var STEP_RES_BODY_REGEXP = regexp.MustCompile(fmt.Sprintf(STEP_PATTERN_BOUND, `\s*case\[([^\]]*)\]\.Body\s*(\:\-([^\}]*))?\s*`))
 var STEP_RES_BODY_FIELD_REGEXP = regexp.MustCompile(fmt.Sprintf(STEP_PATTERN_BOUND, `\s*case\[([^\]]*)\]\.Body\[([^\]]*)\]\s*(\:\-([^\}]*))?\s*`))
+var STEP_RES_BODY_SIZE_REGEXP = regexp.MustCompile(fmt.Sprintf(STEP_PATTERN_BOUND, `\s*case\[([^\]]*)\]\.BodySize\s*(\:\-([^\}]*))?\s*`))
+var STEP_RUN_VARIABLE_REGEXP = regexp.MustCompile(fmt.Sprintf(STEP_PATTERN_BOUND, `\s*run\.([A-Za-z0-9_\-]+)\s*(\:\-([^\}]*))?\s*`))
+var STEP_STORED_VARIABLE_REGEXP = regexp.MustCompile(fmt.Sprintf(STEP_PATTERN_BOUND, `\s*vars\.([A-Za-z0-9_\-]+)\s*(\:\-([^\}]*))?\s*`))
+var STEP_RES_IDEMPOTENCY_KEY_REGEXP = regexp.MustCompile(fmt.Sprintf(STEP_PATTERN_BOUND, `\s*case\[([^\]]*)\]\.IdempotencyKey\s*(\:\-([^\}]*))?\s*`))
 
 func Parse(query string) (*Query, error) {
 	var q *Query
@@ -325,6 +526,22 @@ func Parse(query string) (*Query, error) {
 	if q != nil {
 		return q, nil
 	}
+	q = extract2(RESP_BODY_SIZE, STEP_RES_BODY_SIZE_REGEXP.FindAllStringSubmatch(query, -1))
+	if q != nil {
+		return q, nil
+	}
+	q = extract2(RESP_IDEMPOTENCY_KEY, STEP_RES_IDEMPOTENCY_KEY_REGEXP.FindAllStringSubmatch(query, -1))
+	if q != nil {
+		return q, nil
+	}
+	q = extract2(RUN_VARIABLE, STEP_RUN_VARIABLE_REGEXP.FindAllStringSubmatch(query, -1))
+	if q != nil {
+		return q, nil
+	}
+	q = extract2(STORED_VARIABLE, STEP_STORED_VARIABLE_REGEXP.FindAllStringSubmatch(query, -1))
+	if q != nil {
+		return q, nil
+	}
 	return nil, nil
 }
 
@@ -0,0 +1,80 @@
+package sieve
+
+import(
+	"net/http"
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/opwire/opwire-testa/lib/client"
+)
+
+func TestRestCache_StoredVariables(t *testing.T) {
+	store := NewVariableStore()
+	first, _ := NewRestCache()
+	first.SetVariableStore(store)
+	second, _ := NewRestCache()
+	second.SetVariableStore(store)
+
+	first.StoreVariable("userId", "42")
+	assert.Equal(t, "/users/42", second.Evaluate("/users/${{vars.userId}}"))
+	assert.Equal(t, "guest", second.Evaluate("${{ vars.role :- guest }}"))
+
+	_, errs := second.EvaluateWithExplanation("${{vars.role}}")
+	assert.Equal(t, []string{ "Vars[role] not found" }, errs)
+
+	other, _ := NewRestCache()
+	_, errs = other.EvaluateWithExplanation("${{vars.userId}}")
+	assert.Equal(t, 1, len(errs))
+}
+
+func TestRestCache_Apply_ConditionalOn(t *testing.T) {
+	cache, _ := NewRestCache()
+	lastModified := "Wed, 14 Oct 2026 18:00:00 GMT"
+	cache.Store("both", &client.HttpResponse{ StatusCode: 200, Header: http.Header{ "Etag": []string{`"v1"`}, "Last-Modified": []string{lastModified} } })
+	cache.Store("etag", &client.HttpResponse{ StatusCode: 200, Header: http.Header{ "Etag": []string{`"v2"`} } })
+	cache.Store("date", &client.HttpResponse{ StatusCode: 200, Header: http.Header{ "Last-Modified": []string{lastModified} } })
+	cache.Store("none", &client.HttpResponse{ StatusCode: 200, Header: http.Header{} })
+
+	TESTCASES := []struct {
+		conditionalOn string
+		headers []client.HttpHeader
+		expected []client.HttpHeader
+		err string
+	}{
+		{
+			conditionalOn: "both",
+			expected: []client.HttpHeader{ { Name: "If-None-Match", Value: `"v1"` }, { Name: "If-Modified-Since", Value: lastModified } },
+		},
+		{
+			conditionalOn: "etag",
+			expected: []client.HttpHeader{ { Name: "If-None-Match", Value: `"v2"` } },
+		},
+		{
+			conditionalOn: "date",
+			expected: []client.HttpHeader{ { Name: "If-Modified-Since", Value: lastModified } },
+		},
+		{
+			conditionalOn: "both",
+			headers: []client.HttpHeader{ { Name: "if-none-match", Value: "*" } },
+			expected: []client.HttpHeader{ { Name: "if-none-match", Value: "*" }, { Name: "If-Modified-Since", Value: lastModified } },
+		},
+		{
+			conditionalOn: "none",
+			err: "Resp[none] has neither ETag nor Last-Modified header",
+		},
+		{
+			conditionalOn: "unknown",
+			err: "Evaluate(req.ConditionalOn) failed",
+		},
+	}
+	for i, tc := range TESTCASES {
+		req, err := cache.Apply(&client.HttpRequest{ Method: "GET", Path: "/doc", Headers: tc.headers, ConditionalOn: tc.conditionalOn })
+		if len(tc.err) > 0 {
+			assert.NotNil(t, err, "case #%d", i)
+			assert.Contains(t, err.Error(), tc.err, "case #%d", i)
+			continue
+		}
+		assert.Nil(t, err, "case #%d", i)
+		assert.Equal(t, tc.conditionalOn, req.ConditionalOn, "case #%d", i)
+		assert.Equal(t, tc.expected, req.Headers, "case #%d", i)
+	}
+}
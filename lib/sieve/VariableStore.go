@@ -0,0 +1,30 @@
+package sieve
+
+import (
+	"sync"
+)
+
+// VariableStore holds the values captured by the test cases, it is shared by
+// the RestCaches of a run so that a value captured in a file is available to
+// the requests of the other files (as ${{vars.name}}).
+type VariableStore struct {
+	values map[string]string
+	mutex sync.RWMutex
+}
+
+func NewVariableStore() *VariableStore {
+	return &VariableStore{ values: make(map[string]string, 0) }
+}
+
+func (s *VariableStore) Set(name string, value string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.values[name] = value
+}
+
+func (s *VariableStore) Get(name string) (string, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	value, found := s.values[name]
+	return value, found
+}
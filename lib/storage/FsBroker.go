@@ -2,6 +2,7 @@ package storage
 
 import (
 	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 )
@@ -24,6 +25,11 @@ type File interface {
 
 type Fs interface {
 	Open(name string) (File, error)
+	Create(name string) (File, error)
+	MkdirAll(path string, perm os.FileMode) error
+	TempDir(dir, prefix string) (string, error)
+	RemoveAll(path string) error
+	Rename(oldpath, newpath string) error
 	Stat(name string) (os.FileInfo, error)
 	IsNotExist(err error) bool
 	Getwd() (dir string, err error)
@@ -36,6 +42,26 @@ func (fs *OsFs) Open(name string) (File, error) {
 	return os.Open(name)
 }
 
+func (fs *OsFs) Create(name string) (File, error) {
+	return os.Create(name)
+}
+
+func (fs *OsFs) MkdirAll(path string, perm os.FileMode) error {
+	return os.MkdirAll(path, perm)
+}
+
+func (fs *OsFs) TempDir(dir, prefix string) (string, error) {
+	return ioutil.TempDir(dir, prefix)
+}
+
+func (fs *OsFs) RemoveAll(path string) error {
+	return os.RemoveAll(path)
+}
+
+func (fs *OsFs) Rename(oldpath, newpath string) error {
+	return os.Rename(oldpath, newpath)
+}
+
 func (fs *OsFs) Stat(name string) (os.FileInfo, error) {
 	return os.Stat(name)
 }
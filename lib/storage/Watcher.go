@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"os"
+	"sort"
+	"time"
+)
+
+// Watcher detects the files of directories which are added, modified or
+// removed, by comparing the snapshots (modification time and size) taken
+// through the current Fs, it does not depend on the notifications of a
+// platform.
+type Watcher struct {
+	dirs []string
+	stamps map[string]fileStamp
+}
+
+type fileStamp struct {
+	modTime time.Time
+	size int64
+}
+
+// Changes lists the files changed between two snapshots, sorted by path.
+type Changes struct {
+	Modified []string
+	Removed []string
+}
+
+func (c *Changes) IsEmpty() bool {
+	return c == nil || len(c.Modified) == 0 && len(c.Removed) == 0
+}
+
+func NewWatcher(dirs []string) (*Watcher, error) {
+	w := &Watcher{ dirs: dirs }
+	stamps, err := w.snapshot()
+	if err != nil {
+		return nil, err
+	}
+	w.stamps = stamps
+	return w, nil
+}
+
+// Poll returns the files added or modified (both reported as modified) and
+// the files removed since the previous call.
+func (w *Watcher) Poll() (*Changes, error) {
+	stamps, err := w.snapshot()
+	if err != nil {
+		return nil, err
+	}
+	changes := &Changes{ Modified: make([]string, 0), Removed: make([]string, 0) }
+	for path, stamp := range stamps {
+		if previous, found := w.stamps[path]; !found || !previous.modTime.Equal(stamp.modTime) || previous.size != stamp.size {
+			changes.Modified = append(changes.Modified, path)
+		}
+	}
+	for path := range w.stamps {
+		if _, found := stamps[path]; !found {
+			changes.Removed = append(changes.Removed, path)
+		}
+	}
+	sort.Strings(changes.Modified)
+	sort.Strings(changes.Removed)
+	w.stamps = stamps
+	return changes, nil
+}
+
+func (w *Watcher) snapshot() (map[string]fileStamp, error) {
+	stamps := make(map[string]fileStamp, 0)
+	fs := GetFs()
+	for _, dir := range w.dirs {
+		err := fs.Walk(dir, func(path string, f os.FileInfo, err error) error {
+			if err == nil && !f.IsDir() {
+				stamps[path] = fileStamp{ modTime: f.ModTime(), size: f.Size() }
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return stamps, nil
+}
@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatcher_Poll(t *testing.T) {
+	dir, err := ioutil.TempDir("", "opwire-testa-watcher-")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	kept := filepath.Join(dir, "kept.yml")
+	changed := filepath.Join(dir, "changed.yml")
+	removed := filepath.Join(dir, "removed.yml")
+	for _, path := range []string{ kept, changed, removed } {
+		assert.Nil(t, ioutil.WriteFile(path, []byte("testcases: []"), 0644))
+	}
+
+	watcher, err := NewWatcher([]string{ dir })
+	assert.Nil(t, err)
+
+	changes, err := watcher.Poll()
+	assert.Nil(t, err)
+	assert.True(t, changes.IsEmpty())
+
+	added := filepath.Join(dir, "sub", "added.yml")
+	assert.Nil(t, os.MkdirAll(filepath.Dir(added), 0755))
+	assert.Nil(t, ioutil.WriteFile(added, []byte("testcases: []"), 0644))
+	assert.Nil(t, ioutil.WriteFile(changed, []byte("testcases: [ ]"), 0644))
+	future := time.Now().Add(time.Minute)
+	assert.Nil(t, os.Chtimes(changed, future, future))
+	assert.Nil(t, os.Remove(removed))
+
+	changes, err = watcher.Poll()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{ changed, added }, changes.Modified)
+	assert.Equal(t, []string{ removed }, changes.Removed)
+
+	changes, err = watcher.Poll()
+	assert.Nil(t, err)
+	assert.True(t, changes.IsEmpty())
+}
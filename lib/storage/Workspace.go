@@ -0,0 +1,29 @@
+package storage
+
+// Workspace is a temporary directory living as long as a run, which receives
+// the artifacts and the downloaded files.
+type Workspace struct {
+	path string
+}
+
+func NewWorkspace() (*Workspace, error) {
+	path, err := GetFs().TempDir("", "opwire-testa-run-")
+	if err != nil {
+		return nil, err
+	}
+	return &Workspace{ path: path }, nil
+}
+
+func (w *Workspace) GetPath() string {
+	if w == nil {
+		return ""
+	}
+	return w.path
+}
+
+func (w *Workspace) Cleanup() error {
+	if w == nil || len(w.path) == 0 {
+		return nil
+	}
+	return GetFs().RemoveAll(w.path)
+}
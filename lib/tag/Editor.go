@@ -0,0 +1,186 @@
+package tag
+
+import(
+	"fmt"
+	"strings"
+	"gopkg.in/yaml.v3"
+	"github.com/opwire/opwire-testa/lib/utils"
+)
+
+// Editor rewrites the tags of the testcases in the text of a script file,
+// only the tags lines are touched so that comments and layout are preserved.
+type Editor struct {}
+
+func NewEditor() (ref *Editor, err error) {
+	ref = &Editor{}
+	return ref, err
+}
+
+func ValidateTag(tag string) error {
+	if len(tag) == 0 {
+		return fmt.Errorf("The tag must not be empty")
+	}
+	if strings.HasPrefix(tag, "+") || strings.HasPrefix(tag, "-") {
+		return fmt.Errorf("The tag [%s] must not start with a sign", tag)
+	}
+	if strings.ContainsAny(tag, ", \t[]{}:#") {
+		return fmt.Errorf("The tag [%s] contains an invalid character", tag)
+	}
+	return nil
+}
+
+type lineEdit struct {
+	line int
+	replace bool
+	lines []string
+}
+
+// AddTag appends the tag to every testcase accepted by the selector (by its
+// title) which does not have it yet, it returns the new content and the
+// titles of the updated testcases.
+func (e *Editor) AddTag(content []byte, tag string, selector func(title string, tags []string) bool) ([]byte, []string, error) {
+	if err := ValidateTag(tag); err != nil {
+		return nil, nil, err
+	}
+	testcases, endLine, err := utils.LocateTestCases(content)
+	if err != nil {
+		return nil, nil, err
+	}
+	if testcases == nil {
+		return content, nil, nil
+	}
+
+	lines := strings.Split(string(content), "\n")
+
+	edits := make([]lineEdit, 0)
+	updated := make([]string, 0)
+	for i, testcase := range testcases.Content {
+		if testcase.Kind != yaml.MappingNode || len(testcase.Content) == 0 {
+			continue
+		}
+		title, tagsKey, tagsNode := inspectTestCase(testcase)
+		if selector != nil && !selector(title, tagValues(tagsNode)) {
+			continue
+		}
+		if utils.Contains(tagValues(tagsNode), tag) {
+			continue
+		}
+		end := utils.FindTestCaseEnd(lines, testcases, i, endLine)
+		edit, err := buildLineEdit(lines, testcase, tagsKey, tagsNode, end, tag)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Testcase [%s]: %s", title, err.Error())
+		}
+		edits = append(edits, *edit)
+		updated = append(updated, title)
+	}
+
+	for i := len(edits) - 1; i >= 0; i-- {
+		edit := edits[i]
+		if edit.replace {
+			lines[edit.line] = edit.lines[0]
+			continue
+		}
+		tail := append(append([]string{}, edit.lines...), lines[edit.line:]...)
+		lines = append(lines[:edit.line], tail...)
+	}
+	return []byte(strings.Join(lines, "\n")), updated, nil
+}
+
+// CountTags returns the number of testcases per tag.
+func (e *Editor) CountTags(content []byte, selector func(title string, tags []string) bool) (map[string]int, error) {
+	testcases, _, err := utils.LocateTestCases(content)
+	if err != nil {
+		return nil, err
+	}
+	counter := make(map[string]int)
+	if testcases == nil {
+		return counter, nil
+	}
+	for _, testcase := range testcases.Content {
+		if testcase.Kind != yaml.MappingNode {
+			continue
+		}
+		title, _, tagsNode := inspectTestCase(testcase)
+		if selector != nil && !selector(title, tagValues(tagsNode)) {
+			continue
+		}
+		for _, value := range tagValues(tagsNode) {
+			counter[value] += 1
+		}
+	}
+	return counter, nil
+}
+
+func inspectTestCase(testcase *yaml.Node) (title string, tagsKey *yaml.Node, tagsNode *yaml.Node) {
+	for i := 0; i + 1 < len(testcase.Content); i += 2 {
+		switch testcase.Content[i].Value {
+		case "title":
+			title = testcase.Content[i + 1].Value
+		case "tags":
+			tagsKey = testcase.Content[i]
+			tagsNode = testcase.Content[i + 1]
+		}
+	}
+	return title, tagsKey, tagsNode
+}
+
+func tagValues(tagsNode *yaml.Node) []string {
+	values := make([]string, 0)
+	if tagsNode == nil {
+		return values
+	}
+	for _, item := range tagsNode.Content {
+		if len(item.Value) > 0 {
+			values = append(values, item.Value)
+		}
+	}
+	return values
+}
+
+func buildLineEdit(lines []string, testcase *yaml.Node, tagsKey *yaml.Node, tagsNode *yaml.Node, end int, tag string) (*lineEdit, error) {
+	// no tags: append a block sequence at the end of the testcase
+	if tagsKey == nil {
+		indent := strings.Repeat(" ", testcase.Column - 1)
+		return &lineEdit{ line: end, lines: []string{ indent + "tags:", indent + "- " + tag } }, nil
+	}
+
+	// empty value (tags: or tags: ~)
+	if tagsNode.Kind == yaml.ScalarNode && tagsNode.Tag == "!!null" {
+		text := lines[tagsKey.Line - 1]
+		keyEnd := tagsKey.Column - 1 + len("tags:")
+		if keyEnd > len(text) {
+			return nil, fmt.Errorf("Unable to locate the tags")
+		}
+		return &lineEdit{ line: tagsKey.Line - 1, replace: true, lines: []string{ text[:keyEnd] + " [" + tag + "]" } }, nil
+	}
+
+	if tagsNode.Kind != yaml.SequenceNode {
+		return nil, fmt.Errorf("The tags must be a list")
+	}
+
+	// flow sequence on a single line: [a, b]
+	if tagsNode.Style & yaml.FlowStyle != 0 {
+		text := lines[tagsNode.Line - 1]
+		start := tagsNode.Column - 1
+		closing := strings.Index(text[start:], "]")
+		if closing < 0 {
+			return nil, fmt.Errorf("Multiline flow sequences of tags are not supported")
+		}
+		closing += start
+		separator := ", "
+		if len(tagsNode.Content) == 0 {
+			separator = ""
+		}
+		text = strings.TrimRight(text[:closing], " ") + separator + tag + text[closing:]
+		return &lineEdit{ line: tagsNode.Line - 1, replace: true, lines: []string{ text } }, nil
+	}
+
+	// block sequence: add an item after the last one, with the same indentation
+	last := tagsNode.Content[len(tagsNode.Content) - 1]
+	text := lines[last.Line - 1]
+	dash := strings.LastIndex(text[:last.Column - 1], "-")
+	if dash < 0 {
+		return nil, fmt.Errorf("Unable to locate the tags")
+	}
+	return &lineEdit{ line: last.Line, lines: []string{ strings.Repeat(" ", dash) + "- " + tag } }, nil
+}
@@ -0,0 +1,137 @@
+package tag
+
+import(
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEditor_AddTag(t *testing.T) {
+	ref, err := NewEditor()
+	assert.NotNil(t, ref)
+	assert.Nil(t, err)
+
+	TESTCASES := []struct {
+		content string
+		selector func(string, []string) bool
+		expected string
+		updated []string
+	}{
+		// block sequence, comments preserved
+		{
+			content: "---\n" +
+				"testcases:\n" +
+				"# the login\n" +
+				"- title: login\n" +
+				"  tags:\n" +
+				"  - auth # main\n" +
+				"  request:\n" +
+				"    method: GET\n",
+			expected: "---\n" +
+				"testcases:\n" +
+				"# the login\n" +
+				"- title: login\n" +
+				"  tags:\n" +
+				"  - auth # main\n" +
+				"  - smoke\n" +
+				"  request:\n" +
+				"    method: GET\n",
+			updated: []string{ "login" },
+		},
+		// flow sequence, empty flow sequence and null value
+		{
+			content: "testcases:\n" +
+				"  - title: a\n" +
+				"    tags: [auth, slow]\n" +
+				"  - title: b\n" +
+				"    tags: []\n" +
+				"  - title: c\n" +
+				"    tags:\n",
+			expected: "testcases:\n" +
+				"  - title: a\n" +
+				"    tags: [auth, slow, smoke]\n" +
+				"  - title: b\n" +
+				"    tags: [smoke]\n" +
+				"  - title: c\n" +
+				"    tags: [smoke]\n",
+			updated: []string{ "a", "b", "c" },
+		},
+		// no tags, the tags are appended at the end of the testcase
+		{
+			content: "testcases:\n" +
+				"- title: a\n" +
+				"  request:\n" +
+				"    url: /a\n" +
+				"\n" +
+				"- title: b\n" +
+				"  tags:\n" +
+				"  - smoke\n" +
+				"- title: c\n" +
+				"  expectation:\n" +
+				"    status-code:\n" +
+				"      is-equal-to: 200\n" +
+				"\n",
+			expected: "testcases:\n" +
+				"- title: a\n" +
+				"  request:\n" +
+				"    url: /a\n" +
+				"  tags:\n" +
+				"  - smoke\n" +
+				"\n" +
+				"- title: b\n" +
+				"  tags:\n" +
+				"  - smoke\n" +
+				"- title: c\n" +
+				"  expectation:\n" +
+				"    status-code:\n" +
+				"      is-equal-to: 200\n" +
+				"  tags:\n" +
+				"  - smoke\n" +
+				"\n",
+			updated: []string{ "a", "c" },
+		},
+		// selected testcases only, followed by another top-level key
+		{
+			content: "testcases:\n" +
+				"- title: a\n" +
+				"- title: b\n" +
+				"pending: false\n",
+			selector: func(title string, tags []string) bool {
+				return title == "b"
+			},
+			expected: "testcases:\n" +
+				"- title: a\n" +
+				"- title: b\n" +
+				"  tags:\n" +
+				"  - smoke\n" +
+				"pending: false\n",
+			updated: []string{ "b" },
+		},
+	}
+
+	for i, c := range TESTCASES {
+		output, updated, err := ref.AddTag([]byte(c.content), "smoke", c.selector)
+		assert.Nil(t, err, "testcase #%d", i)
+		assert.Equal(t, c.expected, string(output), "testcase #%d", i)
+		assert.Equal(t, c.updated, updated, "testcase #%d", i)
+	}
+
+	t.Run("Invalid tag", func(t *testing.T) {
+		for _, tag := range []string{ "", "-smoke", "+smoke", "a,b", "a b" } {
+			_, _, err := ref.AddTag([]byte("testcases:\n- title: a\n"), tag, nil)
+			assert.NotNil(t, err, "tag [%s]", tag)
+		}
+	})
+}
+
+func TestEditor_CountTags(t *testing.T) {
+	ref, _ := NewEditor()
+	counter, err := ref.CountTags([]byte("testcases:\n" +
+		"- title: a\n" +
+		"  tags: [auth, smoke]\n" +
+		"- title: b\n" +
+		"  tags:\n" +
+		"  - smoke\n" +
+		"- title: c\n"), nil)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]int{ "auth": 1, "smoke": 2 }, counter)
+}
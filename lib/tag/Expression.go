@@ -0,0 +1,203 @@
+package tag
+
+import(
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Expression is a boolean combination of tags, e.g. "(smoke || critical) && !slow".
+type Expression struct {
+	source string
+	root *exprNode
+}
+
+type exprNode struct {
+	op string
+	tag string
+	operands []*exprNode
+}
+
+const (
+	opTag = ""
+	opNot = "!"
+	opAnd = "&&"
+	opOr = "||"
+)
+
+// IsExpression reports whether the value of a --tags argument uses the
+// operators of the expressions instead of a list of signed tags.
+func IsExpression(tagexp string) bool {
+	return strings.ContainsAny(tagexp, "&|!()")
+}
+
+func ParseExpression(source string) (*Expression, error) {
+	p := &exprParser{ tokens: tokenizeExpression(source) }
+	if len(p.tokens) == 0 {
+		return nil, fmt.Errorf("Tag expression is empty")
+	}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("Invalid tag expression [%s]: %s", source, err.Error())
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("Invalid tag expression [%s]: unexpected [%s]", source, p.tokens[p.pos])
+	}
+	return &Expression{ source: source, root: root }, nil
+}
+
+// Evaluate reports whether the tags of a test case satisfy the expression.
+func (x *Expression) Evaluate(tags []string) bool {
+	return x.root.evaluate(tags)
+}
+
+// GetTags returns the tags referenced by the expression.
+func (x *Expression) GetTags() []string {
+	return x.root.collect(make([]string, 0))
+}
+
+func (x *Expression) String() string {
+	return x.source
+}
+
+func (n *exprNode) evaluate(tags []string) bool {
+	switch n.op {
+	case opNot:
+		return !n.operands[0].evaluate(tags)
+	case opAnd:
+		for _, operand := range n.operands {
+			if !operand.evaluate(tags) {
+				return false
+			}
+		}
+		return true
+	case opOr:
+		for _, operand := range n.operands {
+			if operand.evaluate(tags) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, tag := range tags {
+		if tag == n.tag {
+			return true
+		}
+	}
+	return false
+}
+
+func (n *exprNode) collect(tags []string) []string {
+	if n.op == opTag {
+		return appendTag(tags, n.tag)
+	}
+	for _, operand := range n.operands {
+		tags = operand.collect(tags)
+	}
+	return tags
+}
+
+type exprParser struct {
+	tokens []string
+	pos int
+}
+
+func (p *exprParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *exprParser) parseOr() (*exprNode, error) {
+	return p.parseBinary(opOr, p.parseAnd)
+}
+
+func (p *exprParser) parseAnd() (*exprNode, error) {
+	return p.parseBinary(opAnd, p.parseUnary)
+}
+
+func (p *exprParser) parseBinary(op string, parseOperand func() (*exprNode, error)) (*exprNode, error) {
+	first, err := parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	node := &exprNode{ op: op, operands: []*exprNode{ first } }
+	for p.peek() == op {
+		p.pos++
+		next, err := parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		node.operands = append(node.operands, next)
+	}
+	if len(node.operands) == 1 {
+		return first, nil
+	}
+	return node, nil
+}
+
+func (p *exprParser) parseUnary() (*exprNode, error) {
+	token := p.peek()
+	switch token {
+	case "":
+		return nil, fmt.Errorf("unexpected end of expression")
+	case opNot:
+		p.pos++
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &exprNode{ op: opNot, operands: []*exprNode{ operand } }, nil
+	case "(":
+		p.pos++
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		p.pos++
+		return node, nil
+	case ")", opAnd, opOr:
+		return nil, fmt.Errorf("unexpected [%s]", token)
+	}
+	if strings.ContainsAny(token, "&|") {
+		return nil, fmt.Errorf("unknown operator [%s]", token)
+	}
+	p.pos++
+	return &exprNode{ op: opTag, tag: token }, nil
+}
+
+// tokenizeExpression splits the expression into the tags, the operators and
+// the parentheses.
+func tokenizeExpression(source string) []string {
+	tokens := make([]string, 0)
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			tokens = append(tokens, word.String())
+			word.Reset()
+		}
+	}
+	runes := []rune(source)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			flush()
+		case r == '!' || r == '(' || r == ')':
+			flush()
+			tokens = append(tokens, string(r))
+		case (r == '&' || r == '|') && i + 1 < len(runes) && runes[i+1] == r:
+			flush()
+			tokens = append(tokens, string([]rune{ r, r }))
+			i++
+		default:
+			word.WriteRune(r)
+		}
+	}
+	flush()
+	return tokens
+}
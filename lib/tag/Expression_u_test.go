@@ -0,0 +1,32 @@
+package tag
+
+import(
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseExpression(t *testing.T) {
+	TESTCASES := []struct {
+		expression string
+		tags []string
+		ok bool
+	}{
+		{ expression: "(smoke || critical) && !slow", tags: []string{ "smoke" }, ok: true },
+		{ expression: "(smoke || critical) && !slow", tags: []string{ "critical", "slow" }, ok: false },
+		{ expression: "(smoke || critical) && !slow", tags: []string{}, ok: false },
+		{ expression: "!slow", tags: nil, ok: true },
+		{ expression: "smoke || critical && !slow", tags: []string{ "smoke", "slow" }, ok: true },
+		{ expression: "!!api-v1&&db", tags: []string{ "api-v1", "db" }, ok: true },
+	}
+	for i, c := range TESTCASES {
+		expression, err := ParseExpression(c.expression)
+		assert.Nil(t, err, "testcase #%d", i)
+		assert.Equal(t, c.ok, expression.Evaluate(c.tags), "testcase #%d", i)
+	}
+	for _, source := range []string{ "", "(smoke", "smoke &&", "smoke & slow", "smoke slow", "()", "|| smoke" } {
+		_, err := ParseExpression(source)
+		assert.NotNil(t, err, source)
+	}
+	expression, _ := ParseExpression("(smoke || critical) && !smoke")
+	assert.Equal(t, []string{ "smoke", "critical" }, expression.GetTags())
+}
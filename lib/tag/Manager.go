@@ -12,6 +12,7 @@ type ManagerOptions interface {
 type Manager struct {
 	inclusiveTags []string
 	exclusiveTags []string
+	expressions []*Expression
 }
 
 func NewManager(opts ManagerOptions) (ref *Manager, err error) {
@@ -20,12 +21,28 @@ func NewManager(opts ManagerOptions) (ref *Manager, err error) {
 	if opts != nil {
 		conditionalTags = opts.GetConditionalTags()
 	}
-	ref.Initialize(conditionalTags)
+	err = ref.Initialize(conditionalTags)
 	return ref, err
 }
 
 func (g *Manager) IsActive(tags []string) (bool, map[string]int8) {
 	mark := make(map[string]int8, 0)
+	// the expressions are evaluated for the untagged test cases too
+	for _, expression := range g.expressions {
+		matched := expression.Evaluate(tags)
+		for _, tag := range expression.GetTags() {
+			if utils.Contains(tags, tag) {
+				if matched {
+					mark[tag] = +1
+				} else {
+					mark[tag] = -1
+				}
+			}
+		}
+		if !matched {
+			return false, mark
+		}
+	}
 	if len(tags) == 0 {
 		return true, mark
 	}
@@ -49,7 +66,10 @@ func (g *Manager) IsActive(tags []string) (bool, map[string]int8) {
 	return true, mark
 }
 
-func (g *Manager) Parse(tagexps []string) {
+// Parse reads the lists of signed tags (e.g. "+smoke,-slow") and the boolean
+// expressions (e.g. "(smoke || critical) && !slow"), a test case must satisfy
+// all of them.
+func (g *Manager) Parse(tagexps []string) error {
 	if g.exclusiveTags == nil {
 		g.exclusiveTags = make([]string, 0)
 	}
@@ -57,6 +77,14 @@ func (g *Manager) Parse(tagexps []string) {
 		g.inclusiveTags = make([]string, 0)
 	}
 	for _, tagexp := range tagexps {
+		if IsExpression(tagexp) {
+			expression, err := ParseExpression(tagexp)
+			if err != nil {
+				return err
+			}
+			g.expressions = append(g.expressions, expression)
+			continue
+		}
 		signedTags := utils.Split(tagexp, ",")
 		for _, tag := range signedTags {
 			if strings.HasPrefix(tag, "-") {
@@ -66,16 +94,18 @@ func (g *Manager) Parse(tagexps []string) {
 			}
 		}
 	}
+	return nil
 }
 
 func (g *Manager) Reset() {
 	g.inclusiveTags = nil
 	g.exclusiveTags = nil
+	g.expressions = nil
 }
 
-func (g *Manager) Initialize(tagexps []string) {
+func (g *Manager) Initialize(tagexps []string) error {
 	g.Reset()
-	g.Parse(tagexps)
+	return g.Parse(tagexps)
 }
 
 func (g *Manager) GetInclusiveTags() []string {
@@ -86,6 +116,10 @@ func (g *Manager) GetExclusiveTags() []string {
 	return g.exclusiveTags
 }
 
+func (g *Manager) GetExpressions() []*Expression {
+	return g.expressions
+}
+
 func appendTag(tagStore []string, tags ...string) []string {
 	for _, tag := range tags {
 		if len(tag) > 0 && !utils.Contains(tagStore, tag) {
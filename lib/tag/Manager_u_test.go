@@ -89,6 +89,29 @@ func TestManager_IsActive(t *testing.T) {
 					"def": -1,
 				},
 			},
+			{
+				tagExpression: []string{ "(abc || xyz) && !slow" },
+				tags: []string{ "abc", "def" },
+				ok: true,
+				mark: map[string]int8{
+					"abc": 1,
+				},
+			},
+			{
+				tagExpression: []string{ "-def", "!slow" },
+				tags: []string{},
+				ok: true,
+				mark: map[string]int8{},
+			},
+			{
+				tagExpression: []string{ "+abc", "abc && !slow" },
+				tags: []string{ "abc", "slow" },
+				ok: false,
+				mark: map[string]int8{
+					"abc": -1,
+					"slow": -1,
+				},
+			},
 		}
 		for _, TEST := range TESTCASES {
 			ref.Initialize(TEST.tagExpression)
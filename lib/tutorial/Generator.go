@@ -0,0 +1,232 @@
+package tutorial
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"github.com/opwire/opwire-testa/lib/config"
+	"github.com/opwire/opwire-testa/lib/storage"
+	"github.com/opwire/opwire-testa/lib/utils"
+)
+
+type GeneratorOptions interface {
+	GetForce() bool
+}
+
+type Generator struct {
+	force bool
+}
+
+func NewGenerator(opts GeneratorOptions) (ref *Generator, err error) {
+	ref = &Generator{}
+	if opts != nil {
+		ref.force = opts.GetForce()
+	}
+	return ref, err
+}
+
+type Outcome struct {
+	Path string
+	Created bool
+	Error error
+}
+
+func (g *Generator) GetFileNames() []string {
+	names := make([]string, 0, len(exampleFiles))
+	for name := range exampleFiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (g *Generator) Generate(targetDir string) []Outcome {
+	fs := storage.GetFs()
+	outcomes := make([]Outcome, 0)
+	for _, name := range g.GetFileNames() {
+		path := filepath.Join(targetDir, filepath.FromSlash(name))
+		outcome := Outcome{ Path: path }
+		if _, err := fs.Stat(path); !fs.IsNotExist(err) && !g.force {
+			outcome.Error = fmt.Errorf("File already exists, use --force to overwrite")
+			outcomes = append(outcomes, outcome)
+			continue
+		}
+		outcome.Error = writeFile(fs, path, strings.TrimRight(utils.ConvertTabToSpaces(exampleFiles[name], 0), "\n") + "\n")
+		outcome.Created = outcome.Error == nil
+		outcomes = append(outcomes, outcome)
+	}
+	return outcomes
+}
+
+func writeFile(fs storage.Fs, path string, content string) error {
+	if err := fs.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	file, err := fs.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.WriteString(content)
+	return err
+}
+
+var exampleFiles = map[string]string{
+	config.DEFAULT_CONFIG_FILE: `
+	# the testing scripts are loaded from these directories
+	test-dirs:
+	- tests
+	# select the target of the requests with --env=demo
+	environments:
+		demo:
+			pdp: http://localhost:17779
+	`,
+	"tests/examples/echo.yml": `
+	testcases:
+	- title: Echo a JSON payload
+		request:
+			method: POST
+			path: /echo
+			headers:
+			- name: Content-Type
+				value: application/json
+			body: '{"name": "opwire"}'
+		expectation:
+			status-code:
+				is:
+					equal-to: 200
+			body:
+				has-format: json
+				fields:
+				- path: method
+					is:
+						equal-to: POST
+				- path: path
+					is:
+						equal-to: /echo
+				- path: body
+					is:
+						equal-to: '{"name": "opwire"}'
+		tags:
+		- example
+	- title: Capture a response and reuse it
+		request:
+			method: GET
+			path: /echo
+		capture:
+			store-id: first-echo
+		expectation:
+			status-code:
+				is:
+					equal-to: 200
+		tags:
+		- example
+	- title: Send a captured value in the next request
+		request:
+			method: POST
+			path: /echo
+			body: ${{case[first-echo].Body[method]}}
+		expectation:
+			body:
+				has-format: json
+				fields:
+				- path: body
+					is:
+						equal-to: GET
+		tags:
+		- example
+	`,
+	"tests/examples/status.yml": `
+	testcases:
+	- title: Return the requested status code
+		request:
+			method: GET
+			path: /status/404
+		expectation:
+			status-code:
+				is:
+					equal-to: 404
+		tags:
+		- example
+	- title: Accept one of the status codes
+		request:
+			method: GET
+			path: /status/201
+		expectation:
+			status-code:
+				is:
+					member-of: [200, 201, 202]
+		tags:
+		- example
+	- title: A pending testcase is not executed
+		request:
+			method: GET
+			path: /status/500
+		pending: true
+		tags:
+		- example
+	`,
+	"tests/examples/formats.yml": `
+	testcases:
+	- title: Compare a YAML body partially
+		request:
+			method: GET
+			path: /format/yaml
+		expectation:
+			body:
+				has-format: yaml
+				includes: |
+					name: opwire
+		tags:
+		- example
+	- title: Match a text body with a pattern
+		request:
+			method: GET
+			path: /format/text
+		expectation:
+			body:
+				has-format: text
+				match-with: ^Hello.*
+		tags:
+		- example
+	- title: Wait for a slow response
+		request:
+			method: GET
+			path: /delay/100ms
+			timeout: 2s
+		expectation:
+			status-code:
+				is:
+					equal-to: 200
+		tags:
+		- example
+	`,
+	"ci/github-actions.yml": `
+	# Copy this file to .github/workflows/opwire-testa.yml
+	name: opwire-testa
+	on: [push, pull_request]
+	jobs:
+		api-tests:
+			runs-on: ubuntu-latest
+			steps:
+			- uses: actions/checkout@v2
+			- name: Install opwire-testa
+				run: curl https://opwire.org/opwire-testa/install.sh | bash
+			- name: Start the demo server
+				run: ./opwire-testa demo-server &
+			- name: Run the tests
+				run: ./opwire-testa run --no-color --env=demo
+	`,
+	"ci/gitlab-ci.yml": `
+	# Merge this job into your .gitlab-ci.yml
+	api-tests:
+		image: ubuntu:latest
+		before_script:
+			- apt-get update && apt-get install -y curl unzip
+			- curl https://opwire.org/opwire-testa/install.sh | bash
+		script:
+			- ./opwire-testa demo-server &
+			- ./opwire-testa run --no-color --env=demo
+	`,
+}
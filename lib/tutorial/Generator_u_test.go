@@ -0,0 +1,64 @@
+package tutorial
+
+import(
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"gopkg.in/yaml.v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/opwire/opwire-testa/lib/config"
+)
+
+func TestGenerator_Generate(t *testing.T) {
+	targetDir, err := ioutil.TempDir("", "opwire-testa-init-")
+	assert.Nil(t, err)
+	defer os.RemoveAll(targetDir)
+
+	g, err := NewGenerator(nil)
+	assert.Nil(t, err)
+	for _, outcome := range g.Generate(targetDir) {
+		assert.Nil(t, outcome.Error)
+		assert.True(t, outcome.Created)
+	}
+
+	t.Run("Configuration of the project", func(t *testing.T) {
+		loader, err := config.NewLoader(nil)
+		assert.Nil(t, err)
+		cfg, err := loader.Load(filepath.Join(targetDir, config.DEFAULT_CONFIG_FILE))
+		assert.Nil(t, err)
+		assert.Equal(t, []string{ filepath.Join(targetDir, "tests") }, cfg.GetTestDirs())
+		env, err := cfg.GetEnvironment("demo")
+		assert.Nil(t, err)
+		assert.Equal(t, "http://localhost:17779", env.PDP)
+	})
+
+	t.Run("Every example is tagged", func(t *testing.T) {
+		for _, name := range g.GetFileNames() {
+			if !strings.HasPrefix(name, "tests/") {
+				continue
+			}
+			content, err := ioutil.ReadFile(filepath.Join(targetDir, filepath.FromSlash(name)))
+			assert.Nil(t, err)
+			suite := struct {
+				Testcases []struct {
+					Title string `yaml:"title"`
+					Tags []string `yaml:"tags"`
+				} `yaml:"testcases"`
+			}{}
+			assert.Nil(t, yaml.Unmarshal(content, &suite))
+			assert.True(t, len(suite.Testcases) > 0, name)
+			for _, testcase := range suite.Testcases {
+				assert.Contains(t, testcase.Tags, "example", "[%s] of %s", testcase.Title, name)
+			}
+		}
+	})
+
+	t.Run("Existing files are kept", func(t *testing.T) {
+		for _, outcome := range g.Generate(targetDir) {
+			assert.False(t, outcome.Created)
+			assert.NotNil(t, outcome.Error)
+		}
+	})
+}
@@ -0,0 +1,289 @@
+package update
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+	"github.com/opwire/opwire-testa/lib/utils"
+)
+
+const DEFAULT_REPOSITORY string = `opwire/opwire-testa`
+const DEFAULT_API_URL string = `https://api.github.com`
+const CHECKSUMS_ASSET_NAME string = `SHA256SUMS`
+const CHECK_INTERVAL time.Duration = 24 * time.Hour
+
+type UpdaterOptions interface {
+	GetVersion() string
+}
+
+type Updater struct {
+	currentVersion string
+	repository string
+	apiUrl string
+	statePath string
+	httpClient *http.Client
+}
+
+func NewUpdater(opts UpdaterOptions) (ref *Updater, err error) {
+	ref = &Updater{
+		repository: DEFAULT_REPOSITORY,
+		apiUrl: DEFAULT_API_URL,
+		httpClient: &http.Client{ Timeout: 30 * time.Second },
+	}
+	if opts != nil {
+		ref.currentVersion = opts.GetVersion()
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		ref.statePath = filepath.Join(home, ".opwire-testa", "version-check.json")
+	}
+	return ref, nil
+}
+
+func (u *Updater) GetCurrentVersion() string {
+	return u.currentVersion
+}
+
+func (u *Updater) GetLatestRelease() (*Release, error) {
+	return u.getLatestRelease(u.httpClient)
+}
+
+func (u *Updater) getLatestRelease(client *http.Client) (*Release, error) {
+	url := fmt.Sprintf("%s/repos/%s/releases/latest", u.apiUrl, u.repository)
+	content, err := download(client, url)
+	if err != nil {
+		return nil, err
+	}
+	release := &Release{}
+	if err := json.Unmarshal(content, release); err != nil {
+		return nil, err
+	}
+	if len(release.TagName) == 0 {
+		return nil, fmt.Errorf("The latest release has no tag")
+	}
+	return release, nil
+}
+
+// CheckNewVersion returns the latest version if it is newer than the current one.
+// The result is cached for CHECK_INTERVAL so that the check stays non-intrusive,
+// a failed check is also recorded (keeping the version known before), it is
+// not attempted again before the interval.
+func (u *Updater) CheckNewVersion() (string, bool) {
+	if len(u.currentVersion) == 0 {
+		return utils.BLANK, false
+	}
+	state := u.loadState()
+	if state == nil || time.Since(state.CheckedAt) > CHECK_INTERVAL {
+		previous := state
+		state = &versionState{ CheckedAt: time.Now() }
+		release, err := u.getLatestRelease(&http.Client{ Timeout: 2 * time.Second })
+		if err != nil {
+			state.Failed = true
+			if previous != nil {
+				state.LatestVersion = previous.LatestVersion
+			}
+		} else {
+			state.LatestVersion = release.TagName
+		}
+		u.saveState(state)
+	}
+	if len(state.LatestVersion) == 0 {
+		return utils.BLANK, false
+	}
+	if utils.CompareVersions(state.LatestVersion, u.currentVersion) > 0 {
+		return state.LatestVersion, true
+	}
+	return state.LatestVersion, false
+}
+
+// Update downloads the latest release for the current platform, verifies its
+// checksum and replaces the running executable.
+func (u *Updater) Update() (*Release, bool, error) {
+	release, err := u.GetLatestRelease()
+	if err != nil {
+		return nil, false, err
+	}
+	if len(u.currentVersion) > 0 && utils.CompareVersions(release.TagName, u.currentVersion) <= 0 {
+		return release, false, nil
+	}
+
+	artifactName := fmt.Sprintf("opwire-testa-%s-%s-%s.zip", release.TagName, runtime.GOOS, runtime.GOARCH)
+	artifact := release.FindAsset(artifactName)
+	if artifact == nil {
+		return release, false, fmt.Errorf("Release [%s] has no artifact for %s/%s", release.TagName, runtime.GOOS, runtime.GOARCH)
+	}
+	checksums := release.FindAsset(CHECKSUMS_ASSET_NAME)
+	if checksums == nil {
+		return release, false, fmt.Errorf("Release [%s] has no %s file", release.TagName, CHECKSUMS_ASSET_NAME)
+	}
+
+	sums, err := download(u.httpClient, checksums.DownloadUrl)
+	if err != nil {
+		return release, false, err
+	}
+	expected, err := findChecksum(sums, artifactName)
+	if err != nil {
+		return release, false, err
+	}
+
+	archive, err := download(u.httpClient, artifact.DownloadUrl)
+	if err != nil {
+		return release, false, err
+	}
+	digest := sha256.Sum256(archive)
+	if received := hex.EncodeToString(digest[:]); received != expected {
+		return release, false, fmt.Errorf("Checksum mismatch for [%s], expected: %s, received: %s", artifactName, expected, received)
+	}
+
+	binary, err := extractBinary(archive)
+	if err != nil {
+		return release, false, err
+	}
+	if err := replaceExecutable(binary); err != nil {
+		return release, false, err
+	}
+	return release, true, nil
+}
+
+func download(client *http.Client, url string) ([]byte, error) {
+	res, err := client.Get(url)
+	if res != nil && res.Body != nil {
+		defer res.Body.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Downloading [%s] failed with status: %s", url, res.Status)
+	}
+	return ioutil.ReadAll(res.Body)
+}
+
+func (u *Updater) loadState() *versionState {
+	if len(u.statePath) == 0 {
+		return nil
+	}
+	content, err := ioutil.ReadFile(u.statePath)
+	if err != nil {
+		return nil
+	}
+	state := &versionState{}
+	if err := json.Unmarshal(content, state); err != nil {
+		return nil
+	}
+	return state
+}
+
+func (u *Updater) saveState(state *versionState) {
+	if len(u.statePath) == 0 {
+		return
+	}
+	content, err := json.Marshal(state)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(u.statePath), 0755); err != nil {
+		return
+	}
+	ioutil.WriteFile(u.statePath, content, 0644)
+}
+
+type versionState struct {
+	CheckedAt time.Time `json:"checked-at"`
+	LatestVersion string `json:"latest-version"`
+	Failed bool `json:"failed,omitempty"`
+}
+
+type Release struct {
+	TagName string `json:"tag_name"`
+	Assets []ReleaseAsset `json:"assets"`
+}
+
+func (r *Release) FindAsset(name string) *ReleaseAsset {
+	for i := range r.Assets {
+		if r.Assets[i].Name == name {
+			return &r.Assets[i]
+		}
+	}
+	return nil
+}
+
+type ReleaseAsset struct {
+	Name string `json:"name"`
+	DownloadUrl string `json:"browser_download_url"`
+}
+
+func findChecksum(sums []byte, name string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(sums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	return utils.BLANK, fmt.Errorf("Checksum of [%s] not found", name)
+}
+
+func extractBinary(archive []byte) ([]byte, error) {
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return nil, err
+	}
+	binaryName := "opwire-testa"
+	if runtime.GOOS == "windows" {
+		binaryName = binaryName + ".exe"
+	}
+	for _, file := range reader.File {
+		if filepath.Base(file.Name) != binaryName {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return ioutil.ReadAll(rc)
+	}
+	return nil, fmt.Errorf("Binary [%s] not found in the archive", binaryName)
+}
+
+func replaceExecutable(binary []byte) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	executable, err = filepath.EvalSymlinks(executable)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(executable)
+	if err != nil {
+		return err
+	}
+	newPath := executable + ".new"
+	oldPath := executable + ".old"
+	if err := ioutil.WriteFile(newPath, binary, info.Mode()); err != nil {
+		return err
+	}
+	os.Remove(oldPath)
+	if err := os.Rename(executable, oldPath); err != nil {
+		os.Remove(newPath)
+		return err
+	}
+	if err := os.Rename(newPath, executable); err != nil {
+		os.Rename(oldPath, executable)
+		return err
+	}
+	os.Remove(oldPath)
+	return nil
+}
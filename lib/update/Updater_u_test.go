@@ -0,0 +1,58 @@
+package update
+
+import(
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindChecksum(t *testing.T) {
+	sums := []byte("0a1b2c  opwire-testa-v1.0.0-linux-amd64.zip\nDEADBEEF *opwire-testa-v1.0.0-windows-amd64.zip\n")
+
+	sum, err := findChecksum(sums, "opwire-testa-v1.0.0-linux-amd64.zip")
+	assert.Nil(t, err)
+	assert.Equal(t, "0a1b2c", sum)
+
+	sum, err = findChecksum(sums, "opwire-testa-v1.0.0-windows-amd64.zip")
+	assert.Nil(t, err)
+	assert.Equal(t, "deadbeef", sum)
+
+	_, err = findChecksum(sums, "opwire-testa-v1.0.0-darwin-amd64.zip")
+	assert.NotNil(t, err)
+}
+
+func TestUpdater_CheckNewVersion_RecordsFailures(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "opwire-testa-update-")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	u := &Updater{ currentVersion: "v1.0.0", apiUrl: server.URL, repository: DEFAULT_REPOSITORY }
+	u.statePath = filepath.Join(dir, "version-check.json")
+	u.saveState(&versionState{ CheckedAt: time.Now().Add(-2 * CHECK_INTERVAL), LatestVersion: "v1.1.0" })
+
+	latest, ok := u.CheckNewVersion()
+	assert.Equal(t, "v1.1.0", latest)
+	assert.True(t, ok)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+	state := u.loadState()
+	assert.True(t, state.Failed)
+	assert.Equal(t, "v1.1.0", state.LatestVersion)
+
+	// the failed check is not attempted again before the interval
+	u.CheckNewVersion()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+}
@@ -10,6 +10,9 @@ const BLANK string = ""
 const BODY_FORMAT_FLAT = `text`
 const BODY_FORMAT_JSON = `json`
 const BODY_FORMAT_YAML = `yaml`
+const BODY_FORMAT_XML = `xml`
+const BODY_FORMAT_HTML = `html`
+const BODY_FORMAT_CSV = `csv`
 
 const DEFAULT_PDP string = `http://localhost:17779`
 const DEFAULT_PATH string = `/-`
@@ -1,8 +1,10 @@
 package utils
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"gopkg.in/yaml.v2"
 )
 
@@ -11,7 +13,57 @@ func Unmarshal(format string, source []byte, target interface{}) error {
 		return json.Unmarshal(source, target)
 	}
 	if format == BODY_FORMAT_YAML {
-		return yaml.Unmarshal(source, target)
+		if err := yaml.Unmarshal(source, target); err != nil {
+			return err
+		}
+		if obj, ok := target.(*map[string]interface{}); ok && *obj != nil {
+			*obj = NormalizeYaml(*obj).(map[string]interface{})
+		}
+		return nil
 	}
 	return fmt.Errorf("Invalid body format: %s", format)
 }
+
+// UnmarshalYamlDocuments decodes every document of a (multi-document) YAML
+// stream, the empty documents are skipped.
+func UnmarshalYamlDocuments(source []byte) ([]interface{}, error) {
+	docs := make([]interface{}, 0)
+	decoder := yaml.NewDecoder(bytes.NewReader(source))
+	for {
+		var doc interface{}
+		if err := decoder.Decode(&doc); err != nil {
+			if err == io.EOF {
+				return docs, nil
+			}
+			return nil, err
+		}
+		if doc != nil {
+			docs = append(docs, NormalizeYaml(doc))
+		}
+	}
+}
+
+// NormalizeYaml converts the nested mappings decoded by yaml.v2 into the
+// map[string]interface{} used by the JSON decoder, so that both formats are
+// compared and flattened the same way.
+func NormalizeYaml(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[fmt.Sprintf("%v", key)] = NormalizeYaml(item)
+		}
+		return result
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = NormalizeYaml(item)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = NormalizeYaml(item)
+		}
+		return v
+	}
+	return value
+}
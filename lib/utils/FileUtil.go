@@ -2,6 +2,7 @@ package utils
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 	"github.com/opwire/opwire-testa/lib/storage"
 )
@@ -14,6 +15,24 @@ func IsDir(name string) bool {
 	return false
 }
 
+func IsFile(name string) bool {
+	fs := storage.GetFs()
+	if stat, err := fs.Stat(name); err == nil {
+		return !stat.IsDir()
+	}
+	return false
+}
+
+// IsTerminal reports whether the file is a character device (a terminal),
+// rather than a pipe or a regular file.
+func IsTerminal(file *os.File) bool {
+	stat, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return stat.Mode() & os.ModeCharDevice != 0
+}
+
 func FindWorkingDir() string {
 	fs := storage.GetFs()
 	dir, err := fs.Getwd()
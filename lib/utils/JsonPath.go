@@ -0,0 +1,195 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// EvaluateJsonPath resolves a JSONPath expression composed of child members
+// ($.data.items, $['data']) and array indexes ($.items[0], $.items[-1]).
+func EvaluateJsonPath(path string, doc interface{}) (interface{}, bool, error) {
+	steps, err := parseJsonPath(path)
+	if err != nil {
+		return nil, false, err
+	}
+	current := doc
+	for _, step := range steps {
+		var found bool
+		if step.isWildcard {
+			return nil, false, fmt.Errorf("JSONPath [%s] must not contain a wildcard", path)
+		}
+		if step.isIndex {
+			current, found = selectIndex(current, step.index)
+		} else {
+			current, found = selectMember(current, step.name)
+		}
+		if !found {
+			return nil, false, nil
+		}
+	}
+	return current, true, nil
+}
+
+// RemoveJsonPath deletes the members designated by the path from the document
+// and returns how many were removed, the wildcard ($.items[*].id) selects
+// every element of an array or every member of an object.
+func RemoveJsonPath(path string, doc interface{}) (int, error) {
+	steps, err := parseJsonPath(path)
+	if err != nil {
+		return 0, err
+	}
+	if len(steps) == 0 || steps[len(steps)-1].isIndex {
+		return 0, fmt.Errorf("JSONPath [%s] must end with a member name", path)
+	}
+	return removeSteps(doc, steps), nil
+}
+
+func removeSteps(node interface{}, steps []jsonPathStep) int {
+	step := steps[0]
+	if len(steps) == 1 {
+		return removeMember(node, step)
+	}
+	total := 0
+	if step.isWildcard {
+		switch obj := node.(type) {
+		case map[string]interface{}:
+			for _, child := range obj {
+				total += removeSteps(child, steps[1:])
+			}
+		case map[interface{}]interface{}:
+			for _, child := range obj {
+				total += removeSteps(child, steps[1:])
+			}
+		case []interface{}:
+			for _, child := range obj {
+				total += removeSteps(child, steps[1:])
+			}
+		}
+		return total
+	}
+	var child interface{}
+	var found bool
+	if step.isIndex {
+		child, found = selectIndex(node, step.index)
+	} else {
+		child, found = selectMember(node, step.name)
+	}
+	if found {
+		total += removeSteps(child, steps[1:])
+	}
+	return total
+}
+
+func removeMember(node interface{}, step jsonPathStep) int {
+	switch obj := node.(type) {
+	case map[string]interface{}:
+		if step.isWildcard {
+			total := len(obj)
+			for key := range obj {
+				delete(obj, key)
+			}
+			return total
+		}
+		if _, ok := obj[step.name]; ok {
+			delete(obj, step.name)
+			return 1
+		}
+	case map[interface{}]interface{}:
+		if step.isWildcard {
+			total := len(obj)
+			for key := range obj {
+				delete(obj, key)
+			}
+			return total
+		}
+		if _, ok := obj[step.name]; ok {
+			delete(obj, step.name)
+			return 1
+		}
+	}
+	return 0
+}
+
+type jsonPathStep struct {
+	name string
+	index int
+	isIndex bool
+	isWildcard bool
+}
+
+func parseJsonPath(path string) ([]jsonPathStep, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("JSONPath [%s] must start with [$]", path)
+	}
+	steps := make([]jsonPathStep, 0)
+	rest := path[1:]
+	for len(rest) > 0 {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("JSONPath [%s] has an empty member name", path)
+			}
+			if rest[:end] == "*" {
+				steps = append(steps, jsonPathStep{ isWildcard: true })
+			} else {
+				steps = append(steps, jsonPathStep{ name: rest[:end] })
+			}
+			rest = rest[end:]
+		case '[':
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("JSONPath [%s] has an unclosed bracket", path)
+			}
+			selector := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+			if len(selector) >= 2 && (selector[0] == '\'' || selector[0] == '"') && selector[len(selector)-1] == selector[0] {
+				steps = append(steps, jsonPathStep{ name: selector[1:len(selector)-1] })
+				continue
+			}
+			if selector == "*" {
+				steps = append(steps, jsonPathStep{ isWildcard: true })
+				continue
+			}
+			index, err := strconv.Atoi(selector)
+			if err != nil {
+				return nil, fmt.Errorf("JSONPath [%s] has an invalid selector [%s]", path, selector)
+			}
+			steps = append(steps, jsonPathStep{ index: index, isIndex: true })
+		default:
+			return nil, fmt.Errorf("JSONPath [%s] is invalid at [%s]", path, rest)
+		}
+	}
+	return steps, nil
+}
+
+func selectMember(node interface{}, name string) (interface{}, bool) {
+	switch obj := node.(type) {
+	case map[string]interface{}:
+		val, ok := obj[name]
+		return val, ok
+	case map[interface{}]interface{}:
+		val, ok := obj[name]
+		return val, ok
+	}
+	return nil, false
+}
+
+func selectIndex(node interface{}, index int) (interface{}, bool) {
+	list, ok := node.([]interface{})
+	if !ok {
+		return nil, false
+	}
+	if index < 0 {
+		index = len(list) + index
+	}
+	if index < 0 || index >= len(list) {
+		return nil, false
+	}
+	return list[index], true
+}
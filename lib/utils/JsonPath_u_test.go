@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"encoding/json"
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluateJsonPath(t *testing.T) {
+	var doc interface{}
+	json.Unmarshal([]byte(`{"data": {"items": [{"id": 1}, {"id": 2}], "full name": "opwire"}}`), &doc)
+
+	TESTCASES := []struct {
+		path string
+		value interface{}
+		found bool
+		failed bool
+	}{
+		{ path: "$", value: doc, found: true },
+		{ path: "$.data.items[0].id", value: float64(1), found: true },
+		{ path: "$.data.items[-1].id", value: float64(2), found: true },
+		{ path: "$['data']['full name']", value: "opwire", found: true },
+		{ path: "$.data.items[2].id", found: false },
+		{ path: "$.data.missing", found: false },
+		{ path: "data.items", failed: true },
+		{ path: "$.data.items[x]", failed: true },
+		{ path: "$.data.items[0", failed: true },
+	}
+	for _, c := range TESTCASES {
+		value, found, err := EvaluateJsonPath(c.path, doc)
+		if c.failed {
+			assert.NotNil(t, err, c.path)
+			continue
+		}
+		assert.Nil(t, err, c.path)
+		assert.Equal(t, c.found, found, c.path)
+		if c.found {
+			assert.Equal(t, c.value, value, c.path)
+		}
+	}
+}
+
+func TestRemoveJsonPath(t *testing.T) {
+	TESTCASES := []struct {
+		path string
+		removed int
+		expected string
+		failed bool
+	}{
+		{ path: "$.timestamp", removed: 1, expected: `{"data":{"items":[{"id":1,"at":"x"},{"id":2,"at":"y"}]},"request_id":"r1"}` },
+		{ path: "$.data.items[*].at", removed: 2, expected: `{"data":{"items":[{"id":1},{"id":2}]},"request_id":"r1","timestamp":1}` },
+		{ path: "$.data.items[0].at", removed: 1, expected: `{"data":{"items":[{"id":1},{"id":2,"at":"y"}]},"request_id":"r1","timestamp":1}` },
+		{ path: "$['request_id']", removed: 1, expected: `{"data":{"items":[{"id":1,"at":"x"},{"id":2,"at":"y"}]},"timestamp":1}` },
+		{ path: "$.missing", removed: 0, expected: `{"data":{"items":[{"id":1,"at":"x"},{"id":2,"at":"y"}]},"request_id":"r1","timestamp":1}` },
+		{ path: "$.data.items[0]", failed: true },
+		{ path: "$", failed: true },
+	}
+	for _, c := range TESTCASES {
+		var doc, expected interface{}
+		json.Unmarshal([]byte(`{"data":{"items":[{"id":1,"at":"x"},{"id":2,"at":"y"}]},"request_id":"r1","timestamp":1}`), &doc)
+		removed, err := RemoveJsonPath(c.path, doc)
+		if c.failed {
+			assert.NotNil(t, err, c.path)
+			continue
+		}
+		assert.Nil(t, err, c.path)
+		assert.Equal(t, c.removed, removed, c.path)
+		json.Unmarshal([]byte(c.expected), &expected)
+		assert.Equal(t, expected, doc, c.path)
+	}
+}
@@ -3,6 +3,7 @@ package utils
 import (
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -14,6 +15,68 @@ func StandardizeVersion(version string) string {
 	return versionRe.ReplaceAllString(version, `$1`)
 }
 
+func CompareVersions(v1 string, v2 string) int {
+	p1 := splitVersion(v1)
+	p2 := splitVersion(v2)
+	for i := 0; i < len(p1) || i < len(p2); i++ {
+		var n1, n2 int
+		if i < len(p1) {
+			n1 = p1[i]
+		}
+		if i < len(p2) {
+			n2 = p2[i]
+		}
+		if n1 < n2 {
+			return -1
+		}
+		if n1 > n2 {
+			return 1
+		}
+	}
+	return 0
+}
+
+func splitVersion(version string) []int {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if pos := strings.IndexAny(version, "-+"); pos >= 0 {
+		version = version[:pos]
+	}
+	parts := make([]int, 0)
+	for _, part := range strings.Split(version, ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			break
+		}
+		parts = append(parts, n)
+	}
+	return parts
+}
+
+var byteSizeRe = regexp.MustCompile(`^(?i)\s*([0-9]+)\s*(B|KB|MB|GB)?\s*$`)
+
+var byteSizeUnits = map[string]int64{
+	"": 1,
+	"B": 1,
+	"KB": 1 << 10,
+	"MB": 1 << 20,
+	"GB": 1 << 30,
+}
+
+func ParseByteSize(size string) (int64, error) {
+	if len(strings.TrimSpace(size)) == 0 {
+		return 0, nil
+	}
+	parts := byteSizeRe.FindStringSubmatch(size)
+	if parts == nil {
+		return 0, fmt.Errorf("Byte size [%s] is invalid", size)
+	}
+	number, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return number * byteSizeUnits[strings.ToUpper(parts[2])], nil
+}
+
 var tagRe = regexp.MustCompile(`^` + strings.ReplaceAll(TAG_PATTERN, `\\`, `\`) + `$`)
 
 var tagCharRe = regexp.MustCompile(TAG_CHAR_PATTERN)
@@ -107,3 +170,13 @@ func AppendBlockWithIndent(array []string, block string, indent int) []string {
 	}
 	return array
 }
+
+// Redact replaces every occurrence of the secrets with the mask.
+func Redact(text string, secrets []string, mask string) string {
+	for _, secret := range secrets {
+		if len(secret) > 0 {
+			text = strings.Replace(text, secret, mask, -1)
+		}
+	}
+	return text
+}
@@ -48,6 +48,76 @@ func TestStandardizeVersion(t *testing.T) {
 	}
 }
 
+func TestCompareVersions(t *testing.T) {
+	TESTCASES := []struct {
+		v1 string
+		v2 string
+		result int
+	}{
+		{
+			v1: "v1.2.3",
+			v2: "1.2.3",
+			result: 0,
+		},
+		{
+			v1: "1.2",
+			v2: "1.2.0",
+			result: 0,
+		},
+		{
+			v1: "v1.2.3",
+			v2: "v1.10.0",
+			result: -1,
+		},
+		{
+			v1: "v2.0.0-hotfix1",
+			v2: "v1.9.9",
+			result: 1,
+		},
+	}
+	for _, TEST := range TESTCASES {
+		assert.Equal(t, TEST.result, CompareVersions(TEST.v1, TEST.v2))
+	}
+}
+
+func TestParseByteSize(t *testing.T) {
+	TESTCASES := []struct {
+		size string
+		bytes int64
+		hasError bool
+	}{
+		{
+			size: "",
+			bytes: 0,
+		},
+		{
+			size: "512",
+			bytes: 512,
+		},
+		{
+			size: "64KB",
+			bytes: 64 * 1024,
+		},
+		{
+			size: "10 mb",
+			bytes: 10 * 1024 * 1024,
+		},
+		{
+			size: "1.5GB",
+			hasError: true,
+		},
+	}
+	for _, TEST := range TESTCASES {
+		bytes, err := ParseByteSize(TEST.size)
+		assert.Equal(t, TEST.bytes, bytes)
+		if TEST.hasError {
+			assert.NotNil(t, err)
+		} else {
+			assert.Nil(t, err)
+		}
+	}
+}
+
 func TestStandardizeTagLabel(t *testing.T) {
 	TESTCASES := []struct {
 		label string
@@ -95,3 +165,10 @@ func TestStandardizeTagLabel(t *testing.T) {
 		}
 	}
 }
+
+func TestRedact(t *testing.T) {
+	text := "Authorization: Bearer abc123, retry with abc123"
+	assert.Equal(t, "Authorization: Bearer ***, retry with ***", Redact(text, []string{"abc123"}, "***"))
+	assert.Equal(t, text, Redact(text, []string{""}, "***"))
+	assert.Equal(t, text, Redact(text, nil, "***"))
+}
@@ -0,0 +1,16 @@
+package utils
+
+import(
+	"regexp"
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateUUID(t *testing.T) {
+	re := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	first, err := GenerateUUID()
+	assert.Nil(t, err)
+	assert.True(t, re.MatchString(first))
+	second, _ := GenerateUUID()
+	assert.NotEqual(t, first, second)
+}
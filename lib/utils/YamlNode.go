@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"strings"
+	"gopkg.in/yaml.v3"
+)
+
+// LocateTestCases finds the block sequence of the testcases in the text of a
+// script file, with the line (1-based) where the testcases end.
+func LocateTestCases(content []byte) (*yaml.Node, int, error) {
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(content, doc); err != nil {
+		return nil, 0, err
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil, 0, nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, 0, nil
+	}
+	endLine := strings.Count(string(content), "\n") + 2
+	for i := 0; i + 1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != "testcases" {
+			continue
+		}
+		value := root.Content[i + 1]
+		if value.Kind != yaml.SequenceNode || value.Style & yaml.FlowStyle != 0 {
+			return nil, 0, nil
+		}
+		if i + 2 < len(root.Content) {
+			endLine = root.Content[i + 2].Line
+		}
+		return value, endLine, nil
+	}
+	return nil, 0, nil
+}
+
+// FindTestCaseEnd returns the index of the line following the i-th testcase,
+// its trailing blank lines and comments are excluded.
+func FindTestCaseEnd(lines []string, testcases *yaml.Node, i int, endLine int) int {
+	end := endLine - 1
+	if i + 1 < len(testcases.Content) {
+		end = testcases.Content[i + 1].Line - 1
+	}
+	if end > len(lines) {
+		end = len(lines)
+	}
+	for end > testcases.Content[i].Line && isBlankYamlLine(lines[end - 1]) {
+		end--
+	}
+	return end
+}
+
+func isBlankYamlLine(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return len(trimmed) == 0 || strings.HasPrefix(trimmed, "#")
+}